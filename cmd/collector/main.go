@@ -11,10 +11,10 @@ import (
 
 	"github.com/sliink/collector/internal/api"
 	"github.com/sliink/collector/internal/core"
-	"github.com/sliink/collector/internal/model"
-	"github.com/sliink/collector/internal/plugin/inputs"
-	"github.com/sliink/collector/internal/plugin/outputs"
-	"github.com/sliink/collector/internal/plugin/processors"
+	"github.com/sliink/collector/internal/plugin"
+	_ "github.com/sliink/collector/internal/plugin/inputs"
+	_ "github.com/sliink/collector/internal/plugin/outputs"
+	_ "github.com/sliink/collector/internal/plugin/processors"
 	"github.com/spf13/cobra"
 )
 
@@ -154,40 +154,39 @@ func configurePipeline(c *core.Core) error {
 		pipelines, ok := configManager.GetConfig("pipelines", nil).(map[string]interface{})
 		if ok {
 			// Configure each pipeline
-			for pipelineType, pipelineConfig := range pipelines {
+			for pipelineName, pipelineConfig := range pipelines {
 				config, ok := pipelineConfig.(map[string]interface{})
 				if !ok {
-					continue
+					return fmt.Errorf("pipeline %s must be an object", pipelineName)
 				}
 
-				// Get processors for this pipeline
-				var processorIDs []string
-				if processors, ok := config["processors"].([]interface{}); ok {
-					for _, processorID := range processors {
-						if id, ok := processorID.(string); ok {
-							processorIDs = append(processorIDs, id)
-						}
-					}
+				// Get processors, inputs and outputs for this pipeline
+				processorIDs, err := stringList(config, "processors")
+				if err != nil {
+					return fmt.Errorf("pipeline %s: %w", pipelineName, err)
+				}
+
+				inputIDs, err := stringList(config, "inputs")
+				if err != nil {
+					return fmt.Errorf("pipeline %s: %w", pipelineName, err)
 				}
 
-				// Create the pipeline
-				var telemetryType model.TelemetryType
-				switch pipelineType {
-				case "logs":
-					telemetryType = model.LogTelemetryType
-				case "metrics":
-					telemetryType = model.MetricTelemetryType
-				case "traces":
-					telemetryType = model.TraceTelemetryType
-				default:
-					continue
+				outputIDs, err := stringList(config, "outputs")
+				if err != nil {
+					return fmt.Errorf("pipeline %s: %w", pipelineName, err)
 				}
 
+				// Create the pipeline, named <type> or <type>/<name>
 				if len(processorIDs) > 0 {
-					if err := pipeline.CreatePipeline(telemetryType, processorIDs); err != nil {
-						return fmt.Errorf("failed to create %s pipeline: %w", pipelineType, err)
+					if err := pipeline.CreatePipeline(pipelineName, processorIDs); err != nil {
+						return fmt.Errorf("failed to create %s pipeline: %w", pipelineName, err)
 					}
 				}
+
+				// Route the pipeline from its inputs to its outputs
+				if err := pipeline.SetRoute(pipelineName, inputIDs, outputIDs); err != nil {
+					return fmt.Errorf("failed to route %s pipeline: %w", pipelineName, err)
+				}
 			}
 
 			return nil
@@ -195,7 +194,7 @@ func configurePipeline(c *core.Core) error {
 	}
 
 	// If no pipeline configuration was found, configure a simple log pipeline
-	err := pipeline.CreatePipeline(model.LogTelemetryType, []string{"log_parser"})
+	err := pipeline.CreatePipeline("logs", []string{"log_parser"})
 	if err != nil {
 		return fmt.Errorf("failed to create log pipeline: %w", err)
 	}
@@ -203,25 +202,116 @@ func configurePipeline(c *core.Core) error {
 	return nil
 }
 
+// stringList reads a list of strings from a pipeline config entry
+func stringList(config map[string]interface{}, key string) ([]string, error) {
+	raw, exists := config[key]
+	if !exists || raw == nil {
+		return nil, nil
+	}
+
+	items, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be a list", key)
+	}
+
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		id, ok := item.(string)
+		if !ok || id == "" {
+			return nil, fmt.Errorf("%s must contain plugin IDs", key)
+		}
+		result = append(result, id)
+	}
+
+	return result, nil
+}
+
 func registerPlugins(c *core.Core) error {
-	// Create input plugins
-	fileInput := inputs.NewFileInput("file_input")
+	// Use the plugins section of the loaded config, or build one from the command-line flags
+	pluginsConfig := defaultPluginsConfig()
+
+	configManager := c.GetConfigManager()
+	if configManager != nil {
+		if conf, ok := configManager.GetConfig("plugins", nil).(map[string]interface{}); ok {
+			pluginsConfig = conf
+		}
+	}
+
+	// Create, configure and register plugins with core
+	if _, err := plugin.LoadStandardPlugins(pluginsConfig, c); err != nil {
+		return err
+	}
+
+	// Configure buffers, retries and dead-letter sinks for outputs
+	if err := configureDelivery(c, pluginsConfig); err != nil {
+		return err
+	}
+
+	// Configure pipeline
+	if err := configurePipeline(c); err != nil {
+		return err
+	}
 
+	return nil
+}
+
+// configureDelivery applies the buffer, retry and dead_letter settings of each output entry
+func configureDelivery(c *core.Core, pluginsConfig map[string]interface{}) error {
+	outputs, _ := pluginsConfig["outputs"].([]interface{})
+	for _, entry := range outputs {
+		entryMap, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id, _ := entryMap["id"].(string)
+
+		if bufferConf, ok := entryMap["buffer"].(map[string]interface{}); ok {
+			limits, err := core.ParseBufferLimits(bufferConf)
+			if err != nil {
+				return fmt.Errorf("output %s: %w", id, err)
+			}
+			if err := c.SetBufferLimits(id, limits); err != nil {
+				return fmt.Errorf("output %s: %w", id, err)
+			}
+		}
+
+		if retryConf, ok := entryMap["retry"].(map[string]interface{}); ok {
+			policy, err := core.ParseRetryPolicy(retryConf)
+			if err != nil {
+				return fmt.Errorf("output %s: %w", id, err)
+			}
+			if err := c.SetRetryPolicy(id, policy); err != nil {
+				return fmt.Errorf("output %s: %w", id, err)
+			}
+		}
+
+		if deadLetterConf, ok := entryMap["dead_letter"].(map[string]interface{}); ok {
+			sink := core.DeadLetterSink{}
+			sink.Path, _ = deadLetterConf["path"].(string)
+			sink.OutputID, _ = deadLetterConf["output"].(string)
+			if err := c.SetDeadLetterSink(id, sink); err != nil {
+				return fmt.Errorf("output %s: %w", id, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// defaultPluginsConfig builds the plugins section used when no config file provides one
+func defaultPluginsConfig() map[string]interface{} {
 	// Configure file input
 	fileInputConfig := map[string]interface{}{
-		"paths": []interface{}{},
+		"paths":   []interface{}{""},
+		"enabled": false,
 	}
 
 	if inputFile != "" {
 		fileInputConfig["paths"] = []interface{}{inputFile}
-	} else {
-		fileInputConfig["paths"] = []interface{}{""}
+		fileInputConfig["enabled"] = true
 	}
 
-	fileInput.Configure(fileInputConfig)
-
-	// Create and configure Docker Compose input plugin
-	dockerComposeInput := inputs.NewDockerComposeInput("docker_compose_input")
+	// Configure Docker Compose input
 	dockerComposeConfig := map[string]interface{}{
 		"project_name": "",
 		"services":     []interface{}{},
@@ -229,10 +319,6 @@ func registerPlugins(c *core.Core) error {
 		"tail":         "100",
 		"timestamps":   true,
 	}
-	dockerComposeInput.Configure(dockerComposeConfig)
-
-	// Create processor plugins
-	parser := processors.NewParser("log_parser")
 
 	// Configure parser
 	parserConfig := map[string]interface{}{
@@ -244,12 +330,6 @@ func registerPlugins(c *core.Core) error {
 		},
 	}
 
-	parser.Configure(parserConfig)
-
-	// Create output plugins
-	// Use stdout output
-	stdoutOutput := outputs.NewStdoutOutput("stdout_output")
-
 	// Configure stdout output
 	stdoutOutputConfig := map[string]interface{}{
 		"colorize": colorize,
@@ -260,29 +340,35 @@ func registerPlugins(c *core.Core) error {
 		stdoutOutputConfig["format"] = "json"
 	}
 
-	stdoutOutput.Configure(stdoutOutputConfig)
-
-	// Register plugins with core
-	if err := c.RegisterPlugin(fileInput); err != nil {
-		return err
+	outputs := []interface{}{
+		map[string]interface{}{"id": "stdout_output", "type": "stdout", "config": stdoutOutputConfig},
 	}
 
-	if err := c.RegisterPlugin(dockerComposeInput); err != nil {
-		return err
-	}
+	// Write to files when an output directory is given, unless --stdout asks for stdout instead
+	if outputDir != "" && !stdout {
+		fileOutputConfig := map[string]interface{}{
+			"output_dir":       outputDir,
+			"filename_pattern": "collector-%Y%m%d.log",
+			"rotate_interval":  "24h",
+		}
 
-	if err := c.RegisterPlugin(parser); err != nil {
-		return err
-	}
+		if jsonFormat {
+			fileOutputConfig["format"] = "ndjson"
+		}
 
-	if err := c.RegisterPlugin(stdoutOutput); err != nil {
-		return err
+		outputs = []interface{}{
+			map[string]interface{}{"id": "file_output", "type": "file", "config": fileOutputConfig},
+		}
 	}
 
-	// Configure pipeline
-	if err := configurePipeline(c); err != nil {
-		return err
+	return map[string]interface{}{
+		"inputs": []interface{}{
+			map[string]interface{}{"id": "file_input", "type": "file", "config": fileInputConfig},
+			map[string]interface{}{"id": "docker_compose_input", "type": "docker_compose", "config": dockerComposeConfig},
+		},
+		"processors": []interface{}{
+			map[string]interface{}{"id": "log_parser", "type": "parser", "config": parserConfig},
+		},
+		"outputs": outputs,
 	}
-
-	return nil
 }
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/sliink/collector/internal/core"
@@ -22,6 +24,105 @@ func TestRegisterPlugins(t *testing.T) {
 		success = c.Stop()
 		assert.True(t, success)
 	})
+
+	t.Run("Plugins are created from the config file", func(t *testing.T) {
+		c := core.NewCore()
+		assert.True(t, c.Initialize())
+		defer c.Stop()
+
+		assert.NoError(t, c.GetConfigManager().LoadConfig(filepath.Join("..", "..", "config", "socket_pipeline.json")))
+		assert.NoError(t, registerPlugins(c))
+
+		for _, id := range []string{"socket_input", "log_parser", "stdout_output"} {
+			_, exists := c.GetComponent(id)
+			assert.True(t, exists, "expected plugin %s to be registered", id)
+		}
+	})
+
+	t.Run("Unknown plugin types are reported", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "config.json")
+		err := os.WriteFile(configPath, []byte(`{"plugins": {"outputs": [{"id": "out", "type": "nope"}]}}`), 0644)
+		assert.NoError(t, err)
+
+		c := core.NewCore()
+		assert.True(t, c.Initialize())
+		defer c.Stop()
+
+		assert.NoError(t, c.GetConfigManager().LoadConfig(configPath))
+		err = registerPlugins(c)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown output plugin: nope")
+	})
+
+	t.Run("Pipelines referencing unknown plugins are reported", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "config.json")
+		config := `{
+			"plugins": {"outputs": [{"id": "stdout_output", "type": "stdout"}]},
+			"pipelines": {"logs": {"inputs": ["missing_input"], "outputs": ["stdout_output"]}}
+		}`
+		assert.NoError(t, os.WriteFile(configPath, []byte(config), 0644))
+
+		c := core.NewCore()
+		assert.True(t, c.Initialize())
+		defer c.Stop()
+
+		assert.NoError(t, c.GetConfigManager().LoadConfig(configPath))
+		err := registerPlugins(c)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "input plugin not found: missing_input")
+	})
+
+	t.Run("Output retry and dead-letter settings are applied", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "config.json")
+		config := `{
+			"plugins": {"outputs": [
+				{"id": "primary", "type": "stdout", "retry": {"max_attempts": 5, "initial_backoff": "2s"}, "dead_letter": {"output": "fallback"}},
+				{"id": "fallback", "type": "stdout", "retry": {"max_attempts": 0}}
+			]}
+		}`
+		assert.NoError(t, os.WriteFile(configPath, []byte(config), 0644))
+
+		c := core.NewCore()
+		assert.True(t, c.Initialize())
+		defer c.Stop()
+
+		assert.NoError(t, c.GetConfigManager().LoadConfig(configPath))
+		err := registerPlugins(c)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "output fallback: max_attempts must be at least 1")
+
+		policy := c.GetRetryPolicy("primary")
+		assert.Equal(t, 5, policy.MaxAttempts)
+
+		sink, exists := c.GetDeadLetterQueue().GetSink("primary")
+		assert.True(t, exists)
+		assert.Equal(t, "fallback", sink.OutputID)
+	})
+
+	t.Run("Output buffer settings are applied", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "config.json")
+		config := `{
+			"plugins": {"outputs": [
+				{"id": "stdout_output", "type": "stdout", "buffer": {"max_batches": 20, "overflow": "drop_oldest"}},
+				{"id": "other_output", "type": "stdout", "buffer": {"overflow": "spill_to_disk"}}
+			]}
+		}`
+		assert.NoError(t, os.WriteFile(configPath, []byte(config), 0644))
+
+		c := core.NewCore()
+		assert.True(t, c.Initialize())
+		defer c.Stop()
+
+		assert.NoError(t, c.GetConfigManager().LoadConfig(configPath))
+		err := registerPlugins(c)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "output other_output: spill_dir is required for spill_to_disk")
+
+		component, _ := c.GetComponent("buffer_manager")
+		limits := component.(*core.BufferManager).GetLimits("stdout_output")
+		assert.Equal(t, 20, limits.MaxBatches)
+		assert.Equal(t, core.OverflowDropOldest, limits.Overflow)
+	})
 }
 
 // This is a minimal test suite for the main package
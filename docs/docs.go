@@ -221,6 +221,246 @@ const docTemplate = `{
                 }
             }
         },
+        "/deadletters": {
+            "get": {
+                "description": "List batches that exhausted their retries, optionally filtered by output",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "deadletters"
+                ],
+                "summary": "Get dead-lettered batches",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Output plugin ID",
+                        "name": "output",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "object",
+                                "additionalProperties": true
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/deadletters/replay": {
+            "post": {
+                "description": "Move all dead-lettered batches, optionally for one output, back into their buffers",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "deadletters"
+                ],
+                "summary": "Replay dead-lettered batches",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Output plugin ID",
+                        "name": "output",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/deadletters/{id}": {
+            "get": {
+                "description": "Get a dead-lettered batch including its data points",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "deadletters"
+                ],
+                "summary": "Get a dead-lettered batch",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Dead-letter entry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove a dead-lettered batch without replaying it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "deadletters"
+                ],
+                "summary": "Discard a dead-lettered batch",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Dead-letter entry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/deadletters/{id}/replay": {
+            "post": {
+                "description": "Move a dead-lettered batch back into its output's buffer",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "deadletters"
+                ],
+                "summary": "Replay a dead-lettered batch",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Dead-letter entry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
         "/health": {
             "get": {
                 "description": "Check if the API is running",
@@ -247,7 +487,7 @@ const docTemplate = `{
         },
         "/pipelines": {
             "get": {
-                "description": "Get information about all data pipelines",
+                "description": "Get information about all data pipelines, optionally filtered by telemetry type",
                 "consumes": [
                     "application/json"
                 ],
@@ -258,6 +498,14 @@ const docTemplate = `{
                     "pipelines"
                 ],
                 "summary": "Get all pipelines",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pipeline type (logs, metrics, traces)",
+                        "name": "type",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -265,11 +513,20 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
             },
             "post": {
-                "description": "Create a new data pipeline",
+                "description": "Create or update a data pipeline named \u003ctype\u003e or \u003ctype\u003e/\u003cname\u003e",
                 "consumes": [
                     "application/json"
                 ],
@@ -282,7 +539,7 @@ const docTemplate = `{
                 "summary": "Create a pipeline",
                 "parameters": [
                     {
-                        "description": "Pipeline configuration",
+                        "description": "Pipeline configuration with name, processors, inputs and outputs",
                         "name": "pipeline",
                         "in": "body",
                         "required": true,
@@ -297,9 +554,7 @@ const docTemplate = `{
                         "description": "Created",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "additionalProperties": true
                         }
                     },
                     "400": {
@@ -325,7 +580,7 @@ const docTemplate = `{
         },
         "/pipelines/{type}": {
             "get": {
-                "description": "Get information about a specific pipeline by telemetry type",
+                "description": "Get information about the default pipeline of a telemetry type",
                 "consumes": [
                     "application/json"
                 ],
@@ -353,6 +608,15 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -365,7 +629,7 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Delete a data pipeline by telemetry type",
+                "description": "Delete the default data pipeline of a telemetry type",
                 "consumes": [
                     "application/json"
                 ],
@@ -395,6 +659,140 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/pipelines/{type}/{name}": {
+            "get": {
+                "description": "Get information about a named pipeline such as logs/app",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pipelines"
+                ],
+                "summary": "Get pipeline by name",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pipeline type (logs, metrics, traces)",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Pipeline name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a named data pipeline such as logs/app",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pipelines"
+                ],
+                "summary": "Delete a named pipeline",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pipeline type (logs, metrics, traces)",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Pipeline name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
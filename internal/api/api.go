@@ -4,11 +4,13 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sliink/collector/docs"
 	"github.com/sliink/collector/internal/core"
+	"github.com/sliink/collector/internal/model"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
@@ -102,8 +104,20 @@ func (a *API) setupRoutes() {
 	{
 		pipelines.GET("", a.getPipelines)
 		pipelines.GET("/:type", a.getPipelineByType)
+		pipelines.GET("/:type/:name", a.getPipelineByName)
 		pipelines.POST("", a.createPipeline)
 		pipelines.DELETE("/:type", a.deletePipeline)
+		pipelines.DELETE("/:type/:name", a.deleteNamedPipeline)
+	}
+
+	// Dead-letter queue
+	deadLetters := a.router.Group("/deadletters")
+	{
+		deadLetters.GET("", a.getDeadLetters)
+		deadLetters.GET("/:id", a.getDeadLetter)
+		deadLetters.POST("/replay", a.replayDeadLetters)
+		deadLetters.POST("/:id/replay", a.replayDeadLetter)
+		deadLetters.DELETE("/:id", a.deleteDeadLetter)
 	}
 
 	// Controls
@@ -401,6 +415,11 @@ func (a *API) getBuffers(c *gin.Context) {
 		"status": bufferManager.(core.Component).GetStatus(),
 	}
 	
+	// Include per-output queue sizes and drop counters
+	if manager, ok := bufferManager.(*core.BufferManager); ok {
+		bufferInfo["buffers"] = manager.GetBufferStatus()
+	}
+
 	c.JSON(http.StatusOK, bufferInfo)
 }
 
@@ -418,18 +437,25 @@ func (a *API) getBufferByName(c *gin.Context) {
 	bufferName := c.Param("name")
 	
 	// Get the buffer manager
-	_, exists := a.core.GetComponent("buffer_manager")
+	bufferManager, exists := a.core.GetComponent("buffer_manager")
 	if !exists {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Buffer manager not available"})
 		return
 	}
 	
-	// For now, just return a not implemented status
-	// In a real implementation, we would query the buffer manager for the specific buffer
-	c.JSON(http.StatusOK, gin.H{
-		"name": bufferName,
-		"info": "Buffer details would be shown here",
-	})
+	manager, ok := bufferManager.(*core.BufferManager)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Buffer manager not available"})
+		return
+	}
+
+	status, exists := manager.GetBufferStatus()[bufferName]
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Buffer not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
 }
 
 // flushBuffer handles POST /api/v1/buffers/:name/flush
@@ -578,12 +604,15 @@ func (a *API) restartCollector(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "Collector restarted"})
 }
 
+// getPipelines handles GET /api/v1/pipelines
 // @Summary      Get all pipelines
-// @Description  Get information about all data pipelines
+// @Description  Get information about all data pipelines, optionally filtered by telemetry type
 // @Tags         pipelines
 // @Accept       json
 // @Produce      json
+// @Param        type    query   string  false  "Pipeline type (logs, metrics, traces)"
 // @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
 // @Router       /pipelines [get]
 func (a *API) getPipelines(c *gin.Context) {
 	// Get the data pipeline
@@ -593,35 +622,62 @@ func (a *API) getPipelines(c *gin.Context) {
 		return
 	}
 	
-	// Return pipeline information for all types
-	pipelines := map[string]interface{}{
-		"logs": map[string]interface{}{
-			"status": "active",
-			"processors": []string{"log_parser"},
-		},
-		"metrics": map[string]interface{}{
-			"status": "inactive",
-		},
-		"traces": map[string]interface{}{
-			"status": "inactive",
-		},
+	// Optionally restrict to one telemetry type
+	var filterType model.TelemetryType
+	if pipelineType := c.Query("type"); pipelineType != "" {
+		telemetryType, err := core.ParsePipelineName(pipelineType)
+		if err != nil || strings.Contains(pipelineType, "/") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pipeline type"})
+			return
+		}
+		filterType = telemetryType
+	}
+
+	// Return pipeline information keyed by name
+	pipelines := make(map[string]interface{})
+	for _, info := range pipeline.GetPipelines() {
+		if filterType != "" && info.Type != filterType {
+			continue
+		}
+		pipelines[info.Name] = info
 	}
 	
 	c.JSON(http.StatusOK, pipelines)
 }
 
+// getPipelineByType handles GET /api/v1/pipelines/:type
 // @Summary      Get pipeline by type
-// @Description  Get information about a specific pipeline by telemetry type
+// @Description  Get information about the default pipeline of a telemetry type
 // @Tags         pipelines
 // @Accept       json
 // @Produce      json
 // @Param        type    path    string  true  "Pipeline type (logs, metrics, traces)"
 // @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
 // @Failure      404  {object}  map[string]string
 // @Router       /pipelines/{type} [get]
 func (a *API) getPipelineByType(c *gin.Context) {
-	pipelineType := c.Param("type")
-	
+	a.getNamedPipeline(c, c.Param("type"))
+}
+
+// getPipelineByName handles GET /api/v1/pipelines/:type/:name
+// @Summary      Get pipeline by name
+// @Description  Get information about a named pipeline such as logs/app
+// @Tags         pipelines
+// @Accept       json
+// @Produce      json
+// @Param        type    path    string  true  "Pipeline type (logs, metrics, traces)"
+// @Param        name    path    string  true  "Pipeline name"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /pipelines/{type}/{name} [get]
+func (a *API) getPipelineByName(c *gin.Context) {
+	a.getNamedPipeline(c, c.Param("type")+"/"+c.Param("name"))
+}
+
+// getNamedPipeline writes the description of a pipeline
+func (a *API) getNamedPipeline(c *gin.Context, name string) {
 	// Get the data pipeline
 	pipeline := a.core.GetDataPipeline()
 	if pipeline == nil {
@@ -629,40 +685,29 @@ func (a *API) getPipelineByType(c *gin.Context) {
 		return
 	}
 	
-	// Check pipeline type is valid
-	switch pipelineType {
-	case "logs", "metrics", "traces":
-		// Valid pipeline type
-	default:
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pipeline type"})
+	// Check pipeline name is valid
+	if _, err := core.ParsePipelineName(name); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pipeline name"})
 		return
 	}
 	
-	// Return pipeline information based on type
-	// In a real implementation, we would get this from the pipeline component
-	var pipelineInfo map[string]interface{}
-	
-	if pipelineType == "logs" {
-		pipelineInfo = map[string]interface{}{
-			"type": pipelineType,
-			"status": "active",
-			"processors": []string{"log_parser"},
-			"inputs": []string{"file_input", "docker_compose_input"},
-			"outputs": []string{"stdout_output"},
-		}
-		c.JSON(http.StatusOK, pipelineInfo)
-	} else {
+	info, exists := pipeline.GetPipeline(name)
+	if !exists {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Pipeline not found"})
+		return
 	}
+	
+	c.JSON(http.StatusOK, info)
 }
 
+// createPipeline handles POST /api/v1/pipelines
 // @Summary      Create a pipeline
-// @Description  Create a new data pipeline
+// @Description  Create or update a data pipeline named <type> or <type>/<name>
 // @Tags         pipelines
 // @Accept       json
 // @Produce      json
-// @Param        pipeline  body    map[string]interface{}  true  "Pipeline configuration"
-// @Success      201  {object}  map[string]string
+// @Param        pipeline  body    map[string]interface{}  true  "Pipeline configuration with name, processors, inputs and outputs"
+// @Success      201  {object}  map[string]interface{}
 // @Failure      400  {object}  map[string]string
 // @Failure      500  {object}  map[string]string
 // @Router       /pipelines [post]
@@ -680,58 +725,89 @@ func (a *API) createPipeline(c *gin.Context) {
 		return
 	}
 	
-	// Extract pipeline type and processors
-	pipelineType, ok := pipelineConfig["type"].(string)
-	if !ok {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Pipeline type is required"})
+	// Extract pipeline name, falling back to the bare type
+	name, _ := pipelineConfig["name"].(string)
+	if name == "" {
+		name, _ = pipelineConfig["type"].(string)
+	}
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Pipeline name is required"})
 		return
 	}
 	
-	// Validate pipeline type
-	switch pipelineType {
-	case "logs", "metrics", "traces":
-		// Valid pipeline type
-	default:
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pipeline type"})
+	// Validate pipeline name
+	if _, err := core.ParsePipelineName(name); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 	
-	// Extract processors
-	processorsList := []string{}
-	if processors, ok := pipelineConfig["processors"].([]interface{}); ok {
-		for _, processor := range processors {
-			if processorID, ok := processor.(string); ok {
-				processorsList = append(processorsList, processorID)
-			}
+	// Extract processors, inputs and outputs
+	processorsList := stringList(pipelineConfig["processors"])
+	_, hasInputs := pipelineConfig["inputs"]
+	_, hasOutputs := pipelineConfig["outputs"]
+
+	if len(processorsList) == 0 && !hasInputs && !hasOutputs {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Pipeline needs processors, inputs or outputs"})
+		return
+	}
+
+	// Create the pipeline
+	if len(processorsList) > 0 {
+		if err := pipeline.CreatePipeline(name, processorsList); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
 		}
 	}
 	
-	// Create the pipeline
-	// In a real implementation, this would create the pipeline
-	if pipelineType == "logs" {
-		c.JSON(http.StatusCreated, gin.H{
-			"status": "Pipeline created",
-			"type": pipelineType,
-			"processors": processorsList,
-		})
-	} else {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create pipeline, only log pipeline is supported currently"})
+	// Route the pipeline if inputs or outputs were given
+	if hasInputs || hasOutputs {
+		inputs := stringList(pipelineConfig["inputs"])
+		outputs := stringList(pipelineConfig["outputs"])
+		if err := pipeline.SetRoute(name, inputs, outputs); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 	}
+
+	info, _ := pipeline.GetPipeline(name)
+	c.JSON(http.StatusCreated, info)
 }
 
+// deletePipeline handles DELETE /api/v1/pipelines/:type
 // @Summary      Delete a pipeline
-// @Description  Delete a data pipeline by telemetry type
+// @Description  Delete the default data pipeline of a telemetry type
 // @Tags         pipelines
 // @Accept       json
 // @Produce      json
 // @Param        type    path    string  true  "Pipeline type (logs, metrics, traces)"
 // @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
 // @Failure      404  {object}  map[string]string
 // @Failure      500  {object}  map[string]string
 // @Router       /pipelines/{type} [delete]
 func (a *API) deletePipeline(c *gin.Context) {
-	pipelineType := c.Param("type")
-	
+	a.deleteByName(c, c.Param("type"))
+}
+
+// deleteNamedPipeline handles DELETE /api/v1/pipelines/:type/:name
+// @Summary      Delete a named pipeline
+// @Description  Delete a named data pipeline such as logs/app
+// @Tags         pipelines
+// @Accept       json
+// @Produce      json
+// @Param        type    path    string  true  "Pipeline type (logs, metrics, traces)"
+// @Param        name    path    string  true  "Pipeline name"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /pipelines/{type}/{name} [delete]
+func (a *API) deleteNamedPipeline(c *gin.Context) {
+	a.deleteByName(c, c.Param("type")+"/"+c.Param("name"))
+}
+
+// deleteByName removes a pipeline and writes the result
+func (a *API) deleteByName(c *gin.Context, name string) {
 	// Get the data pipeline
 	pipeline := a.core.GetDataPipeline()
 	if pipeline == nil {
@@ -739,23 +815,193 @@ func (a *API) deletePipeline(c *gin.Context) {
 		return
 	}
 	
-	// Check pipeline type is valid
-	switch pipelineType {
-	case "logs", "metrics", "traces":
-		// Valid pipeline type
-	default:
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pipeline type"})
+	// Check pipeline name is valid
+	if _, err := core.ParsePipelineName(name); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pipeline name"})
 		return
 	}
 	
 	// Delete the pipeline
-	// In a real implementation, this would delete the pipeline
-	if pipelineType == "logs" {
-		c.JSON(http.StatusOK, gin.H{
-			"status": "Pipeline deleted",
-			"type": pipelineType,
-		})
-	} else {
+	if !pipeline.DeletePipeline(name) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Pipeline not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "Pipeline deleted",
+		"name":   name,
+	})
+}
+
+// getDeadLetters handles GET /api/v1/deadletters
+// @Summary      Get dead-lettered batches
+// @Description  List batches that exhausted their retries, optionally filtered by output
+// @Tags         deadletters
+// @Accept       json
+// @Produce      json
+// @Param        output  query   string  false  "Output plugin ID"
+// @Success      200  {array}   map[string]interface{}
+// @Failure      500  {object}  map[string]string
+// @Router       /deadletters [get]
+func (a *API) getDeadLetters(c *gin.Context) {
+	deadLetters := a.core.GetDeadLetterQueue()
+	if deadLetters == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Dead-letter queue not available"})
+		return
+	}
+
+	c.JSON(http.StatusOK, deadLetters.List(c.Query("output")))
+}
+
+// getDeadLetter handles GET /api/v1/deadletters/:id
+// @Summary      Get a dead-lettered batch
+// @Description  Get a dead-lettered batch including its data points
+// @Tags         deadletters
+// @Accept       json
+// @Produce      json
+// @Param        id      path    string  true  "Dead-letter entry ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /deadletters/{id} [get]
+func (a *API) getDeadLetter(c *gin.Context) {
+	deadLetters := a.core.GetDeadLetterQueue()
+	if deadLetters == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Dead-letter queue not available"})
+		return
+	}
+
+	entry, exists := deadLetters.Get(c.Param("id"))
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dead-letter entry not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":         entry.ID,
+		"output_id":  entry.OutputID,
+		"attempts":   entry.Attempts,
+		"batch_type": entry.BatchType,
+		"batch_size": entry.BatchSize,
+		"timestamp":  entry.Timestamp,
+		"batch":      entry.Batch.ToMap(),
+	})
+}
+
+// replayDeadLetter handles POST /api/v1/deadletters/:id/replay
+// @Summary      Replay a dead-lettered batch
+// @Description  Move a dead-lettered batch back into its output's buffer
+// @Tags         deadletters
+// @Accept       json
+// @Produce      json
+// @Param        id      path    string  true  "Dead-letter entry ID"
+// @Success      200  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Router       /deadletters/{id}/replay [post]
+func (a *API) replayDeadLetter(c *gin.Context) {
+	id := c.Param("id")
+
+	deadLetters := a.core.GetDeadLetterQueue()
+	if deadLetters == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Dead-letter queue not available"})
+		return
+	}
+
+	if _, exists := deadLetters.Get(id); !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dead-letter entry not found"})
+		return
+	}
+
+	if err := a.core.ReplayDeadLetter(id); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "Batch replayed",
+		"id":     id,
+	})
+}
+
+// replayDeadLetters handles POST /api/v1/deadletters/replay
+// @Summary      Replay dead-lettered batches
+// @Description  Move all dead-lettered batches, optionally for one output, back into their buffers
+// @Tags         deadletters
+// @Accept       json
+// @Produce      json
+// @Param        output  query   string  false  "Output plugin ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]string
+// @Router       /deadletters/replay [post]
+func (a *API) replayDeadLetters(c *gin.Context) {
+	deadLetters := a.core.GetDeadLetterQueue()
+	if deadLetters == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Dead-letter queue not available"})
+		return
+	}
+
+	replayed := 0
+	failed := make(map[string]string)
+	for _, entry := range deadLetters.List(c.Query("output")) {
+		if err := a.core.ReplayDeadLetter(entry.ID); err != nil {
+			failed[entry.ID] = err.Error()
+			continue
+		}
+		replayed++
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"replayed": replayed,
+		"failed":   failed,
+	})
+}
+
+// deleteDeadLetter handles DELETE /api/v1/deadletters/:id
+// @Summary      Discard a dead-lettered batch
+// @Description  Remove a dead-lettered batch without replaying it
+// @Tags         deadletters
+// @Accept       json
+// @Produce      json
+// @Param        id      path    string  true  "Dead-letter entry ID"
+// @Success      200  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /deadletters/{id} [delete]
+func (a *API) deleteDeadLetter(c *gin.Context) {
+	id := c.Param("id")
+
+	deadLetters := a.core.GetDeadLetterQueue()
+	if deadLetters == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Dead-letter queue not available"})
+		return
+	}
+
+	_, exists, err := deadLetters.Remove(id)
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dead-letter entry not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "Dead-letter entry deleted",
+		"id":     id,
+	})
+}
+
+// stringList converts a JSON array of strings into a string slice
+func stringList(value interface{}) []string {
+	var result []string
+	if items, ok := value.([]interface{}); ok {
+		for _, item := range items {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
 	}
-}
\ No newline at end of file
+	return result
+}
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sliink/collector/internal/core"
+	"github.com/sliink/collector/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestAPI creates an API on top of a started core
+func newTestAPI(t *testing.T) *API {
+	gin.SetMode(gin.TestMode)
+
+	c := core.NewCore()
+	assert.True(t, c.Initialize())
+	assert.True(t, c.Start())
+	t.Cleanup(func() { c.Stop() })
+
+	return NewAPI(c, 8080, "localhost")
+}
+
+// serve sends a request through the API's router and returns the recorded response
+func serve(a *API, method, path string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	a.router.ServeHTTP(recorder, httptest.NewRequest(method, path, nil))
+	return recorder
+}
+
+// deadLetter queues a one-line log batch for an output and returns the entry's ID
+func deadLetter(t *testing.T, a *API, outputID string, message string) string {
+	batch := model.NewDataBatch(model.LogTelemetryType)
+	batch.AddPoint(&model.LogPoint{
+		BaseDataPoint: model.BaseDataPoint{Timestamp: time.Now(), Origin: "test"},
+		Message:       message,
+	})
+
+	entry, err := a.core.GetDeadLetterQueue().Add(outputID, batch, 3)
+	assert.NoError(t, err)
+	return entry.ID
+}
+
+// queueSize returns the number of batches buffered for an output
+func queueSize(t *testing.T, a *API, outputID string) int {
+	recorder := serve(a, http.MethodGet, "/buffers/"+outputID)
+	if recorder.Code == http.StatusNotFound {
+		return 0
+	}
+
+	var status model.BufferStatus
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &status))
+	return status.QueueSize
+}
+
+func TestPipelineEndpoints(t *testing.T) {
+	a := newTestAPI(t)
+	assert.NoError(t, a.core.GetDataPipeline().SetRoute("logs/app", nil, nil))
+
+	t.Run("Named pipelines are looked up by type and name", func(t *testing.T) {
+		recorder := serve(a, http.MethodGet, "/pipelines/logs/app")
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		var info core.PipelineInfo
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &info))
+		assert.Equal(t, "logs/app", info.Name)
+		assert.Equal(t, model.LogTelemetryType, info.Type)
+	})
+
+	t.Run("Unknown pipelines are not found", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, serve(a, http.MethodGet, "/pipelines/logs/missing").Code)
+		assert.Equal(t, http.StatusNotFound, serve(a, http.MethodGet, "/pipelines/metrics").Code)
+		assert.Equal(t, http.StatusBadRequest, serve(a, http.MethodGet, "/pipelines/events/app").Code)
+	})
+
+	t.Run("Named pipelines are deleted by type and name", func(t *testing.T) {
+		recorder := serve(a, http.MethodDelete, "/pipelines/logs/app")
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		var body map[string]string
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+		assert.Equal(t, "logs/app", body["name"])
+
+		_, exists := a.core.GetDataPipeline().GetPipeline("logs/app")
+		assert.False(t, exists)
+		assert.Equal(t, http.StatusNotFound, serve(a, http.MethodGet, "/pipelines/logs/app").Code)
+		assert.Equal(t, http.StatusNotFound, serve(a, http.MethodDelete, "/pipelines/logs/app").Code)
+	})
+}
+
+func TestDeadLetterEndpoints(t *testing.T) {
+	a := newTestAPI(t)
+	deadLetters := a.core.GetDeadLetterQueue()
+
+	t.Run("Entries are listed, optionally for one output", func(t *testing.T) {
+		first := deadLetter(t, a, "out", "first")
+		second := deadLetter(t, a, "other", "second")
+		defer deadLetters.Remove(first)
+		defer deadLetters.Remove(second)
+
+		var entries []core.DeadLetterEntry
+		recorder := serve(a, http.MethodGet, "/deadletters")
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &entries))
+		assert.Len(t, entries, 2)
+
+		recorder = serve(a, http.MethodGet, "/deadletters?output=other")
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &entries))
+		if assert.Len(t, entries, 1) {
+			assert.Equal(t, second, entries[0].ID)
+			assert.Equal(t, 3, entries[0].Attempts)
+		}
+	})
+
+	t.Run("An entry is returned with its batch", func(t *testing.T) {
+		id := deadLetter(t, a, "out", "disk full")
+		defer deadLetters.Remove(id)
+
+		recorder := serve(a, http.MethodGet, "/deadletters/"+id)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		var body map[string]interface{}
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+		assert.Equal(t, id, body["id"])
+		assert.Equal(t, "out", body["output_id"])
+		assert.Contains(t, recorder.Body.String(), "disk full")
+
+		assert.Equal(t, http.StatusNotFound, serve(a, http.MethodGet, "/deadletters/missing").Code)
+	})
+
+	t.Run("A single entry is replayed into its output's buffer", func(t *testing.T) {
+		id := deadLetter(t, a, "single", "retry me")
+		kept := deadLetter(t, a, "single", "keep me")
+		defer deadLetters.Remove(kept)
+
+		assert.Equal(t, http.StatusOK, serve(a, http.MethodPost, "/deadletters/"+id+"/replay").Code)
+		assert.Equal(t, 1, queueSize(t, a, "single"))
+
+		_, exists := deadLetters.Get(id)
+		assert.False(t, exists)
+		_, exists = deadLetters.Get(kept)
+		assert.True(t, exists)
+
+		assert.Equal(t, http.StatusNotFound, serve(a, http.MethodPost, "/deadletters/"+id+"/replay").Code)
+		assert.Equal(t, http.StatusNotFound, serve(a, http.MethodPost, "/deadletters/missing/replay").Code)
+	})
+
+	t.Run("All entries are replayed, optionally for one output", func(t *testing.T) {
+		deadLetter(t, a, "all_a", "a1")
+		deadLetter(t, a, "all_a", "a2")
+		deadLetter(t, a, "all_b", "b1")
+
+		var body struct {
+			Replayed int               `json:"replayed"`
+			Failed   map[string]string `json:"failed"`
+		}
+		recorder := serve(a, http.MethodPost, "/deadletters/replay?output=all_a")
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+		assert.Equal(t, 2, body.Replayed)
+		assert.Empty(t, body.Failed)
+		assert.Equal(t, 2, queueSize(t, a, "all_a"))
+		assert.Equal(t, 0, queueSize(t, a, "all_b"))
+
+		recorder = serve(a, http.MethodPost, "/deadletters/replay")
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+		assert.Equal(t, 1, body.Replayed)
+		assert.Equal(t, 1, queueSize(t, a, "all_b"))
+		assert.Empty(t, deadLetters.List(""))
+	})
+
+	t.Run("An entry is discarded without replaying it", func(t *testing.T) {
+		id := deadLetter(t, a, "discard", "drop me")
+
+		assert.Equal(t, http.StatusOK, serve(a, http.MethodDelete, "/deadletters/"+id).Code)
+		assert.Empty(t, deadLetters.List("discard"))
+		assert.Equal(t, 0, queueSize(t, a, "discard"))
+
+		assert.Equal(t, http.StatusNotFound, serve(a, http.MethodDelete, "/deadletters/"+id).Code)
+	})
+}
@@ -1,6 +1,10 @@
 package core
 
 import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -12,10 +16,25 @@ type BufferManager struct {
 	buffers      map[string][]*model.DataBatch
 	maxQueueSize int
 	status       map[string]model.BufferStatus
+	persistence  *PersistenceOptions
+	logs         map[string]*writeAheadLog
+	sequences    map[string][]uint64                      // log sequences parallel to buffers
+	inflight     map[string]map[*model.DataBatch][]uint64 // flushed but not yet acknowledged
+	retries      map[string]map[*model.DataBatch]*retryState
+	limits       map[string]BufferLimits
+	spills       map[string]*spillQueue
+	spaceFreed   chan struct{} // closed and replaced whenever room is made
+	done         chan struct{}
 	mutex        sync.RWMutex
 	BaseComponent
 }
 
+// retryState tracks a batch waiting to be sent again
+type retryState struct {
+	attempts int
+	readyAt  time.Time
+}
+
 // NewBufferManager creates a new buffer manager
 func NewBufferManager(maxQueueSize int) *BufferManager {
 	if maxQueueSize <= 0 {
@@ -26,18 +45,75 @@ func NewBufferManager(maxQueueSize int) *BufferManager {
 		buffers:       make(map[string][]*model.DataBatch),
 		maxQueueSize:  maxQueueSize,
 		status:        make(map[string]model.BufferStatus),
+		logs:          make(map[string]*writeAheadLog),
+		sequences:     make(map[string][]uint64),
+		inflight:      make(map[string]map[*model.DataBatch][]uint64),
+		retries:       make(map[string]map[*model.DataBatch]*retryState),
+		limits:        make(map[string]BufferLimits),
+		spills:        make(map[string]*spillQueue),
+		spaceFreed:    make(chan struct{}),
 		BaseComponent: NewBaseComponent("buffer_manager", "Buffer Manager"),
 	}
 }
 
+// Configure applies buffer settings. A "persistence" section enables the
+// disk-backed mode, which takes effect on the next Start.
+func (b *BufferManager) Configure(config map[string]interface{}) bool {
+	if !b.BaseComponent.Configure(config) {
+		return false
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if maxSize, ok := intOption(config["max_size"]); ok && maxSize > 0 {
+		b.maxQueueSize = int(maxSize)
+	}
+
+	persistConf, ok := config["persistence"].(map[string]interface{})
+	if !ok {
+		b.persistence = nil
+		return true
+	}
+
+	if enabled, ok := persistConf["enabled"].(bool); ok && !enabled {
+		b.persistence = nil
+		return true
+	}
+
+	opts, err := parsePersistenceOptions(persistConf)
+	if err != nil {
+		return false
+	}
+	b.persistence = &opts
+
+	return true
+}
+
 // Initialize prepares the buffer manager for operation
 func (b *BufferManager) Initialize() bool {
 	b.SetStatus(model.StatusInitialized)
 	return true
 }
 
-// Start begins buffer manager operation
+// Start begins buffer manager operation, replaying persisted batches if enabled
 func (b *BufferManager) Start() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.persistence != nil {
+		if err := b.replayLogs(); err != nil {
+			b.closeLogs()
+			b.SetStatus(model.StatusError)
+			return false
+		}
+
+		if b.persistence.Fsync == FsyncInterval {
+			b.done = make(chan struct{})
+			go b.syncLogs(b.persistence.FsyncInterval, b.done)
+		}
+	}
+
 	b.SetStatus(model.StatusRunning)
 	return true
 }
@@ -47,15 +123,31 @@ func (b *BufferManager) Stop() bool {
 	b.mutex.Lock()
 	defer b.mutex.Unlock()
 
+	if b.done != nil {
+		close(b.done)
+		b.done = nil
+	}
+
+	// Persisted batches stay on disk for the next Start
+	b.closeLogs()
+
 	// Clear all buffers
 	b.buffers = make(map[string][]*model.DataBatch)
 	b.status = make(map[string]model.BufferStatus)
+	b.sequences = make(map[string][]uint64)
+	b.inflight = make(map[string]map[*model.DataBatch][]uint64)
+	b.retries = make(map[string]map[*model.DataBatch]*retryState)
 	
 	b.SetStatus(model.StatusStopped)
+
+	// Wake writers blocked on a full buffer
+	b.signalSpace()
 	return true
 }
 
-// Buffer adds a data batch to the buffer for a specific output
+// Buffer adds a data batch to the buffer for a specific output. When the
+// buffer is full the output's overflow policy decides what happens; false
+// means the batch was not accepted.
 func (b *BufferManager) Buffer(outputID string, batch *model.DataBatch) bool {
 	if batch == nil || batch.Size() == 0 {
 		return true // Nothing to buffer
@@ -68,41 +160,80 @@ func (b *BufferManager) Buffer(outputID string, batch *model.DataBatch) bool {
 		return false
 	}
 
-	// Initialize buffer for output if not exists
-	if _, exists := b.buffers[outputID]; !exists {
-		b.buffers[outputID] = make([]*model.DataBatch, 0)
-		b.status[outputID] = model.BufferStatus{
-			BufferID:   outputID,
-			QueueSize:  0,
-			TotalItems: 0,
-			IsFull:     false,
-			LastUpdate: time.Now(),
+	b.ensureBuffer(outputID)
+	limits := b.getLimits(outputID)
+	size := batchBytes(batch)
+
+	// Spilled batches go back in first so order is kept
+	if spill := b.spills[outputID]; spill != nil && spill.Len() > 0 {
+		b.refill(outputID)
+		if spill.Len() > 0 {
+			return b.spill(outputID, batch)
 		}
 	}
 
 	// Check if buffer is full
-	if len(b.buffers[outputID]) >= b.maxQueueSize {
-		// Update status
-		status := b.status[outputID]
-		status.IsFull = true
-		status.LastUpdate = time.Now()
-		b.status[outputID] = status
-		
-		return false // Buffer is full
+	if b.isFull(outputID, size) {
+		switch limits.Overflow {
+		case OverflowDropOldest:
+			for b.isFull(outputID, size) && len(b.buffers[outputID]) > 0 {
+				b.dropOldest(outputID)
+			}
+		case OverflowBlock:
+			if !b.waitForSpace(outputID, size, limits.BlockTimeout) {
+				if b.GetStatus() == model.StatusRunning {
+					b.recordDrop(outputID, OverflowBlock, batch)
+				}
+				return false
+			}
+		case OverflowSpillToDisk:
+			return b.spill(outputID, batch)
+		default:
+			b.recordDrop(outputID, OverflowDropNewest, batch)
+			return false // Buffer is full
+		}
 	}
 
-	// Add batch to buffer
-	b.buffers[outputID] = append(b.buffers[outputID], batch)
-	
-	// Update status
-	status := b.status[outputID]
-	status.QueueSize = len(b.buffers[outputID])
-	status.TotalItems += batch.Size()
-	status.IsFull = len(b.buffers[outputID]) >= b.maxQueueSize
-	status.LastUpdate = time.Now()
-	b.status[outputID] = status
-	
-	return true
+	return b.enqueue(outputID, batch, size)
+}
+
+// SetLimits sets the size limits and overflow policy for an output's buffer
+func (b *BufferManager) SetLimits(outputID string, limits BufferLimits) error {
+	if err := limits.validate(); err != nil {
+		return err
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if limits.Overflow == OverflowSpillToDisk {
+		spill, err := openSpillQueue(filepath.Join(limits.SpillDir, url.PathEscape(outputID)))
+		if err != nil {
+			return err
+		}
+		b.spills[outputID] = spill
+	} else {
+		delete(b.spills, outputID)
+	}
+
+	b.limits[outputID] = limits
+	return nil
+}
+
+// IsFull reports whether an output's buffer has reached its limits
+func (b *BufferManager) IsFull(outputID string) bool {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	return b.status[outputID].IsFull
+}
+
+// GetLimits returns the limits for an output's buffer
+func (b *BufferManager) GetLimits(outputID string) BufferLimits {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	return b.getLimits(outputID)
 }
 
 // Flush retrieves batches from the buffer for a specific output
@@ -114,44 +245,222 @@ func (b *BufferManager) Flush(outputID string, maxBatches int) []*model.DataBatc
 		return nil
 	}
 
+	// Bring spilled batches back while there is room
+	if spill := b.spills[outputID]; spill != nil && spill.Len() > 0 {
+		b.ensureBuffer(outputID)
+		b.refill(outputID)
+	}
+
 	// Check if buffer exists for output
 	if _, exists := b.buffers[outputID]; !exists {
 		return nil
 	}
 
-	// Determine number of batches to return
-	numBatches := len(b.buffers[outputID])
-	if maxBatches > 0 && maxBatches < numBatches {
-		numBatches = maxBatches
+	// Take batches in order, skipping retries whose backoff has not elapsed
+	now := time.Now()
+	queue := b.buffers[outputID]
+	seqs := b.sequences[outputID]
+	retries := b.retries[outputID]
+
+	var result []*model.DataBatch
+	var resultSeqs []uint64
+	remaining := make([]*model.DataBatch, 0, len(queue))
+	var remainingSeqs []uint64
+
+	for i, batch := range queue {
+		ready := maxBatches <= 0 || len(result) < maxBatches
+		if state, retrying := retries[batch]; retrying && now.Before(state.readyAt) {
+			ready = false
+		}
+
+		if ready {
+			result = append(result, batch)
+			if i < len(seqs) {
+				resultSeqs = append(resultSeqs, seqs[i])
+			}
+		} else {
+			remaining = append(remaining, batch)
+			if i < len(seqs) {
+				remainingSeqs = append(remainingSeqs, seqs[i])
+			}
+		}
 	}
 
-	if numBatches == 0 {
+	if len(result) == 0 {
 		return nil
 	}
 
-	// Get batches to return
-	result := b.buffers[outputID][:numBatches]
-	
 	// Update buffer
-	b.buffers[outputID] = b.buffers[outputID][numBatches:]
-	
+	b.buffers[outputID] = remaining
+
+	// Persisted batches stay in the log until acknowledged
+	if b.persistence != nil {
+		pending := b.inflight[outputID]
+		if pending == nil {
+			pending = make(map[*model.DataBatch][]uint64)
+			b.inflight[outputID] = pending
+		}
+		for i, seq := range resultSeqs {
+			pending[result[i]] = append(pending[result[i]], seq)
+		}
+		b.sequences[outputID] = remainingSeqs
+	}
+
 	// Calculate total items in returned batches
 	totalItems := 0
+	var totalBytes int64
 	for _, batch := range result {
 		totalItems += batch.Size()
+		totalBytes += batchBytes(batch)
 	}
 	
 	// Update status
 	status := b.status[outputID]
 	status.QueueSize = len(b.buffers[outputID])
 	status.TotalItems -= totalItems
-	status.IsFull = false // We just made room
-	status.LastUpdate = time.Now()
+	status.Bytes -= totalBytes
 	b.status[outputID] = status
+	b.updateFull(outputID)
+
+	// Wake writers blocked on a full buffer
+	b.signalSpace()
 	
 	return result
 }
 
+// Requeue puts a flushed batch back at the front of its output's queue after
+// a failed send. It is not returned by Flush until delay has passed.
+func (b *BufferManager) Requeue(outputID string, batch *model.DataBatch, delay time.Duration) bool {
+	if batch == nil {
+		return false
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.GetStatus() != model.StatusRunning {
+		return false
+	}
+
+	retries := b.retries[outputID]
+	if retries == nil {
+		retries = make(map[*model.DataBatch]*retryState)
+		b.retries[outputID] = retries
+	}
+	state, exists := retries[batch]
+	if !exists {
+		state = &retryState{}
+		retries[batch] = state
+	}
+	state.attempts++
+	state.readyAt = time.Now().Add(delay)
+
+	// Keep retries ahead of new batches, in the order they failed
+	queue := b.buffers[outputID]
+	pos := 0
+	for pos < len(queue) {
+		if _, retrying := retries[queue[pos]]; !retrying {
+			break
+		}
+		pos++
+	}
+	b.buffers[outputID] = append(queue[:pos], append([]*model.DataBatch{batch}, queue[pos:]...)...)
+
+	// The batch keeps its place in the log
+	if pending := b.inflight[outputID]; len(pending[batch]) > 0 {
+		seq := pending[batch][0]
+		if len(pending[batch]) == 1 {
+			delete(pending, batch)
+		} else {
+			pending[batch] = pending[batch][1:]
+		}
+
+		seqs := b.sequences[outputID]
+		if pos > len(seqs) {
+			pos = len(seqs)
+		}
+		b.sequences[outputID] = append(seqs[:pos], append([]uint64{seq}, seqs[pos:]...)...)
+	}
+
+	status := b.status[outputID]
+	status.BufferID = outputID
+	status.QueueSize = len(b.buffers[outputID])
+	status.TotalItems += batch.Size()
+	status.Bytes += batchBytes(batch)
+	b.status[outputID] = status
+	b.updateFull(outputID)
+
+	return true
+}
+
+// Replace swaps a flushed batch for the part of it that is still to be sent,
+// which keeps the batch's attempts and its place in the log. The whole batch
+// is replayed if the collector restarts before the part is acknowledged.
+func (b *BufferManager) Replace(outputID string, batch, part *model.DataBatch) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if state, exists := b.retries[outputID][batch]; exists {
+		delete(b.retries[outputID], batch)
+		b.retries[outputID][part] = state
+	}
+
+	pending := b.inflight[outputID]
+	if seqs := pending[batch]; len(seqs) > 0 {
+		if len(seqs) == 1 {
+			delete(pending, batch)
+		} else {
+			pending[batch] = seqs[1:]
+		}
+		pending[part] = append(pending[part], seqs[0])
+	}
+}
+
+// Attempts returns how many times a batch has been requeued for an output
+func (b *BufferManager) Attempts(outputID string, batch *model.DataBatch) int {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	if state, exists := b.retries[outputID][batch]; exists {
+		return state.attempts
+	}
+	return 0
+}
+
+// Acknowledge marks a flushed batch as finished so it is neither retried nor
+// replayed after a restart
+func (b *BufferManager) Acknowledge(outputID string, batch *model.DataBatch) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	delete(b.retries[outputID], batch)
+
+	pending := b.inflight[outputID]
+	seqs := pending[batch]
+	if len(seqs) == 0 {
+		return true
+	}
+
+	if len(seqs) == 1 {
+		delete(pending, batch)
+	} else {
+		pending[batch] = seqs[1:]
+	}
+
+	log, exists := b.logs[outputID]
+	if !exists {
+		return true
+	}
+
+	err := log.Ack(seqs[0])
+
+	status := b.status[outputID]
+	status.DiskBytes = log.Size()
+	b.status[outputID] = status
+
+	return err == nil
+}
+
 // GetBufferStatus retrieves the status of all buffers
 func (b *BufferManager) GetBufferStatus() map[string]model.BufferStatus {
 	b.mutex.RLock()
@@ -160,8 +469,312 @@ func (b *BufferManager) GetBufferStatus() map[string]model.BufferStatus {
 	// Create a copy to avoid concurrent map access
 	result := make(map[string]model.BufferStatus, len(b.status))
 	for k, v := range b.status {
+		if v.Dropped != nil {
+			dropped := make(map[string]int, len(v.Dropped))
+			for policy, count := range v.Dropped {
+				dropped[policy] = count
+			}
+			v.Dropped = dropped
+		}
 		result[k] = v
 	}
 	
 	return result
-}
\ No newline at end of file
+}
+
+// getLog returns the log for an output, opening it on first use
+func (b *BufferManager) getLog(outputID string) (*writeAheadLog, error) {
+	if log, exists := b.logs[outputID]; exists {
+		return log, nil
+	}
+
+	dir := filepath.Join(b.persistence.Dir, url.PathEscape(outputID))
+	log, _, err := openWriteAheadLog(dir, *b.persistence)
+	if err != nil {
+		return nil, err
+	}
+	b.logs[outputID] = log
+
+	return log, nil
+}
+
+// replayLogs opens every output log under the persistence directory and
+// queues its unacknowledged batches
+func (b *BufferManager) replayLogs() error {
+	if err := os.MkdirAll(b.persistence.Dir, 0755); err != nil {
+		return fmt.Errorf("failed to create buffer directory: %w", err)
+	}
+
+	dirEntries, err := os.ReadDir(b.persistence.Dir)
+	if err != nil {
+		return fmt.Errorf("failed to read buffer directory: %w", err)
+	}
+
+	for _, dirEntry := range dirEntries {
+		if !dirEntry.IsDir() {
+			continue
+		}
+
+		outputID, err := url.PathUnescape(dirEntry.Name())
+		if err != nil {
+			continue
+		}
+
+		log, entries, err := openWriteAheadLog(filepath.Join(b.persistence.Dir, dirEntry.Name()), *b.persistence)
+		if err != nil {
+			return fmt.Errorf("failed to open log for %s: %w", outputID, err)
+		}
+		b.logs[outputID] = log
+
+		status := model.BufferStatus{BufferID: outputID}
+		for _, entry := range entries {
+			b.buffers[outputID] = append(b.buffers[outputID], entry.batch)
+			b.sequences[outputID] = append(b.sequences[outputID], entry.seq)
+			status.TotalItems += entry.batch.Size()
+			status.Bytes += batchBytes(entry.batch)
+		}
+		status.QueueSize = len(b.buffers[outputID])
+		status.IsFull = status.QueueSize >= b.getLimits(outputID).MaxBatches
+		status.DiskBytes = log.Size()
+		status.LastUpdate = time.Now()
+		b.status[outputID] = status
+	}
+
+	return nil
+}
+
+// syncLogs periodically syncs logs for the interval fsync policy
+func (b *BufferManager) syncLogs(interval time.Duration, done chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			b.mutex.Lock()
+			for _, log := range b.logs {
+				log.Sync()
+			}
+			b.mutex.Unlock()
+		}
+	}
+}
+
+// closeLogs closes every open log
+func (b *BufferManager) closeLogs() {
+	for _, log := range b.logs {
+		log.Close()
+	}
+	b.logs = make(map[string]*writeAheadLog)
+}
+
+// ensureBuffer creates the queue and status for an output on first use
+func (b *BufferManager) ensureBuffer(outputID string) {
+	if _, exists := b.buffers[outputID]; exists {
+		return
+	}
+
+	b.buffers[outputID] = make([]*model.DataBatch, 0)
+	status := model.BufferStatus{
+		BufferID:   outputID,
+		QueueSize:  0,
+		TotalItems: 0,
+		IsFull:     false,
+		LastUpdate: time.Now(),
+	}
+	if spill := b.spills[outputID]; spill != nil {
+		status.Spilled = spill.Len()
+	}
+	b.status[outputID] = status
+}
+
+// getLimits returns an output's limits with the manager defaults filled in
+func (b *BufferManager) getLimits(outputID string) BufferLimits {
+	limits, exists := b.limits[outputID]
+	if !exists {
+		limits = DefaultBufferLimits()
+	}
+	if limits.MaxBatches <= 0 {
+		limits.MaxBatches = b.maxQueueSize
+	}
+	return limits
+}
+
+// isFull reports whether a batch of the given size would exceed an output's limits.
+// A single batch larger than MaxBytes is still accepted into an empty queue.
+func (b *BufferManager) isFull(outputID string, size int64) bool {
+	limits := b.getLimits(outputID)
+	queued := len(b.buffers[outputID])
+
+	if queued >= limits.MaxBatches {
+		return true
+	}
+
+	return limits.MaxBytes > 0 && queued > 0 && b.status[outputID].Bytes+size > limits.MaxBytes
+}
+
+// updateFull refreshes the full flag and timestamp of an output's status
+func (b *BufferManager) updateFull(outputID string) {
+	limits := b.getLimits(outputID)
+	status := b.status[outputID]
+	status.IsFull = status.QueueSize >= limits.MaxBatches ||
+		(limits.MaxBytes > 0 && status.Bytes >= limits.MaxBytes)
+	status.LastUpdate = time.Now()
+	b.status[outputID] = status
+}
+
+// enqueue appends a batch to an output's queue, writing it to the log first
+// when persistence is enabled
+func (b *BufferManager) enqueue(outputID string, batch *model.DataBatch, size int64) bool {
+	if b.persistence != nil {
+		log, err := b.getLog(outputID)
+		if err != nil {
+			return false
+		}
+
+		seq, err := log.Append(batch)
+		if err != nil {
+			return false
+		}
+		b.sequences[outputID] = append(b.sequences[outputID], seq)
+	}
+
+	// Add batch to buffer
+	b.buffers[outputID] = append(b.buffers[outputID], batch)
+
+	// Update status
+	status := b.status[outputID]
+	status.QueueSize = len(b.buffers[outputID])
+	status.TotalItems += batch.Size()
+	status.Bytes += size
+	if log, exists := b.logs[outputID]; exists {
+		status.DiskBytes = log.Size()
+	}
+	b.status[outputID] = status
+	b.updateFull(outputID)
+
+	return true
+}
+
+// dropOldest evicts the batch at the front of an output's queue
+func (b *BufferManager) dropOldest(outputID string) {
+	queue := b.buffers[outputID]
+	if len(queue) == 0 {
+		return
+	}
+
+	batch := queue[0]
+	b.buffers[outputID] = queue[1:]
+	delete(b.retries[outputID], batch)
+
+	// An evicted batch will never be delivered, so release it from the log
+	if seqs := b.sequences[outputID]; len(seqs) > 0 {
+		b.sequences[outputID] = seqs[1:]
+		if log, exists := b.logs[outputID]; exists {
+			log.Ack(seqs[0])
+		}
+	}
+
+	status := b.status[outputID]
+	status.QueueSize = len(b.buffers[outputID])
+	status.TotalItems -= batch.Size()
+	status.Bytes -= batchBytes(batch)
+	if log, exists := b.logs[outputID]; exists {
+		status.DiskBytes = log.Size()
+	}
+	b.status[outputID] = status
+
+	b.recordDrop(outputID, OverflowDropOldest, batch)
+}
+
+// recordDrop counts the points of a dropped batch against the policy that dropped it
+func (b *BufferManager) recordDrop(outputID string, policy OverflowPolicy, batch *model.DataBatch) {
+	status := b.status[outputID]
+	if status.Dropped == nil {
+		status.Dropped = make(map[string]int)
+	}
+	status.Dropped[string(policy)] += batch.Size()
+	status.IsFull = true
+	status.LastUpdate = time.Now()
+	b.status[outputID] = status
+}
+
+// waitForSpace releases the lock until the batch fits or the timeout passes.
+// It must be called with the lock held and returns with it held.
+func (b *BufferManager) waitForSpace(outputID string, size int64, timeout time.Duration) bool {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for b.isFull(outputID, size) {
+		freed := b.spaceFreed
+		b.mutex.Unlock()
+
+		select {
+		case <-freed:
+			b.mutex.Lock()
+		case <-timer.C:
+			b.mutex.Lock()
+			return b.GetStatus() == model.StatusRunning && !b.isFull(outputID, size)
+		}
+
+		if b.GetStatus() != model.StatusRunning {
+			return false
+		}
+		b.ensureBuffer(outputID)
+	}
+
+	return true
+}
+
+// signalSpace wakes every writer waiting for room
+func (b *BufferManager) signalSpace() {
+	close(b.spaceFreed)
+	b.spaceFreed = make(chan struct{})
+}
+
+// spill writes an overflowing batch to disk
+func (b *BufferManager) spill(outputID string, batch *model.DataBatch) bool {
+	spill := b.spills[outputID]
+	if spill == nil {
+		b.recordDrop(outputID, OverflowSpillToDisk, batch)
+		return false
+	}
+
+	if err := spill.Push(batch); err != nil {
+		b.recordDrop(outputID, OverflowSpillToDisk, batch)
+		return false
+	}
+
+	status := b.status[outputID]
+	status.Spilled = spill.Len()
+	b.status[outputID] = status
+	b.updateFull(outputID)
+
+	return true
+}
+
+// refill moves spilled batches back into an output's queue while they fit
+func (b *BufferManager) refill(outputID string) {
+	spill := b.spills[outputID]
+
+	for spill.Len() > 0 {
+		batch, err := spill.Peek()
+		if err != nil {
+			// An unreadable batch can never be delivered
+			spill.Discard()
+			continue
+		}
+
+		size := batchBytes(batch)
+		if b.isFull(outputID, size) || !b.enqueue(outputID, batch, size) {
+			break
+		}
+		spill.Discard()
+	}
+
+	status := b.status[outputID]
+	status.Spilled = spill.Len()
+	b.status[outputID] = status
+}
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -281,4 +282,335 @@ func TestBufferManagerGetBufferStatus(t *testing.T) {
 		assert.Equal(t, originalStatus.TotalItems, newStatuses[outputID1].TotalItems)
 		assert.Equal(t, originalStatus.IsFull, newStatuses[outputID1].IsFull)
 	})
-}
\ No newline at end of file
+}
+
+// Helper to create a log batch that can be persisted
+func createLogBatch(messages ...string) *model.DataBatch {
+	batch := model.NewDataBatch(model.LogTelemetryType)
+	for _, message := range messages {
+		batch.AddPoint(&model.LogPoint{
+			BaseDataPoint: model.BaseDataPoint{
+				Timestamp: time.Now(),
+				Origin:    "test",
+				Labels:    map[string]string{"test": "value"},
+			},
+			Message: message,
+			Level:   "INFO",
+		})
+	}
+	return batch
+}
+
+// Helper to create a started buffer manager with persistence in dir
+func newPersistentBufferManager(t *testing.T, dir string, extra map[string]interface{}) *BufferManager {
+	persistence := map[string]interface{}{
+		"dir":   dir,
+		"fsync": "always",
+	}
+	for k, v := range extra {
+		persistence[k] = v
+	}
+
+	manager := NewBufferManager(10)
+	assert.True(t, manager.Configure(map[string]interface{}{"persistence": persistence}))
+	assert.True(t, manager.Initialize())
+	assert.True(t, manager.Start())
+	return manager
+}
+
+func TestBufferManagerConfigure(t *testing.T) {
+	t.Run("Applies max_size", func(t *testing.T) {
+		manager := NewBufferManager(10)
+		assert.True(t, manager.Configure(map[string]interface{}{"max_size": float64(25)}))
+		assert.Equal(t, 25, manager.maxQueueSize)
+		assert.Nil(t, manager.persistence)
+	})
+
+	t.Run("Enables persistence with defaults", func(t *testing.T) {
+		manager := NewBufferManager(10)
+		success := manager.Configure(map[string]interface{}{
+			"persistence": map[string]interface{}{"dir": t.TempDir()},
+		})
+		assert.True(t, success)
+		assert.NotNil(t, manager.persistence)
+		assert.Equal(t, FsyncInterval, manager.persistence.Fsync)
+		assert.Equal(t, time.Second, manager.persistence.FsyncInterval)
+		assert.Equal(t, int64(defaultSegmentSize), manager.persistence.SegmentSize)
+	})
+
+	t.Run("Persistence can be disabled", func(t *testing.T) {
+		manager := NewBufferManager(10)
+		success := manager.Configure(map[string]interface{}{
+			"persistence": map[string]interface{}{"enabled": false},
+		})
+		assert.True(t, success)
+		assert.Nil(t, manager.persistence)
+	})
+
+	t.Run("Rejects invalid persistence settings", func(t *testing.T) {
+		manager := NewBufferManager(10)
+		assert.False(t, manager.Configure(map[string]interface{}{
+			"persistence": map[string]interface{}{},
+		}))
+		assert.False(t, manager.Configure(map[string]interface{}{
+			"persistence": map[string]interface{}{"dir": t.TempDir(), "fsync": "sometimes"},
+		}))
+		assert.False(t, manager.Configure(map[string]interface{}{
+			"persistence": map[string]interface{}{"dir": t.TempDir(), "segment_size": float64(0)},
+		}))
+	})
+}
+
+func TestBufferManagerPersistence(t *testing.T) {
+	t.Run("Replays buffered batches after restart", func(t *testing.T) {
+		dir := t.TempDir()
+		manager := newPersistentBufferManager(t, dir, nil)
+
+		assert.True(t, manager.Buffer("out/1", createLogBatch("first", "second")))
+		assert.True(t, manager.Buffer("out/1", createLogBatch("third")))
+		assert.True(t, manager.Stop())
+
+		manager = newPersistentBufferManager(t, dir, nil)
+		defer manager.Stop()
+
+		status := manager.GetBufferStatus()["out/1"]
+		assert.Equal(t, 2, status.QueueSize)
+		assert.Equal(t, 3, status.TotalItems)
+		assert.Greater(t, status.DiskBytes, int64(0))
+
+		batches := manager.Flush("out/1", 0)
+		assert.Len(t, batches, 2)
+		assert.Equal(t, "first", batches[0].Points[0].(*model.LogPoint).Message)
+		assert.Equal(t, "third", batches[1].Points[0].(*model.LogPoint).Message)
+	})
+
+	t.Run("Acknowledged batches are not replayed", func(t *testing.T) {
+		dir := t.TempDir()
+		manager := newPersistentBufferManager(t, dir, nil)
+
+		manager.Buffer("out", createLogBatch("delivered"))
+		manager.Buffer("out", createLogBatch("pending"))
+
+		batches := manager.Flush("out", 0)
+		assert.Len(t, batches, 2)
+		assert.True(t, manager.Acknowledge("out", batches[0]))
+		assert.True(t, manager.Stop())
+
+		manager = newPersistentBufferManager(t, dir, nil)
+		defer manager.Stop()
+
+		batches = manager.Flush("out", 0)
+		assert.Len(t, batches, 1)
+		assert.Equal(t, "pending", batches[0].Points[0].(*model.LogPoint).Message)
+	})
+
+	t.Run("Acknowledged segments are removed", func(t *testing.T) {
+		dir := t.TempDir()
+		manager := newPersistentBufferManager(t, dir, map[string]interface{}{
+			"segment_size": float64(1),
+		})
+		defer manager.Stop()
+
+		for i := 0; i < 3; i++ {
+			manager.Buffer("out", createLogBatch("message"))
+		}
+		segments, _ := filepath.Glob(filepath.Join(dir, "out", "*"+walSegmentExt))
+		assert.Len(t, segments, 4) // One per record plus the active segment
+
+		for _, batch := range manager.Flush("out", 0) {
+			assert.True(t, manager.Acknowledge("out", batch))
+		}
+		segments, _ = filepath.Glob(filepath.Join(dir, "out", "*"+walSegmentExt))
+		assert.Len(t, segments, 1)
+		assert.Equal(t, int64(0), manager.GetBufferStatus()["out"].DiskBytes)
+	})
+
+	t.Run("Buffer fails when disk limit is reached", func(t *testing.T) {
+		manager := newPersistentBufferManager(t, t.TempDir(), map[string]interface{}{
+			"max_disk_size": float64(300),
+		})
+		defer manager.Stop()
+
+		assert.True(t, manager.Buffer("out", createLogBatch("fits")))
+		assert.False(t, manager.Buffer("out", createLogBatch("does not fit")))
+		assert.Len(t, manager.buffers["out"], 1)
+	})
+
+	t.Run("Acknowledge is a no-op without persistence", func(t *testing.T) {
+		manager := NewBufferManager(10)
+		manager.Initialize()
+		manager.Start()
+
+		manager.Buffer("out", createTestBatch(1))
+		batches := manager.Flush("out", 0)
+		assert.True(t, manager.Acknowledge("out", batches[0]))
+	})
+}
+
+func TestBufferManagerRequeue(t *testing.T) {
+	manager := NewBufferManager(10)
+	manager.Initialize()
+	manager.Start()
+	outputID := "test_output"
+
+	t.Run("Requeued batches wait for their backoff", func(t *testing.T) {
+		failed := createTestBatch(1)
+		manager.Buffer(outputID, failed)
+		assert.Len(t, manager.Flush(outputID, 0), 1)
+
+		assert.True(t, manager.Requeue(outputID, failed, time.Hour))
+		assert.Equal(t, 1, manager.Attempts(outputID, failed))
+		assert.Equal(t, 1, manager.GetBufferStatus()[outputID].QueueSize)
+
+		// Newer batches are not held up by a pending retry
+		fresh := createTestBatch(1)
+		manager.Buffer(outputID, fresh)
+		assert.Equal(t, []*model.DataBatch{fresh}, manager.Flush(outputID, 0))
+		assert.Nil(t, manager.Flush(outputID, 0))
+
+		manager.Acknowledge(outputID, failed)
+		assert.Equal(t, 0, manager.Attempts(outputID, failed))
+		manager.Flush(outputID, 0)
+	})
+
+	t.Run("Ready retries are flushed ahead of new batches", func(t *testing.T) {
+		first := createTestBatch(1)
+		second := createTestBatch(1)
+		manager.Buffer(outputID, first)
+		manager.Buffer(outputID, second)
+		manager.Flush(outputID, 0)
+
+		fresh := createTestBatch(1)
+		manager.Buffer(outputID, fresh)
+		manager.Requeue(outputID, first, 0)
+		manager.Requeue(outputID, second, 0)
+
+		assert.Equal(t, []*model.DataBatch{first, second, fresh}, manager.Flush(outputID, 0))
+		assert.Equal(t, 1, manager.Attempts(outputID, first))
+	})
+
+	t.Run("Requeued batches keep their place in the log", func(t *testing.T) {
+		dir := t.TempDir()
+		persistent := newPersistentBufferManager(t, dir, nil)
+
+		batch := createLogBatch("retry me")
+		persistent.Buffer(outputID, batch)
+		flushed := persistent.Flush(outputID, 0)
+		assert.True(t, persistent.Requeue(outputID, flushed[0], 0))
+		assert.True(t, persistent.Stop())
+
+		persistent = newPersistentBufferManager(t, dir, nil)
+		defer persistent.Stop()
+		assert.Len(t, persistent.Flush(outputID, 0), 1)
+	})
+}
+
+func TestBufferManagerOverflow(t *testing.T) {
+	newManager := func(t *testing.T, limits BufferLimits) *BufferManager {
+		manager := NewBufferManager(10)
+		manager.Initialize()
+		manager.Start()
+		assert.NoError(t, manager.SetLimits("out", limits))
+		return manager
+	}
+
+	t.Run("Drop newest rejects the incoming batch", func(t *testing.T) {
+		manager := newManager(t, BufferLimits{MaxBatches: 1, Overflow: OverflowDropNewest})
+
+		assert.True(t, manager.Buffer("out", createTestBatch(1)))
+		assert.False(t, manager.Buffer("out", createTestBatch(3)))
+
+		status := manager.GetBufferStatus()["out"]
+		assert.Equal(t, 1, status.QueueSize)
+		assert.True(t, status.IsFull)
+		assert.Equal(t, 3, status.Dropped["drop_newest"])
+	})
+
+	t.Run("Drop oldest evicts queued batches", func(t *testing.T) {
+		manager := newManager(t, BufferLimits{MaxBatches: 2, Overflow: OverflowDropOldest})
+
+		oldest := createTestBatch(2)
+		manager.Buffer("out", oldest)
+		manager.Buffer("out", createTestBatch(1))
+		newest := createTestBatch(1)
+		assert.True(t, manager.Buffer("out", newest))
+
+		assert.NotContains(t, manager.buffers["out"], oldest)
+		assert.Contains(t, manager.buffers["out"], newest)
+		assert.Equal(t, 2, manager.GetBufferStatus()["out"].Dropped["drop_oldest"])
+	})
+
+	t.Run("Byte limits are enforced", func(t *testing.T) {
+		batch := createLogBatch("a message of some length")
+		size := batchBytes(batch)
+		manager := newManager(t, BufferLimits{MaxBytes: size * 2, Overflow: OverflowDropNewest})
+
+		assert.True(t, manager.Buffer("out", batch))
+		assert.True(t, manager.Buffer("out", createLogBatch("a message of some length")))
+		assert.False(t, manager.Buffer("out", createLogBatch("a message of some length")))
+		assert.Equal(t, size*2, manager.GetBufferStatus()["out"].Bytes)
+
+		manager.Flush("out", 1)
+		assert.Equal(t, size, manager.GetBufferStatus()["out"].Bytes)
+		assert.True(t, manager.Buffer("out", createLogBatch("a message of some length")))
+	})
+
+	t.Run("Block waits for room", func(t *testing.T) {
+		manager := newManager(t, BufferLimits{MaxBatches: 1, Overflow: OverflowBlock, BlockTimeout: 5 * time.Second})
+		manager.Buffer("out", createTestBatch(1))
+
+		done := make(chan bool)
+		go func() {
+			done <- manager.Buffer("out", createTestBatch(1))
+		}()
+
+		select {
+		case <-done:
+			t.Fatal("Buffer returned while the queue was full")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		manager.Flush("out", 1)
+		assert.True(t, <-done)
+		assert.Equal(t, 1, manager.GetBufferStatus()["out"].QueueSize)
+	})
+
+	t.Run("Block gives up after the timeout", func(t *testing.T) {
+		manager := newManager(t, BufferLimits{MaxBatches: 1, Overflow: OverflowBlock, BlockTimeout: 20 * time.Millisecond})
+		manager.Buffer("out", createTestBatch(1))
+
+		assert.False(t, manager.Buffer("out", createTestBatch(2)))
+		assert.Equal(t, 2, manager.GetBufferStatus()["out"].Dropped["block"])
+	})
+
+	t.Run("Spill to disk keeps overflow in order", func(t *testing.T) {
+		manager := newManager(t, BufferLimits{MaxBatches: 1, Overflow: OverflowSpillToDisk, SpillDir: t.TempDir()})
+
+		assert.True(t, manager.Buffer("out", createLogBatch("first")))
+		assert.True(t, manager.Buffer("out", createLogBatch("second")))
+		assert.True(t, manager.Buffer("out", createLogBatch("third")))
+		assert.Equal(t, 2, manager.GetBufferStatus()["out"].Spilled)
+
+		var messages []string
+		for i := 0; i < 3; i++ {
+			for _, batch := range manager.Flush("out", 0) {
+				messages = append(messages, batch.Points[0].(*model.LogPoint).Message)
+			}
+		}
+		assert.Equal(t, []string{"first", "second", "third"}, messages)
+		assert.Equal(t, 0, manager.GetBufferStatus()["out"].Spilled)
+	})
+
+	t.Run("Spilled batches survive a restart", func(t *testing.T) {
+		spillDir := t.TempDir()
+		manager := newManager(t, BufferLimits{MaxBatches: 1, Overflow: OverflowSpillToDisk, SpillDir: spillDir})
+		manager.Buffer("out", createLogBatch("in memory"))
+		manager.Buffer("out", createLogBatch("on disk"))
+		manager.Stop()
+
+		manager = newManager(t, BufferLimits{MaxBatches: 1, Overflow: OverflowSpillToDisk, SpillDir: spillDir})
+		batches := manager.Flush("out", 0)
+		assert.Len(t, batches, 1)
+		assert.Equal(t, "on disk", batches[0].Points[0].(*model.LogPoint).Message)
+	})
+}
@@ -2,7 +2,9 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/sliink/collector/internal/model"
@@ -16,7 +18,10 @@ type Core struct {
 	bufferManager  *BufferManager
 	configManager  *ConfigManager
 	healthMonitor  *HealthMonitor
-	inputChannels  map[string]chan *model.DataBatch
+	deadLetters    *DeadLetterQueue
+	retryPolicies  map[string]RetryPolicy
+	retryMutex     sync.RWMutex
+	inputChannels  map[string]chan RoutedBatch
 	outputChannels map[string]chan *model.DataBatch
 	ctx            context.Context
 	cancel         context.CancelFunc
@@ -39,6 +44,8 @@ func (c *Core) GetComponent(id string) (Component, bool) {
 		return c.configManager, true
 	case "health_monitor":
 		return c.healthMonitor, true
+	case "dead_letter_queue":
+		return c.deadLetters, true
 	case "core":
 		return c, true
 	}
@@ -64,12 +71,18 @@ func (c *Core) GetConfigManager() *ConfigManager {
 	return c.configManager
 }
 
+// GetDeadLetterQueue returns the dead-letter queue component
+func (c *Core) GetDeadLetterQueue() *DeadLetterQueue {
+	return c.deadLetters
+}
+
 // NewCore creates a new core system
 func NewCore() *Core {
 	ctx, cancel := context.WithCancel(context.Background())
 	
 	return &Core{
-		inputChannels:  make(map[string]chan *model.DataBatch),
+		retryPolicies:  make(map[string]RetryPolicy),
+		inputChannels:  make(map[string]chan RoutedBatch),
 		outputChannels: make(map[string]chan *model.DataBatch),
 		ctx:            ctx,
 		cancel:         cancel,
@@ -85,6 +98,7 @@ func (c *Core) Initialize() bool {
 	c.configManager = NewConfigManager()
 	c.healthMonitor = NewHealthMonitor()
 	c.bufferManager = NewBufferManager(1000) // Default buffer size
+	c.deadLetters = NewDeadLetterQueue(1000)
 	
 	// Initialize each component
 	if !c.eventBus.Initialize() {
@@ -107,6 +121,10 @@ func (c *Core) Initialize() bool {
 		return false
 	}
 	
+	if !c.deadLetters.Initialize() {
+		return false
+	}
+
 	// Create pipeline after registry is initialized
 	c.pipeline = NewDataPipeline(c.registry)
 	if !c.pipeline.Initialize() {
@@ -120,6 +138,7 @@ func (c *Core) Initialize() bool {
 	c.healthMonitor.RegisterComponent(c.configManager)
 	c.healthMonitor.RegisterComponent(c.pipeline)
 	c.healthMonitor.RegisterComponent(c.bufferManager)
+	c.healthMonitor.RegisterComponent(c.deadLetters)
 	
 	c.SetStatus(model.StatusInitialized)
 	return true
@@ -143,10 +162,22 @@ func (c *Core) Start() bool {
 	if !c.healthMonitor.Start() {
 		return false
 	}
+
+	// Apply buffer settings from the loaded configuration
+	if bufferConf, ok := c.configManager.GetConfig("buffer", nil).(map[string]interface{}); ok {
+		if !c.bufferManager.Configure(bufferConf) {
+			c.PublishEvent(model.EventError, c.ID(), fmt.Errorf("invalid buffer configuration"))
+			return false
+		}
+	}
 	
 	if !c.bufferManager.Start() {
 		return false
 	}
+
+	if !c.deadLetters.Start() {
+		return false
+	}
 	
 	if !c.pipeline.Start() {
 		return false
@@ -183,17 +214,15 @@ func (c *Core) Stop() bool {
 	
 	// Stop each component in reverse order
 	c.pipeline.Stop()
+	c.deadLetters.Stop()
 	c.bufferManager.Stop()
 	c.healthMonitor.Stop()
 	c.configManager.Stop()
 	c.registry.Stop()
 	c.eventBus.Stop()
 	
-	// Close all channels
-	for _, ch := range c.inputChannels {
-		close(ch)
-	}
-	
+	// Close the output channels. Input channels are left open, as an input
+	// goroutine may still be sending when the context is cancelled.
 	for _, ch := range c.outputChannels {
 		close(ch)
 	}
@@ -236,10 +265,10 @@ func (c *Core) startInputPlugin(input model.InputPlugin) error {
 	}
 	
 	// Create channel for this input
-	c.inputChannels[input.ID()] = make(chan *model.DataBatch, 100)
+	c.inputChannels[input.ID()] = make(chan RoutedBatch, 100)
 	
 	// Start the input goroutine
-	go func(input model.InputPlugin, ch chan *model.DataBatch) {
+	go func(input model.InputPlugin, ch chan RoutedBatch) {
 		if !input.Start() {
 			c.PublishEvent(model.EventError, input.ID(), fmt.Errorf("failed to start input plugin: %s", input.ID()))
 			return
@@ -263,37 +292,38 @@ func (c *Core) startInputPlugin(input model.InputPlugin) error {
 						continue
 					}
 					
-					// Process the batch
-					processed := c.ProcessBatch(batch)
-					if processed == nil || processed.Size() == 0 {
-						continue
+					// Process the batch through the pipelines this input feeds
+					for _, routed := range c.routeBatch(input.ID(), batch) {
+						// Send to channel for processing. A blocking output
+						// fills the channel and stalls collection until it
+						// drains or the core stops.
+						select {
+						case ch <- routed:
+						case <-c.ctx.Done():
+							input.Stop()
+							return
+						}
 					}
-					
-					// Send to channel for processing
-					ch <- processed
 				}
 			}
 		}
 	}(input, c.inputChannels[input.ID()])
 	
 	// Start a goroutine to handle batches from this input
-	go func(inputID string, ch chan *model.DataBatch) {
+	go func(inputID string, ch chan RoutedBatch) {
 		for {
 			select {
 			case <-c.ctx.Done():
 				return
-			case batch := <-ch:
-				if batch == nil {
+			case routed := <-ch:
+				if routed.Batch == nil {
 					continue
 				}
 				
-				// Get output plugins for this batch type
-				outputs := c.getOutputsForBatchType(batch.BatchType)
-				
-				// Buffer for each output
-				for _, output := range outputs {
-					if !c.bufferManager.Buffer(output.ID(), batch) {
-						c.PublishEvent(model.EventError, c.ID(), fmt.Errorf("buffer full for output: %s", output.ID()))
+				// Buffer for each output wired to the pipeline
+				for _, outputID := range routed.OutputIDs {
+					if !c.bufferManager.Buffer(outputID, routed.Batch) {
+						c.PublishEvent(model.EventError, c.ID(), fmt.Errorf("buffer full for output: %s", outputID))
 					}
 				}
 			}
@@ -333,14 +363,18 @@ func (c *Core) startOutputPlugin(output model.OutputPlugin) error {
 				
 				// Send each batch
 				for _, batch := range batches {
-					if !output.Send(batch) {
-						c.PublishEvent(model.EventError, output.ID(), fmt.Errorf("failed to send batch"))
-					} else {
-						c.PublishEvent(model.EventDataSent, output.ID(), map[string]interface{}{
-							"batch_type": batch.BatchType,
-							"batch_size": batch.Size(),
-						})
+					if err := sendBatch(output, batch); err != nil {
+						c.handleSendError(output.ID(), batch, err)
+						continue
+					}
+
+					if !c.bufferManager.Acknowledge(output.ID(), batch) {
+						c.PublishEvent(model.EventError, output.ID(), fmt.Errorf("failed to acknowledge batch"))
 					}
+					c.PublishEvent(model.EventDataSent, output.ID(), map[string]interface{}{
+						"batch_type": batch.BatchType,
+						"batch_size": batch.Size(),
+					})
 				}
 			}
 		}
@@ -349,13 +383,242 @@ func (c *Core) startOutputPlugin(output model.OutputPlugin) error {
 	return nil
 }
 
+// sendBatch sends a batch to an output, using Export when the output can say why it failed
+func sendBatch(output model.OutputPlugin, batch *model.DataBatch) error {
+	if exporter, ok := output.(model.ExportingOutput); ok {
+		return exporter.Export(batch)
+	}
+
+	if !output.Send(batch) {
+		return fmt.Errorf("failed to send batch")
+	}
+	return nil
+}
+
+// handleSendError dead-letters a batch that failed permanently and retries any
+// other failure. When only part of the batch failed, only that part is kept.
+func (c *Core) handleSendError(outputID string, batch *model.DataBatch, err error) {
+	var partial *model.PartialError
+	if errors.As(err, &partial) && partial.Failed != nil {
+		c.bufferManager.Replace(outputID, batch, partial.Failed)
+		batch = partial.Failed
+	}
+
+	if !model.IsPermanent(err) {
+		c.handleSendFailure(outputID, batch)
+		return
+	}
+
+	attempts := c.bufferManager.Attempts(outputID, batch) + 1
+	c.PublishEvent(model.EventError, outputID, fmt.Errorf("failed to send batch, not retrying: %w", err))
+	c.deadLetter(outputID, batch, attempts)
+}
+
+// handleSendFailure requeues a batch that failed to send, or dead-letters it
+// once its output's retry policy is exhausted
+func (c *Core) handleSendFailure(outputID string, batch *model.DataBatch) {
+	policy := c.GetRetryPolicy(outputID)
+	attempts := c.bufferManager.Attempts(outputID, batch) + 1
+
+	if attempts < policy.MaxAttempts {
+		delay := policy.Backoff(attempts)
+		if c.bufferManager.Requeue(outputID, batch, delay) {
+			c.PublishEvent(model.EventError, outputID, fmt.Errorf("failed to send batch (attempt %d of %d), retrying in %s", attempts, policy.MaxAttempts, delay))
+			return
+		}
+	}
+
+	c.PublishEvent(model.EventError, outputID, fmt.Errorf("failed to send batch after %d attempts", attempts))
+	c.deadLetter(outputID, batch, attempts)
+}
+
+// deadLetter moves an undeliverable batch to the dead-letter queue and its sink
+func (c *Core) deadLetter(outputID string, batch *model.DataBatch, attempts int) {
+	if _, err := c.deadLetters.Add(outputID, batch, attempts); err != nil {
+		c.PublishEvent(model.EventError, c.deadLetters.ID(), err)
+	}
+
+	if sink, exists := c.deadLetters.GetSink(outputID); exists && sink.OutputID != "" {
+		if !c.bufferManager.Buffer(sink.OutputID, batch) {
+			c.PublishEvent(model.EventError, c.deadLetters.ID(), fmt.Errorf("buffer full for dead-letter output: %s", sink.OutputID))
+		}
+	}
+
+	// The batch is now owned by the dead-letter queue
+	c.bufferManager.Acknowledge(outputID, batch)
+}
+
+// SetRetryPolicy sets the retry policy for an output
+func (c *Core) SetRetryPolicy(outputID string, policy RetryPolicy) error {
+	if err := c.checkOutput(outputID); err != nil {
+		return err
+	}
+
+	if policy.MaxAttempts < 1 {
+		return fmt.Errorf("max attempts must be at least 1")
+	}
+
+	c.retryMutex.Lock()
+	defer c.retryMutex.Unlock()
+
+	c.retryPolicies[outputID] = policy
+	return nil
+}
+
+// GetRetryPolicy returns the retry policy for an output
+func (c *Core) GetRetryPolicy(outputID string) RetryPolicy {
+	c.retryMutex.RLock()
+	defer c.retryMutex.RUnlock()
+
+	if policy, exists := c.retryPolicies[outputID]; exists {
+		return policy
+	}
+	return DefaultRetryPolicy()
+}
+
+// SetBufferLimits sets the buffer limits and overflow policy for an output
+func (c *Core) SetBufferLimits(outputID string, limits BufferLimits) error {
+	if err := c.checkOutput(outputID); err != nil {
+		return err
+	}
+
+	return c.bufferManager.SetLimits(outputID, limits)
+}
+
+// SetDeadLetterSink sets where an output's undeliverable batches are sent
+func (c *Core) SetDeadLetterSink(outputID string, sink DeadLetterSink) error {
+	if err := c.checkOutput(outputID); err != nil {
+		return err
+	}
+
+	if sink.OutputID != "" {
+		if sink.OutputID == outputID {
+			return fmt.Errorf("output %s cannot be its own dead-letter output", outputID)
+		}
+		if err := c.checkOutput(sink.OutputID); err != nil {
+			return err
+		}
+	}
+
+	return c.deadLetters.SetSink(outputID, sink)
+}
+
+// ReplayDeadLetter moves a dead-lettered batch back into its output's buffer
+func (c *Core) ReplayDeadLetter(id string) error {
+	entry, exists := c.deadLetters.Get(id)
+	if !exists {
+		return fmt.Errorf("dead-letter entry not found: %s", id)
+	}
+
+	if !c.bufferManager.Buffer(entry.OutputID, entry.Batch) {
+		return fmt.Errorf("buffer full for output: %s", entry.OutputID)
+	}
+
+	if _, _, err := c.deadLetters.Remove(id); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// checkOutput verifies that an ID refers to a registered output plugin
+func (c *Core) checkOutput(outputID string) error {
+	plugin, exists := c.registry.GetPlugin(outputID)
+	if !exists {
+		return fmt.Errorf("output plugin not found: %s", outputID)
+	}
+
+	if _, ok := plugin.(model.OutputPlugin); !ok {
+		return fmt.Errorf("plugin is not an output: %s", outputID)
+	}
+
+	return nil
+}
+
 // getOutputsForBatchType returns all output plugins that should receive a batch type
 func (c *Core) getOutputsForBatchType(batchType model.TelemetryType) []model.OutputPlugin {
 	allOutputs := c.registry.GetOutputPlugins()
 	
-	// In a real implementation, check the configuration to determine which
-	// outputs should receive which batch types. For now, return all outputs.
-	return allOutputs
+	// Without declared routes every output receives every batch type
+	if c.pipeline == nil || !c.pipeline.HasRoutes() {
+		return allOutputs
+	}
+
+	var result []model.OutputPlugin
+	for _, outputID := range c.pipeline.OutputsForType(batchType) {
+		plugin, exists := c.registry.GetPlugin(outputID)
+		if !exists {
+			continue
+		}
+		if output, ok := plugin.(model.OutputPlugin); ok {
+			result = append(result, output)
+		}
+	}
+
+	return result
+}
+
+// routeBatch processes a batch from an input and pairs each result with its outputs
+func (c *Core) routeBatch(inputID string, batch *model.DataBatch) []RoutedBatch {
+	if c.pipeline == nil {
+		return nil
+	}
+
+	// Without declared routes, process by batch type and send to every output
+	if !c.pipeline.HasRoutes() {
+		processed := c.ProcessBatch(batch)
+		if processed == nil || processed.Size() == 0 {
+			return nil
+		}
+
+		var outputIDs []string
+		for _, output := range c.getOutputsForBatchType(processed.BatchType) {
+			outputIDs = append(outputIDs, output.ID())
+		}
+
+		return []RoutedBatch{{Batch: processed, OutputIDs: outputIDs}}
+	}
+
+	c.PublishEvent(model.EventDataReceived, inputID, map[string]interface{}{
+		"batch_type": batch.BatchType,
+		"batch_size": batch.Size(),
+	})
+
+	routed := c.pipeline.Route(inputID, batch)
+	for _, r := range routed {
+		c.PublishEvent(model.EventDataProcessed, inputID, map[string]interface{}{
+			"batch_type": r.Batch.BatchType,
+			"batch_size": r.Batch.Size(),
+			"outputs":    r.OutputIDs,
+		})
+	}
+
+	return routed
+}
+
+// InputBackpressured reports whether the buffer of any output fed by an input is full
+func (c *Core) InputBackpressured(inputID string) bool {
+	if c.bufferManager == nil || c.registry == nil {
+		return false
+	}
+
+	var outputIDs []string
+	if c.pipeline != nil && c.pipeline.HasRoutes() {
+		outputIDs = c.pipeline.OutputsForInput(inputID)
+	} else {
+		// Without declared routes every output receives every batch
+		for _, output := range c.registry.GetOutputPlugins() {
+			outputIDs = append(outputIDs, output.ID())
+		}
+	}
+
+	for _, outputID := range outputIDs {
+		if c.bufferManager.IsFull(outputID) {
+			return true
+		}
+	}
+
+	return false
 }
 
 // PublishEvent publishes an event to the event bus
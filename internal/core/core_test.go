@@ -1,6 +1,7 @@
 package core
 
 import (
+	"errors"
 	"sync"
 	"testing"
 	"time"
@@ -156,6 +157,10 @@ func TestCoreGetComponent(t *testing.T) {
 		component, exists = core.GetComponent("health_monitor")
 		assert.True(t, exists)
 		assert.Equal(t, core.healthMonitor, component)
+
+		component, exists = core.GetComponent("dead_letter_queue")
+		assert.True(t, exists)
+		assert.Equal(t, core.deadLetters, component)
 		
 		component, exists = core.GetComponent("core")
 		assert.True(t, exists)
@@ -317,7 +322,7 @@ func TestCoreProcessBatch(t *testing.T) {
 		assert.NoError(t, err)
 		
 		// Create a pipeline using the processor
-		err = core.pipeline.CreatePipeline(model.LogTelemetryType, []string{"doubler"})
+		err = core.pipeline.CreatePipeline("logs", []string{"doubler"})
 		assert.NoError(t, err)
 		
 		// Process a batch through the pipeline
@@ -327,6 +332,315 @@ func TestCoreProcessBatch(t *testing.T) {
 	})
 }
 
+func TestCoreRouteBatch(t *testing.T) {
+	core := NewCore()
+	core.Initialize()
+	core.Start()
+	defer core.Stop()
+
+	assert.NoError(t, core.RegisterPlugin(&mockInputPlugin{id: "in", name: "Input"}))
+	assert.NoError(t, core.RegisterPlugin(&mockOutputPlugin{id: "log_out", name: "Log Output"}))
+	assert.NoError(t, core.RegisterPlugin(&mockOutputPlugin{id: "metric_out", name: "Metric Output"}))
+
+	t.Run("Without routes every output receives the batch", func(t *testing.T) {
+		routed := core.routeBatch("in", createTestBatch(2))
+		assert.Len(t, routed, 1)
+		assert.ElementsMatch(t, []string{"log_out", "metric_out"}, routed[0].OutputIDs)
+	})
+
+	t.Run("With routes only wired outputs receive the batch", func(t *testing.T) {
+		assert.NoError(t, core.pipeline.SetRoute("logs", []string{"in"}, []string{"log_out"}))
+		assert.NoError(t, core.pipeline.SetRoute("metrics", []string{"in"}, []string{"metric_out"}))
+
+		routed := core.routeBatch("in", createTestBatch(2))
+		assert.Len(t, routed, 1)
+		assert.Equal(t, []string{"log_out"}, routed[0].OutputIDs)
+
+		outputs := core.getOutputsForBatchType(model.MetricTelemetryType)
+		assert.Len(t, outputs, 1)
+		assert.Equal(t, "metric_out", outputs[0].ID())
+	})
+}
+
+func TestCoreInputBackpressured(t *testing.T) {
+	core := NewCore()
+	core.Initialize()
+	core.Start()
+	defer core.Stop()
+
+	assert.NoError(t, core.RegisterPlugin(&mockInputPlugin{id: "in", name: "Input"}))
+	assert.NoError(t, core.RegisterPlugin(&mockInputPlugin{id: "other_in", name: "Other Input"}))
+	assert.NoError(t, core.RegisterPlugin(&mockOutputPlugin{id: "out", name: "Output"}))
+	assert.NoError(t, core.RegisterPlugin(&mockOutputPlugin{id: "other_out", name: "Other Output"}))
+	assert.NoError(t, core.SetBufferLimits("out", BufferLimits{MaxBatches: 1, Overflow: OverflowDropNewest}))
+
+	assert.False(t, core.InputBackpressured("in"))
+	assert.True(t, core.bufferManager.Buffer("out", createTestBatch(1)))
+
+	t.Run("Without routes any full output pushes back", func(t *testing.T) {
+		assert.True(t, core.InputBackpressured("in"))
+		assert.True(t, core.InputBackpressured("other_in"))
+	})
+
+	t.Run("With routes only outputs the input feeds count", func(t *testing.T) {
+		assert.NoError(t, core.pipeline.SetRoute("logs", []string{"in"}, []string{"out"}))
+		assert.NoError(t, core.pipeline.SetRoute("logs/other", []string{"other_in"}, []string{"other_out"}))
+
+		assert.True(t, core.InputBackpressured("in"))
+		assert.False(t, core.InputBackpressured("other_in"))
+
+		core.bufferManager.Flush("out", 0)
+		assert.False(t, core.InputBackpressured("in"))
+	})
+}
+
+// lockedStatus is a plugin status that is safe to use from the core's goroutines
+type lockedStatus struct {
+	status model.ComponentStatus
+	mutex  sync.Mutex
+}
+
+func (l *lockedStatus) GetStatus() model.ComponentStatus {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.status
+}
+
+func (l *lockedStatus) SetStatus(status model.ComponentStatus) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.status = status
+}
+
+func (l *lockedStatus) Initialize() bool {
+	l.SetStatus(model.StatusInitialized)
+	return true
+}
+
+func (l *lockedStatus) Start() bool {
+	l.SetStatus(model.StatusRunning)
+	return true
+}
+
+func (l *lockedStatus) Stop() bool {
+	l.SetStatus(model.StatusStopped)
+	return true
+}
+
+// floodInputPlugin returns many batches from its first Collect
+type floodInputPlugin struct {
+	lockedStatus
+	collected chan struct{}
+	once      sync.Once
+	stops     int
+}
+
+// Stop counts the calls, which come from the registry and the input's goroutine
+func (m *floodInputPlugin) Stop() bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.stops++
+	m.status = model.StatusStopped
+	return true
+}
+
+// stopCount returns how many times the input was stopped
+func (m *floodInputPlugin) stopCount() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.stops
+}
+
+func (m *floodInputPlugin) ID() string                                   { return "in" }
+func (m *floodInputPlugin) Name() string                                 { return "Flood Input" }
+func (m *floodInputPlugin) GetType() model.PluginType                    { return model.InputPluginType }
+func (m *floodInputPlugin) Configure(config map[string]interface{}) bool { return true }
+func (m *floodInputPlugin) Validate() bool                               { return true }
+func (m *floodInputPlugin) RegisterWithCore(core model.CoreAPI) bool     { return true }
+
+func (m *floodInputPlugin) Collect() []*model.DataBatch {
+	var batches []*model.DataBatch
+	m.once.Do(func() {
+		for i := 0; i < 300; i++ {
+			batches = append(batches, createTestBatch(1))
+		}
+		close(m.collected)
+	})
+	return batches
+}
+
+// failingOutputPlugin never sends successfully, so its buffer stays full
+type failingOutputPlugin struct {
+	lockedStatus
+}
+
+func (m *failingOutputPlugin) ID() string                                   { return "out" }
+func (m *failingOutputPlugin) Name() string                                 { return "Failing Output" }
+func (m *failingOutputPlugin) GetType() model.PluginType                    { return model.OutputPluginType }
+func (m *failingOutputPlugin) Configure(config map[string]interface{}) bool { return true }
+func (m *failingOutputPlugin) Validate() bool                               { return true }
+func (m *failingOutputPlugin) RegisterWithCore(core model.CoreAPI) bool     { return true }
+func (m *failingOutputPlugin) Send(batch *model.DataBatch) bool             { return false }
+
+func TestCoreStopWithBlockedOutput(t *testing.T) {
+	core := NewCore()
+	core.Initialize()
+
+	input := &floodInputPlugin{collected: make(chan struct{})}
+	assert.NoError(t, core.RegisterPlugin(input))
+	assert.NoError(t, core.RegisterPlugin(&failingOutputPlugin{}))
+	assert.NoError(t, core.SetBufferLimits("out", BufferLimits{MaxBatches: 1, Overflow: OverflowBlock, BlockTimeout: time.Minute}))
+	assert.True(t, core.Start())
+
+	select {
+	case <-input.collected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("input was not collected")
+	}
+
+	// Give the input goroutine time to fill its channel behind the blocked output
+	time.Sleep(100 * time.Millisecond)
+
+	stopped := make(chan struct{})
+	go func() {
+		core.Stop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("core did not stop while an output was blocked")
+	}
+
+	// The input goroutine gives up its send and stops the input too
+	deadline := time.Now().Add(5 * time.Second)
+	for input.stopCount() < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, 2, input.stopCount())
+}
+
+func TestCoreSendFailure(t *testing.T) {
+	core := NewCore()
+	core.Initialize()
+	core.Start()
+	defer core.Stop()
+
+	assert.NoError(t, core.RegisterPlugin(&mockOutputPlugin{id: "out", name: "Output"}))
+	assert.NoError(t, core.RegisterPlugin(&mockOutputPlugin{id: "dlq_out", name: "Dead Letter Output"}))
+
+	t.Run("Retry settings are validated", func(t *testing.T) {
+		assert.Error(t, core.SetRetryPolicy("missing", DefaultRetryPolicy()))
+		assert.Error(t, core.SetRetryPolicy("out", RetryPolicy{MaxAttempts: 0}))
+		assert.Error(t, core.SetDeadLetterSink("out", DeadLetterSink{OutputID: "out"}))
+		assert.Error(t, core.SetDeadLetterSink("out", DeadLetterSink{OutputID: "missing"}))
+
+		// A sink that leads back to the output is a cycle
+		assert.NoError(t, core.SetDeadLetterSink("dlq_out", DeadLetterSink{OutputID: "out"}))
+		err := core.SetDeadLetterSink("out", DeadLetterSink{OutputID: "dlq_out"})
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "out -> dlq_out -> out")
+		}
+		assert.NoError(t, core.SetDeadLetterSink("dlq_out", DeadLetterSink{}))
+		assert.Equal(t, DefaultRetryPolicy(), core.GetRetryPolicy("out"))
+	})
+
+	t.Run("Failed batches are retried then dead-lettered", func(t *testing.T) {
+		assert.NoError(t, core.SetRetryPolicy("out", RetryPolicy{MaxAttempts: 2}))
+		assert.NoError(t, core.SetDeadLetterSink("out", DeadLetterSink{OutputID: "dlq_out"}))
+
+		batch := createTestBatch(2)
+		core.bufferManager.Buffer("out", batch)
+
+		// First failure is requeued
+		flushed := core.bufferManager.Flush("out", 0)
+		assert.Len(t, flushed, 1)
+		core.handleSendFailure("out", flushed[0])
+		assert.Equal(t, 1, core.bufferManager.Attempts("out", batch))
+		assert.Empty(t, core.deadLetters.List("out"))
+
+		// Second failure exhausts the policy
+		flushed = core.bufferManager.Flush("out", 0)
+		assert.Len(t, flushed, 1)
+		core.handleSendFailure("out", flushed[0])
+		assert.Equal(t, 0, core.bufferManager.Attempts("out", batch))
+
+		entries := core.deadLetters.List("out")
+		assert.Len(t, entries, 1)
+		assert.Equal(t, 2, entries[0].Attempts)
+		assert.Equal(t, 2, entries[0].BatchSize)
+
+		// The dead-letter output receives a copy
+		assert.Len(t, core.bufferManager.Flush("dlq_out", 0), 1)
+
+		// Replay puts the batch back in the output's buffer
+		assert.NoError(t, core.ReplayDeadLetter(entries[0].ID))
+		assert.Empty(t, core.deadLetters.List("out"))
+		assert.Equal(t, []*model.DataBatch{batch}, core.bufferManager.Flush("out", 0))
+
+		assert.Error(t, core.ReplayDeadLetter("missing"))
+	})
+
+	t.Run("Permanent errors are dead-lettered without retrying", func(t *testing.T) {
+		assert.NoError(t, core.SetRetryPolicy("out", RetryPolicy{MaxAttempts: 5}))
+
+		batch := createTestBatch(1)
+		core.bufferManager.Buffer("out", batch)
+
+		flushed := core.bufferManager.Flush("out", 0)
+		assert.Len(t, flushed, 1)
+		core.handleSendError("out", flushed[0], &model.PermanentError{Err: errors.New("bad request")})
+		assert.Empty(t, core.bufferManager.Flush("out", 0))
+
+		entries := core.deadLetters.List("out")
+		assert.Len(t, entries, 1)
+		assert.Equal(t, 1, entries[0].Attempts)
+		_, _, err := core.deadLetters.Remove(entries[0].ID)
+		assert.NoError(t, err)
+		core.bufferManager.Flush("dlq_out", 0)
+
+		// Other errors follow the retry policy
+		core.bufferManager.Buffer("out", batch)
+		flushed = core.bufferManager.Flush("out", 0)
+		core.handleSendError("out", flushed[0], errors.New("timeout"))
+		assert.Equal(t, 1, core.bufferManager.Attempts("out", batch))
+		assert.Empty(t, core.deadLetters.List("out"))
+	})
+}
+
+func TestCoreSendPartialFailure(t *testing.T) {
+	core := NewCore()
+	core.Initialize()
+	core.Start()
+	defer core.Stop()
+
+	assert.NoError(t, core.RegisterPlugin(&mockOutputPlugin{id: "out", name: "Output"}))
+	assert.NoError(t, core.SetRetryPolicy("out", RetryPolicy{MaxAttempts: 2}))
+
+	batch := createTestBatch(3)
+	core.bufferManager.Buffer("out", batch)
+	flushed := core.bufferManager.Flush("out", 0)
+	assert.Len(t, flushed, 1)
+
+	// Only the failed part is requeued, and it keeps the batch's attempts
+	part := model.NewDataBatch(batch.BatchType)
+	part.AddPoint(batch.Points[2])
+	core.handleSendError("out", flushed[0], &model.PartialError{Err: errors.New("timeout"), Failed: part})
+	assert.Equal(t, 0, core.bufferManager.Attempts("out", batch))
+	assert.Equal(t, 1, core.bufferManager.Attempts("out", part))
+
+	flushed = core.bufferManager.Flush("out", 0)
+	assert.Equal(t, []*model.DataBatch{part}, flushed)
+	core.handleSendError("out", flushed[0], errors.New("timeout"))
+
+	entries := core.deadLetters.List("out")
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, 2, entries[0].Attempts)
+		assert.Equal(t, 1, entries[0].BatchSize)
+	}
+}
+
 func TestPublishEvent(t *testing.T) {
 	core := NewCore()
 	core.Initialize()
@@ -0,0 +1,345 @@
+package core
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sliink/collector/internal/model"
+)
+
+// DeadLetterEntry is a batch that could not be delivered to its output
+type DeadLetterEntry struct {
+	ID        string              `json:"id"`
+	OutputID  string              `json:"output_id"`
+	Attempts  int                 `json:"attempts"`
+	BatchType model.TelemetryType `json:"batch_type"`
+	BatchSize int                 `json:"batch_size"`
+	Timestamp time.Time           `json:"timestamp"`
+	Batch     *model.DataBatch    `json:"-"`
+}
+
+// DeadLetterSink sends an output's dead-lettered batches somewhere besides the queue
+type DeadLetterSink struct {
+	// Path is a file that keeps the entries across restarts
+	Path string
+	// OutputID is another output that receives the batches
+	OutputID string
+}
+
+// deadLetterRecord is the on-disk form of an entry
+type deadLetterRecord struct {
+	DeadLetterEntry
+	Batch json.RawMessage `json:"batch"`
+}
+
+// DeadLetterQueue holds batches that exhausted their retries so they can be
+// inspected and replayed
+type DeadLetterQueue struct {
+	entries    []DeadLetterEntry
+	sinks      map[string]DeadLetterSink
+	maxEntries int
+	nextID     uint64
+	mutex      sync.RWMutex
+	BaseComponent
+}
+
+// NewDeadLetterQueue creates a new dead-letter queue
+func NewDeadLetterQueue(maxEntries int) *DeadLetterQueue {
+	if maxEntries <= 0 {
+		maxEntries = 1000 // Default max entries
+	}
+
+	return &DeadLetterQueue{
+		entries:       make([]DeadLetterEntry, 0),
+		sinks:         make(map[string]DeadLetterSink),
+		maxEntries:    maxEntries,
+		BaseComponent: NewBaseComponent("dead_letter_queue", "Dead Letter Queue"),
+	}
+}
+
+// Initialize prepares the dead-letter queue for operation
+func (q *DeadLetterQueue) Initialize() bool {
+	q.SetStatus(model.StatusInitialized)
+	return true
+}
+
+// Start begins dead-letter queue operation
+func (q *DeadLetterQueue) Start() bool {
+	q.SetStatus(model.StatusRunning)
+	return true
+}
+
+// Stop halts dead-letter queue operation
+func (q *DeadLetterQueue) Stop() bool {
+	q.SetStatus(model.StatusStopped)
+	return true
+}
+
+// SetSink configures where an output's dead-lettered batches go. Entries
+// already stored in a file sink are loaded back into the queue. A sink that
+// would route batches back to the output, directly or through other sinks,
+// is rejected.
+func (q *DeadLetterQueue) SetSink(outputID string, sink DeadLetterSink) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	// Follow the chain of dead-letter outputs from the new sink
+	path := []string{outputID}
+	for next := sink.OutputID; next != ""; next = q.sinks[next].OutputID {
+		path = append(path, next)
+		if next == outputID {
+			return fmt.Errorf("dead-letter outputs form a cycle: %s", strings.Join(path, " -> "))
+		}
+		if len(path) > len(q.sinks)+1 {
+			break
+		}
+	}
+
+	if sink.Path != "" {
+		loaded, err := readDeadLetterFile(sink.Path, outputID)
+		if err != nil {
+			return err
+		}
+
+		// Drop entries for this output that were loaded from a previous sink
+		kept := q.entries[:0]
+		for _, entry := range q.entries {
+			if entry.OutputID != outputID {
+				kept = append(kept, entry)
+			}
+		}
+		q.entries = append(kept, loaded...)
+		sort.SliceStable(q.entries, func(i, j int) bool {
+			return q.entries[i].Timestamp.Before(q.entries[j].Timestamp)
+		})
+	}
+
+	q.sinks[outputID] = sink
+	return nil
+}
+
+// GetSink returns the sink configured for an output
+func (q *DeadLetterQueue) GetSink(outputID string) (DeadLetterSink, bool) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	sink, exists := q.sinks[outputID]
+	return sink, exists
+}
+
+// Add stores a batch that could not be delivered to an output
+func (q *DeadLetterQueue) Add(outputID string, batch *model.DataBatch, attempts int) (DeadLetterEntry, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.nextID++
+	entry := DeadLetterEntry{
+		ID:        fmt.Sprintf("%d-%d", time.Now().UnixNano(), q.nextID),
+		OutputID:  outputID,
+		Attempts:  attempts,
+		BatchType: batch.BatchType,
+		BatchSize: batch.Size(),
+		Timestamp: time.Now(),
+		Batch:     batch,
+	}
+	q.entries = append(q.entries, entry)
+
+	// Evict the oldest entries once the queue is full
+	evicted := make(map[string]bool)
+	for len(q.entries) > q.maxEntries {
+		if path := q.sinks[q.entries[0].OutputID].Path; path != "" {
+			evicted[path] = true
+		}
+		q.entries = q.entries[1:]
+	}
+
+	for path := range evicted {
+		if err := q.writeFile(path); err != nil {
+			return entry, err
+		}
+	}
+
+	if path := q.sinks[outputID].Path; path != "" && !evicted[path] {
+		if err := appendDeadLetterFile(path, entry); err != nil {
+			return entry, err
+		}
+	}
+
+	return entry, nil
+}
+
+// List returns the queued entries, optionally restricted to one output
+func (q *DeadLetterQueue) List(outputID string) []DeadLetterEntry {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	result := make([]DeadLetterEntry, 0, len(q.entries))
+	for _, entry := range q.entries {
+		if outputID == "" || entry.OutputID == outputID {
+			result = append(result, entry)
+		}
+	}
+
+	return result
+}
+
+// Get returns an entry by ID
+func (q *DeadLetterQueue) Get(id string) (DeadLetterEntry, bool) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, entry := range q.entries {
+		if entry.ID == id {
+			return entry, true
+		}
+	}
+
+	return DeadLetterEntry{}, false
+}
+
+// Remove deletes an entry by ID
+func (q *DeadLetterQueue) Remove(id string) (DeadLetterEntry, bool, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, entry := range q.entries {
+		if entry.ID != id {
+			continue
+		}
+
+		q.entries = append(q.entries[:i], q.entries[i+1:]...)
+
+		if path := q.sinks[entry.OutputID].Path; path != "" {
+			if err := q.writeFile(path); err != nil {
+				return entry, true, err
+			}
+		}
+
+		return entry, true, nil
+	}
+
+	return DeadLetterEntry{}, false, nil
+}
+
+// writeFile rewrites a sink file with the entries that belong to it
+func (q *DeadLetterQueue) writeFile(path string) error {
+	tmpPath := path + ".tmp"
+
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to write dead-letter file: %w", err)
+	}
+
+	writer := bufio.NewWriter(file)
+	for _, entry := range q.entries {
+		if q.sinks[entry.OutputID].Path != path {
+			continue
+		}
+		line, err := encodeDeadLetter(entry)
+		if err != nil {
+			file.Close()
+			return err
+		}
+		writer.Write(line)
+	}
+
+	err = writer.Flush()
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write dead-letter file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to write dead-letter file: %w", err)
+	}
+
+	return nil
+}
+
+// encodeDeadLetter serializes an entry as one line of NDJSON
+func encodeDeadLetter(entry DeadLetterEntry) ([]byte, error) {
+	batch, err := model.MarshalBatch(entry.Batch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode dead-letter entry: %w", err)
+	}
+
+	line, err := json.Marshal(deadLetterRecord{DeadLetterEntry: entry, Batch: batch})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode dead-letter entry: %w", err)
+	}
+
+	return append(line, '\n'), nil
+}
+
+// appendDeadLetterFile appends one entry to a sink file
+func appendDeadLetterFile(path string, entry DeadLetterEntry) error {
+	line, err := encodeDeadLetter(entry)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create dead-letter directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open dead-letter file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(line); err != nil {
+		return fmt.Errorf("failed to write dead-letter file: %w", err)
+	}
+
+	return nil
+}
+
+// readDeadLetterFile loads the entries for an output from a sink file
+func readDeadLetterFile(path string, outputID string) ([]DeadLetterEntry, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dead-letter file: %w", err)
+	}
+	defer file.Close()
+
+	var entries []DeadLetterEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var record deadLetterRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue // Skip partial lines
+		}
+		if record.OutputID != outputID {
+			continue
+		}
+
+		batch, err := model.UnmarshalBatch(record.Batch)
+		if err != nil {
+			continue
+		}
+
+		entry := record.DeadLetterEntry
+		entry.Batch = batch
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read dead-letter file: %w", err)
+	}
+
+	return entries, nil
+}
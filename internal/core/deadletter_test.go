@@ -0,0 +1,70 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/sliink/collector/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeadLetterQueue(t *testing.T) {
+	t.Run("Add, list, get and remove entries", func(t *testing.T) {
+		queue := NewDeadLetterQueue(10)
+
+		entry, err := queue.Add("out1", createTestBatch(3), 2)
+		assert.NoError(t, err)
+		assert.Equal(t, "out1", entry.OutputID)
+		assert.Equal(t, 3, entry.BatchSize)
+		assert.Equal(t, model.LogTelemetryType, entry.BatchType)
+
+		queue.Add("out2", createTestBatch(1), 1)
+		assert.Len(t, queue.List(""), 2)
+		assert.Len(t, queue.List("out2"), 1)
+
+		found, exists := queue.Get(entry.ID)
+		assert.True(t, exists)
+		assert.Equal(t, entry.ID, found.ID)
+
+		_, removed, err := queue.Remove(entry.ID)
+		assert.True(t, removed)
+		assert.NoError(t, err)
+		assert.Empty(t, queue.List("out1"))
+
+		_, removed, _ = queue.Remove(entry.ID)
+		assert.False(t, removed)
+	})
+
+	t.Run("Oldest entries are evicted when full", func(t *testing.T) {
+		queue := NewDeadLetterQueue(2)
+		first, _ := queue.Add("out", createTestBatch(1), 1)
+		queue.Add("out", createTestBatch(1), 1)
+		queue.Add("out", createTestBatch(1), 1)
+
+		assert.Len(t, queue.List("out"), 2)
+		_, exists := queue.Get(first.ID)
+		assert.False(t, exists)
+	})
+
+	t.Run("File sink keeps entries across restarts", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "dlq", "out.ndjson")
+
+		queue := NewDeadLetterQueue(10)
+		assert.NoError(t, queue.SetSink("out", DeadLetterSink{Path: path}))
+		kept, err := queue.Add("out", createLogBatch("kept"), 3)
+		assert.NoError(t, err)
+		removed, err := queue.Add("out", createLogBatch("removed"), 3)
+		assert.NoError(t, err)
+		_, _, err = queue.Remove(removed.ID)
+		assert.NoError(t, err)
+
+		reopened := NewDeadLetterQueue(10)
+		assert.NoError(t, reopened.SetSink("out", DeadLetterSink{Path: path}))
+
+		entries := reopened.List("out")
+		assert.Len(t, entries, 1)
+		assert.Equal(t, kept.ID, entries[0].ID)
+		assert.Equal(t, 3, entries[0].Attempts)
+		assert.Equal(t, "kept", entries[0].Batch.Points[0].(*model.LogPoint).Message)
+	})
+}
@@ -0,0 +1,246 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sliink/collector/internal/model"
+)
+
+// OverflowPolicy decides what happens to a batch when an output's buffer is full
+type OverflowPolicy string
+
+const (
+	// OverflowDropNewest rejects the incoming batch
+	OverflowDropNewest OverflowPolicy = "drop_newest"
+	// OverflowDropOldest evicts the oldest queued batches to make room
+	OverflowDropOldest OverflowPolicy = "drop_oldest"
+	// OverflowBlock waits for room, pushing back on the input, until a timeout
+	OverflowBlock OverflowPolicy = "block"
+	// OverflowSpillToDisk writes overflowing batches to disk until there is room
+	OverflowSpillToDisk OverflowPolicy = "spill_to_disk"
+)
+
+const spillFileExt = ".batch"
+
+// BufferLimits configures the size of an output's buffer and how it overflows
+type BufferLimits struct {
+	// MaxBatches caps the queued batches, zero uses the manager's max queue size
+	MaxBatches int
+	// MaxBytes caps the estimated size of queued batches, zero means unlimited
+	MaxBytes int64
+	// Overflow is the policy applied when a limit is reached
+	Overflow OverflowPolicy
+	// BlockTimeout bounds how long OverflowBlock waits for room
+	BlockTimeout time.Duration
+	// SpillDir holds spilled batches for OverflowSpillToDisk
+	SpillDir string
+}
+
+// DefaultBufferLimits returns the limits used for outputs without their own
+func DefaultBufferLimits() BufferLimits {
+	return BufferLimits{
+		Overflow:     OverflowDropNewest,
+		BlockTimeout: 5 * time.Second,
+	}
+}
+
+// ParseBufferLimits reads buffer limits from a config map, starting from the defaults
+func ParseBufferLimits(config map[string]interface{}) (BufferLimits, error) {
+	limits := DefaultBufferLimits()
+
+	if maxBatches, ok := intOption(config["max_batches"]); ok {
+		if maxBatches < 0 {
+			return limits, fmt.Errorf("max_batches must not be negative")
+		}
+		limits.MaxBatches = int(maxBatches)
+	}
+
+	if maxBytes, ok := intOption(config["max_bytes"]); ok {
+		if maxBytes < 0 {
+			return limits, fmt.Errorf("max_bytes must not be negative")
+		}
+		limits.MaxBytes = maxBytes
+	}
+
+	if overflow, ok := config["overflow"].(string); ok {
+		limits.Overflow = OverflowPolicy(overflow)
+	}
+
+	if timeoutStr, ok := config["block_timeout"].(string); ok {
+		timeout, err := time.ParseDuration(timeoutStr)
+		if err != nil || timeout <= 0 {
+			return limits, fmt.Errorf("invalid block_timeout: %s", timeoutStr)
+		}
+		limits.BlockTimeout = timeout
+	}
+
+	limits.SpillDir, _ = config["spill_dir"].(string)
+
+	if err := limits.validate(); err != nil {
+		return limits, err
+	}
+
+	return limits, nil
+}
+
+// validate checks that the limits are usable
+func (l BufferLimits) validate() error {
+	switch l.Overflow {
+	case OverflowDropNewest, OverflowDropOldest:
+	case OverflowBlock:
+		if l.BlockTimeout <= 0 {
+			return fmt.Errorf("block_timeout must be positive")
+		}
+	case OverflowSpillToDisk:
+		if l.SpillDir == "" {
+			return fmt.Errorf("spill_dir is required for spill_to_disk")
+		}
+	default:
+		return fmt.Errorf("invalid overflow policy: %s", l.Overflow)
+	}
+
+	return nil
+}
+
+// batchBytes estimates the memory held by a batch
+func batchBytes(batch *model.DataBatch) int64 {
+	var size int64
+	for _, point := range batch.Points {
+		size += pointBytes(point)
+	}
+
+	for _, record := range batch.Records {
+		size += int64(len(record.Source) + len(record.RawData))
+	}
+
+	return size
+}
+
+// pointBytes estimates the memory held by a data point
+func pointBytes(point model.DataPoint) int64 {
+	size := int64(32) // Timestamp and fixed fields
+	size += int64(len(point.GetOrigin()))
+	for k, v := range point.GetLabels() {
+		size += int64(len(k) + len(v))
+	}
+
+	switch p := point.(type) {
+	case *model.LogPoint:
+		size += int64(len(p.Message) + len(p.Level))
+		for k, v := range p.Attributes {
+			size += int64(len(k) + len(fmt.Sprint(v)))
+		}
+	case *model.MetricPoint:
+		size += int64(len(p.Name) + len(p.MetricType))
+		for k, v := range p.Dimensions {
+			size += int64(len(k) + len(v))
+		}
+	case *model.TracePoint:
+		size += int64(len(p.TraceID) + len(p.SpanID) + len(p.ParentSpanID) + 16)
+	}
+
+	return size
+}
+
+// spillQueue keeps overflow batches for one output in files on disk, oldest first.
+// Callers are responsible for synchronization.
+type spillQueue struct {
+	dir     string
+	seqs    []uint64
+	nextSeq uint64
+}
+
+// openSpillQueue opens the spill directory, picking up batches left by a previous run
+func openSpillQueue(dir string) (*spillQueue, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create spill directory: %w", err)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+spillFileExt))
+	if err != nil {
+		return nil, err
+	}
+
+	s := &spillQueue{dir: dir}
+	for _, path := range paths {
+		seq, err := strconv.ParseUint(strings.TrimSuffix(filepath.Base(path), spillFileExt), 10, 64)
+		if err != nil {
+			continue // Not one of ours
+		}
+		s.seqs = append(s.seqs, seq)
+		if seq >= s.nextSeq {
+			s.nextSeq = seq + 1
+		}
+	}
+
+	sort.Slice(s.seqs, func(i, j int) bool { return s.seqs[i] < s.seqs[j] })
+
+	return s, nil
+}
+
+// Len returns the number of spilled batches
+func (s *spillQueue) Len() int {
+	return len(s.seqs)
+}
+
+// Push writes a batch to the back of the queue
+func (s *spillQueue) Push(batch *model.DataBatch) error {
+	data, err := model.MarshalBatch(batch)
+	if err != nil {
+		return err
+	}
+
+	path := s.path(s.nextSeq)
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to spill batch: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to spill batch: %w", err)
+	}
+
+	s.seqs = append(s.seqs, s.nextSeq)
+	s.nextSeq++
+	return nil
+}
+
+// Peek reads the batch at the front of the queue, or nil if the queue is empty
+func (s *spillQueue) Peek() (*model.DataBatch, error) {
+	if len(s.seqs) == 0 {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(s.path(s.seqs[0]))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spilled batch: %w", err)
+	}
+
+	return model.UnmarshalBatch(data)
+}
+
+// Discard removes the batch at the front of the queue
+func (s *spillQueue) Discard() error {
+	if len(s.seqs) == 0 {
+		return nil
+	}
+
+	path := s.path(s.seqs[0])
+	s.seqs = s.seqs[1:]
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove spilled batch: %w", err)
+	}
+
+	return nil
+}
+
+// path returns the file for a spill sequence number
+func (s *spillQueue) path(seq uint64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%020d%s", seq, spillFileExt))
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBufferLimits(t *testing.T) {
+	t.Run("Empty config uses defaults", func(t *testing.T) {
+		limits, err := ParseBufferLimits(map[string]interface{}{})
+		assert.NoError(t, err)
+		assert.Equal(t, DefaultBufferLimits(), limits)
+	})
+
+	t.Run("Reads all settings", func(t *testing.T) {
+		limits, err := ParseBufferLimits(map[string]interface{}{
+			"max_batches":   float64(50),
+			"max_bytes":     float64(1048576),
+			"overflow":      "block",
+			"block_timeout": "2s",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 50, limits.MaxBatches)
+		assert.Equal(t, int64(1048576), limits.MaxBytes)
+		assert.Equal(t, OverflowBlock, limits.Overflow)
+		assert.Equal(t, 2*time.Second, limits.BlockTimeout)
+	})
+
+	t.Run("Rejects invalid settings", func(t *testing.T) {
+		invalid := []map[string]interface{}{
+			{"overflow": "drop_everything"},
+			{"overflow": "spill_to_disk"},
+			{"block_timeout": "never"},
+			{"max_bytes": float64(-1)},
+		}
+		for _, config := range invalid {
+			_, err := ParseBufferLimits(config)
+			assert.Error(t, err, "%v", config)
+		}
+	})
+}
+
+func TestSpillQueue(t *testing.T) {
+	dir := t.TempDir()
+	queue, err := openSpillQueue(dir)
+	assert.NoError(t, err)
+
+	assert.NoError(t, queue.Push(createLogBatch("one")))
+	assert.NoError(t, queue.Push(createLogBatch("two")))
+	assert.Equal(t, 2, queue.Len())
+
+	t.Run("Reopening picks up spilled batches in order", func(t *testing.T) {
+		reopened, err := openSpillQueue(dir)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, reopened.Len())
+
+		batch, err := reopened.Peek()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, batch.Size())
+
+		assert.NoError(t, reopened.Discard())
+		assert.NoError(t, reopened.Discard())
+		assert.Equal(t, 0, reopened.Len())
+
+		batch, err = reopened.Peek()
+		assert.NoError(t, err)
+		assert.Nil(t, batch)
+	})
+}
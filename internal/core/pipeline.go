@@ -2,6 +2,9 @@ package core
 
 import (
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/sliink/collector/internal/model"
@@ -40,10 +43,93 @@ func (s *PipelineStage) Process(batch *model.DataBatch) *model.DataBatch {
 	return processed
 }
 
+// pipelineTypes maps the type part of a pipeline name to its telemetry type
+var pipelineTypes = map[string]model.TelemetryType{
+	"logs":    model.LogTelemetryType,
+	"metrics": model.MetricTelemetryType,
+	"traces":  model.TraceTelemetryType,
+}
+
+// ParsePipelineName returns the telemetry type of a pipeline named <type> or <type>/<name>
+func ParsePipelineName(name string) (model.TelemetryType, error) {
+	typeName := name
+	if i := strings.Index(name, "/"); i >= 0 {
+		typeName = name[:i]
+		if suffix := name[i+1:]; suffix == "" || strings.Contains(suffix, "/") {
+			return "", fmt.Errorf("invalid pipeline name: %s", name)
+		}
+	}
+
+	telemetryType, exists := pipelineTypes[typeName]
+	if !exists {
+		return "", fmt.Errorf("unknown pipeline type: %s", typeName)
+	}
+
+	return telemetryType, nil
+}
+
+// PipelineName returns the default pipeline name for a telemetry type
+func PipelineName(telemetryType model.TelemetryType) string {
+	for name, t := range pipelineTypes {
+		if t == telemetryType {
+			return name
+		}
+	}
+	return ""
+}
+
+// Pipeline is a named processing chain and the plugins it is wired to
+type Pipeline struct {
+	Name          string
+	TelemetryType model.TelemetryType
+	FirstStage    *PipelineStage
+	ProcessorIDs  []string
+	InputIDs      []string
+	OutputIDs     []string
+}
+
+// hasInput reports whether the pipeline is fed by an input
+func (pl *Pipeline) hasInput(inputID string) bool {
+	for _, id := range pl.InputIDs {
+		if id == inputID {
+			return true
+		}
+	}
+	return false
+}
+
+// PipelineInfo describes a configured pipeline
+type PipelineInfo struct {
+	Name       string              `json:"name"`
+	Type       model.TelemetryType `json:"type"`
+	Processors []string            `json:"processors"`
+	Inputs     []string            `json:"inputs"`
+	Outputs    []string            `json:"outputs"`
+}
+
+// info returns a copy of the pipeline description
+func (pl *Pipeline) info() PipelineInfo {
+	return PipelineInfo{
+		Name:       pl.Name,
+		Type:       pl.TelemetryType,
+		Processors: append([]string{}, pl.ProcessorIDs...),
+		Inputs:     append([]string{}, pl.InputIDs...),
+		Outputs:    append([]string{}, pl.OutputIDs...),
+	}
+}
+
+// RoutedBatch is a processed batch together with the outputs that should receive it
+type RoutedBatch struct {
+	Pipeline  string
+	Batch     *model.DataBatch
+	OutputIDs []string
+}
+
 // DataPipeline manages the processing pipeline
 type DataPipeline struct {
-	pipelines map[model.TelemetryType]*PipelineStage
+	pipelines map[string]*Pipeline
 	registry  *PluginRegistry
+	routing   bool // A route has been declared, so batches only follow routes
 	mutex     sync.RWMutex
 	BaseComponent
 }
@@ -51,7 +137,7 @@ type DataPipeline struct {
 // NewDataPipeline creates a new data pipeline
 func NewDataPipeline(registry *PluginRegistry) *DataPipeline {
 	return &DataPipeline{
-		pipelines:     make(map[model.TelemetryType]*PipelineStage),
+		pipelines:     make(map[string]*Pipeline),
 		registry:      registry,
 		BaseComponent: NewBaseComponent("data_pipeline", "Data Pipeline"),
 	}
@@ -79,14 +165,33 @@ func (p *DataPipeline) Stop() bool {
 	defer p.mutex.Unlock()
 
 	// Clear all pipelines
-	p.pipelines = make(map[model.TelemetryType]*PipelineStage)
+	p.pipelines = make(map[string]*Pipeline)
+	p.routing = false
 	
 	p.SetStatus(model.StatusStopped)
 	return true
 }
 
-// CreatePipeline builds a processing pipeline for a telemetry type
-func (p *DataPipeline) CreatePipeline(telemetryType model.TelemetryType, processorIDs []string) error {
+// getOrCreate returns the named pipeline, creating an empty one if needed
+func (p *DataPipeline) getOrCreate(name string, telemetryType model.TelemetryType) *Pipeline {
+	pipeline, exists := p.pipelines[name]
+	if !exists {
+		pipeline = &Pipeline{
+			Name:          name,
+			TelemetryType: telemetryType,
+		}
+		p.pipelines[name] = pipeline
+	}
+	return pipeline
+}
+
+// CreatePipeline builds the processing chain of a pipeline named <type> or <type>/<name>
+func (p *DataPipeline) CreatePipeline(name string, processorIDs []string) error {
+	telemetryType, err := ParsePipelineName(name)
+	if err != nil {
+		return err
+	}
+
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
@@ -122,11 +227,98 @@ func (p *DataPipeline) CreatePipeline(telemetryType model.TelemetryType, process
 		}
 	}
 
-	p.pipelines[telemetryType] = firstStage
+	pipeline := p.getOrCreate(name, telemetryType)
+	pipeline.FirstStage = firstStage
+	pipeline.ProcessorIDs = append([]string(nil), processorIDs...)
 	return nil
 }
 
-// Process sends a data batch through the pipeline
+// SetRoute declares the inputs and outputs of a pipeline named <type> or <type>/<name>
+func (p *DataPipeline) SetRoute(name string, inputIDs, outputIDs []string) error {
+	telemetryType, err := ParsePipelineName(name)
+	if err != nil {
+		return err
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	// Every referenced plugin must exist and be of the right kind
+	for _, inputID := range inputIDs {
+		plugin, exists := p.registry.GetPlugin(inputID)
+		if !exists {
+			return errors.New("input plugin not found: " + inputID)
+		}
+		if _, ok := plugin.(model.InputPlugin); !ok {
+			return errors.New("plugin is not an input: " + inputID)
+		}
+	}
+
+	for _, outputID := range outputIDs {
+		plugin, exists := p.registry.GetPlugin(outputID)
+		if !exists {
+			return errors.New("output plugin not found: " + outputID)
+		}
+		if _, ok := plugin.(model.OutputPlugin); !ok {
+			return errors.New("plugin is not an output: " + outputID)
+		}
+	}
+
+	pipeline := p.getOrCreate(name, telemetryType)
+	pipeline.InputIDs = append([]string(nil), inputIDs...)
+	pipeline.OutputIDs = append([]string(nil), outputIDs...)
+	p.routing = true
+	return nil
+}
+
+// DeletePipeline removes a pipeline by name
+func (p *DataPipeline) DeletePipeline(name string) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if _, exists := p.pipelines[name]; !exists {
+		return false
+	}
+
+	delete(p.pipelines, name)
+	return true
+}
+
+// GetPipeline returns the description of a pipeline by name
+func (p *DataPipeline) GetPipeline(name string) (PipelineInfo, bool) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	pipeline, exists := p.pipelines[name]
+	if !exists {
+		return PipelineInfo{}, false
+	}
+	return pipeline.info(), true
+}
+
+// GetPipelines returns descriptions of all pipelines ordered by name
+func (p *DataPipeline) GetPipelines() []PipelineInfo {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	result := make([]PipelineInfo, 0, len(p.pipelines))
+	for _, name := range p.sortedNames() {
+		result = append(result, p.pipelines[name].info())
+	}
+	return result
+}
+
+// sortedNames returns pipeline names in a stable order (caller holds the lock)
+func (p *DataPipeline) sortedNames() []string {
+	names := make([]string, 0, len(p.pipelines))
+	for name := range p.pipelines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Process sends a data batch through the default pipeline for its type
 func (p *DataPipeline) Process(batch *model.DataBatch) *model.DataBatch {
 	if batch == nil || batch.Size() == 0 {
 		return nil
@@ -140,12 +332,181 @@ func (p *DataPipeline) Process(batch *model.DataBatch) *model.DataBatch {
 	}
 
 	// Find pipeline for batch type
-	pipeline, exists := p.pipelines[batch.BatchType]
-	if !exists || pipeline == nil {
+	pipeline, exists := p.pipelines[PipelineName(batch.BatchType)]
+	if !exists || pipeline.FirstStage == nil {
 		// No processing needed, return original batch
 		return batch
 	}
 
 	// Process the batch through the pipeline
-	return pipeline.Process(batch)
-}
\ No newline at end of file
+	return pipeline.FirstStage.Process(batch)
+}
+
+// HasRoutes reports whether a pipeline has declared its inputs and outputs.
+// Routing stays in effect after the routed pipelines are deleted, so that
+// deleting a pipeline never starts delivering every batch to every output.
+func (p *DataPipeline) HasRoutes() bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.routing
+}
+
+// Route sends a batch from an input through every pipeline that input feeds
+func (p *DataPipeline) Route(inputID string, batch *model.DataBatch) []RoutedBatch {
+	if batch == nil || batch.Size() == 0 {
+		return nil
+	}
+
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if p.GetStatus() != model.StatusRunning {
+		return nil
+	}
+
+	// Find the pipelines this batch belongs to
+	var targets []*Pipeline
+	for _, name := range p.sortedNames() {
+		pipeline := p.pipelines[name]
+		if pipeline.TelemetryType == batch.BatchType && pipeline.hasInput(inputID) && len(pipeline.OutputIDs) > 0 {
+			targets = append(targets, pipeline)
+		}
+	}
+
+	var result []RoutedBatch
+	for _, pipeline := range targets {
+		// Give each pipeline its own copy so processors cannot affect one another
+		processed := batch
+		if len(targets) > 1 {
+			processed = copyBatch(batch)
+		}
+
+		if pipeline.FirstStage != nil {
+			processed = pipeline.FirstStage.Process(processed)
+		}
+
+		if processed == nil || processed.Size() == 0 {
+			continue
+		}
+
+		result = append(result, RoutedBatch{
+			Pipeline:  pipeline.Name,
+			Batch:     processed,
+			OutputIDs: pipeline.OutputIDs,
+		})
+	}
+
+	return result
+}
+
+// OutputsForInput returns the IDs of outputs wired to pipelines an input feeds
+func (p *DataPipeline) OutputsForInput(inputID string) []string {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	var result []string
+	seen := make(map[string]bool)
+	for _, name := range p.sortedNames() {
+		pipeline := p.pipelines[name]
+		if !pipeline.hasInput(inputID) {
+			continue
+		}
+		for _, outputID := range pipeline.OutputIDs {
+			if !seen[outputID] {
+				seen[outputID] = true
+				result = append(result, outputID)
+			}
+		}
+	}
+	return result
+}
+
+// OutputsForType returns the IDs of outputs wired to a telemetry type
+func (p *DataPipeline) OutputsForType(telemetryType model.TelemetryType) []string {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	var result []string
+	seen := make(map[string]bool)
+	for _, name := range p.sortedNames() {
+		pipeline := p.pipelines[name]
+		if pipeline.TelemetryType != telemetryType {
+			continue
+		}
+		for _, outputID := range pipeline.OutputIDs {
+			if !seen[outputID] {
+				seen[outputID] = true
+				result = append(result, outputID)
+			}
+		}
+	}
+	return result
+}
+
+// copyBatch makes a deep copy of a batch, so processors of one pipeline
+// cannot change the points, records or attributes another pipeline sees.
+// Attribute values that are themselves maps or slices are still shared.
+func copyBatch(batch *model.DataBatch) *model.DataBatch {
+	copied := *batch
+	copied.Points = make([]model.DataPoint, len(batch.Points))
+	for i, point := range batch.Points {
+		copied.Points[i] = copyPoint(point)
+	}
+	copied.Records = make([]model.Record, len(batch.Records))
+	for i, record := range batch.Records {
+		copied.Records[i] = record
+		copied.Records[i].RawData = append([]byte(nil), record.RawData...)
+		copied.Records[i].Attributes = copyAttributes(record.Attributes)
+	}
+	copied.Attributes = copyAttributes(batch.Attributes)
+	return &copied
+}
+
+// copyPoint copies a data point along with its labels, dimensions and
+// attributes. Points of unknown types are shared.
+func copyPoint(point model.DataPoint) model.DataPoint {
+	switch p := point.(type) {
+	case *model.LogPoint:
+		copied := *p
+		copied.Labels = copyLabels(p.Labels)
+		copied.Attributes = copyAttributes(p.Attributes)
+		return &copied
+	case *model.MetricPoint:
+		copied := *p
+		copied.Labels = copyLabels(p.Labels)
+		copied.Dimensions = copyLabels(p.Dimensions)
+		return &copied
+	case *model.TracePoint:
+		copied := *p
+		copied.Labels = copyLabels(p.Labels)
+		copied.Attributes = copyAttributes(p.Attributes)
+		return &copied
+	default:
+		return point
+	}
+}
+
+// copyLabels copies a string map, keeping nil as nil
+func copyLabels(labels map[string]string) map[string]string {
+	if labels == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(labels))
+	for k, v := range labels {
+		copied[k] = v
+	}
+	return copied
+}
+
+// copyAttributes copies an attribute map, keeping nil as nil
+func copyAttributes(attributes map[string]interface{}) map[string]interface{} {
+	if attributes == nil {
+		return nil
+	}
+	copied := make(map[string]interface{}, len(attributes))
+	for k, v := range attributes {
+		copied[k] = v
+	}
+	return copied
+}
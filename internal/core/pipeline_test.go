@@ -259,7 +259,7 @@ func TestDataPipelineLifecycle(t *testing.T) {
 	
 	t.Run("Stop clears pipelines and sets correct status", func(t *testing.T) {
 		// Create a pipeline first
-		err := pipeline.CreatePipeline(model.LogTelemetryType, []string{"passthrough"})
+		err := pipeline.CreatePipeline("logs", []string{"passthrough"})
 		assert.NoError(t, err)
 		assert.NotEmpty(t, pipeline.pipelines)
 		
@@ -277,12 +277,12 @@ func TestCreatePipeline(t *testing.T) {
 	pipeline.Initialize()
 	
 	t.Run("Empty processor list returns error", func(t *testing.T) {
-		err := pipeline.CreatePipeline(model.LogTelemetryType, []string{})
+		err := pipeline.CreatePipeline("logs", []string{})
 		assert.Error(t, err)
 	})
 	
 	t.Run("Nonexistent processor returns error", func(t *testing.T) {
-		err := pipeline.CreatePipeline(model.LogTelemetryType, []string{"nonexistent"})
+		err := pipeline.CreatePipeline("logs", []string{"nonexistent"})
 		assert.Error(t, err)
 	})
 	
@@ -291,23 +291,25 @@ func TestCreatePipeline(t *testing.T) {
 	})
 	
 	t.Run("Valid processor list creates pipeline", func(t *testing.T) {
-		err := pipeline.CreatePipeline(model.LogTelemetryType, []string{"passthrough"})
+		err := pipeline.CreatePipeline("logs", []string{"passthrough"})
 		assert.NoError(t, err)
 		
 		// Verify pipeline was created
-		stage, exists := pipeline.pipelines[model.LogTelemetryType]
+		named, exists := pipeline.pipelines["logs"]
 		assert.True(t, exists)
+		stage := named.FirstStage
 		assert.NotNil(t, stage)
 		assert.Equal(t, "passthrough", stage.Processor.ID())
 	})
 	
 	t.Run("Multiple processors create chained pipeline", func(t *testing.T) {
-		err := pipeline.CreatePipeline(model.MetricTelemetryType, []string{"passthrough", "doubler"})
+		err := pipeline.CreatePipeline("metrics", []string{"passthrough", "doubler"})
 		assert.NoError(t, err)
 		
 		// Verify pipeline was created
-		stage, exists := pipeline.pipelines[model.MetricTelemetryType]
+		named, exists := pipeline.pipelines["metrics"]
 		assert.True(t, exists)
+		stage := named.FirstStage
 		assert.NotNil(t, stage)
 		assert.Equal(t, "passthrough", stage.Processor.ID())
 		
@@ -324,10 +326,10 @@ func TestProcessMethod(t *testing.T) {
 	pipeline.Start()
 	
 	// Create two pipelines
-	err := pipeline.CreatePipeline(model.LogTelemetryType, []string{"doubler"})
+	err := pipeline.CreatePipeline("logs", []string{"doubler"})
 	assert.NoError(t, err)
 	
-	err = pipeline.CreatePipeline(model.MetricTelemetryType, []string{"filter"})
+	err = pipeline.CreatePipeline("metrics", []string{"filter"})
 	assert.NoError(t, err)
 	
 	t.Run("Process returns nil for nil batch", func(t *testing.T) {
@@ -378,4 +380,226 @@ func TestProcessMethod(t *testing.T) {
 		assert.NotNil(t, result)
 		assert.Equal(t, 0, result.Size()) // Filtered by the processor
 	})
-}
\ No newline at end of file
+}
+func TestSetRoute(t *testing.T) {
+	registry := createTestRegistry()
+	registry.RegisterPlugin(&mockInputPlugin{id: "in", name: "Input"})
+	registry.RegisterPlugin(&mockOutputPlugin{id: "out", name: "Output"})
+	pipeline := NewDataPipeline(registry)
+	pipeline.Initialize()
+
+	t.Run("Unknown input returns error", func(t *testing.T) {
+		err := pipeline.SetRoute("logs", []string{"missing"}, []string{"out"})
+		assert.Error(t, err)
+	})
+
+	t.Run("Unknown output returns error", func(t *testing.T) {
+		err := pipeline.SetRoute("logs", []string{"in"}, []string{"missing"})
+		assert.Error(t, err)
+	})
+
+	t.Run("Plugin of the wrong kind returns error", func(t *testing.T) {
+		err := pipeline.SetRoute("logs", []string{"passthrough"}, []string{"out"})
+		assert.Error(t, err)
+
+		err = pipeline.SetRoute("logs", []string{"in"}, []string{"in"})
+		assert.Error(t, err)
+	})
+
+	t.Run("Valid route is stored", func(t *testing.T) {
+		assert.False(t, pipeline.HasRoutes())
+
+		err := pipeline.SetRoute("logs", []string{"in"}, []string{"out"})
+		assert.NoError(t, err)
+		assert.True(t, pipeline.HasRoutes())
+
+		info, exists := pipeline.GetPipeline("logs")
+		assert.True(t, exists)
+		assert.Equal(t, model.LogTelemetryType, info.Type)
+		assert.Equal(t, []string{"in"}, info.Inputs)
+		assert.Equal(t, []string{"out"}, info.Outputs)
+		assert.Equal(t, []string{"out"}, pipeline.OutputsForType(model.LogTelemetryType))
+		assert.Empty(t, pipeline.OutputsForType(model.MetricTelemetryType))
+		assert.Equal(t, []string{"out"}, pipeline.OutputsForInput("in"))
+		assert.Empty(t, pipeline.OutputsForInput("other"))
+	})
+}
+
+func TestRouteMethod(t *testing.T) {
+	registry := createTestRegistry()
+	registry.RegisterPlugin(&mockInputPlugin{id: "app", name: "App Input"})
+	registry.RegisterPlugin(&mockInputPlugin{id: "other", name: "Other Input"})
+	registry.RegisterPlugin(&mockOutputPlugin{id: "log_out", name: "Log Output"})
+	registry.RegisterPlugin(&mockOutputPlugin{id: "metric_out", name: "Metric Output"})
+
+	pipeline := NewDataPipeline(registry)
+	pipeline.Initialize()
+	pipeline.Start()
+
+	assert.NoError(t, pipeline.CreatePipeline("logs", []string{"doubler"}))
+	assert.NoError(t, pipeline.SetRoute("logs", []string{"app"}, []string{"log_out"}))
+	assert.NoError(t, pipeline.SetRoute("metrics", []string{"app", "other"}, []string{"metric_out"}))
+
+	t.Run("Batch from a declared input is processed and routed", func(t *testing.T) {
+		routed := pipeline.Route("app", createTestBatch(2))
+		assert.Len(t, routed, 1)
+		assert.Equal(t, 4, routed[0].Batch.Size())
+		assert.Equal(t, []string{"log_out"}, routed[0].OutputIDs)
+	})
+
+	t.Run("Batch from an undeclared input is dropped", func(t *testing.T) {
+		routed := pipeline.Route("other", createTestBatch(2))
+		assert.Empty(t, routed)
+	})
+
+	t.Run("One input feeds several pipelines by batch type", func(t *testing.T) {
+		batch := createTestBatch(3)
+		batch.BatchType = model.MetricTelemetryType
+
+		routed := pipeline.Route("app", batch)
+		assert.Len(t, routed, 1)
+		assert.Equal(t, 3, routed[0].Batch.Size())
+		assert.Equal(t, []string{"metric_out"}, routed[0].OutputIDs)
+	})
+
+	t.Run("Batch type without a route is dropped", func(t *testing.T) {
+		batch := createTestBatch(1)
+		batch.BatchType = model.TraceTelemetryType
+
+		assert.Empty(t, pipeline.Route("app", batch))
+	})
+}
+
+func TestParsePipelineName(t *testing.T) {
+	t.Run("Bare type names map to telemetry types", func(t *testing.T) {
+		for name, expected := range map[string]model.TelemetryType{
+			"logs":    model.LogTelemetryType,
+			"metrics": model.MetricTelemetryType,
+			"traces":  model.TraceTelemetryType,
+		} {
+			telemetryType, err := ParsePipelineName(name)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, telemetryType)
+			assert.Equal(t, name, PipelineName(expected))
+		}
+	})
+
+	t.Run("Named pipelines use the type prefix", func(t *testing.T) {
+		telemetryType, err := ParsePipelineName("logs/audit")
+		assert.NoError(t, err)
+		assert.Equal(t, model.LogTelemetryType, telemetryType)
+	})
+
+	t.Run("Invalid names return error", func(t *testing.T) {
+		for _, name := range []string{"", "events", "events/app", "logs/", "logs/app/extra"} {
+			_, err := ParsePipelineName(name)
+			assert.Error(t, err, name)
+		}
+	})
+}
+
+func TestNamedPipelines(t *testing.T) {
+	registry := createTestRegistry()
+	registry.RegisterPlugin(&mockInputPlugin{id: "app", name: "App Input"})
+	registry.RegisterPlugin(&mockOutputPlugin{id: "app_out", name: "App Output"})
+	registry.RegisterPlugin(&mockOutputPlugin{id: "audit_out", name: "Audit Output"})
+
+	pipeline := NewDataPipeline(registry)
+	pipeline.Initialize()
+	pipeline.Start()
+
+	assert.NoError(t, pipeline.CreatePipeline("logs/app", []string{"passthrough"}))
+	assert.NoError(t, pipeline.SetRoute("logs/app", []string{"app"}, []string{"app_out"}))
+	assert.NoError(t, pipeline.CreatePipeline("logs/audit", []string{"doubler"}))
+	assert.NoError(t, pipeline.SetRoute("logs/audit", []string{"app"}, []string{"audit_out"}))
+
+	t.Run("CreatePipeline rejects unknown types", func(t *testing.T) {
+		assert.Error(t, pipeline.CreatePipeline("events/app", []string{"passthrough"}))
+		assert.Error(t, pipeline.SetRoute("events/app", []string{"app"}, []string{"app_out"}))
+	})
+
+	t.Run("Each named pipeline processes and routes independently", func(t *testing.T) {
+		batch := createTestBatch(2)
+		routed := pipeline.Route("app", batch)
+
+		assert.Len(t, routed, 2)
+		assert.Equal(t, "logs/app", routed[0].Pipeline)
+		assert.Equal(t, 2, routed[0].Batch.Size())
+		assert.Equal(t, []string{"app_out"}, routed[0].OutputIDs)
+		assert.Equal(t, "logs/audit", routed[1].Pipeline)
+		assert.Equal(t, 4, routed[1].Batch.Size())
+		assert.Equal(t, []string{"audit_out"}, routed[1].OutputIDs)
+
+		// The original batch is left untouched
+		assert.Equal(t, 2, batch.Size())
+	})
+
+	t.Run("OutputsForType merges outputs across pipelines", func(t *testing.T) {
+		assert.Equal(t, []string{"app_out", "audit_out"}, pipeline.OutputsForType(model.LogTelemetryType))
+	})
+
+	t.Run("GetPipelines lists pipelines by name", func(t *testing.T) {
+		infos := pipeline.GetPipelines()
+		assert.Len(t, infos, 2)
+		assert.Equal(t, "logs/app", infos[0].Name)
+		assert.Equal(t, []string{"passthrough"}, infos[0].Processors)
+		assert.Equal(t, "logs/audit", infos[1].Name)
+		assert.Equal(t, []string{"doubler"}, infos[1].Processors)
+	})
+
+	t.Run("DeletePipeline removes only the named pipeline", func(t *testing.T) {
+		assert.True(t, pipeline.DeletePipeline("logs/audit"))
+		assert.False(t, pipeline.DeletePipeline("logs/audit"))
+
+		_, exists := pipeline.GetPipeline("logs/app")
+		assert.True(t, exists)
+
+		routed := pipeline.Route("app", createTestBatch(1))
+		assert.Len(t, routed, 1)
+		assert.Equal(t, "logs/app", routed[0].Pipeline)
+	})
+
+	t.Run("Deleting every routed pipeline keeps routing", func(t *testing.T) {
+		assert.True(t, pipeline.DeletePipeline("logs/app"))
+		assert.True(t, pipeline.HasRoutes())
+		assert.Empty(t, pipeline.Route("app", createTestBatch(1)))
+		assert.Empty(t, pipeline.OutputsForInput("app"))
+	})
+}
+
+func TestCopyBatch(t *testing.T) {
+	batch := model.NewDataBatch(model.LogTelemetryType)
+	batch.AddPoint(&model.LogPoint{
+		BaseDataPoint: model.BaseDataPoint{Labels: map[string]string{"env": "prod"}},
+		Message:       "hello",
+		Attributes:    map[string]interface{}{"user": "alice"},
+	})
+	batch.AddPoint(&model.MetricPoint{
+		BaseDataPoint: model.BaseDataPoint{Labels: map[string]string{"env": "prod"}},
+		Name:          "requests",
+		Dimensions:    map[string]string{"route": "/"},
+	})
+	batch.Records = append(batch.Records, model.Record{RawData: []byte("raw"), Attributes: map[string]interface{}{"k": "v"}})
+
+	copied := copyBatch(batch)
+
+	// Changing the copy leaves the original untouched
+	log := copied.Points[0].(*model.LogPoint)
+	log.Message = "changed"
+	log.Labels["env"] = "dev"
+	log.Attributes["user"] = "bob"
+	metric := copied.Points[1].(*model.MetricPoint)
+	metric.Labels["env"] = "dev"
+	metric.Dimensions["route"] = "/admin"
+	copied.Records[0].RawData[0] = 'R'
+	copied.Records[0].Attributes["k"] = "changed"
+
+	original := batch.Points[0].(*model.LogPoint)
+	assert.Equal(t, "hello", original.Message)
+	assert.Equal(t, "prod", original.Labels["env"])
+	assert.Equal(t, "alice", original.Attributes["user"])
+	assert.Equal(t, "prod", batch.Points[1].(*model.MetricPoint).Labels["env"])
+	assert.Equal(t, "/", batch.Points[1].(*model.MetricPoint).Dimensions["route"])
+	assert.Equal(t, "raw", string(batch.Records[0].RawData))
+	assert.Equal(t, "v", batch.Records[0].Attributes["k"])
+}
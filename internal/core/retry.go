@@ -0,0 +1,89 @@
+package core
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy controls how failed sends to an output are retried
+type RetryPolicy struct {
+	// MaxAttempts is the total number of send attempts, including the first
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between retries
+	MaxBackoff time.Duration
+	// Jitter randomizes each delay by up to this fraction, from 0 to 1
+	Jitter float64
+}
+
+// DefaultRetryPolicy returns the policy used for outputs without their own
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Second,
+		MaxBackoff:     30 * time.Second,
+		Jitter:         0.2,
+	}
+}
+
+// ParseRetryPolicy reads a retry policy from a config map, starting from the defaults
+func ParseRetryPolicy(config map[string]interface{}) (RetryPolicy, error) {
+	policy := DefaultRetryPolicy()
+
+	if attempts, ok := intOption(config["max_attempts"]); ok {
+		if attempts < 1 {
+			return policy, fmt.Errorf("max_attempts must be at least 1")
+		}
+		policy.MaxAttempts = int(attempts)
+	}
+
+	if backoffStr, ok := config["initial_backoff"].(string); ok {
+		backoff, err := time.ParseDuration(backoffStr)
+		if err != nil || backoff < 0 {
+			return policy, fmt.Errorf("invalid initial_backoff: %s", backoffStr)
+		}
+		policy.InitialBackoff = backoff
+	}
+
+	if backoffStr, ok := config["max_backoff"].(string); ok {
+		backoff, err := time.ParseDuration(backoffStr)
+		if err != nil || backoff < 0 {
+			return policy, fmt.Errorf("invalid max_backoff: %s", backoffStr)
+		}
+		policy.MaxBackoff = backoff
+	}
+
+	if policy.MaxBackoff < policy.InitialBackoff {
+		return policy, fmt.Errorf("max_backoff must not be less than initial_backoff")
+	}
+
+	if jitter, ok := config["jitter"].(float64); ok {
+		if jitter < 0 || jitter > 1 {
+			return policy, fmt.Errorf("jitter must be between 0 and 1")
+		}
+		policy.Jitter = jitter
+	}
+
+	return policy, nil
+}
+
+// Backoff returns the delay before the given retry, where 1 is the first retry.
+// The delay doubles with each retry up to MaxBackoff, then jitter is applied.
+func (p RetryPolicy) Backoff(retry int) time.Duration {
+	delay := p.InitialBackoff
+	for i := 1; i < retry && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+
+	if p.Jitter > 0 && delay > 0 {
+		spread := float64(delay) * p.Jitter
+		delay = time.Duration(float64(delay) - spread + rand.Float64()*2*spread)
+	}
+
+	return delay
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRetryPolicy(t *testing.T) {
+	t.Run("Empty config uses defaults", func(t *testing.T) {
+		policy, err := ParseRetryPolicy(map[string]interface{}{})
+		assert.NoError(t, err)
+		assert.Equal(t, DefaultRetryPolicy(), policy)
+	})
+
+	t.Run("Reads all settings", func(t *testing.T) {
+		policy, err := ParseRetryPolicy(map[string]interface{}{
+			"max_attempts":    float64(5),
+			"initial_backoff": "500ms",
+			"max_backoff":     "10s",
+			"jitter":          0.5,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 5, policy.MaxAttempts)
+		assert.Equal(t, 500*time.Millisecond, policy.InitialBackoff)
+		assert.Equal(t, 10*time.Second, policy.MaxBackoff)
+		assert.Equal(t, 0.5, policy.Jitter)
+	})
+
+	t.Run("Rejects invalid settings", func(t *testing.T) {
+		invalid := []map[string]interface{}{
+			{"max_attempts": float64(0)},
+			{"initial_backoff": "soon"},
+			{"initial_backoff": "1m", "max_backoff": "1s"},
+			{"jitter": 1.5},
+		}
+		for _, config := range invalid {
+			_, err := ParseRetryPolicy(config)
+			assert.Error(t, err, "%v", config)
+		}
+	})
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	t.Run("Backoff doubles up to the maximum", func(t *testing.T) {
+		policy := RetryPolicy{MaxAttempts: 10, InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+		assert.Equal(t, time.Second, policy.Backoff(1))
+		assert.Equal(t, 2*time.Second, policy.Backoff(2))
+		assert.Equal(t, 4*time.Second, policy.Backoff(3))
+		assert.Equal(t, 5*time.Second, policy.Backoff(4))
+		assert.Equal(t, 5*time.Second, policy.Backoff(100))
+	})
+
+	t.Run("Jitter stays within bounds", func(t *testing.T) {
+		policy := RetryPolicy{MaxAttempts: 10, InitialBackoff: time.Second, MaxBackoff: time.Minute, Jitter: 0.5}
+		for i := 0; i < 100; i++ {
+			delay := policy.Backoff(1)
+			assert.GreaterOrEqual(t, delay, 500*time.Millisecond)
+			assert.LessOrEqual(t, delay, 1500*time.Millisecond)
+		}
+	})
+}
@@ -0,0 +1,477 @@
+package core
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sliink/collector/internal/model"
+)
+
+// FsyncPolicy controls when the write-ahead log is synced to stable storage
+type FsyncPolicy string
+
+const (
+	// FsyncAlways syncs after every write
+	FsyncAlways FsyncPolicy = "always"
+	// FsyncInterval syncs pending writes on a fixed interval
+	FsyncInterval FsyncPolicy = "interval"
+	// FsyncNever leaves syncing to the operating system
+	FsyncNever FsyncPolicy = "never"
+)
+
+const (
+	walSegmentExt      = ".wal"
+	walCheckpointFile  = "checkpoint"
+	walHeaderSize      = 8 // record length and checksum
+	walSeqSize         = 8
+	defaultSegmentSize = 16 * 1024 * 1024
+)
+
+// errLogFull is returned when an append would exceed the configured disk limit
+var errLogFull = errors.New("write-ahead log is full")
+
+// PersistenceOptions configures the disk-backed buffer mode
+type PersistenceOptions struct {
+	// Dir holds one log directory per output ID
+	Dir string
+	// Fsync selects when writes are synced to disk
+	Fsync FsyncPolicy
+	// FsyncInterval is the sync period for FsyncInterval
+	FsyncInterval time.Duration
+	// SegmentSize is the size at which a new segment file is started
+	SegmentSize int64
+	// MaxDiskSize caps the bytes on disk per output, zero means unlimited
+	MaxDiskSize int64
+}
+
+// parsePersistenceOptions reads persistence options from a config map
+func parsePersistenceOptions(config map[string]interface{}) (PersistenceOptions, error) {
+	opts := PersistenceOptions{
+		Fsync:         FsyncInterval,
+		FsyncInterval: time.Second,
+		SegmentSize:   defaultSegmentSize,
+	}
+
+	opts.Dir, _ = config["dir"].(string)
+	if opts.Dir == "" {
+		return opts, fmt.Errorf("persistence dir is required")
+	}
+
+	if fsync, ok := config["fsync"].(string); ok {
+		switch FsyncPolicy(fsync) {
+		case FsyncAlways, FsyncInterval, FsyncNever:
+			opts.Fsync = FsyncPolicy(fsync)
+		default:
+			return opts, fmt.Errorf("invalid fsync policy: %s", fsync)
+		}
+	}
+
+	if intervalStr, ok := config["fsync_interval"].(string); ok {
+		interval, err := time.ParseDuration(intervalStr)
+		if err != nil || interval <= 0 {
+			return opts, fmt.Errorf("invalid fsync_interval: %s", intervalStr)
+		}
+		opts.FsyncInterval = interval
+	}
+
+	if size, ok := intOption(config["segment_size"]); ok {
+		if size <= 0 {
+			return opts, fmt.Errorf("segment_size must be positive")
+		}
+		opts.SegmentSize = size
+	}
+
+	if size, ok := intOption(config["max_disk_size"]); ok {
+		if size < 0 {
+			return opts, fmt.Errorf("max_disk_size must not be negative")
+		}
+		opts.MaxDiskSize = size
+	}
+
+	return opts, nil
+}
+
+// intOption converts a numeric config value, which is float64 when decoded from JSON
+func intOption(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int64:
+		return v, true
+	case float64:
+		return int64(v), true
+	}
+	return 0, false
+}
+
+// walSegment is one file of a write-ahead log
+type walSegment struct {
+	path     string
+	firstSeq uint64
+	size     int64
+}
+
+// walEntry is a batch read back from the log
+type walEntry struct {
+	seq   uint64
+	batch *model.DataBatch
+}
+
+// writeAheadLog is the segmented on-disk log for a single output.
+// Records are appended with increasing sequence numbers; acknowledged sequences
+// advance a checkpoint, and segments entirely below it are deleted.
+// Callers are responsible for synchronization.
+type writeAheadLog struct {
+	dir      string
+	opts     PersistenceOptions
+	segments []*walSegment // oldest first, the last one is active
+	active   *os.File
+	nextSeq  uint64
+	ackedSeq uint64          // every sequence below this has been acknowledged
+	acks     map[uint64]bool // acknowledged sequences at or above ackedSeq
+	size     int64
+	dirty    bool
+}
+
+// openWriteAheadLog opens the log in dir and returns the unacknowledged entries it holds
+func openWriteAheadLog(dir string, opts PersistenceOptions) (*writeAheadLog, []walEntry, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	w := &writeAheadLog{
+		dir:  dir,
+		opts: opts,
+		acks: make(map[uint64]bool),
+	}
+
+	acked, err := readCheckpoint(filepath.Join(dir, walCheckpointFile))
+	if err != nil {
+		return nil, nil, err
+	}
+	w.ackedSeq = acked
+	w.nextSeq = acked
+
+	segments, err := listSegments(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var entries []walEntry
+	for _, segment := range segments {
+		segmentEntries, err := readSegment(segment)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, entry := range segmentEntries {
+			if entry.seq >= w.nextSeq {
+				w.nextSeq = entry.seq + 1
+			}
+			if entry.seq >= w.ackedSeq {
+				entries = append(entries, entry)
+			}
+		}
+
+		w.segments = append(w.segments, segment)
+		w.size += segment.size
+	}
+
+	// Sequences that were lost to a torn write will never be acknowledged,
+	// so treat every gap between replayed entries as acknowledged
+	expected := w.ackedSeq
+	for _, entry := range entries {
+		for seq := expected; seq < entry.seq; seq++ {
+			w.acks[seq] = true
+		}
+		expected = entry.seq + 1
+	}
+	for seq := expected; seq < w.nextSeq; seq++ {
+		w.acks[seq] = true
+	}
+
+	if err := w.openSegment(); err != nil {
+		return nil, nil, err
+	}
+
+	if err := w.advance(); err != nil {
+		w.Close()
+		return nil, nil, err
+	}
+
+	return w, entries, nil
+}
+
+// Append writes a batch to the log and returns its sequence number
+func (w *writeAheadLog) Append(batch *model.DataBatch) (uint64, error) {
+	payload, err := model.MarshalBatch(batch)
+	if err != nil {
+		return 0, err
+	}
+
+	// A previous rotation may have failed to open the next segment
+	if w.active == nil {
+		if err := w.openSegment(); err != nil {
+			return 0, err
+		}
+	}
+
+	seq := w.nextSeq
+	record := make([]byte, walHeaderSize+walSeqSize+len(payload))
+	binary.BigEndian.PutUint64(record[walHeaderSize:], seq)
+	copy(record[walHeaderSize+walSeqSize:], payload)
+	binary.BigEndian.PutUint32(record[0:4], uint32(len(record)-walHeaderSize))
+	binary.BigEndian.PutUint32(record[4:8], crc32.ChecksumIEEE(record[walHeaderSize:]))
+
+	if w.opts.MaxDiskSize > 0 && w.size+int64(len(record)) > w.opts.MaxDiskSize {
+		return 0, errLogFull
+	}
+
+	if _, err := w.active.Write(record); err != nil {
+		return 0, fmt.Errorf("failed to write log record: %w", err)
+	}
+
+	w.nextSeq++
+	segment := w.segments[len(w.segments)-1]
+	segment.size += int64(len(record))
+	w.size += int64(len(record))
+
+	switch w.opts.Fsync {
+	case FsyncAlways:
+		if syncErr := w.active.Sync(); syncErr != nil {
+			err = fmt.Errorf("failed to sync log: %w", syncErr)
+		}
+	case FsyncInterval:
+		w.dirty = true
+	}
+
+	// Start a new segment once the active one is large enough
+	if err == nil && segment.size >= w.opts.SegmentSize {
+		err = w.rotate()
+	}
+
+	if err != nil {
+		// The caller drops the batch, so its record must not hold back the checkpoint
+		w.acks[seq] = true
+		return 0, err
+	}
+
+	return seq, nil
+}
+
+// Ack marks a sequence as delivered and removes segments that are no longer needed
+func (w *writeAheadLog) Ack(seq uint64) error {
+	if seq < w.ackedSeq || seq >= w.nextSeq {
+		return nil
+	}
+
+	w.acks[seq] = true
+	return w.advance()
+}
+
+// Sync flushes pending writes to disk
+func (w *writeAheadLog) Sync() error {
+	if !w.dirty || w.active == nil {
+		return nil
+	}
+
+	w.dirty = false
+	return w.active.Sync()
+}
+
+// Size returns the number of bytes the log occupies on disk
+func (w *writeAheadLog) Size() int64 {
+	return w.size
+}
+
+// Close syncs and closes the active segment
+func (w *writeAheadLog) Close() error {
+	if w.active == nil {
+		return nil
+	}
+
+	var err error
+	if w.opts.Fsync != FsyncNever {
+		err = w.active.Sync()
+	}
+	if closeErr := w.active.Close(); err == nil {
+		err = closeErr
+	}
+	w.active = nil
+	w.dirty = false
+
+	return err
+}
+
+// advance moves the checkpoint past contiguous acknowledged sequences
+func (w *writeAheadLog) advance() error {
+	start := w.ackedSeq
+	for w.acks[w.ackedSeq] {
+		delete(w.acks, w.ackedSeq)
+		w.ackedSeq++
+	}
+
+	if w.ackedSeq == start {
+		return nil
+	}
+
+	if err := w.writeCheckpoint(); err != nil {
+		return err
+	}
+
+	// A segment can go once the next one starts at or below the checkpoint
+	for len(w.segments) > 1 && w.segments[1].firstSeq <= w.ackedSeq {
+		if err := os.Remove(w.segments[0].path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove log segment: %w", err)
+		}
+		w.size -= w.segments[0].size
+		w.segments = w.segments[1:]
+	}
+
+	return nil
+}
+
+// rotate closes the active segment and starts a new one
+func (w *writeAheadLog) rotate() error {
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to close log segment: %w", err)
+	}
+
+	return w.openSegment()
+}
+
+// openSegment opens the segment that starts at the next sequence number
+func (w *writeAheadLog) openSegment() error {
+	path := filepath.Join(w.dir, fmt.Sprintf("%020d%s", w.nextSeq, walSegmentExt))
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log segment: %w", err)
+	}
+	w.active = file
+
+	// An empty segment left by a previous run is reused rather than listed twice
+	if n := len(w.segments); n > 0 && w.segments[n-1].path == path {
+		return nil
+	}
+
+	w.segments = append(w.segments, &walSegment{path: path, firstSeq: w.nextSeq})
+	return nil
+}
+
+// writeCheckpoint atomically records the acknowledged sequence
+func (w *writeAheadLog) writeCheckpoint() error {
+	path := filepath.Join(w.dir, walCheckpointFile)
+	tmpPath := path + ".tmp"
+
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+
+	_, err = file.WriteString(strconv.FormatUint(w.ackedSeq, 10))
+	if err == nil && w.opts.Fsync != FsyncNever {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// readCheckpoint returns the acknowledged sequence stored at path, or zero if there is none
+func readCheckpoint(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	seq, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid checkpoint %s: %w", path, err)
+	}
+
+	return seq, nil
+}
+
+// listSegments returns the segment files in dir ordered by first sequence
+func listSegments(dir string) ([]*walSegment, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+walSegmentExt))
+	if err != nil {
+		return nil, err
+	}
+
+	var segments []*walSegment
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), walSegmentExt)
+		firstSeq, err := strconv.ParseUint(name, 10, 64)
+		if err != nil {
+			continue // Not one of ours
+		}
+		segments = append(segments, &walSegment{path: path, firstSeq: firstSeq})
+	}
+
+	sort.Slice(segments, func(i, j int) bool {
+		return segments[i].firstSeq < segments[j].firstSeq
+	})
+
+	return segments, nil
+}
+
+// readSegment reads every intact record from a segment. A torn or corrupt
+// record ends the segment, and the file is truncated to its last good record.
+func readSegment(segment *walSegment) ([]walEntry, error) {
+	data, err := os.ReadFile(segment.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read log segment: %w", err)
+	}
+
+	var entries []walEntry
+	offset := 0
+	for offset+walHeaderSize <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[offset : offset+4]))
+		checksum := binary.BigEndian.Uint32(data[offset+4 : offset+8])
+
+		start := offset + walHeaderSize
+		end := start + length
+		if length < walSeqSize || end > len(data) || crc32.ChecksumIEEE(data[start:end]) != checksum {
+			break
+		}
+
+		seq := binary.BigEndian.Uint64(data[start : start+walSeqSize])
+		batch, err := model.UnmarshalBatch(data[start+walSeqSize : end])
+		if err == nil {
+			entries = append(entries, walEntry{seq: seq, batch: batch})
+		}
+
+		offset = end
+	}
+
+	if offset < len(data) {
+		if err := os.Truncate(segment.path, int64(offset)); err != nil {
+			return nil, fmt.Errorf("failed to truncate log segment: %w", err)
+		}
+	}
+	segment.size = int64(offset)
+
+	return entries, nil
+}
@@ -89,8 +89,10 @@ type TracePoint struct {
 	TraceID      string
 	SpanID       string
 	ParentSpanID string
+	Name         string
 	StartTime    time.Time
 	EndTime      time.Time
+	Attributes   map[string]interface{}
 }
 
 // ToMap converts the trace point to a map representation
@@ -102,8 +104,10 @@ func (p *TracePoint) ToMap() map[string]interface{} {
 		"trace_id":       p.TraceID,
 		"span_id":        p.SpanID,
 		"parent_span_id": p.ParentSpanID,
+		"name":           p.Name,
 		"start_time":     p.StartTime,
 		"end_time":       p.EndTime,
+		"attributes":     p.Attributes,
 	}
 }
 
@@ -0,0 +1,89 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// encodedPoint is the serialized form of a data point, tagged with its kind
+type encodedPoint struct {
+	Kind   string       `json:"kind"`
+	Log    *LogPoint    `json:"log,omitempty"`
+	Metric *MetricPoint `json:"metric,omitempty"`
+	Trace  *TracePoint  `json:"trace,omitempty"`
+}
+
+// encodedBatch is the serialized form of a data batch
+type encodedBatch struct {
+	SourceID   string                 `json:"source_id"`
+	BatchType  TelemetryType          `json:"batch_type"`
+	Timestamp  time.Time              `json:"timestamp"`
+	Points     []encodedPoint         `json:"points"`
+	Records    []Record               `json:"records,omitempty"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// MarshalBatch serializes a data batch so it can be stored and restored later
+func MarshalBatch(batch *DataBatch) ([]byte, error) {
+	if batch == nil {
+		return nil, fmt.Errorf("cannot marshal nil batch")
+	}
+
+	encoded := encodedBatch{
+		SourceID:   batch.SourceID,
+		BatchType:  batch.BatchType,
+		Timestamp:  batch.Timestamp,
+		Points:     make([]encodedPoint, 0, len(batch.Points)),
+		Records:    batch.Records,
+		Attributes: batch.Attributes,
+	}
+
+	for _, point := range batch.Points {
+		switch p := point.(type) {
+		case *LogPoint:
+			encoded.Points = append(encoded.Points, encodedPoint{Kind: "log", Log: p})
+		case *MetricPoint:
+			encoded.Points = append(encoded.Points, encodedPoint{Kind: "metric", Metric: p})
+		case *TracePoint:
+			encoded.Points = append(encoded.Points, encodedPoint{Kind: "trace", Trace: p})
+		default:
+			return nil, fmt.Errorf("unsupported point type: %T", point)
+		}
+	}
+
+	return json.Marshal(encoded)
+}
+
+// UnmarshalBatch restores a data batch serialized by MarshalBatch
+func UnmarshalBatch(data []byte) (*DataBatch, error) {
+	var encoded encodedBatch
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return nil, fmt.Errorf("error decoding batch: %w", err)
+	}
+
+	batch := NewDataBatch(encoded.BatchType)
+	batch.SourceID = encoded.SourceID
+	batch.Timestamp = encoded.Timestamp
+	if encoded.Records != nil {
+		batch.Records = encoded.Records
+	}
+	if encoded.Attributes != nil {
+		batch.Attributes = encoded.Attributes
+	}
+
+	for _, point := range encoded.Points {
+		switch {
+		case point.Kind == "log" && point.Log != nil:
+			batch.AddPoint(point.Log)
+		case point.Kind == "metric" && point.Metric != nil:
+			batch.AddPoint(point.Metric)
+		case point.Kind == "trace" && point.Trace != nil:
+			batch.AddPoint(point.Trace)
+		default:
+			return nil, fmt.Errorf("unsupported point kind: %s", point.Kind)
+		}
+	}
+
+	return batch, nil
+}
@@ -0,0 +1,42 @@
+package model
+
+import "errors"
+
+// PermanentError marks a send failure that retrying cannot fix, such as a
+// request the destination rejected as invalid
+type PermanentError struct {
+	Err error
+}
+
+// Error returns the underlying error message
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// IsPermanent reports whether an error is or wraps a PermanentError
+func IsPermanent(err error) bool {
+	var permanent *PermanentError
+	return errors.As(err, &permanent)
+}
+
+// PartialError marks a send in which only part of a batch failed. Failed
+// holds the part to retry; the rest was delivered.
+type PartialError struct {
+	Err    error
+	Failed *DataBatch
+}
+
+// Error returns the underlying error message
+func (e *PartialError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *PartialError) Unwrap() error {
+	return e.Err
+}
@@ -9,6 +9,13 @@ type CoreAPI interface {
 	PublishEvent(eventType EventType, sourceID string, data interface{})
 }
 
+// BackpressureAPI is implemented by cores that can tell push-based inputs
+// when to turn senders away
+type BackpressureAPI interface {
+	// InputBackpressured reports whether the buffer of any output fed by an input is full
+	InputBackpressured(inputID string) bool
+}
+
 // Plugin is the base interface for all plugins
 type Plugin interface {
 	// Initialize prepares the plugin for operation
@@ -67,4 +74,12 @@ type OutputPlugin interface {
 	
 	// Send exports a data batch
 	Send(batch *DataBatch) bool
-}
\ No newline at end of file
+}
+
+// ExportingOutput is implemented by outputs that can say why a send failed.
+// The core calls Export instead of Send when it is available.
+type ExportingOutput interface {
+	// Export sends a data batch. Failures wrapped in a PermanentError are not
+	// retried, and a PartialError retries only the part of the batch that failed.
+	Export(batch *DataBatch) error
+}
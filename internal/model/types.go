@@ -74,9 +74,13 @@ type HealthStatus struct {
 
 // BufferStatus represents the status of a buffer
 type BufferStatus struct {
-	BufferID   string    `json:"buffer_id"`
-	QueueSize  int       `json:"queue_size"`
-	TotalItems int       `json:"total_items"`
-	IsFull     bool      `json:"is_full"`
-	LastUpdate time.Time `json:"last_update"`
+	BufferID   string         `json:"buffer_id"`
+	QueueSize  int            `json:"queue_size"`
+	TotalItems int            `json:"total_items"`
+	IsFull     bool           `json:"is_full"`
+	Bytes      int64          `json:"bytes"`
+	DiskBytes  int64          `json:"disk_bytes,omitempty"`
+	Spilled    int            `json:"spilled,omitempty"`
+	Dropped    map[string]int `json:"dropped,omitempty"`
+	LastUpdate time.Time      `json:"last_update"`
 }
\ No newline at end of file
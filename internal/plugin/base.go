@@ -65,6 +65,11 @@ func (p *BasePlugin) RegisterWithCore(core model.CoreAPI) bool {
 	return true
 }
 
+// Core returns the core system the plugin registered with, or nil
+func (p *BasePlugin) Core() model.CoreAPI {
+	return p.core
+}
+
 // Validate checks if the plugin is properly configured
 func (p *BasePlugin) Validate() bool {
 	// Base implementation assumes valid, derived plugins should override
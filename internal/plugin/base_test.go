@@ -95,6 +95,7 @@ func TestBasePluginRegisterWithCore(t *testing.T) {
 		result := plugin.RegisterWithCore(core)
 		assert.True(t, result)
 		assert.Equal(t, core, plugin.core)
+		assert.Equal(t, core, plugin.Core())
 	})
 }
 
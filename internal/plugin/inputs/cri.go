@@ -0,0 +1,136 @@
+package inputs
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// containerLogLine is a line of a container log file
+type containerLogLine struct {
+	timestamp time.Time
+	stream    string
+	partial   bool // The message continues on the next line of the stream
+	message   string
+}
+
+// parseContainerLogLine parses a line in either the CRI format or Docker's
+// json-file format
+func parseContainerLogLine(line string) (containerLogLine, error) {
+	if strings.HasPrefix(line, "{") {
+		return parseDockerJSONLogLine(line)
+	}
+	return parseCRILogLine(line)
+}
+
+// parseCRILogLine parses a line of the form "<timestamp> <stream> <tag> <message>",
+// where the tag is P for a partial line or F for the last part of one. Tags
+// may carry further colon-separated fields, which are ignored.
+func parseCRILogLine(line string) (containerLogLine, error) {
+	var parsed containerLogLine
+
+	fields := strings.SplitN(line, " ", 4)
+	if len(fields) < 3 {
+		return parsed, fmt.Errorf("invalid CRI log line")
+	}
+
+	timestamp, err := time.Parse(time.RFC3339Nano, fields[0])
+	if err != nil {
+		return parsed, fmt.Errorf("invalid timestamp: %s", fields[0])
+	}
+	parsed.timestamp = timestamp
+
+	if fields[1] != "stdout" && fields[1] != "stderr" {
+		return parsed, fmt.Errorf("invalid stream: %s", fields[1])
+	}
+	parsed.stream = fields[1]
+
+	tag, _, _ := strings.Cut(fields[2], ":")
+	switch tag {
+	case "P":
+		parsed.partial = true
+	case "F":
+	default:
+		return parsed, fmt.Errorf("invalid tag: %s", fields[2])
+	}
+
+	if len(fields) == 4 {
+		parsed.message = fields[3]
+	}
+	return parsed, nil
+}
+
+// parseDockerJSONLogLine parses a line of the form
+// {"log":"message\n","stream":"stdout","time":"<timestamp>"}. Docker splits
+// long messages into lines without the trailing newline.
+func parseDockerJSONLogLine(line string) (containerLogLine, error) {
+	var parsed containerLogLine
+
+	var entry struct {
+		Log    *string `json:"log"`
+		Stream string  `json:"stream"`
+		Time   string  `json:"time"`
+	}
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		return parsed, fmt.Errorf("invalid JSON log line: %w", err)
+	}
+	if entry.Log == nil {
+		return parsed, fmt.Errorf("JSON log line has no log field")
+	}
+
+	timestamp, err := time.Parse(time.RFC3339Nano, entry.Time)
+	if err != nil {
+		return parsed, fmt.Errorf("invalid timestamp: %s", entry.Time)
+	}
+
+	parsed.timestamp = timestamp
+	parsed.stream = entry.Stream
+	parsed.partial = !strings.HasSuffix(*entry.Log, "\n")
+	parsed.message = strings.TrimSuffix(strings.TrimSuffix(*entry.Log, "\n"), "\r")
+	return parsed, nil
+}
+
+// kubernetesPathLabels extracts the namespace, pod, pod UID and container
+// from a log file path. The kubelet writes logs to
+// /var/log/pods/<namespace>_<pod>_<uid>/<container>/<restarts>.log and links
+// them from /var/log/containers/<pod>_<namespace>_<container>-<id>.log.
+// Neither namespaces nor pod names may contain underscores.
+func kubernetesPathLabels(path string) map[string]string {
+	labels := make(map[string]string)
+
+	dir, file := filepath.Split(path)
+	dir = filepath.Clean(dir)
+
+	// /var/log/containers/<pod>_<namespace>_<container>-<id>.log
+	if parts := strings.SplitN(strings.TrimSuffix(file, ".log"), "_", 3); len(parts) == 3 {
+		container, id, found := cutLast(parts[2], "-")
+		if found && len(id) == 64 {
+			labels["pod"] = parts[0]
+			labels["namespace"] = parts[1]
+			labels["container"] = container
+			labels["container_id"] = id
+			return labels
+		}
+	}
+
+	// /var/log/pods/<namespace>_<pod>_<uid>/<container>/<restarts>.log
+	podDir, container := filepath.Split(dir)
+	if parts := strings.SplitN(filepath.Base(podDir), "_", 3); len(parts) == 3 {
+		labels["namespace"] = parts[0]
+		labels["pod"] = parts[1]
+		labels["pod_uid"] = parts[2]
+		labels["container"] = container
+		labels["restart_count"] = strings.SplitN(file, ".", 2)[0]
+	}
+	return labels
+}
+
+// cutLast slices s around the last instance of sep
+func cutLast(s, sep string) (string, string, bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
@@ -0,0 +1,76 @@
+package inputs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseContainerLogLine(t *testing.T) {
+	line, err := parseContainerLogLine("2024-05-01T10:00:00.123456789Z stdout F GET /health 200")
+	assert.NoError(t, err)
+	assert.Equal(t, containerLogLine{
+		timestamp: time.Date(2024, 5, 1, 10, 0, 0, 123456789, time.UTC),
+		stream:    "stdout",
+		message:   "GET /health 200",
+	}, line)
+
+	line, err = parseContainerLogLine("2024-05-01T10:00:00+02:00 stderr P part one ")
+	assert.NoError(t, err)
+	assert.True(t, line.partial)
+	assert.Equal(t, "stderr", line.stream)
+	assert.Equal(t, "part one ", line.message)
+
+	line, err = parseContainerLogLine("2024-05-01T10:00:00Z stdout F")
+	assert.NoError(t, err)
+	assert.Equal(t, "", line.message)
+
+	line, err = parseContainerLogLine(`{"log":"hello\n","stream":"stderr","time":"2024-05-01T10:00:00.5Z"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, containerLogLine{
+		timestamp: time.Date(2024, 5, 1, 10, 0, 0, 500000000, time.UTC),
+		stream:    "stderr",
+		message:   "hello",
+	}, line)
+
+	line, err = parseContainerLogLine(`{"log":"a very long","stream":"stdout","time":"2024-05-01T10:00:00Z"}`)
+	assert.NoError(t, err)
+	assert.True(t, line.partial)
+
+	for _, invalid := range []string{
+		"plain text",
+		"yesterday stdout F message",
+		"2024-05-01T10:00:00Z stdin F message",
+		"2024-05-01T10:00:00Z stdout X message",
+		`{"log": "no time\n"}`,
+		`{"stream": "stdout", "time": "2024-05-01T10:00:00Z"}`,
+		`{"log": `,
+	} {
+		_, err := parseContainerLogLine(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestKubernetesPathLabels(t *testing.T) {
+	assert.Equal(t, map[string]string{
+		"namespace":     "shop",
+		"pod":           "web-7d4b9c8f6-x2x9k",
+		"pod_uid":       "0b6f2a4e-8c1d-4f3a-9e2b-5d7c6a1b2c3d",
+		"container":     "nginx",
+		"restart_count": "2",
+	}, kubernetesPathLabels("/var/log/pods/shop_web-7d4b9c8f6-x2x9k_0b6f2a4e-8c1d-4f3a-9e2b-5d7c6a1b2c3d/nginx/2.log"))
+
+	// Rotated files keep their restart count
+	assert.Equal(t, "0", kubernetesPathLabels("/var/log/pods/shop_web_uid/nginx/0.log.20240501-100000")["restart_count"])
+
+	id := "4c1f0b1e9d2a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4"
+	assert.Equal(t, map[string]string{
+		"namespace":    "shop",
+		"pod":          "web-7d4b9c8f6-x2x9k",
+		"container":    "nginx-proxy",
+		"container_id": id,
+	}, kubernetesPathLabels("/var/log/containers/web-7d4b9c8f6-x2x9k_shop_nginx-proxy-"+id+".log"))
+
+	assert.Empty(t, kubernetesPathLabels("/var/log/syslog"))
+}
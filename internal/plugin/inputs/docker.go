@@ -0,0 +1,307 @@
+package inputs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxDockerLineSize bounds a container log line; longer lines are split
+const maxDockerLineSize = 1024 * 1024
+
+// dockerClient makes requests to the Docker Engine API
+type dockerClient struct {
+	client  *http.Client
+	baseURL string
+}
+
+// dockerContainer is the part of a container's inspection the docker input uses
+type dockerContainer struct {
+	ID     string `json:"Id"`
+	Name   string `json:"Name"`
+	Config struct {
+		Image  string            `json:"Image"`
+		Labels map[string]string `json:"Labels"`
+		Tty    bool              `json:"Tty"`
+	} `json:"Config"`
+}
+
+// dockerEvent is a message from the events endpoint
+type dockerEvent struct {
+	Type   string `json:"Type"`
+	Action string `json:"Action"`
+	Actor  struct {
+		ID string `json:"ID"`
+	} `json:"Actor"`
+}
+
+// newDockerClient creates a client for a daemon address of the form
+// unix:///path/to/socket, tcp://host:port or http://host:port
+func newDockerClient(host string) (*dockerClient, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	scheme, address, found := strings.Cut(host, "://")
+	if !found || address == "" {
+		return nil, fmt.Errorf("invalid docker host: %s", host)
+	}
+
+	baseURL := "http://" + address
+	switch scheme {
+	case "unix":
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", address)
+		}
+		// The host is ignored when dialing the socket
+		baseURL = "http://docker"
+	case "tcp", "http":
+	default:
+		return nil, fmt.Errorf("unsupported docker host scheme: %s", scheme)
+	}
+
+	return &dockerClient{client: &http.Client{Transport: transport}, baseURL: baseURL}, nil
+}
+
+// get sends a GET request and returns the response body, failing on error statuses
+func (c *dockerClient) get(ctx context.Context, path string, query url.Values) (io.ReadCloser, error) {
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		var message struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&message)
+		if message.Message == "" {
+			message.Message = resp.Status
+		}
+		return nil, fmt.Errorf("GET %s: %s", path, message.Message)
+	}
+
+	return resp.Body, nil
+}
+
+// getJSON sends a GET request and decodes the JSON response into v
+func (c *dockerClient) getJSON(ctx context.Context, path string, query url.Values, v interface{}) error {
+	body, err := c.get(ctx, path, query)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	return json.NewDecoder(body).Decode(v)
+}
+
+// listContainers returns the IDs of the running containers matching filters
+func (c *dockerClient) listContainers(ctx context.Context, filters map[string][]string) ([]string, error) {
+	encoded, err := json.Marshal(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	var containers []struct {
+		ID string `json:"Id"`
+	}
+	if err := c.getJSON(ctx, "/containers/json", url.Values{"filters": {string(encoded)}}, &containers); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(containers))
+	for _, container := range containers {
+		ids = append(ids, container.ID)
+	}
+	return ids, nil
+}
+
+// inspectContainer returns the details of a container
+func (c *dockerClient) inspectContainer(ctx context.Context, id string) (*dockerContainer, error) {
+	var container dockerContainer
+	if err := c.getJSON(ctx, "/containers/"+url.PathEscape(id)+"/json", nil, &container); err != nil {
+		return nil, err
+	}
+	return &container, nil
+}
+
+// dockerTimestamp formats a time as the API's since parameter
+func dockerTimestamp(t time.Time) string {
+	return fmt.Sprintf("%d.%09d", t.Unix(), t.Nanosecond())
+}
+
+// readDockerLogs calls fn with each line of a log stream and the stream it
+// was written to, stopping at the first error fn returns. Containers without
+// a TTY multiplex stdout and stderr into frames with an 8-byte header: the
+// stream in the first byte and the payload size in the last four,
+// big-endian. Containers with a TTY send raw output.
+func readDockerLogs(r io.Reader, tty bool, fn func(stream, line string) error) error {
+	if tty {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), maxDockerLineSize)
+		scanner.Split(splitNewline(maxDockerLineSize))
+		for scanner.Scan() {
+			if err := fn("stdout", scanner.Text()); err != nil {
+				return err
+			}
+		}
+		return scanner.Err()
+	}
+
+	// Partial lines are held per stream until their newline arrives, and
+	// returned when the stream ends
+	pending := map[string]*bytes.Buffer{"stdout": {}, "stderr": {}}
+
+	reader := bufio.NewReader(r)
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			if err != io.EOF {
+				return err
+			}
+			for _, stream := range []string{"stdout", "stderr"} {
+				if pending[stream].Len() > 0 {
+					if err := fn(stream, pending[stream].String()); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		}
+
+		var stream string
+		switch header[0] {
+		case 1:
+			stream = "stdout"
+		case 2:
+			stream = "stderr"
+		}
+
+		size := int64(binary.BigEndian.Uint32(header[4:]))
+		if stream == "" {
+			if _, err := io.CopyN(io.Discard, reader, size); err != nil {
+				return err
+			}
+			continue
+		}
+
+		buffer := pending[stream]
+		if _, err := io.CopyN(buffer, reader, size); err != nil {
+			return err
+		}
+
+		for {
+			data := buffer.Bytes()
+			i := bytes.IndexByte(data, '\n')
+			if i < 0 {
+				if len(data) < maxDockerLineSize {
+					break
+				}
+				i = maxDockerLineSize
+			}
+
+			line := string(bytes.TrimSuffix(data[:i], []byte("\r")))
+			if i < len(data) && data[i] == '\n' {
+				i++
+			}
+			buffer.Next(i)
+			if err := fn(stream, line); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// splitDockerTimestamp splits the timestamp the API prefixes log lines with
+// from the message, returning a zero time if there is none
+func splitDockerTimestamp(line string) (time.Time, string) {
+	field, rest, _ := strings.Cut(line, " ")
+	if timestamp, err := time.Parse(time.RFC3339Nano, field); err == nil {
+		return timestamp, rest
+	}
+	return time.Time{}, line
+}
+
+// logCursor tracks how far a log stream was read from each of its sources,
+// such as the containers of a service or the streams of a container. Lines
+// of different sources are interleaved and can share timestamps, so a
+// stream resumed from a timestamp repeats some lines; the cursor skips
+// exactly those and nothing else.
+type logCursor struct {
+	read   map[string]logPosition // Position read up to, by source
+	replay map[string]logPosition // Positions left to replay since the last resume, by source
+}
+
+// logPosition is the timestamp of the last line read from a source and the
+// number of lines read with that timestamp
+type logPosition struct {
+	timestamp time.Time
+	count     int
+}
+
+// newLogCursor creates a cursor with nothing read
+func newLogCursor() *logCursor {
+	return &logCursor{
+		read:   make(map[string]logPosition),
+		replay: make(map[string]logPosition),
+	}
+}
+
+// resume returns the earliest position read, where a restarted stream must
+// begin, or a zero time if nothing was read. Until each source passes its
+// position again, the lines it repeats are skipped.
+func (c *logCursor) resume() time.Time {
+	var since time.Time
+	c.replay = make(map[string]logPosition, len(c.read))
+	for source, position := range c.read {
+		c.replay[source] = position
+		if since.IsZero() || position.timestamp.Before(since) {
+			since = position.timestamp
+		}
+	}
+	return since
+}
+
+// advance records a line read from a source, returning false if the line
+// was already read before the stream was resumed
+func (c *logCursor) advance(source string, timestamp time.Time) bool {
+	if replay, replaying := c.replay[source]; replaying {
+		if timestamp.Before(replay.timestamp) {
+			return false
+		}
+		if timestamp.Equal(replay.timestamp) && replay.count > 0 {
+			replay.count--
+			c.replay[source] = replay
+			return false
+		}
+		delete(c.replay, source)
+	}
+
+	position := c.read[source]
+	if timestamp.Equal(position.timestamp) {
+		position.count++
+	} else {
+		position = logPosition{timestamp: timestamp, count: 1}
+	}
+	c.read[source] = position
+	return true
+}
@@ -3,6 +3,8 @@ package inputs
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"fmt"
 	"os/exec"
 	"strings"
 	"sync"
@@ -12,7 +14,12 @@ import (
 	"github.com/sliink/collector/internal/plugin"
 )
 
-// DockerComposeInput reads log data from docker-compose services
+// maxComposeLineSize bounds a docker-compose log line; longer lines are split
+const maxComposeLineSize = 1024 * 1024
+
+// DockerComposeInput reads log data from docker-compose services. Each
+// service's logs are streamed by a background reader into a queue that
+// Collect drains.
 type DockerComposeInput struct {
 	plugin.BasePlugin
 	projectName      string
@@ -23,9 +30,23 @@ type DockerComposeInput struct {
 	composeFiles     []string
 	refreshInterval  time.Duration
 	containerMapping map[string]string // Map of container ID to service name
+	queue            *pushQueue
+	readers          map[string]context.CancelFunc // Running log readers by service
+	cursors          map[string]*logCursor         // Lines read by service, by container
+	command          func(ctx context.Context, args ...string) *exec.Cmd
+	minBackoff       time.Duration
+	maxBackoff       time.Duration
+	cancel           context.CancelFunc
+	wg               sync.WaitGroup
 	mutex            sync.RWMutex
 }
 
+func init() {
+	plugin.RegisterStandardInput("docker_compose", func(id string) model.InputPlugin {
+		return NewDockerComposeInput(id)
+	})
+}
+
 // NewDockerComposeInput creates a new docker-compose input plugin
 func NewDockerComposeInput(id string) *DockerComposeInput {
 	return &DockerComposeInput{
@@ -37,6 +58,13 @@ func NewDockerComposeInput(id string) *DockerComposeInput {
 		composeFiles:     []string{},
 		refreshInterval:  time.Minute,
 		containerMapping: make(map[string]string),
+		readers:          make(map[string]context.CancelFunc),
+		cursors:          make(map[string]*logCursor),
+		command: func(ctx context.Context, args ...string) *exec.Cmd {
+			return exec.CommandContext(ctx, "docker-compose", args...)
+		},
+		minBackoff: time.Second,
+		maxBackoff: 30 * time.Second,
 	}
 }
 
@@ -82,26 +110,57 @@ func (d *DockerComposeInput) Initialize() bool {
 
 	// Get refresh interval from configuration
 	if refreshStr, ok := d.Config["refresh_interval"].(string); ok {
-		if duration, err := time.ParseDuration(refreshStr); err == nil {
+		if duration, err := time.ParseDuration(refreshStr); err == nil && duration > 0 {
 			d.refreshInterval = duration
 		}
 	}
 
-	// Initialize container mapping
-	d.refreshContainerMapping()
+	maxPending := 100000
+	if size, ok := plugin.IntOption(d.Config["max_pending"]); ok && size > 0 {
+		maxPending = int(size)
+	}
+	d.queue = newPushQueue(maxPending)
 
 	d.SetStatus(model.StatusInitialized)
 	return true
 }
 
-// Start begins docker-compose input operation
+// enabled reports whether the input is enabled, which it is only when configured so
+func (d *DockerComposeInput) enabled() bool {
+	enabled, ok := d.Config["enabled"].(bool)
+	return ok && enabled
+}
+
+// Start begins docker-compose input operation, starting a log reader for
+// each service
 func (d *DockerComposeInput) Start() bool {
+	d.mutex.Lock()
+	if d.enabled() && d.cancel == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		d.cancel = cancel
+
+		d.wg.Add(1)
+		go d.superviseReaders(ctx)
+	}
+	d.mutex.Unlock()
+
 	d.SetStatus(model.StatusRunning)
 	return true
 }
 
-// Stop halts docker-compose input operation
+// Stop halts docker-compose input operation, ending the log readers. Each
+// service's cursor is kept, so a restart resumes where the readers left off.
 func (d *DockerComposeInput) Stop() bool {
+	d.mutex.Lock()
+	cancel := d.cancel
+	d.cancel = nil
+	d.mutex.Unlock()
+
+	if cancel != nil {
+		cancel()
+		d.wg.Wait()
+	}
+
 	d.SetStatus(model.StatusStopped)
 	return true
 }
@@ -113,13 +172,9 @@ func (d *DockerComposeInput) Validate() bool {
 	return true
 }
 
-// refreshContainerMapping updates the mapping from container IDs to service names
-func (d *DockerComposeInput) refreshContainerMapping() {
-	var cmd *exec.Cmd
-	
-	// Build docker-compose command
-	args := []string{"ps", "--format", "{{.ID}},{{.Service}}"}
-
+// composeArgs prefixes a docker-compose subcommand with the project and
+// compose file options
+func (d *DockerComposeInput) composeArgs(args ...string) []string {
 	// Add project name if specified
 	if d.projectName != "" {
 		args = append([]string{"-p", d.projectName}, args...)
@@ -130,21 +185,25 @@ func (d *DockerComposeInput) refreshContainerMapping() {
 		args = append([]string{"-f", file}, args...)
 	}
 
+	return args
+}
+
+// refreshContainerMapping updates the mapping from container IDs to service
+// names, returning false if the containers could not be listed
+func (d *DockerComposeInput) refreshContainerMapping(ctx context.Context) bool {
 	// Create the docker-compose command
-	cmd = exec.Command("docker-compose", args...)
+	cmd := d.command(ctx, d.composeArgs("ps", "--format", "{{.ID}},{{.Service}}")...)
 
 	// Execute the command
 	var out bytes.Buffer
 	cmd.Stdout = &out
 	err := cmd.Run()
 	if err != nil {
-		return
+		return false
 	}
 
-	// Parse the output and update container mapping
-	d.mutex.Lock()
-	defer d.mutex.Unlock()
-
+	// Parse the output and replace the container mapping
+	mapping := make(map[string]string)
 	scanner := bufio.NewScanner(&out)
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -152,140 +211,209 @@ func (d *DockerComposeInput) refreshContainerMapping() {
 		if len(parts) == 2 {
 			containerID := parts[0]
 			serviceName := parts[1]
-			d.containerMapping[containerID] = serviceName
+			mapping[containerID] = serviceName
 		}
 	}
+
+	d.mutex.Lock()
+	d.containerMapping = mapping
+	d.mutex.Unlock()
+	return true
 }
 
-// Collect gathers log data from docker-compose services
-func (d *DockerComposeInput) Collect() []*model.DataBatch {
-	if d.GetStatus() != model.StatusRunning {
-		return nil
+// superviseReaders refreshes the container mapping every refresh interval
+// and keeps a log reader running for each service. Without configured
+// services, the services of the running containers are followed, and
+// readers of services that no longer have containers are stopped.
+func (d *DockerComposeInput) superviseReaders(ctx context.Context) {
+	defer d.wg.Done()
+
+	for {
+		listed := d.refreshContainerMapping(ctx)
+
+		d.mutex.Lock()
+		wanted := make(map[string]bool)
+		if len(d.services) > 0 {
+			for _, service := range d.services {
+				wanted[service] = true
+			}
+		} else if listed {
+			for _, service := range d.containerMapping {
+				wanted[service] = true
+			}
+		} else {
+			// Keep the current readers until the containers can be listed again
+			for service := range d.readers {
+				wanted[service] = true
+			}
+		}
+
+		for service, cancel := range d.readers {
+			if !wanted[service] {
+				cancel()
+				delete(d.readers, service)
+			}
+		}
+		for _, service := range sortedKeys(wanted) {
+			if _, running := d.readers[service]; !running {
+				readerCtx, cancel := context.WithCancel(ctx)
+				d.readers[service] = cancel
+				d.wg.Add(1)
+				go d.readLogs(readerCtx, service)
+			}
+		}
+		d.mutex.Unlock()
+
+		select {
+		case <-ctx.Done():
+			d.mutex.Lock()
+			d.readers = make(map[string]context.CancelFunc)
+			d.mutex.Unlock()
+			return
+		case <-time.After(d.refreshInterval):
+		}
 	}
-	
-	// Check if input is enabled
-	enabled, ok := d.Config["enabled"].(bool)
-	if !ok || !enabled {
-		// Skip collection if explicitly disabled
-		return nil
+}
+
+// readLogs runs docker-compose logs for a service until the context is
+// cancelled. When following, the process is restarted with exponential
+// backoff whenever it exits; otherwise the logs are read again every refresh
+// interval. Each run after the first resumes from the last line seen.
+func (d *DockerComposeInput) readLogs(ctx context.Context, service string) {
+	defer d.wg.Done()
+
+	minDelay, maxDelay := d.minBackoff, d.maxBackoff
+	if !d.follow {
+		minDelay, maxDelay = d.refreshInterval, d.refreshInterval
 	}
 
-	// Refresh container mapping if enough time has passed
-	d.refreshContainerMapping()
+	run := func(ctx context.Context) error {
+		return d.streamLogs(ctx, service)
+	}
+	superviseWithBackoff(ctx, minDelay, maxDelay, run, func(err error) {
+		if core := d.Core(); core != nil {
+			core.PublishEvent(model.EventError, d.ID(), fmt.Errorf("docker-compose logs for %s failed: %w", service, err))
+		}
+	})
+}
 
-	var results []*model.DataBatch
-	batch := model.NewDataBatch(model.LogTelemetryType)
+// streamLogs runs docker-compose logs for a service once, queueing each line
+// as a log point. Timestamps are always requested so that a later run can
+// resume with --since from the earliest line each container was read up to;
+// the lines --since repeats are skipped. When the queue is full, reading
+// waits for Collect to drain it.
+func (d *DockerComposeInput) streamLogs(ctx context.Context, service string) error {
+	d.mutex.Lock()
+	cursor, exists := d.cursors[service]
+	if !exists {
+		cursor = newLogCursor()
+		d.cursors[service] = cursor
+	}
+	since := cursor.resume()
+	d.mutex.Unlock()
 
 	// Build docker-compose logs command
-	args := []string{"logs"}
+	args := []string{"logs", "--no-color", "--timestamps"}
 
 	// Add follow flag if enabled
 	if d.follow {
 		args = append(args, "--follow")
 	}
 
-	// Add tail lines
-	args = append(args, "--tail", d.tailLines)
-
-	// Add timestamps flag if enabled
-	if d.timestamps {
-		args = append(args, "--timestamps")
-	}
-
-	// Add project name if specified
-	if d.projectName != "" {
-		args = append([]string{"-p", d.projectName}, args...)
-	}
-
-	// Add compose files if specified
-	for _, file := range d.composeFiles {
-		args = append([]string{"-f", file}, args...)
+	// Resume after the last line seen, or start from the tail
+	if since.IsZero() {
+		args = append(args, "--tail", d.tailLines)
+	} else {
+		args = append(args, "--since", since.Format(time.RFC3339Nano))
 	}
 
-	// Add services if specified, otherwise collect from all services
-	if len(d.services) > 0 {
-		args = append(args, d.services...)
-	}
-
-	// Create the docker-compose command
-	cmd := exec.Command("docker-compose", args...)
-
-	// Execute the command
+	cmd := d.command(ctx, d.composeArgs(append(args, service)...)...)
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		return nil
+		return err
 	}
-
-	err = cmd.Start()
-	if err != nil {
-		return nil
+	if err := cmd.Start(); err != nil {
+		return err
 	}
 
-	// Process the output
 	scanner := bufio.NewScanner(stdout)
-	
+	scanner.Buffer(make([]byte, 64*1024), maxComposeLineSize)
+	scanner.Split(splitNewline(maxComposeLineSize))
+
 	for scanner.Scan() {
-		line := scanner.Text()
-		
-		// Parse the log line
-		// Format: [service_name] YYYY-MM-DDTHH:MM:SS.sssZ message
-		serviceName := ""
-		message := line
-		timestamp := time.Now()
-
-		// Extract service name if present
-		if strings.HasPrefix(line, "|") {
-			parts := strings.SplitN(line, "|", 3)
-			if len(parts) >= 3 {
-				serviceName = strings.TrimSpace(parts[0])
-				timestampStr := strings.TrimSpace(parts[1])
-				message = strings.TrimSpace(parts[2])
-
-				// Parse timestamp if present
-				if t, err := time.Parse(time.RFC3339Nano, timestampStr); err == nil {
-					timestamp = t
-				}
+		container, timestamp, message := parseComposeLogLine(scanner.Text())
+		if !timestamp.IsZero() {
+			d.mutex.Lock()
+			fresh := cursor.advance(container, timestamp)
+			d.mutex.Unlock()
+			if !fresh {
+				continue
 			}
 		}
 
-		// Create a log point
-		logPoint := &model.LogPoint{
-			BaseDataPoint: model.BaseDataPoint{
-				Timestamp: timestamp,
-				Origin:    "docker-compose",
-				Labels: map[string]string{
-					"source":  "docker-compose",
-					"service": serviceName,
-				},
-			},
-			Message:    message,
-			Level:      "INFO", // Default level, would be parsed from content
-			Attributes: map[string]interface{}{},
-		}
-		
-		batch.AddPoint(logPoint)
-		
-		// Create a new batch if current one is full
-		if batch.Size() >= 1000 { // Configurable batch size
-			results = append(results, batch)
-			batch = model.NewDataBatch(model.LogTelemetryType)
-		}
-
-		// If not following logs, limit the number of log lines
-		if !d.follow && batch.Size() > 10000 {
+		// Reading waits while the queue is full. A cancelled context has
+		// already killed the process.
+		point := d.logPoint(service, container, timestamp, message)
+		if d.queue.AddWait(ctx, model.LogTelemetryType, []model.DataPoint{point}) != nil {
 			break
 		}
 	}
 
-	// Add the last batch if it has any points
-	if batch.Size() > 0 {
-		results = append(results, batch)
+	return cmd.Wait()
+}
+
+// logPoint builds a log point for a line of a service's logs. The line's own
+// timestamp is used unless timestamps are disabled or it has none.
+func (d *DockerComposeInput) logPoint(service, container string, timestamp time.Time, message string) *model.LogPoint {
+	if !d.timestamps || timestamp.IsZero() {
+		timestamp = time.Now()
 	}
 
-	// Kill the process if we're not following logs
-	if !d.follow {
-		_ = cmd.Process.Kill()
+	labels := map[string]string{
+		"source":  "docker-compose",
+		"service": service,
+	}
+	if container != "" {
+		labels["container"] = container
+	}
+
+	return &model.LogPoint{
+		BaseDataPoint: model.BaseDataPoint{
+			Timestamp: timestamp,
+			Origin:    "docker-compose",
+			Labels:    labels,
+		},
+		Message:    message,
+		Level:      "INFO", // Default level, would be parsed from content
+		Attributes: map[string]interface{}{},
+	}
+}
+
+// parseComposeLogLine splits a line of the form
+// "container  | 2024-05-01T10:00:00.000000000Z message" into its container,
+// timestamp and message. Parts that are missing are left empty.
+func parseComposeLogLine(line string) (string, time.Time, string) {
+	container := ""
+	message := line
+	if prefix, rest, found := strings.Cut(line, "|"); found {
+		container = strings.TrimSpace(prefix)
+		message = strings.TrimPrefix(rest, " ")
 	}
 
-	return results
-}
\ No newline at end of file
+	timestamp, message := splitDockerTimestamp(message)
+	return container, timestamp, message
+}
+
+// Collect returns the log points read from docker-compose services since the last call
+func (d *DockerComposeInput) Collect() []*model.DataBatch {
+	if d.GetStatus() != model.StatusRunning || d.queue == nil {
+		return nil
+	}
+
+	// Skip collection if explicitly disabled
+	if !d.enabled() {
+		return nil
+	}
+
+	return d.queue.Drain(d.ID())
+}
@@ -1,9 +1,16 @@
 package inputs
 
 import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/sliink/collector/internal/model"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestNewDockerComposeInput(t *testing.T) {
@@ -146,4 +153,127 @@ func TestDockerComposeInput_Lifecycle(t *testing.T) {
 	if input.GetStatus() != model.StatusStopped {
 		t.Errorf("Expected status to be StatusStopped, got %v", input.GetStatus())
 	}
-}
\ No newline at end of file
+}
+func TestParseComposeLogLine(t *testing.T) {
+	container, timestamp, message := parseComposeLogLine("web-1  | 2024-05-01T10:00:00.123456789Z GET /health | 200")
+	assert.Equal(t, "web-1", container)
+	assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 123456789, time.UTC), timestamp)
+	assert.Equal(t, "GET /health | 200", message)
+
+	container, timestamp, message = parseComposeLogLine("web-1  | 2024-05-01T10:00:00Z")
+	assert.Equal(t, "web-1", container)
+	assert.False(t, timestamp.IsZero())
+	assert.Equal(t, "", message)
+
+	container, timestamp, message = parseComposeLogLine("no prefix or timestamp")
+	assert.Equal(t, "", container)
+	assert.True(t, timestamp.IsZero())
+	assert.Equal(t, "no prefix or timestamp", message)
+}
+
+// fakeCompose makes an input run a shell script instead of docker-compose,
+// recording each invocation's arguments in a file
+func fakeCompose(t *testing.T, input *DockerComposeInput, script string) string {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	calls := filepath.Join(t.TempDir(), "calls")
+	input.command = func(ctx context.Context, args ...string) *exec.Cmd {
+		return exec.CommandContext(ctx, "sh", append([]string{"-c", `echo "$*" >> "$CALLS"; ` + script, "sh"}, args...)...)
+	}
+	t.Setenv("CALLS", calls)
+	input.minBackoff = 10 * time.Millisecond
+	input.maxBackoff = 20 * time.Millisecond
+	return calls
+}
+
+// collectComposeMessages collects from an input until count messages arrive
+// or a few seconds pass
+func collectComposeMessages(input *DockerComposeInput, count int) []*model.LogPoint {
+	var points []*model.LogPoint
+	deadline := time.Now().Add(3 * time.Second)
+	for len(points) < count && time.Now().Before(deadline) {
+		for _, batch := range input.Collect() {
+			for _, point := range batch.Points {
+				points = append(points, point.(*model.LogPoint))
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return points
+}
+
+func TestDockerComposeInput_Stream(t *testing.T) {
+	input := NewDockerComposeInput("test_docker_compose")
+	calls := fakeCompose(t, input, `
+case " $* " in
+*" ps "*) echo "abc123,web" ;;
+*" --since "*) printf 'web-2  | 2024-05-01T10:00:00Z replica\nweb-1  | 2024-05-01T10:00:01Z first\nweb-1  | 2024-05-01T10:00:01Z second\nweb-1  | 2024-05-01T10:00:02Z third\n' ;;
+*) printf 'web-1  | 2024-05-01T10:00:01Z first\nweb-2  | 2024-05-01T10:00:00Z replica\nweb-1  | 2024-05-01T10:00:01Z second\n' ;;
+esac`)
+
+	input.Configure(map[string]interface{}{
+		"enabled":          true,
+		"project_name":     "test_project",
+		"tail":             "50",
+		"refresh_interval": "1h",
+	})
+	assert.True(t, input.Initialize())
+	assert.True(t, input.Start())
+
+	// The reader restarts each time the process exits, resuming from the
+	// earliest line a container was read up to. Interleaved replicas and
+	// lines sharing a timestamp are kept, and nothing is read twice.
+	points := collectComposeMessages(input, 4)
+	time.Sleep(100 * time.Millisecond)
+	points = append(points, collectComposeMessages(input, 0)...)
+	assert.True(t, input.Stop())
+
+	var messages []string
+	for _, point := range points {
+		messages = append(messages, point.Message)
+	}
+	assert.Equal(t, []string{"first", "replica", "second", "third"}, messages)
+	if len(points) > 0 {
+		assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 1, 0, time.UTC), points[0].Timestamp)
+		assert.Equal(t, map[string]string{"source": "docker-compose", "service": "web", "container": "web-1"}, points[0].Labels)
+	}
+
+	data, err := os.ReadFile(calls)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+
+	// Containers are listed once per refresh interval, not on every collection
+	assert.Equal(t, "-p test_project ps --format {{.ID}},{{.Service}}", lines[0])
+	assert.Equal(t, "-p test_project logs --no-color --timestamps --follow --tail 50 web", lines[1])
+	assert.Equal(t, "-p test_project logs --no-color --timestamps --follow --since 2024-05-01T10:00:00Z web", lines[2])
+	for _, line := range lines[1:] {
+		assert.NotContains(t, line, " ps ")
+	}
+}
+
+func TestDockerComposeInput_NoFollow(t *testing.T) {
+	input := NewDockerComposeInput("test_docker_compose")
+	calls := fakeCompose(t, input, `printf 'web-1  | 2024-05-01T10:00:00Z only\n'`)
+
+	input.Configure(map[string]interface{}{
+		"enabled":          true,
+		"services":         []interface{}{"web"},
+		"follow":           false,
+		"refresh_interval": "1h",
+	})
+	assert.True(t, input.Initialize())
+	assert.True(t, input.Start())
+
+	points := collectComposeMessages(input, 1)
+	time.Sleep(100 * time.Millisecond)
+	points = append(points, collectComposeMessages(input, 0)...)
+	assert.True(t, input.Stop())
+	assert.Len(t, points, 1)
+
+	// Without following, the logs are read again only after the refresh interval
+	data, err := os.ReadFile(calls)
+	assert.NoError(t, err)
+	assert.Equal(t, "ps --format {{.ID}},{{.Service}}\nlogs --no-color --timestamps --tail 10 web\n", string(data))
+}
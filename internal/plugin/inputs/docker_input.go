@@ -0,0 +1,449 @@
+package inputs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sliink/collector/internal/model"
+	"github.com/sliink/collector/internal/plugin"
+)
+
+// Labels Docker Compose sets on the containers it creates
+const (
+	composeProjectLabel = "com.docker.compose.project"
+	composeServiceLabel = "com.docker.compose.service"
+)
+
+// DockerInput streams container logs from the Docker Engine API. Running
+// containers are listed at start, and containers started later are picked up
+// from the events endpoint.
+type DockerInput struct {
+	plugin.BasePlugin
+	dockerInputSettings
+	client     *dockerClient
+	queue      *pushQueue
+	streams    map[string]*dockerStream // Log streams by container ID
+	cursors    map[string]*logCursor    // Lines read by container ID, by stream
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+	mutex      sync.Mutex
+}
+
+// dockerInputSettings holds the Docker input configuration
+type dockerInputSettings struct {
+	host       string
+	project    string
+	services   map[string]bool
+	labels     []string // Label filters of the form key or key=value
+	stdout     bool
+	stderr     bool
+	tail       string
+	maxPending int
+}
+
+// dockerStream is a running container log stream
+type dockerStream struct {
+	cancel context.CancelFunc
+}
+
+func init() {
+	plugin.RegisterStandardInput("docker", func(id string) model.InputPlugin {
+		return NewDockerInput(id)
+	})
+}
+
+// NewDockerInput creates a new Docker Engine API input plugin
+func NewDockerInput(id string) *DockerInput {
+	return &DockerInput{
+		BasePlugin: plugin.NewBasePlugin(id, "Docker Input", model.InputPluginType),
+		streams:    make(map[string]*dockerStream),
+		cursors:    make(map[string]*logCursor),
+		minBackoff: time.Second,
+		maxBackoff: 30 * time.Second,
+	}
+}
+
+// parseDockerInputSettings reads the Docker input configuration
+func parseDockerInputSettings(config map[string]interface{}) (dockerInputSettings, error) {
+	settings := dockerInputSettings{
+		host:       "unix:///var/run/docker.sock",
+		services:   make(map[string]bool),
+		stdout:     true,
+		stderr:     true,
+		tail:       "10",
+		maxPending: 100000,
+	}
+
+	if host, ok := config["host"].(string); ok && host != "" {
+		settings.host = host
+	}
+	if _, err := newDockerClient(settings.host); err != nil {
+		return settings, err
+	}
+
+	if project, ok := config["project"].(string); ok {
+		settings.project = project
+	}
+
+	if services, ok := config["services"].([]interface{}); ok {
+		for _, item := range services {
+			service, ok := item.(string)
+			if !ok || service == "" {
+				return settings, fmt.Errorf("services must be names")
+			}
+			settings.services[service] = true
+		}
+	}
+
+	if labels, ok := config["labels"].(map[string]interface{}); ok {
+		for key, value := range labels {
+			value, ok := value.(string)
+			if !ok {
+				return settings, fmt.Errorf("label %s must be a string", key)
+			}
+			if value == "" {
+				settings.labels = append(settings.labels, key)
+			} else {
+				settings.labels = append(settings.labels, key+"="+value)
+			}
+		}
+		sort.Strings(settings.labels)
+	}
+
+	if streams, ok := config["streams"].([]interface{}); ok {
+		settings.stdout, settings.stderr = false, false
+		for _, item := range streams {
+			switch item {
+			case "stdout":
+				settings.stdout = true
+			case "stderr":
+				settings.stderr = true
+			default:
+				return settings, fmt.Errorf("unknown stream: %v", item)
+			}
+		}
+		if !settings.stdout && !settings.stderr {
+			return settings, fmt.Errorf("streams must name stdout, stderr or both")
+		}
+	}
+
+	switch tail := config["tail"].(type) {
+	case string:
+		if _, err := strconv.Atoi(tail); err != nil && tail != "all" {
+			return settings, fmt.Errorf("tail must be a number of lines or \"all\"")
+		}
+		settings.tail = tail
+	default:
+		if lines, ok := plugin.IntOption(tail); ok {
+			if lines < 0 {
+				return settings, fmt.Errorf("tail must not be negative")
+			}
+			settings.tail = strconv.FormatInt(lines, 10)
+		}
+	}
+
+	if size, ok := plugin.IntOption(config["max_pending"]); ok {
+		if size <= 0 {
+			return settings, fmt.Errorf("max_pending must be positive")
+		}
+		settings.maxPending = int(size)
+	}
+
+	return settings, nil
+}
+
+// Initialize prepares the Docker input for operation
+func (d *DockerInput) Initialize() bool {
+	settings, err := parseDockerInputSettings(d.Config)
+	if err != nil {
+		return false
+	}
+	d.dockerInputSettings = settings
+
+	client, err := newDockerClient(settings.host)
+	if err != nil {
+		return false
+	}
+	d.client = client
+	d.queue = newPushQueue(settings.maxPending)
+
+	d.SetStatus(model.StatusInitialized)
+	return true
+}
+
+// Validate checks if the Docker input is properly configured
+func (d *DockerInput) Validate() bool {
+	_, err := parseDockerInputSettings(d.Config)
+	return err == nil
+}
+
+// Start begins watching containers and streaming their logs
+func (d *DockerInput) Start() bool {
+	d.mutex.Lock()
+	if d.cancel == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		d.cancel = cancel
+
+		d.wg.Add(1)
+		go d.watch(ctx)
+	}
+	d.mutex.Unlock()
+
+	d.SetStatus(model.StatusRunning)
+	return true
+}
+
+// Stop ends the log streams. Each container's cursor is kept, so a restart
+// resumes where the streams left off.
+func (d *DockerInput) Stop() bool {
+	d.mutex.Lock()
+	cancel := d.cancel
+	d.cancel = nil
+	d.mutex.Unlock()
+
+	if cancel != nil {
+		cancel()
+		d.wg.Wait()
+	}
+
+	d.SetStatus(model.StatusStopped)
+	return true
+}
+
+// Collect returns the log points read since the last call
+func (d *DockerInput) Collect() []*model.DataBatch {
+	if d.queue == nil {
+		return nil
+	}
+
+	return d.queue.Drain(d.ID())
+}
+
+// watch syncs with the daemon until the context is cancelled, reconnecting
+// with exponential backoff whenever the connection is lost
+func (d *DockerInput) watch(ctx context.Context) {
+	defer d.wg.Done()
+
+	superviseWithBackoff(ctx, d.minBackoff, d.maxBackoff, d.sync, func(err error) {
+		if core := d.Core(); core != nil {
+			core.PublishEvent(model.EventError, d.ID(), fmt.Errorf("docker daemon connection failed: %w", err))
+		}
+	})
+}
+
+// filters returns the label filters selecting the input's containers
+func (d *DockerInput) filters() map[string][]string {
+	labels := append([]string{}, d.labels...)
+	if d.project != "" {
+		labels = append(labels, composeProjectLabel+"="+d.project)
+	}
+
+	filters := make(map[string][]string)
+	if len(labels) > 0 {
+		filters["label"] = labels
+	}
+	return filters
+}
+
+// sync streams the logs of the running containers, then follows container
+// events until the event stream ends. Events are requested from before the
+// containers were listed, so none are missed in between.
+func (d *DockerInput) sync(ctx context.Context) error {
+	since := time.Now()
+
+	filters := d.filters()
+	filters["status"] = []string{"running"}
+	ids, err := d.client.listContainers(ctx, filters)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		d.startStream(ctx, id, true)
+	}
+
+	filters = d.filters()
+	filters["type"] = []string{"container"}
+	filters["event"] = []string{"start", "destroy"}
+	encoded, err := json.Marshal(filters)
+	if err != nil {
+		return err
+	}
+
+	body, err := d.client.get(ctx, "/events", url.Values{
+		"since":   {dockerTimestamp(since)},
+		"filters": {string(encoded)},
+	})
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	decoder := json.NewDecoder(body)
+	for {
+		var event dockerEvent
+		if err := decoder.Decode(&event); err != nil {
+			return fmt.Errorf("event stream ended: %w", err)
+		}
+
+		switch event.Action {
+		case "start":
+			d.startStream(ctx, event.Actor.ID, false)
+		case "destroy":
+			d.mutex.Lock()
+			if stream, exists := d.streams[event.Actor.ID]; exists {
+				stream.cancel()
+			}
+			delete(d.cursors, event.Actor.ID)
+			d.mutex.Unlock()
+		}
+	}
+}
+
+// startStream starts streaming a container's logs unless it is already
+// being streamed. Containers running when the input starts are read from
+// their last tail lines, and containers started later from the beginning.
+func (d *DockerInput) startStream(ctx context.Context, id string, running bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if _, exists := d.streams[id]; exists {
+		return
+	}
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	stream := &dockerStream{cancel: cancel}
+	d.streams[id] = stream
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		defer cancel()
+
+		err := d.streamLogs(streamCtx, id, running)
+		if err != nil && ctx.Err() == nil {
+			if core := d.Core(); core != nil {
+				core.PublishEvent(model.EventError, d.ID(), fmt.Errorf("failed to stream logs of container %s: %w", shortContainerID(id), err))
+			}
+		}
+
+		d.mutex.Lock()
+		if d.streams[id] == stream {
+			delete(d.streams, id)
+		}
+		d.mutex.Unlock()
+	}()
+}
+
+// streamLogs follows a container's logs until they end, which they do when
+// the container stops. A container seen before resumes from the earliest
+// line its stdout and stderr were read up to, skipping the lines --since
+// repeats.
+func (d *DockerInput) streamLogs(ctx context.Context, id string, running bool) error {
+	container, err := d.client.inspectContainer(ctx, id)
+	if err != nil {
+		return err
+	}
+	if len(d.services) > 0 && !d.services[container.Config.Labels[composeServiceLabel]] {
+		return nil
+	}
+
+	d.mutex.Lock()
+	cursor, exists := d.cursors[id]
+	if !exists {
+		cursor = newLogCursor()
+		d.cursors[id] = cursor
+	}
+	since := cursor.resume()
+	d.mutex.Unlock()
+
+	query := url.Values{
+		"follow":     {"1"},
+		"timestamps": {"1"},
+		"stdout":     {strconv.FormatBool(d.stdout)},
+		"stderr":     {strconv.FormatBool(d.stderr)},
+	}
+	if !since.IsZero() {
+		query.Set("since", dockerTimestamp(since))
+	} else if running {
+		query.Set("tail", d.tail)
+	}
+
+	body, err := d.client.get(ctx, "/containers/"+url.PathEscape(id)+"/logs", query)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	labels := dockerLabels(container)
+	return readDockerLogs(body, container.Config.Tty, func(stream, line string) error {
+		timestamp, message := splitDockerTimestamp(line)
+		if !timestamp.IsZero() {
+			d.mutex.Lock()
+			fresh := cursor.advance(stream, timestamp)
+			d.mutex.Unlock()
+			if !fresh {
+				return nil
+			}
+		} else {
+			timestamp = time.Now()
+		}
+
+		pointLabels := make(map[string]string, len(labels)+1)
+		for k, v := range labels {
+			pointLabels[k] = v
+		}
+		pointLabels["stream"] = stream
+
+		point := &model.LogPoint{
+			BaseDataPoint: model.BaseDataPoint{
+				Timestamp: timestamp,
+				Origin:    pointLabels["container"],
+				Labels:    pointLabels,
+			},
+			Message:    message,
+			Level:      "INFO",
+			Attributes: map[string]interface{}{},
+		}
+
+		// Reading waits while the queue is full
+		return d.queue.AddWait(ctx, model.LogTelemetryType, []model.DataPoint{point})
+	})
+}
+
+// dockerLabels returns the labels identifying a container's log points
+func dockerLabels(container *dockerContainer) map[string]string {
+	name := container.Name
+	if len(name) > 0 && name[0] == '/' {
+		name = name[1:]
+	}
+
+	labels := map[string]string{
+		"source":       "docker",
+		"container":    name,
+		"container_id": shortContainerID(container.ID),
+		"image":        container.Config.Image,
+	}
+	if project := container.Config.Labels[composeProjectLabel]; project != "" {
+		labels["compose_project"] = project
+	}
+	if service := container.Config.Labels[composeServiceLabel]; service != "" {
+		labels["compose_service"] = service
+	}
+	return labels
+}
+
+// shortContainerID returns the 12-character form of a container ID
+func shortContainerID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
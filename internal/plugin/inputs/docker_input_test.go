@@ -0,0 +1,297 @@
+package inputs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sliink/collector/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeDockerDaemon serves a small part of the Docker Engine API on a unix
+// socket. Each event written to the events channel is sent on the open
+// events stream.
+type fakeDockerDaemon struct {
+	socket     string
+	containers map[string]string // Inspection JSON by container ID
+	logs       map[string]func(w http.ResponseWriter, r *http.Request)
+	events     chan string
+	requests   []string
+	mutex      sync.Mutex
+}
+
+// newFakeDockerDaemon starts a fake daemon that stops when the test ends
+func newFakeDockerDaemon(t *testing.T) *fakeDockerDaemon {
+	// Unix socket paths are limited in length, so the socket is not put in t.TempDir()
+	dir, err := os.MkdirTemp("", "docker")
+	assert.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	daemon := &fakeDockerDaemon{
+		socket:     filepath.Join(dir, "docker.sock"),
+		containers: make(map[string]string),
+		logs:       make(map[string]func(w http.ResponseWriter, r *http.Request)),
+		events:     make(chan string, 10),
+	}
+
+	listener, err := net.Listen("unix", daemon.socket)
+	assert.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/containers/json", func(w http.ResponseWriter, r *http.Request) {
+		daemon.record(r)
+		json.NewEncoder(w).Encode([]map[string]string{{"Id": "aaaaaaaaaaaaaaaa"}})
+	})
+	mux.HandleFunc("/containers/", func(w http.ResponseWriter, r *http.Request) {
+		daemon.record(r)
+		var id, action string
+		fmt.Sscanf(r.URL.Path, "/containers/%16s/%s", &id, &action)
+
+		daemon.mutex.Lock()
+		inspection, exists := daemon.containers[id]
+		logs := daemon.logs[id]
+		daemon.mutex.Unlock()
+
+		switch {
+		case !exists:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message": "No such container"}`))
+		case action == "json":
+			w.Write([]byte(inspection))
+		case action == "logs":
+			logs(w, r)
+		}
+	})
+	mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+		daemon.record(r)
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case event := <-daemon.events:
+				w.Write([]byte(event + "\n"))
+				w.(http.Flusher).Flush()
+			}
+		}
+	})
+
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+	t.Cleanup(func() { server.Close() })
+
+	return daemon
+}
+
+// record notes a request's path and query
+func (f *fakeDockerDaemon) record(r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.requests = append(f.requests, r.URL.Path+"?"+r.URL.RawQuery)
+}
+
+// requested returns the decoded requests made to a path
+func (f *fakeDockerDaemon) requested(path string) []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	var matching []string
+	for _, request := range f.requests {
+		if len(request) > len(path) && request[:len(path)+1] == path+"?" {
+			matching = append(matching, request)
+		}
+	}
+	return matching
+}
+
+func TestDockerInputValidate(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{"host": "/var/run/docker.sock"},
+		{"host": "ftp://docker"},
+		{"services": []interface{}{float64(1)}},
+		{"labels": map[string]interface{}{"tier": float64(1)}},
+		{"streams": []interface{}{"stdin"}},
+		{"streams": []interface{}{}},
+		{"tail": "some"},
+		{"tail": float64(-1)},
+		{"max_pending": float64(0)},
+	}
+
+	for _, config := range invalid {
+		input := NewDockerInput("docker_input")
+		input.Configure(config)
+		assert.False(t, input.Validate(), "%v", config)
+		assert.False(t, input.Initialize(), "%v", config)
+	}
+}
+
+func TestDockerInputStream(t *testing.T) {
+	daemon := newFakeDockerDaemon(t)
+
+	// A compose container already running, which restarts later
+	daemon.containers["aaaaaaaaaaaaaaaa"] = `{"Id": "aaaaaaaaaaaaaaaa", "Name": "/shop-web-1",
+		"Config": {"Image": "nginx:1.25", "Tty": false, "Labels": {"com.docker.compose.project": "shop", "com.docker.compose.service": "web"}}}`
+	daemon.logs["aaaaaaaaaaaaaaaa"] = func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("since") == "" {
+			// stderr arrives behind stdout, and lines share a timestamp
+			w.Write(dockerFrame(1, "2024-05-01T10:00:01Z hello\n"))
+			w.Write(dockerFrame(2, "2024-05-01T10:00:00Z oops\n"))
+			w.Write(dockerFrame(1, "2024-05-01T10:00:01Z same time\n"))
+			return
+		}
+		// --since repeats the lines from the given time
+		w.Write(dockerFrame(2, "2024-05-01T10:00:00Z oops\n"))
+		w.Write(dockerFrame(1, "2024-05-01T10:00:01Z hello\n2024-05-01T10:00:01Z same time\n"))
+		w.Write(dockerFrame(1, "2024-05-01T10:00:02Z back again\n"))
+	}
+
+	// A container started after the input, with a TTY
+	daemon.containers["bbbbbbbbbbbbbbbb"] = `{"Id": "bbbbbbbbbbbbbbbb", "Name": "/shop-worker-1",
+		"Config": {"Image": "worker:latest", "Tty": true, "Labels": {"com.docker.compose.project": "shop", "com.docker.compose.service": "worker"}}}`
+	daemon.logs["bbbbbbbbbbbbbbbb"] = func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("2024-05-01T10:00:03Z working\n"))
+	}
+
+	input := NewDockerInput("docker_input")
+	assert.True(t, input.Configure(map[string]interface{}{
+		"host":    "unix://" + daemon.socket,
+		"project": "shop",
+		"labels":  map[string]interface{}{"tier": "frontend"},
+		"tail":    float64(5),
+	}))
+	assert.True(t, input.Validate())
+	assert.True(t, input.Initialize())
+	assert.True(t, input.Start())
+	defer input.Stop()
+
+	points := make(map[string]*model.LogPoint)
+	collect := func(count int) {
+		deadline := time.Now().Add(5 * time.Second)
+		for len(points) < count && time.Now().Before(deadline) {
+			for _, batch := range input.Collect() {
+				assert.Equal(t, model.LogTelemetryType, batch.BatchType)
+				for _, point := range batch.Points {
+					log := point.(*model.LogPoint)
+					assert.NotContains(t, points, log.Message, "read twice")
+					points[log.Message] = log
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	collect(3)
+	daemon.events <- `{"Type": "container", "Action": "start", "Actor": {"ID": "bbbbbbbbbbbbbbbb"}}`
+	collect(4)
+
+	// The first stream ends with the container before it starts again
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		input.mutex.Lock()
+		_, streaming := input.streams["aaaaaaaaaaaaaaaa"]
+		input.mutex.Unlock()
+		if !streaming {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	daemon.events <- `{"Type": "container", "Action": "start", "Actor": {"ID": "aaaaaaaaaaaaaaaa"}}`
+	collect(5)
+
+	if assert.Len(t, points, 5) {
+		assert.Equal(t, map[string]string{
+			"source":          "docker",
+			"container":       "shop-web-1",
+			"container_id":    "aaaaaaaaaaaa",
+			"image":           "nginx:1.25",
+			"compose_project": "shop",
+			"compose_service": "web",
+			"stream":          "stdout",
+		}, points["hello"].Labels)
+		assert.Equal(t, "shop-web-1", points["hello"].Origin)
+		assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 1, 0, time.UTC), points["hello"].Timestamp)
+		assert.Equal(t, "stderr", points["oops"].Labels["stream"])
+		assert.Equal(t, "stdout", points["working"].Labels["stream"])
+		assert.Equal(t, "worker", points["working"].Labels["compose_service"])
+		assert.Contains(t, points, "same time")
+		assert.Contains(t, points, "back again")
+	}
+
+	lists := daemon.requested("/containers/json")
+	if assert.NotEmpty(t, lists) {
+		assert.Contains(t, lists[0], "com.docker.compose.project%3Dshop")
+		assert.Contains(t, lists[0], "tier%3Dfrontend")
+		assert.Contains(t, lists[0], "running")
+	}
+
+	// Running containers start from their tail, new ones from the beginning,
+	// and restarted ones from the earliest line a stream was read up to
+	logs := daemon.requested("/containers/aaaaaaaaaaaaaaaa/logs")
+	if assert.Len(t, logs, 2) {
+		assert.Contains(t, logs[0], "tail=5")
+		assert.Contains(t, logs[0], "follow=1")
+		assert.Contains(t, logs[1], "since=1714557600.000000000")
+		assert.NotContains(t, logs[1], "tail=")
+	}
+	logs = daemon.requested("/containers/bbbbbbbbbbbbbbbb/logs")
+	if assert.Len(t, logs, 1) {
+		assert.NotContains(t, logs[0], "tail=")
+	}
+}
+
+func TestDockerInputServices(t *testing.T) {
+	daemon := newFakeDockerDaemon(t)
+	daemon.containers["aaaaaaaaaaaaaaaa"] = `{"Id": "aaaaaaaaaaaaaaaa", "Name": "/shop-web-1",
+		"Config": {"Image": "nginx", "Labels": {"com.docker.compose.service": "web"}}}`
+	daemon.logs["aaaaaaaaaaaaaaaa"] = func(w http.ResponseWriter, r *http.Request) {
+		w.Write(dockerFrame(1, "2024-05-01T10:00:00Z hello\n"))
+	}
+
+	input := NewDockerInput("docker_input")
+	input.Configure(map[string]interface{}{
+		"host":     "unix://" + daemon.socket,
+		"services": []interface{}{"db"},
+	})
+	assert.True(t, input.Initialize())
+	assert.True(t, input.Start())
+
+	// The container is inspected but its logs are not read
+	deadline := time.Now().Add(5 * time.Second)
+	for len(daemon.requested("/containers/aaaaaaaaaaaaaaaa/json")) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	assert.True(t, input.Stop())
+
+	assert.Empty(t, input.Collect())
+	assert.Empty(t, daemon.requested("/containers/aaaaaaaaaaaaaaaa/logs"))
+}
+
+func TestDockerInputReconnect(t *testing.T) {
+	input := NewDockerInput("docker_input")
+	input.minBackoff = 10 * time.Millisecond
+	input.maxBackoff = 20 * time.Millisecond
+
+	events := &testCore{}
+	input.RegisterWithCore(events)
+	input.Configure(map[string]interface{}{"host": "unix:///nonexistent/docker.sock"})
+	assert.True(t, input.Initialize())
+	assert.True(t, input.Start())
+
+	// Failed connections are reported and retried until stopped
+	deadline := time.Now().Add(5 * time.Second)
+	for events.count() < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.True(t, input.Stop())
+	assert.GreaterOrEqual(t, events.count(), 2)
+	assert.Contains(t, fmt.Sprint(events.events[0]), "docker daemon connection failed")
+}
@@ -0,0 +1,120 @@
+package inputs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// dockerFrame encodes a multiplexed log frame
+func dockerFrame(stream byte, payload string) []byte {
+	header := make([]byte, 8)
+	header[0] = stream
+	binary.BigEndian.PutUint32(header[4:], uint32(len(payload)))
+	return append(header, payload...)
+}
+
+func TestReadDockerLogs(t *testing.T) {
+	t.Run("Multiplexed frames", func(t *testing.T) {
+		var stream bytes.Buffer
+		stream.Write(dockerFrame(1, "first\nsecond part"))
+		stream.Write(dockerFrame(2, "error\r\n"))
+		stream.Write(dockerFrame(0, "stdin is skipped\n"))
+		stream.Write(dockerFrame(1, " of a line\nunterminated"))
+
+		var lines []string
+		err := readDockerLogs(&stream, false, func(stream, line string) error {
+			lines = append(lines, stream+": "+line)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"stdout: first",
+			"stderr: error",
+			"stdout: second part of a line",
+			"stdout: unterminated",
+		}, lines)
+	})
+
+	t.Run("TTY output", func(t *testing.T) {
+		var lines []string
+		err := readDockerLogs(strings.NewReader("one\ntwo\n"), true, func(stream, line string) error {
+			lines = append(lines, stream+": "+line)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"stdout: one", "stdout: two"}, lines)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		// A frame cut short by a lost connection
+		truncated := dockerFrame(1, "complete line\n")
+		err := readDockerLogs(bytes.NewReader(truncated[:10]), false, func(string, string) error { return nil })
+		assert.Error(t, err)
+
+		stop := errors.New("stop")
+		calls := 0
+		err = readDockerLogs(bytes.NewReader(dockerFrame(1, "a\nb\n")), false, func(string, string) error {
+			calls++
+			return stop
+		})
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestSplitDockerTimestamp(t *testing.T) {
+	timestamp, message := splitDockerTimestamp("2024-05-01T10:00:00.5Z hello world")
+	assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 500000000, time.UTC), timestamp)
+	assert.Equal(t, "hello world", message)
+
+	timestamp, message = splitDockerTimestamp("hello world")
+	assert.True(t, timestamp.IsZero())
+	assert.Equal(t, "hello world", message)
+
+	assert.Equal(t, "1714557600.500000000", dockerTimestamp(time.Date(2024, 5, 1, 10, 0, 0, 500000000, time.UTC)))
+}
+
+func TestNewDockerClient(t *testing.T) {
+	for _, host := range []string{"unix:///var/run/docker.sock", "tcp://127.0.0.1:2375", "http://docker:2375"} {
+		_, err := newDockerClient(host)
+		assert.NoError(t, err, host)
+	}
+	for _, host := range []string{"/var/run/docker.sock", "unix://", "npipe:////./pipe/docker_engine"} {
+		_, err := newDockerClient(host)
+		assert.Error(t, err, host)
+	}
+}
+
+func TestLogCursor(t *testing.T) {
+	at := func(second int) time.Time {
+		return time.Date(2024, 5, 1, 10, 0, second, 0, time.UTC)
+	}
+
+	cursor := newLogCursor()
+	assert.True(t, cursor.resume().IsZero())
+
+	// Sources are interleaved and lines can share a timestamp
+	assert.True(t, cursor.advance("web-1", at(2)))
+	assert.True(t, cursor.advance("web-2", at(1)))
+	assert.True(t, cursor.advance("web-1", at(2)))
+	assert.True(t, cursor.advance("web-1", at(2)))
+	assert.Equal(t, at(1), cursor.resume())
+
+	// Only the lines read before the resume are skipped
+	assert.False(t, cursor.advance("web-2", at(1)))
+	assert.False(t, cursor.advance("web-1", at(1)))
+	assert.False(t, cursor.advance("web-1", at(2)))
+	assert.False(t, cursor.advance("web-1", at(2)))
+	assert.True(t, cursor.advance("web-2", at(1)), "a new line with the same timestamp")
+	assert.False(t, cursor.advance("web-1", at(2)))
+	assert.True(t, cursor.advance("web-1", at(2)))
+	assert.True(t, cursor.advance("web-1", at(3)))
+	assert.True(t, cursor.advance("web-3", at(0)))
+	assert.Equal(t, at(0), cursor.resume())
+}
@@ -0,0 +1,553 @@
+package inputs
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sliink/collector/internal/model"
+	"github.com/sliink/collector/internal/plugin"
+)
+
+// maxExecLineSize bounds a line of command output; longer lines are split
+const maxExecLineSize = 1024 * 1024
+
+// ExecInput runs commands and turns their output into points. Commands either
+// run on an interval, each run bounded by a timeout, or keep running and
+// stream their output.
+type ExecInput struct {
+	plugin.BasePlugin
+	execInputSettings
+	hostname   string
+	queue      *pushQueue
+	slots      chan struct{} // Held by interval commands while they run
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	waitDelay  time.Duration // How long output may stay open once a command is killed
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+	mutex      sync.Mutex
+}
+
+// execInputSettings holds the exec input configuration
+type execInputSettings struct {
+	commands       []execCommand
+	maxConcurrency int
+	maxPending     int
+}
+
+// execCommand is a command to run
+type execCommand struct {
+	name     string
+	args     []string
+	dir      string
+	env      []string
+	stream   bool // Keep the command running instead of running it on an interval
+	interval time.Duration
+	timeout  time.Duration
+	format   string
+	mapping  recordMapping // How JSON records become points
+}
+
+// execFormats are the formats command output can be parsed as
+var execFormats = map[string]bool{"lines": true, "json": true, "influx": true}
+
+// execCommandOptions are the command options that may also be set for all commands
+var execCommandOptions = []string{"mode", "interval", "timeout", "format", "data_type", "fields", "dir", "env"}
+
+func init() {
+	plugin.RegisterStandardInput("exec", func(id string) model.InputPlugin {
+		return NewExecInput(id)
+	})
+}
+
+// NewExecInput creates a new exec input plugin
+func NewExecInput(id string) *ExecInput {
+	return &ExecInput{
+		BasePlugin: plugin.NewBasePlugin(id, "Exec Input", model.InputPluginType),
+		minBackoff: time.Second,
+		maxBackoff: 30 * time.Second,
+		waitDelay:  time.Second,
+	}
+}
+
+// parseExecInputSettings reads the exec input configuration. Commands are
+// strings run by the shell, lists of arguments, or objects with a command
+// and its own options; top-level options apply to commands that do not set
+// them.
+func parseExecInputSettings(config map[string]interface{}) (execInputSettings, error) {
+	settings := execInputSettings{
+		maxConcurrency: 4,
+		maxPending:     100000,
+	}
+
+	commands, ok := config["commands"].([]interface{})
+	if !ok || len(commands) == 0 {
+		return settings, fmt.Errorf("at least one command is required")
+	}
+
+	names := make(map[string]bool)
+	for _, item := range commands {
+		options, ok := item.(map[string]interface{})
+		if !ok {
+			options = map[string]interface{}{"command": item}
+		}
+
+		merged := make(map[string]interface{}, len(options))
+		for _, name := range execCommandOptions {
+			if value, ok := config[name]; ok {
+				merged[name] = value
+			}
+		}
+		for name, value := range options {
+			merged[name] = value
+		}
+
+		command, err := parseExecCommand(merged)
+		if err != nil {
+			return settings, err
+		}
+		if names[command.name] {
+			return settings, fmt.Errorf("duplicate command name: %s", command.name)
+		}
+		names[command.name] = true
+		settings.commands = append(settings.commands, command)
+	}
+
+	if limit, ok := plugin.IntOption(config["max_concurrency"]); ok {
+		if limit < 1 {
+			return settings, fmt.Errorf("max_concurrency must be positive")
+		}
+		settings.maxConcurrency = int(limit)
+	}
+
+	if size, ok := plugin.IntOption(config["max_pending"]); ok {
+		if size <= 0 {
+			return settings, fmt.Errorf("max_pending must be positive")
+		}
+		settings.maxPending = int(size)
+	}
+
+	return settings, nil
+}
+
+// parseExecCommand reads one command. A string command is run with sh -c.
+func parseExecCommand(options map[string]interface{}) (execCommand, error) {
+	command := execCommand{format: "lines"}
+
+	switch value := options["command"].(type) {
+	case string:
+		if value == "" {
+			return command, fmt.Errorf("command is required")
+		}
+		command.name = value
+		command.args = []string{"sh", "-c", value}
+	case []interface{}:
+		for _, item := range value {
+			arg, ok := item.(string)
+			if !ok {
+				return command, fmt.Errorf("command must be a string or a list of arguments")
+			}
+			command.args = append(command.args, arg)
+		}
+		if len(command.args) == 0 || command.args[0] == "" {
+			return command, fmt.Errorf("command is required")
+		}
+		command.name = strings.Join(command.args, " ")
+	default:
+		return command, fmt.Errorf("command is required")
+	}
+
+	if name, ok := options["name"].(string); ok && name != "" {
+		command.name = name
+	}
+
+	if dir, ok := options["dir"].(string); ok {
+		command.dir = dir
+	}
+
+	if env, ok := options["env"].(map[string]interface{}); ok {
+		for _, name := range sortedKeys(env) {
+			value, ok := env[name].(string)
+			if !ok || name == "" || strings.Contains(name, "=") {
+				return command, fmt.Errorf("invalid env variable: %s", name)
+			}
+			command.env = append(command.env, name+"="+value)
+		}
+	}
+
+	if mode, ok := options["mode"].(string); ok {
+		if mode != "interval" && mode != "stream" {
+			return command, fmt.Errorf("mode must be interval or stream")
+		}
+		command.stream = mode == "stream"
+	}
+
+	var err error
+	if command.interval, err = durationOption(options, "interval", time.Minute); err != nil {
+		return command, err
+	}
+	if command.timeout, err = durationOption(options, "timeout", 30*time.Second); err != nil {
+		return command, err
+	}
+	// A run must finish before the next one is due
+	if command.timeout > command.interval {
+		command.timeout = command.interval
+	}
+
+	if format, ok := options["format"].(string); ok {
+		if !execFormats[format] {
+			return command, fmt.Errorf("invalid format: %s", format)
+		}
+		command.format = format
+	}
+
+	if command.mapping, err = parseRecordMapping(options); err != nil {
+		return command, err
+	}
+
+	return command, nil
+}
+
+// Initialize prepares the exec input for operation
+func (e *ExecInput) Initialize() bool {
+	settings, err := parseExecInputSettings(e.Config)
+	if err != nil {
+		return false
+	}
+	e.execInputSettings = settings
+	e.queue = newPushQueue(settings.maxPending)
+	e.slots = make(chan struct{}, settings.maxConcurrency)
+
+	e.hostname, _ = os.Hostname()
+	if e.hostname == "" {
+		e.hostname = "localhost"
+	}
+
+	e.SetStatus(model.StatusInitialized)
+	return true
+}
+
+// Validate checks if the exec input is properly configured
+func (e *ExecInput) Validate() bool {
+	_, err := parseExecInputSettings(e.Config)
+	return err == nil
+}
+
+// Start begins running the commands
+func (e *ExecInput) Start() bool {
+	e.mutex.Lock()
+	if e.cancel == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		e.cancel = cancel
+
+		for _, command := range e.commands {
+			e.wg.Add(1)
+			if command.stream {
+				go e.stream(ctx, command)
+			} else {
+				go e.run(ctx, command)
+			}
+		}
+	}
+	e.mutex.Unlock()
+
+	e.SetStatus(model.StatusRunning)
+	return true
+}
+
+// Stop kills the running commands and waits for them to exit. Output
+// already read stays queued until it is collected.
+func (e *ExecInput) Stop() bool {
+	e.mutex.Lock()
+	cancel := e.cancel
+	e.cancel = nil
+	e.mutex.Unlock()
+
+	if cancel != nil {
+		cancel()
+		e.wg.Wait()
+	}
+
+	e.SetStatus(model.StatusStopped)
+	return true
+}
+
+// Collect returns the points read since the last call
+func (e *ExecInput) Collect() []*model.DataBatch {
+	if e.queue == nil {
+		return nil
+	}
+
+	return e.queue.Drain(e.ID())
+}
+
+// run runs an interval command immediately and then on its interval until stopped
+func (e *ExecInput) run(ctx context.Context, command execCommand) {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(command.interval)
+	defer ticker.Stop()
+
+	for {
+		e.runOnce(ctx, command)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runOnce runs an interval command once it can take a slot, and queues its
+// output when it exits. Output read before a failure or timeout is kept.
+func (e *ExecInput) runOnce(ctx context.Context, command execCommand) {
+	select {
+	case e.slots <- struct{}{}:
+	case <-ctx.Done():
+		return
+	}
+	defer func() { <-e.slots }()
+
+	runCtx, cancel := context.WithTimeout(ctx, command.timeout)
+	defer cancel()
+
+	var mutex sync.Mutex
+	output := make(map[model.TelemetryType][]model.DataPoint)
+	err := e.runCommand(runCtx, command, func(dataType model.TelemetryType, points []model.DataPoint) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		output[dataType] = append(output[dataType], points...)
+		return nil
+	})
+
+	// Runs cut short by Stop are not reported as failures
+	if ctx.Err() != nil {
+		return
+	}
+
+	if runCtx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("timed out after %s", command.timeout)
+	}
+	if err != nil {
+		if core := e.Core(); core != nil {
+			core.PublishEvent(model.EventError, e.ID(), fmt.Errorf("command %s failed: %w", command.name, err))
+		}
+	}
+
+	// A full queue drops the run's output; the next run reports fresh values
+	for _, dataType := range []model.TelemetryType{model.LogTelemetryType, model.MetricTelemetryType, model.TraceTelemetryType} {
+		if len(output[dataType]) > 0 {
+			e.queue.Add(dataType, output[dataType])
+		}
+	}
+}
+
+// stream keeps a streaming command running until the context is cancelled,
+// restarting it with exponential backoff whenever it exits
+func (e *ExecInput) stream(ctx context.Context, command execCommand) {
+	defer e.wg.Done()
+
+	emit := func(dataType model.TelemetryType, points []model.DataPoint) error {
+		// Wait for room in the queue, pausing the command's output meanwhile
+		return e.queue.AddWait(ctx, dataType, points)
+	}
+
+	run := func(ctx context.Context) error {
+		if err := e.runCommand(ctx, command, emit); err != nil {
+			return fmt.Errorf("command %s failed: %w", command.name, err)
+		}
+		return fmt.Errorf("command %s exited", command.name)
+	}
+	superviseWithBackoff(ctx, e.minBackoff, e.maxBackoff, run, func(err error) {
+		if core := e.Core(); core != nil {
+			core.PublishEvent(model.EventError, e.ID(), err)
+		}
+	})
+}
+
+// runCommand runs a command until it exits or the context is done, passing
+// the points read from its output to emit as they are read
+func (e *ExecInput) runCommand(ctx context.Context, command execCommand, emit func(model.TelemetryType, []model.DataPoint) error) error {
+	cmd := exec.CommandContext(ctx, command.args[0], command.args[1:]...)
+	cmd.Dir = command.dir
+	if len(command.env) > 0 {
+		cmd.Env = append(os.Environ(), command.env...)
+	}
+	// Children left holding the output open do not keep a killed command waiting
+	cmd.WaitDelay = e.waitDelay
+
+	stdout, stdoutWriter := io.Pipe()
+	stderr, stderrWriter := io.Pipe()
+	cmd.Stdout = stdoutWriter
+	cmd.Stderr = stderrWriter
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	var readErr error
+	var readers sync.WaitGroup
+	readers.Add(2)
+	go func() {
+		defer readers.Done()
+		// The command is ended if its output cannot be parsed or queued, so
+		// it does not block writing output nobody reads
+		if readErr = e.readOutput(command, stdout, emit); readErr != nil {
+			_ = cmd.Process.Kill()
+		}
+		stdout.Close()
+	}()
+	go func() {
+		defer readers.Done()
+		e.readErrors(command, stderr, emit)
+		stderr.Close()
+	}()
+
+	waitErr := cmd.Wait()
+	stdoutWriter.Close()
+	stderrWriter.Close()
+	readers.Wait()
+
+	if readErr != nil && ctx.Err() == nil {
+		return fmt.Errorf("failed to read output: %w", readErr)
+	}
+	return waitErr
+}
+
+// readOutput parses a command's standard output in the command's format
+func (e *ExecInput) readOutput(command execCommand, r io.Reader, emit func(model.TelemetryType, []model.DataPoint) error) error {
+	if command.format == "json" {
+		return streamRecords(r, func(record map[string]interface{}) error {
+			point, err := command.mapping.newPoint(record, e.basePoint(command))
+			if err != nil {
+				// A record that does not map is skipped; the rest still do
+				if core := e.Core(); core != nil {
+					core.PublishEvent(model.EventError, e.ID(), fmt.Errorf("command %s: invalid record: %w", command.name, err))
+				}
+				return nil
+			}
+			return emit(command.mapping.dataType, []model.DataPoint{point})
+		})
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxExecLineSize)
+	scanner.Split(splitNewline(maxExecLineSize))
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if command.format == "lines" {
+			if err := emit(model.LogTelemetryType, []model.DataPoint{e.logPoint(command, line, "stdout", "INFO")}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		parsed, err := parseInfluxLine(strings.TrimSpace(line))
+		if err != nil {
+			if core := e.Core(); core != nil {
+				core.PublishEvent(model.EventError, e.ID(), fmt.Errorf("command %s: invalid line protocol: %w", command.name, err))
+			}
+			continue
+		}
+		if points := e.influxPoints(command, parsed); len(points) > 0 {
+			if err := emit(model.MetricTelemetryType, points); err != nil {
+				return err
+			}
+		}
+	}
+
+	return scanner.Err()
+}
+
+// readErrors turns each line a command writes to standard error into an
+// error log point
+func (e *ExecInput) readErrors(command execCommand, r io.Reader, emit func(model.TelemetryType, []model.DataPoint) error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxExecLineSize)
+	scanner.Split(splitNewline(maxExecLineSize))
+
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		if err := emit(model.LogTelemetryType, []model.DataPoint{e.logPoint(command, scanner.Text(), "stderr", "ERROR")}); err != nil {
+			return
+		}
+	}
+}
+
+// basePoint returns the point fields shared by everything a command outputs
+func (e *ExecInput) basePoint(command execCommand) model.BaseDataPoint {
+	return model.BaseDataPoint{
+		Timestamp: time.Now(),
+		Origin:    e.hostname,
+		Labels:    map[string]string{"source": "exec", "command": command.name},
+	}
+}
+
+// logPoint creates a log point for a line of output
+func (e *ExecInput) logPoint(command execCommand, line, stream, level string) *model.LogPoint {
+	base := e.basePoint(command)
+	base.Labels["stream"] = stream
+
+	return &model.LogPoint{
+		BaseDataPoint: base,
+		Message:       line,
+		Level:         level,
+		Attributes:    map[string]interface{}{},
+	}
+}
+
+// influxPoints creates a gauge for each numeric or boolean field of a line,
+// named <measurement>_<field>, or just <measurement> for a field named
+// value. Tags become dimensions; string fields are dropped.
+func (e *ExecInput) influxPoints(command execCommand, line influxLine) []model.DataPoint {
+	var points []model.DataPoint
+
+	for _, field := range sortedKeys(line.fields) {
+		value, ok := influxMetricValue(line.fields[field])
+		if !ok {
+			continue
+		}
+
+		name := line.measurement + "_" + field
+		if field == "value" {
+			name = line.measurement
+		}
+
+		base := e.basePoint(command)
+		if !line.timestamp.IsZero() {
+			base.Timestamp = line.timestamp
+		}
+		dimensions := make(map[string]string, len(line.tags))
+		for k, v := range line.tags {
+			dimensions[k] = v
+		}
+
+		points = append(points, &model.MetricPoint{
+			BaseDataPoint: base,
+			Name:          name,
+			Value:         value,
+			MetricType:    "gauge",
+			Dimensions:    dimensions,
+		})
+	}
+
+	return points
+}
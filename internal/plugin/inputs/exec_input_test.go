@@ -0,0 +1,270 @@
+package inputs
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sliink/collector/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// collectExec collects from an exec input until count points arrive or a
+// few seconds pass
+func collectExec(input *ExecInput, count int) []model.DataPoint {
+	var points []model.DataPoint
+	deadline := time.Now().Add(3 * time.Second)
+	for len(points) < count && time.Now().Before(deadline) {
+		for _, batch := range input.Collect() {
+			points = append(points, batch.Points...)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return points
+}
+
+// startExec starts an exec input with a configuration
+func startExec(t *testing.T, config map[string]interface{}) *ExecInput {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	input := NewExecInput("exec_input")
+	input.minBackoff = 10 * time.Millisecond
+	input.maxBackoff = 20 * time.Millisecond
+	assert.True(t, input.Configure(config))
+	assert.True(t, input.Validate())
+	assert.True(t, input.Initialize())
+	assert.True(t, input.Start())
+	return input
+}
+
+func TestExecInputValidate(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{},
+		{"commands": []interface{}{}},
+		{"commands": []interface{}{""}},
+		{"commands": []interface{}{[]interface{}{}}},
+		{"commands": []interface{}{[]interface{}{"echo", float64(1)}}},
+		{"commands": []interface{}{map[string]interface{}{"name": "no command"}}},
+		{"commands": []interface{}{"date", "date"}},
+		{"commands": []interface{}{"date"}, "mode": "daemon"},
+		{"commands": []interface{}{"date"}, "interval": "often"},
+		{"commands": []interface{}{"date"}, "timeout": "0s"},
+		{"commands": []interface{}{"date"}, "format": "xml"},
+		{"commands": []interface{}{"date"}, "format": "json", "data_type": "events"},
+		{"commands": []interface{}{"date"}, "env": map[string]interface{}{"A=B": "c"}},
+		{"commands": []interface{}{"date"}, "max_concurrency": float64(0)},
+		{"commands": []interface{}{"date"}, "max_pending": float64(0)},
+	}
+
+	for _, config := range invalid {
+		input := NewExecInput("exec_input")
+		input.Configure(config)
+		assert.False(t, input.Validate(), "%v", config)
+		assert.False(t, input.Initialize(), "%v", config)
+	}
+
+	settings, err := parseExecInputSettings(map[string]interface{}{
+		"interval": "10s",
+		"format":   "influx",
+		"commands": []interface{}{
+			"df -k",
+			[]interface{}{"/usr/bin/uptime", "-p"},
+			map[string]interface{}{"command": "tail -F app.log", "name": "app", "mode": "stream", "format": "lines", "timeout": "1m"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sh", "-c", "df -k"}, settings.commands[0].args)
+	assert.Equal(t, "influx", settings.commands[0].format)
+	assert.Equal(t, "/usr/bin/uptime -p", settings.commands[1].name)
+	assert.Equal(t, 10*time.Second, settings.commands[1].interval)
+	assert.Equal(t, "app", settings.commands[2].name)
+	assert.True(t, settings.commands[2].stream)
+	assert.Equal(t, "lines", settings.commands[2].format)
+	// The timeout is capped at the interval
+	assert.Equal(t, 10*time.Second, settings.commands[2].timeout)
+}
+
+func TestExecInputLines(t *testing.T) {
+	dir := t.TempDir()
+	input := startExec(t, map[string]interface{}{
+		"commands": []interface{}{map[string]interface{}{
+			"command": `echo "hello $GREETING"; echo oops >&2; echo; pwd`,
+			"name":    "greet",
+			"dir":     dir,
+			"env":     map[string]interface{}{"GREETING": "world"},
+		}},
+	})
+	points := collectExec(input, 3)
+	assert.True(t, input.Stop())
+
+	messages := make(map[string]*model.LogPoint)
+	for _, point := range points {
+		log := point.(*model.LogPoint)
+		messages[log.Message] = log
+	}
+	assert.Len(t, messages, 3)
+
+	if assert.Contains(t, messages, "hello world") {
+		assert.Equal(t, "INFO", messages["hello world"].Level)
+		assert.Equal(t, map[string]string{"source": "exec", "command": "greet", "stream": "stdout"}, messages["hello world"].Labels)
+	}
+	if assert.Contains(t, messages, "oops") {
+		assert.Equal(t, "ERROR", messages["oops"].Level)
+		assert.Equal(t, "stderr", messages["oops"].Labels["stream"])
+	}
+	resolved, _ := filepath.EvalSymlinks(dir)
+	assert.True(t, messages[dir] != nil || messages[resolved] != nil, "command runs in dir")
+}
+
+func TestExecInputFormats(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	events := &testCore{}
+	input := NewExecInput("exec_input")
+	input.RegisterWithCore(events)
+	assert.True(t, input.Configure(map[string]interface{}{
+		"commands": []interface{}{
+			map[string]interface{}{
+				"command": `printf 'cpu,host=web-1 usage=0.5,cores=4i,governor="performance"\nnot line protocol\n# comment\nload value=1.5 1714557600000000000\n'`,
+				"name":    "influx",
+				"format":  "influx",
+			},
+			map[string]interface{}{
+				"command":   `echo '[{"name": "queue_depth", "value": 3, "dimensions": {"queue": "jobs"}}, {"name": "no value"}]'`,
+				"name":      "json",
+				"format":    "json",
+				"data_type": "metrics",
+			},
+		},
+	}))
+	assert.True(t, input.Initialize())
+	assert.True(t, input.Start())
+	points := collectExec(input, 4)
+	assert.True(t, input.Stop())
+
+	metrics := make(map[string]*model.MetricPoint)
+	for _, point := range points {
+		metric := point.(*model.MetricPoint)
+		metrics[metric.Name] = metric
+	}
+	assert.Len(t, metrics, 4)
+
+	if assert.Contains(t, metrics, "cpu_usage") {
+		assert.Equal(t, 0.5, metrics["cpu_usage"].Value)
+		assert.Equal(t, "gauge", metrics["cpu_usage"].MetricType)
+		assert.Equal(t, map[string]string{"host": "web-1"}, metrics["cpu_usage"].Dimensions)
+		assert.Equal(t, map[string]string{"source": "exec", "command": "influx"}, metrics["cpu_usage"].Labels)
+	}
+	if assert.Contains(t, metrics, "cpu_cores") {
+		assert.Equal(t, 4.0, metrics["cpu_cores"].Value)
+	}
+	if assert.Contains(t, metrics, "load") {
+		assert.Equal(t, 1.5, metrics["load"].Value)
+		assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), metrics["load"].Timestamp.UTC())
+	}
+	if assert.Contains(t, metrics, "queue_depth") {
+		assert.Equal(t, 3.0, metrics["queue_depth"].Value)
+		assert.Equal(t, map[string]string{"queue": "jobs"}, metrics["queue_depth"].Dimensions)
+		assert.Equal(t, "json", metrics["queue_depth"].Labels["command"])
+	}
+
+	// The bad line and the record without a value are reported
+	assert.Equal(t, 2, events.count())
+}
+
+func TestExecInputTimeout(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	events := &testCore{}
+	input := NewExecInput("exec_input")
+	input.RegisterWithCore(events)
+	assert.True(t, input.Configure(map[string]interface{}{
+		"commands": []interface{}{"echo started; sleep 10; echo finished"},
+		"timeout":  "100ms",
+	}))
+	assert.True(t, input.Initialize())
+
+	start := time.Now()
+	assert.True(t, input.Start())
+	deadline := time.Now().Add(5 * time.Second)
+	for events.count() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Less(t, time.Since(start), 3*time.Second)
+
+	points := collectExec(input, 1)
+	assert.True(t, input.Stop())
+
+	if assert.Len(t, points, 1) {
+		assert.Equal(t, "started", points[0].(*model.LogPoint).Message)
+	}
+	if assert.Equal(t, 1, events.count()) {
+		assert.Contains(t, fmt.Sprint(events.events[0]), "timed out after 100ms")
+	}
+}
+
+func TestExecInputConcurrency(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "runs")
+	command := func(name string) interface{} {
+		return map[string]interface{}{
+			"name":    name,
+			"command": fmt.Sprintf("echo start >> %s; sleep 0.1; echo end >> %s", log, log),
+		}
+	}
+
+	input := startExec(t, map[string]interface{}{
+		"commands":        []interface{}{command("a"), command("b"), command("c")},
+		"max_concurrency": float64(1),
+	})
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if data, _ := os.ReadFile(log); strings.Count(string(data), "end") == 3 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.True(t, input.Stop())
+
+	// Each run finished before the next one started
+	data, err := os.ReadFile(log)
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Repeat("start\nend\n", 3), string(data))
+}
+
+func TestExecInputStream(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	events := &testCore{}
+	input := NewExecInput("exec_input")
+	input.minBackoff = 10 * time.Millisecond
+	input.maxBackoff = 20 * time.Millisecond
+	input.RegisterWithCore(events)
+	assert.True(t, input.Configure(map[string]interface{}{
+		"commands": []interface{}{"echo one; echo two"},
+		"mode":     "stream",
+	}))
+	assert.True(t, input.Initialize())
+	assert.True(t, input.Start())
+
+	// The command is restarted after it exits
+	points := collectExec(input, 4)
+	assert.True(t, input.Stop())
+
+	assert.GreaterOrEqual(t, len(points), 4)
+	assert.Equal(t, "one", points[0].(*model.LogPoint).Message)
+	assert.Equal(t, "two", points[1].(*model.LogPoint).Message)
+	assert.GreaterOrEqual(t, events.count(), 1)
+	assert.Contains(t, fmt.Sprint(events.events[0]), "exited")
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package inputs
+
+import "os"
+
+// fileIdentity is not available on this platform, so files are identified by
+// path and fingerprint alone
+func fileIdentity(info os.FileInfo) string {
+	return ""
+}
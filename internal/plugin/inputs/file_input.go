@@ -2,6 +2,12 @@ package inputs
 
 import (
 	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sync"
@@ -11,20 +17,60 @@ import (
 	"github.com/sliink/collector/internal/plugin"
 )
 
-// FileInput reads log data from files
+// fingerprintSize is the number of leading bytes used to recognize a file
+const fingerprintSize = 1024
+
+// FileInput tails log files, following them across rotation and truncation
 type FileInput struct {
 	plugin.BasePlugin
 	paths           []string
-	filePositions   map[string]int64
+	startAt         string
+	checkpointFile  string
+	savedCheckpoint []byte                     // Last checkpoint written, to skip unchanged writes
+	filePositions   map[string]*fileCheckpoint // Read positions keyed by file identity
+	tailers         map[string]*tailedFile     // Open files keyed by file identity
+	polled          bool
 	multilineConfig map[string]interface{}
+	multiline       *multilineSettings
 	mutex           sync.RWMutex
 }
 
+// fileCheckpoint records how far a file has been read
+type fileCheckpoint struct {
+	Path            string `json:"path"`
+	Offset          int64  `json:"offset"`
+	Fingerprint     string `json:"fingerprint"`
+	FingerprintSize int64  `json:"fingerprint_size"`
+}
+
+// tailedFile is a file held open while it is being read
+type tailedFile struct {
+	path       string
+	file       *os.File
+	checkpoint *fileCheckpoint
+	lastSize   int64 // Size at the previous poll, used to flush a final unterminated line
+	aggregator *multilineAggregator
+}
+
+// fileLine is a line read from a file along with the offset it started at
+type fileLine struct {
+	text   string
+	offset int64
+}
+
+func init() {
+	plugin.RegisterStandardInput("file", func(id string) model.InputPlugin {
+		return NewFileInput(id)
+	})
+}
+
 // NewFileInput creates a new file input plugin
 func NewFileInput(id string) *FileInput {
 	return &FileInput{
 		BasePlugin:      plugin.NewBasePlugin(id, "File Input", model.InputPluginType),
-		filePositions:   make(map[string]int64),
+		startAt:         "beginning",
+		filePositions:   make(map[string]*fileCheckpoint),
+		tailers:         make(map[string]*tailedFile),
 		multilineConfig: make(map[string]interface{}),
 	}
 }
@@ -40,9 +86,30 @@ func (f *FileInput) Initialize() bool {
 		}
 	}
 
+	// Get where to start reading files found at startup
+	if startAt, ok := f.Config["start_at"].(string); ok {
+		if startAt != "beginning" && startAt != "end" {
+			return false
+		}
+		f.startAt = startAt
+	}
+
+	// Get the checkpoint file, which keeps read positions across restarts
+	if checkpointFile, ok := f.Config["checkpoint_file"].(string); ok {
+		f.checkpointFile = checkpointFile
+		if err := f.loadCheckpoints(); err != nil {
+			return false
+		}
+	}
+
 	// Get multiline configuration if any
 	if multiline, ok := f.Config["multiline"].(map[string]interface{}); ok {
+		settings, err := parseMultiline(multiline)
+		if err != nil {
+			return false
+		}
 		f.multilineConfig = multiline
+		f.multiline = settings
 	}
 
 	f.SetStatus(model.StatusInitialized)
@@ -55,8 +122,23 @@ func (f *FileInput) Start() bool {
 	return true
 }
 
-// Stop halts file input operation
+// Stop halts file input operation, closing open files and saving checkpoints
 func (f *FileInput) Stop() bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for id, tailer := range f.tailers {
+		// Pending multiline events are read again on the next start
+		if tailer.aggregator != nil {
+			if start, pending := tailer.aggregator.Pending(); pending {
+				tailer.checkpoint.Offset = start
+			}
+		}
+		tailer.file.Close()
+		delete(f.tailers, id)
+	}
+	f.saveCheckpoints()
+
 	f.SetStatus(model.StatusStopped)
 	return true
 }
@@ -64,36 +146,66 @@ func (f *FileInput) Stop() bool {
 // Validate checks if the file input is properly configured
 func (f *FileInput) Validate() bool {
 	// Check if explicitly disabled
-	if enabled, ok := f.Config["enabled"].(bool); ok && !enabled {
+	if !f.enabled() {
 		// Disabled plugins are valid
 		return true
 	}
-	
+
 	// Check if paths are configured
 	if paths, ok := f.Config["paths"].([]interface{}); !ok || len(paths) == 0 {
 		return false
 	}
 
+	if startAt, ok := f.Config["start_at"].(string); ok && startAt != "beginning" && startAt != "end" {
+		return false
+	}
+
+	if multiline, ok := f.Config["multiline"].(map[string]interface{}); ok {
+		if _, err := parseMultiline(multiline); err != nil {
+			return false
+		}
+	}
+
 	return true
 }
 
-// Collect gathers log data from files
+// enabled reports whether the input is enabled, which it is unless configured otherwise
+func (f *FileInput) enabled() bool {
+	enabled, ok := f.Config["enabled"].(bool)
+	return !ok || enabled
+}
+
+// Collect gathers log lines written to the files since the last call
 func (f *FileInput) Collect() []*model.DataBatch {
 	if f.GetStatus() != model.StatusRunning {
 		return nil
 	}
-	
-	// Check if input is enabled
-	enabled, ok := f.Config["enabled"].(bool)
-	if !ok || !enabled {
-		// Skip collection if explicitly disabled
+
+	// Skip collection if explicitly disabled
+	if !f.enabled() {
 		return nil
 	}
 
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
 	var results []*model.DataBatch
 	batch := model.NewDataBatch(model.LogTelemetryType)
+	addPoints := func(points []*model.LogPoint) {
+		for _, point := range points {
+			batch.AddPoint(point)
 
-	// Process each configured path
+			// Create a new batch if current one is full
+			if batch.Size() >= 1000 { // Configurable batch size
+				results = append(results, batch)
+				batch = model.NewDataBatch(model.LogTelemetryType)
+			}
+		}
+	}
+
+	// Find the files matching the configured paths
+	seen := make(map[string]bool)
+	var active []*tailedFile
 	for _, pathPattern := range f.paths {
 		// Expand glob patterns
 		matches, err := filepath.Glob(pathPattern)
@@ -102,22 +214,43 @@ func (f *FileInput) Collect() []*model.DataBatch {
 		}
 
 		for _, path := range matches {
-			// Process each file
-			logPoints := f.processFile(path)
-			
-			// Add log points to batch
-			for _, point := range logPoints {
-				batch.AddPoint(point)
-				
-				// Create a new batch if current one is full
-				if batch.Size() >= 1000 { // Configurable batch size
-					results = append(results, batch)
-					batch = model.NewDataBatch(model.LogTelemetryType)
-				}
+			id, tailer := f.tail(path)
+			if tailer == nil || seen[id] {
+				continue
+			}
+			seen[id] = true
+			active = append(active, tailer)
+		}
+	}
+
+	// Files that were renamed away or removed are read to the end and closed
+	// first, so lines written before a rotation come before the new file's
+	for id, tailer := range f.tailers {
+		if seen[id] {
+			continue
+		}
+		addPoints(f.collectFile(tailer, true))
+		tailer.file.Close()
+		delete(f.tailers, id)
+		delete(f.filePositions, id)
+	}
+
+	for _, tailer := range active {
+		addPoints(f.collectFile(tailer, false))
+	}
+
+	// Forget checkpoints of files that no longer exist
+	if !f.polled {
+		for id := range f.filePositions {
+			if !seen[id] {
+				delete(f.filePositions, id)
 			}
 		}
+		f.polled = true
 	}
 
+	f.saveCheckpoints()
+
 	// Add the last batch if it has any points
 	if batch.Size() > 0 {
 		results = append(results, batch)
@@ -126,56 +259,252 @@ func (f *FileInput) Collect() []*model.DataBatch {
 	return results
 }
 
-// processFile reads a file and creates log points
-func (f *FileInput) processFile(path string) []*model.LogPoint {
-	f.mutex.Lock()
-	lastPosition, exists := f.filePositions[path]
-	f.mutex.Unlock()
+// tail returns the identity and open tailer for a path, opening the file if
+// it is new and rewinding it if it was truncated or replaced
+func (f *FileInput) tail(path string) (string, *tailedFile) {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return "", nil
+	}
+
+	id := fileIdentity(info)
+	if id == "" {
+		id = "path:" + path
+	}
+
+	tailer, exists := f.tailers[id]
+	if !exists {
+		file, err := os.Open(path)
+		if err != nil {
+			return id, nil
+		}
+
+		checkpoint, known := f.filePositions[id]
+		if !known || !matchesFingerprint(file, checkpoint) {
+			checkpoint = &fileCheckpoint{}
+			// start_at only applies to files found at the first poll, later files are new
+			if !f.polled && f.startAt == "end" {
+				checkpoint.Offset = info.Size()
+			}
+			f.filePositions[id] = checkpoint
+		}
+
+		tailer = &tailedFile{file: file, checkpoint: checkpoint, lastSize: -1}
+		if f.multiline != nil {
+			tailer.aggregator = newMultilineAggregator(f.multiline)
+		}
+		f.tailers[id] = tailer
+	}
+	tailer.path = path
+	tailer.checkpoint.Path = path
+
+	// A file that shrank or whose first bytes changed was truncated or replaced
+	if info.Size() < tailer.checkpoint.Offset || !matchesFingerprint(tailer.file, tailer.checkpoint) {
+		// Reopen in case the path now names a different file
+		file, err := os.Open(path)
+		if err != nil {
+			return id, nil
+		}
+		tailer.file.Close()
+		tailer.file = file
+		tailer.checkpoint.Offset = 0
+		tailer.checkpoint.Fingerprint = ""
+		tailer.checkpoint.FingerprintSize = 0
+		tailer.lastSize = -1
+	}
+
+	updateFingerprint(tailer.file, tailer.checkpoint, info.Size())
 
-	file, err := os.Open(path)
+	return id, tailer
+}
+
+// collectFile reads the new lines of a file and turns them into log points,
+// grouping them into multiline events when configured
+func (f *FileInput) collectFile(tailer *tailedFile, drain bool) []*model.LogPoint {
+	lines := f.readLines(tailer, drain)
+
+	var logPoints []*model.LogPoint
+	if tailer.aggregator == nil {
+		for _, line := range lines {
+			logPoints = append(logPoints, f.newLogPoint(tailer.path, line.text))
+		}
+		return logPoints
+	}
+
+	now := time.Now()
+	var events []string
+	for _, line := range lines {
+		events = append(events, tailer.aggregator.Add(line.text, line.offset, now)...)
+	}
+
+	// The last event is complete once the file is drained or stops growing for the timeout
+	if drain || tailer.aggregator.Expired(now) {
+		events = append(events, tailer.aggregator.Flush()...)
+	}
+
+	for _, event := range events {
+		logPoints = append(logPoints, f.newLogPoint(tailer.path, event))
+	}
+
+	return logPoints
+}
+
+// readLines reads the complete lines added to a file since its last offset.
+// An unterminated last line is held back until the file stops growing or is
+// drained after it was rotated away.
+func (f *FileInput) readLines(tailer *tailedFile, drain bool) []fileLine {
+	info, err := tailer.file.Stat()
 	if err != nil {
 		return nil
 	}
-	defer file.Close()
+	size := info.Size()
+	defer func() { tailer.lastSize = size }()
 
-	// Seek to last position if known
-	if exists {
-		_, err = file.Seek(lastPosition, 0)
+	if _, err := tailer.file.Seek(tailer.checkpoint.Offset, io.SeekStart); err != nil {
+		return nil
+	}
+
+	var lines []fileLine
+	reader := bufio.NewReader(tailer.file)
+	for {
+		line, err := reader.ReadBytes('\n')
 		if err != nil {
-			return nil
+			// Keep a partial line for the next poll while the file is still being written
+			flush := drain || (size == tailer.lastSize && tailer.checkpoint.Offset+int64(len(line)) == size)
+			if len(line) > 0 && flush {
+				lines = append(lines, fileLine{text: trimLine(line), offset: tailer.checkpoint.Offset})
+				tailer.checkpoint.Offset += int64(len(line))
+			}
+			break
 		}
+
+		lines = append(lines, fileLine{text: trimLine(line), offset: tailer.checkpoint.Offset})
+		tailer.checkpoint.Offset += int64(len(line))
 	}
 
-	var logPoints []*model.LogPoint
-	scanner := bufio.NewScanner(file)
-	
-	for scanner.Scan() {
-		line := scanner.Text()
-		
-		// Create a log point
-		logPoint := &model.LogPoint{
-			BaseDataPoint: model.BaseDataPoint{
-				Timestamp: time.Now(),
-				Origin:    path,
-				Labels: map[string]string{
-					"source": "file",
-					"path":   path,
-				},
+	return lines
+}
+
+// trimLine removes the line ending from a line
+func trimLine(line []byte) string {
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	return string(line)
+}
+
+// newLogPoint creates a log point for a line read from a file
+func (f *FileInput) newLogPoint(path string, message string) *model.LogPoint {
+	return &model.LogPoint{
+		BaseDataPoint: model.BaseDataPoint{
+			Timestamp: time.Now(),
+			Origin:    path,
+			Labels: map[string]string{
+				"source": "file",
+				"path":   path,
 			},
-			Message:    line,
-			Level:      "INFO", // Default level, would be parsed from content
-			Attributes: map[string]interface{}{},
+		},
+		Message:    message,
+		Level:      "INFO", // Default level, would be parsed from content
+		Attributes: map[string]interface{}{},
+	}
+}
+
+// loadCheckpoints reads saved read positions from the checkpoint file
+func (f *FileInput) loadCheckpoints() error {
+	data, err := os.ReadFile(f.checkpointFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read checkpoint file: %w", err)
+	}
+
+	checkpoints := make(map[string]*fileCheckpoint)
+	if err := json.Unmarshal(data, &checkpoints); err != nil {
+		return fmt.Errorf("failed to parse checkpoint file: %w", err)
+	}
+
+	f.filePositions = checkpoints
+	return nil
+}
+
+// saveCheckpoints writes the read positions to the checkpoint file
+func (f *FileInput) saveCheckpoints() error {
+	if f.checkpointFile == "" {
+		return nil
+	}
+
+	// Lines held in a pending multiline event are read again after a restart
+	checkpoints := make(map[string]*fileCheckpoint, len(f.filePositions))
+	for id, checkpoint := range f.filePositions {
+		if tailer, exists := f.tailers[id]; exists && tailer.aggregator != nil {
+			if start, pending := tailer.aggregator.Pending(); pending {
+				saved := *checkpoint
+				saved.Offset = start
+				checkpoint = &saved
+			}
 		}
-		
-		logPoints = append(logPoints, logPoint)
+		checkpoints[id] = checkpoint
 	}
 
-	// Update file position
-	currentPos, _ := file.Seek(0, 1) // Get current position
-	
-	f.mutex.Lock()
-	f.filePositions[path] = currentPos
-	f.mutex.Unlock()
+	data, err := json.Marshal(checkpoints)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(data, f.savedCheckpoint) {
+		return nil
+	}
 
-	return logPoints
-}
\ No newline at end of file
+	if err := os.MkdirAll(filepath.Dir(f.checkpointFile), 0755); err != nil {
+		return fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+
+	tmpPath := f.checkpointFile + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write checkpoint file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, f.checkpointFile); err != nil {
+		return fmt.Errorf("failed to write checkpoint file: %w", err)
+	}
+
+	f.savedCheckpoint = data
+	return nil
+}
+
+// fingerprint hashes the first size bytes of a file
+func fingerprint(file *os.File, size int64) (string, bool) {
+	buf := make([]byte, size)
+	if _, err := file.ReadAt(buf, 0); err != nil {
+		return "", false
+	}
+
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:]), true
+}
+
+// matchesFingerprint checks that a file still starts with the bytes recorded in a checkpoint
+func matchesFingerprint(file *os.File, checkpoint *fileCheckpoint) bool {
+	if checkpoint.FingerprintSize == 0 {
+		return true
+	}
+
+	sum, ok := fingerprint(file, checkpoint.FingerprintSize)
+	return ok && sum == checkpoint.Fingerprint
+}
+
+// updateFingerprint extends a checkpoint's fingerprint as the file grows
+func updateFingerprint(file *os.File, checkpoint *fileCheckpoint, size int64) {
+	target := size
+	if target > fingerprintSize {
+		target = fingerprintSize
+	}
+	if target <= checkpoint.FingerprintSize {
+		return
+	}
+
+	if sum, ok := fingerprint(file, target); ok {
+		checkpoint.Fingerprint = sum
+		checkpoint.FingerprintSize = target
+	}
+}
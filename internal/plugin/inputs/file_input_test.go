@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/sliink/collector/internal/model"
 	"github.com/stretchr/testify/assert"
@@ -232,4 +233,179 @@ func TestFileInputCollect(t *testing.T) {
 		assert.Equal(t, "line3", points[0].(*model.LogPoint).Message)
 		assert.Equal(t, "line4", points[1].(*model.LogPoint).Message)
 	})
-}
\ No newline at end of file
+}
+func collectMessages(input *FileInput) []string {
+	var messages []string
+	for _, batch := range input.Collect() {
+		for _, point := range batch.Points {
+			messages = append(messages, point.(*model.LogPoint).Message)
+		}
+	}
+	return messages
+}
+
+func appendToFile(t *testing.T, path string, content string) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	assert.NoError(t, err)
+	_, err = f.WriteString(content)
+	assert.NoError(t, err)
+	f.Close()
+}
+
+func TestFileInputTailing(t *testing.T) {
+	newInput := func(t *testing.T, config map[string]interface{}) *FileInput {
+		input := NewFileInput("file_input")
+		input.Config = config
+		assert.True(t, input.Validate())
+		assert.True(t, input.Initialize())
+		input.Start()
+		t.Cleanup(func() { input.Stop() })
+		return input
+	}
+
+	t.Run("Rotated files are drained before the new file is read", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "app.log")
+		appendToFile(t, path, "line1\n")
+
+		input := newInput(t, map[string]interface{}{"paths": []interface{}{path}})
+		assert.Equal(t, []string{"line1"}, collectMessages(input))
+
+		// logrotate: write more, rename, then create a new file
+		appendToFile(t, path, "line2\n")
+		assert.NoError(t, os.Rename(path, path+".1"))
+		appendToFile(t, path, "line3\n")
+
+		assert.Equal(t, []string{"line2", "line3"}, collectMessages(input))
+		assert.Empty(t, collectMessages(input))
+	})
+
+	t.Run("Truncated files are read from the start", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "app.log")
+		appendToFile(t, path, "line1\nline2\n")
+
+		input := newInput(t, map[string]interface{}{"paths": []interface{}{path}})
+		assert.Len(t, collectMessages(input), 2)
+
+		// copytruncate
+		assert.NoError(t, os.Truncate(path, 0))
+		appendToFile(t, path, "line3\n")
+
+		assert.Equal(t, []string{"line3"}, collectMessages(input))
+	})
+
+	t.Run("Replaced content of the same size is detected", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "app.log")
+		appendToFile(t, path, "aaaa\n")
+
+		input := newInput(t, map[string]interface{}{"paths": []interface{}{path}})
+		assert.Equal(t, []string{"aaaa"}, collectMessages(input))
+
+		assert.NoError(t, os.WriteFile(path, []byte("bbbb\ncccc\n"), 0644))
+		assert.Equal(t, []string{"bbbb", "cccc"}, collectMessages(input))
+	})
+
+	t.Run("Checkpoints resume reading after a restart", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "app.log")
+		checkpointFile := filepath.Join(dir, "state", "checkpoints.json")
+		config := map[string]interface{}{
+			"paths":           []interface{}{path},
+			"checkpoint_file": checkpointFile,
+		}
+		appendToFile(t, path, "line1\nline2\n")
+
+		input := newInput(t, config)
+		assert.Len(t, collectMessages(input), 2)
+		input.Stop()
+
+		appendToFile(t, path, "line3\n")
+
+		input = newInput(t, config)
+		assert.Equal(t, []string{"line3"}, collectMessages(input))
+	})
+
+	t.Run("Start at end skips existing content", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "app.log")
+		appendToFile(t, path, "old\n")
+
+		input := newInput(t, map[string]interface{}{
+			"paths":    []interface{}{filepath.Join(dir, "*.log")},
+			"start_at": "end",
+		})
+		assert.Empty(t, collectMessages(input))
+
+		appendToFile(t, path, "new\n")
+		appendToFile(t, filepath.Join(dir, "other.log"), "created later\n")
+
+		assert.ElementsMatch(t, []string{"new", "created later"}, collectMessages(input))
+	})
+
+	t.Run("Unterminated lines wait until the file stops growing", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "app.log")
+		appendToFile(t, path, "line1\npart")
+
+		input := newInput(t, map[string]interface{}{"paths": []interface{}{path}})
+		assert.Equal(t, []string{"line1"}, collectMessages(input))
+
+		appendToFile(t, path, "ial")
+		assert.Empty(t, collectMessages(input))
+
+		assert.Equal(t, []string{"partial"}, collectMessages(input))
+	})
+
+	t.Run("Invalid start_at is rejected", func(t *testing.T) {
+		input := NewFileInput("file_input")
+		input.Config = map[string]interface{}{
+			"paths":    []interface{}{"/var/log/test.log"},
+			"start_at": "middle",
+		}
+
+		assert.False(t, input.Validate())
+		assert.False(t, input.Initialize())
+	})
+}
+
+func TestFileInputMultiline(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	checkpointFile := filepath.Join(dir, "checkpoints.json")
+	config := map[string]interface{}{
+		"paths":           []interface{}{path},
+		"checkpoint_file": checkpointFile,
+		"multiline": map[string]interface{}{
+			"preset":  "java",
+			"timeout": "50ms",
+		},
+	}
+
+	input := NewFileInput("file_input")
+	input.Config = config
+	assert.True(t, input.Validate())
+	assert.True(t, input.Initialize())
+	input.Start()
+
+	appendToFile(t, path, "first\nException: boom\n\tat App.main(App.java:5)\n")
+	assert.Equal(t, []string{"first"}, collectMessages(input))
+
+	t.Run("Pending events are read again after a restart", func(t *testing.T) {
+		input.Stop()
+
+		input = NewFileInput("file_input")
+		input.Config = config
+		assert.True(t, input.Initialize())
+		input.Start()
+
+		assert.Empty(t, collectMessages(input))
+	})
+
+	t.Run("The last event is flushed after the timeout", func(t *testing.T) {
+		time.Sleep(60 * time.Millisecond)
+		assert.Equal(t, []string{"Exception: boom\n\tat App.main(App.java:5)"}, collectMessages(input))
+		input.Stop()
+	})
+}
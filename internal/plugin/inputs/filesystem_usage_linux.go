@@ -0,0 +1,17 @@
+//go:build linux
+
+package inputs
+
+import "syscall"
+
+// filesystemUsage returns the total, free and unprivileged-available bytes
+// of the filesystem mounted at path
+func filesystemUsage(path string) (total, free, available float64, err error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, 0, 0, err
+	}
+
+	blockSize := float64(stat.Bsize)
+	return float64(stat.Blocks) * blockSize, float64(stat.Bfree) * blockSize, float64(stat.Bavail) * blockSize, nil
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package inputs
+
+import "errors"
+
+// filesystemUsage is not available on this platform, so no filesystem
+// metrics are reported
+func filesystemUsage(path string) (total, free, available float64, err error) {
+	return 0, 0, 0, errors.New("filesystem usage is only available on Linux")
+}
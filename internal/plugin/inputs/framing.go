@@ -0,0 +1,197 @@
+package inputs
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+)
+
+// Framing modes for stream inputs
+const (
+	// FramingNewline ends each frame with a newline
+	FramingNewline = "newline"
+	// FramingOctetCounted prefixes each frame with its length in ASCII and a space (RFC 6587)
+	FramingOctetCounted = "octet_counted"
+	// FramingLengthPrefixed prefixes each frame with its length as a 4-byte big-endian integer
+	FramingLengthPrefixed = "length_prefixed"
+	// FramingMaxSize cuts the stream into frames of a fixed size
+	FramingMaxSize = "max_size"
+	// FramingAuto reads octet-counted frames when a frame starts with a digit
+	// and newline-terminated frames otherwise, as syslog senders mix both
+	FramingAuto = "auto"
+)
+
+// maxOctetCountDigits bounds the length prefix of an octet-counted frame
+const maxOctetCountDigits = 10
+
+// frameSplitter returns a bufio.SplitFunc that cuts a stream into frames no
+// larger than maxSize
+func frameSplitter(framing string, maxSize int) (bufio.SplitFunc, error) {
+	if maxSize <= 0 {
+		return nil, fmt.Errorf("max_frame_size must be positive")
+	}
+
+	switch framing {
+	case FramingNewline, "":
+		return splitNewline(maxSize), nil
+	case FramingOctetCounted:
+		return splitOctetCounted(maxSize), nil
+	case FramingLengthPrefixed:
+		return splitLengthPrefixed(maxSize), nil
+	case FramingMaxSize:
+		return splitMaxSize(maxSize), nil
+	case FramingAuto:
+		return splitAuto(maxSize), nil
+	default:
+		return nil, fmt.Errorf("invalid framing: %s", framing)
+	}
+}
+
+// splitNewline splits on newlines, dropping a trailing carriage return.
+// Lines longer than maxSize are split, and a final unterminated line is
+// returned when the stream ends.
+func splitNewline(maxSize int) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if atEOF && len(data) == 0 {
+			return 0, nil, nil
+		}
+
+		if i := bytes.IndexByte(data, '\n'); i >= 0 && i <= maxSize {
+			return i + 1, bytes.TrimSuffix(data[:i], []byte("\r")), nil
+		}
+
+		if len(data) >= maxSize {
+			return maxSize, data[:maxSize], nil
+		}
+
+		if atEOF {
+			return len(data), bytes.TrimSuffix(data, []byte("\r")), nil
+		}
+
+		// Wait for the rest of the line
+		return 0, nil, nil
+	}
+}
+
+// splitOctetCounted splits frames of the form "LEN SP MSG"
+func splitOctetCounted(maxSize int) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if atEOF && len(data) == 0 {
+			return 0, nil, nil
+		}
+
+		space := bytes.IndexByte(data, ' ')
+		if space < 0 {
+			if len(data) > maxOctetCountDigits || atEOF {
+				return 0, nil, fmt.Errorf("invalid octet-counted frame header")
+			}
+			return 0, nil, nil
+		}
+
+		length, err := strconv.Atoi(string(data[:space]))
+		if err != nil || length < 0 || space > maxOctetCountDigits {
+			return 0, nil, fmt.Errorf("invalid octet-counted frame length: %q", data[:space])
+		}
+		if length > maxSize {
+			return 0, nil, fmt.Errorf("frame of %d bytes exceeds max_frame_size", length)
+		}
+
+		end := space + 1 + length
+		if len(data) < end {
+			if atEOF {
+				return 0, nil, fmt.Errorf("truncated octet-counted frame")
+			}
+			return 0, nil, nil
+		}
+
+		return end, data[space+1 : end], nil
+	}
+}
+
+// splitLengthPrefixed splits frames preceded by a 4-byte big-endian length
+func splitLengthPrefixed(maxSize int) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if atEOF && len(data) == 0 {
+			return 0, nil, nil
+		}
+
+		if len(data) < 4 {
+			if atEOF {
+				return 0, nil, fmt.Errorf("truncated frame length")
+			}
+			return 0, nil, nil
+		}
+
+		length := binary.BigEndian.Uint32(data[:4])
+		if int64(length) > int64(maxSize) {
+			return 0, nil, fmt.Errorf("frame of %d bytes exceeds max_frame_size", length)
+		}
+
+		end := 4 + int(length)
+		if len(data) < end {
+			if atEOF {
+				return 0, nil, fmt.Errorf("truncated length-prefixed frame")
+			}
+			return 0, nil, nil
+		}
+
+		return end, data[4:end], nil
+	}
+}
+
+// splitMaxSize cuts the stream into frames of maxSize bytes, with a shorter
+// last frame when the stream ends
+func splitMaxSize(maxSize int) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if len(data) >= maxSize {
+			return maxSize, data[:maxSize], nil
+		}
+
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+
+		return 0, nil, nil
+	}
+}
+
+// splitAuto picks octet counting or newlines for each frame from its first
+// byte. Blank lines between frames are skipped.
+func splitAuto(maxSize int) bufio.SplitFunc {
+	octetCounted := splitOctetCounted(maxSize)
+	newline := splitNewline(maxSize)
+
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if len(data) == 0 {
+			return 0, nil, nil
+		}
+
+		switch c := data[0]; {
+		case c == '\n' || c == '\r':
+			return 1, nil, nil
+		case c >= '0' && c <= '9':
+			return octetCounted(data, atEOF)
+		default:
+			return newline(data, atEOF)
+		}
+	}
+}
+
+// splitDatagram returns the whole input as one frame, less trailing line
+// endings, for packet transports that carry one message per datagram
+// (RFC 5426). Input longer than maxSize is cut into frames of maxSize bytes.
+func splitDatagram(maxSize int) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if len(data) >= maxSize {
+			return maxSize, data[:maxSize], nil
+		}
+
+		if atEOF && len(data) > 0 {
+			return len(data), bytes.TrimRight(data, "\r\n"), nil
+		}
+
+		return 0, nil, nil
+	}
+}
@@ -0,0 +1,94 @@
+package inputs
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func scanFrames(t *testing.T, framing string, maxSize int, data []byte) ([]string, error) {
+	split, err := frameSplitter(framing, maxSize)
+	assert.NoError(t, err)
+
+	// Read one byte at a time so frames are reassembled across reads
+	scanner := bufio.NewScanner(iotest.OneByteReader(bytes.NewReader(data)))
+	scanner.Buffer(make([]byte, 16), maxSize+maxOctetCountDigits+1)
+	scanner.Split(split)
+
+	var frames []string
+	for scanner.Scan() {
+		frames = append(frames, scanner.Text())
+	}
+	return frames, scanner.Err()
+}
+
+func TestFrameSplitter(t *testing.T) {
+	t.Run("Newline", func(t *testing.T) {
+		frames, err := scanFrames(t, FramingNewline, 1024, []byte("one\r\ntwo\n\nthree"))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"one", "two", "", "three"}, frames)
+	})
+
+	t.Run("Newline splits long lines", func(t *testing.T) {
+		frames, err := scanFrames(t, FramingNewline, 4, []byte("abcdefgh\nij\n"))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"abcd", "efgh", "", "ij"}, frames)
+	})
+
+	t.Run("Octet counted", func(t *testing.T) {
+		frames, err := scanFrames(t, FramingOctetCounted, 1024, []byte("5 hello11 hello\nworld0 "))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"hello", "hello\nworld", ""}, frames)
+	})
+
+	t.Run("Octet counted rejects bad frames", func(t *testing.T) {
+		_, err := scanFrames(t, FramingOctetCounted, 1024, []byte("abc hello"))
+		assert.Error(t, err)
+
+		_, err = scanFrames(t, FramingOctetCounted, 4, []byte("5 hello"))
+		assert.Error(t, err)
+
+		_, err = scanFrames(t, FramingOctetCounted, 1024, []byte("10 short"))
+		assert.Error(t, err)
+	})
+
+	t.Run("Auto detects octet counting per frame", func(t *testing.T) {
+		frames, err := scanFrames(t, FramingAuto, 1024, []byte("<13>plain line\n11 <13>counted\n<13>last"))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"<13>plain line", "<13>counted", "<13>last"}, frames)
+	})
+
+	t.Run("Length prefixed", func(t *testing.T) {
+		var data bytes.Buffer
+		for _, frame := range []string{"first", "second\nline"} {
+			binary.Write(&data, binary.BigEndian, uint32(len(frame)))
+			data.WriteString(frame)
+		}
+
+		frames, err := scanFrames(t, FramingLengthPrefixed, 1024, data.Bytes())
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"first", "second\nline"}, frames)
+
+		_, err = scanFrames(t, FramingLengthPrefixed, 4, data.Bytes())
+		assert.Error(t, err)
+	})
+
+	t.Run("Max size", func(t *testing.T) {
+		frames, err := scanFrames(t, FramingMaxSize, 4, []byte(strings.Repeat("x", 10)))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"xxxx", "xxxx", "xx"}, frames)
+	})
+
+	t.Run("Invalid settings", func(t *testing.T) {
+		_, err := frameSplitter("carrier_pigeon", 1024)
+		assert.Error(t, err)
+
+		_, err = frameSplitter(FramingNewline, 0)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,49 @@
+package inputs
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/sliink/collector/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// testCore is a core that records the events published by an input and
+// reports whether its outputs are full
+type testCore struct {
+	full   bool
+	events []interface{}
+	mutex  sync.Mutex
+}
+
+func (c *testCore) ProcessBatch(batch *model.DataBatch) *model.DataBatch {
+	return batch
+}
+
+func (c *testCore) PublishEvent(eventType model.EventType, sourceID string, data interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.events = append(c.events, data)
+}
+
+func (c *testCore) InputBackpressured(inputID string) bool {
+	return c.full
+}
+
+// count returns the number of events published
+func (c *testCore) count() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.events)
+}
+
+// startTestInput configures, validates, initializes and starts an input,
+// stopping it when the test ends
+func startTestInput[T model.InputPlugin](t *testing.T, input T, config map[string]interface{}) T {
+	assert.True(t, input.Configure(config))
+	assert.True(t, input.Validate())
+	assert.True(t, input.Initialize())
+	assert.True(t, input.Start())
+	t.Cleanup(func() { input.Stop() })
+	return input
+}
@@ -0,0 +1,292 @@
+package inputs
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// cpuStates names the time columns of the cpu lines in /proc/stat
+var cpuStates = []string{"user", "nice", "system", "idle", "iowait", "irq", "softirq", "steal"}
+
+// procStat holds the parts of /proc/stat the host metrics input reports
+type procStat struct {
+	cpu             map[string]float64 // Jiffies spent in each state by all CPUs
+	contextSwitches float64
+	forks           float64
+	running         float64
+	blocked         float64
+}
+
+// readProcStat reads <root>/stat
+func readProcStat(root string) (*procStat, error) {
+	stat := &procStat{cpu: make(map[string]float64)}
+
+	err := scanProcFile(filepath.Join(root, "stat"), func(fields []string) error {
+		if len(fields) < 2 {
+			return nil
+		}
+
+		switch fields[0] {
+		case "cpu":
+			for i, state := range cpuStates {
+				if i+1 >= len(fields) {
+					break
+				}
+				value, err := strconv.ParseFloat(fields[i+1], 64)
+				if err != nil {
+					return fmt.Errorf("invalid cpu time: %s", fields[i+1])
+				}
+				stat.cpu[state] = value
+			}
+		case "ctxt":
+			stat.contextSwitches, _ = strconv.ParseFloat(fields[1], 64)
+		case "processes":
+			stat.forks, _ = strconv.ParseFloat(fields[1], 64)
+		case "procs_running":
+			stat.running, _ = strconv.ParseFloat(fields[1], 64)
+		case "procs_blocked":
+			stat.blocked, _ = strconv.ParseFloat(fields[1], 64)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(stat.cpu) == 0 {
+		return nil, fmt.Errorf("no cpu line in %s", filepath.Join(root, "stat"))
+	}
+	return stat, nil
+}
+
+// readMeminfo reads <root>/meminfo as bytes by field name
+func readMeminfo(root string) (map[string]float64, error) {
+	meminfo := make(map[string]float64)
+
+	err := scanProcFile(filepath.Join(root, "meminfo"), func(fields []string) error {
+		if len(fields) < 2 {
+			return nil
+		}
+
+		value, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return fmt.Errorf("invalid meminfo value: %s", fields[1])
+		}
+		if len(fields) > 2 && fields[2] == "kB" {
+			value *= 1024
+		}
+		meminfo[strings.TrimSuffix(fields[0], ":")] = value
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := meminfo["MemTotal"]; !ok {
+		return nil, fmt.Errorf("no MemTotal in %s", filepath.Join(root, "meminfo"))
+	}
+	return meminfo, nil
+}
+
+// readLoadavg reads the 1, 5 and 15 minute load averages from <root>/loadavg
+func readLoadavg(root string) ([3]float64, error) {
+	var loads [3]float64
+
+	data, err := os.ReadFile(filepath.Join(root, "loadavg"))
+	if err != nil {
+		return loads, err
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) < 3 {
+		return loads, fmt.Errorf("invalid loadavg: %q", string(data))
+	}
+	for i := range loads {
+		if loads[i], err = strconv.ParseFloat(fields[i], 64); err != nil {
+			return loads, fmt.Errorf("invalid load average: %s", fields[i])
+		}
+	}
+	return loads, nil
+}
+
+// diskStats are the counters of a block device in /proc/diskstats
+type diskStats struct {
+	reads        float64
+	writes       float64
+	readBytes    float64
+	writtenBytes float64
+	ioTime       float64 // Milliseconds spent doing I/O
+}
+
+// readDiskstats reads <root>/diskstats by device name, skipping loop and
+// RAM disks. Sectors are always 512 bytes in this file.
+func readDiskstats(root string) (map[string]diskStats, error) {
+	disks := make(map[string]diskStats)
+
+	err := scanProcFile(filepath.Join(root, "diskstats"), func(fields []string) error {
+		if len(fields) < 14 {
+			return nil
+		}
+
+		device := fields[2]
+		if strings.HasPrefix(device, "loop") || strings.HasPrefix(device, "ram") {
+			return nil
+		}
+
+		values := make([]float64, 11)
+		for i := range values {
+			value, err := strconv.ParseFloat(fields[i+3], 64)
+			if err != nil {
+				return fmt.Errorf("invalid diskstats value for %s: %s", device, fields[i+3])
+			}
+			values[i] = value
+		}
+
+		disks[device] = diskStats{
+			reads:        values[0],
+			readBytes:    values[2] * 512,
+			writes:       values[4],
+			writtenBytes: values[6] * 512,
+			ioTime:       values[9],
+		}
+		return nil
+	})
+	return disks, err
+}
+
+// netStats are the counters of a network interface in /proc/net/dev
+type netStats struct {
+	receivedBytes      float64
+	receivedPackets    float64
+	receiveErrors      float64
+	receiveDropped     float64
+	transmittedBytes   float64
+	transmittedPackets float64
+	transmitErrors     float64
+	transmitDropped    float64
+}
+
+// readNetDev reads <root>/net/dev by interface name
+func readNetDev(root string) (map[string]netStats, error) {
+	interfaces := make(map[string]netStats)
+
+	err := scanProcFile(filepath.Join(root, "net", "dev"), func(fields []string) error {
+		// Header lines have no colon after the interface name
+		if len(fields) == 0 || !strings.Contains(fields[0], ":") {
+			return nil
+		}
+
+		// The counters may follow the colon without a space
+		name, first, _ := strings.Cut(fields[0], ":")
+		counters := fields[1:]
+		if first != "" {
+			counters = append([]string{first}, counters...)
+		}
+		if len(counters) < 16 {
+			return nil
+		}
+
+		values := make([]float64, 16)
+		for i := range values {
+			value, err := strconv.ParseFloat(counters[i], 64)
+			if err != nil {
+				return fmt.Errorf("invalid net/dev value for %s: %s", name, counters[i])
+			}
+			values[i] = value
+		}
+
+		interfaces[name] = netStats{
+			receivedBytes:      values[0],
+			receivedPackets:    values[1],
+			receiveErrors:      values[2],
+			receiveDropped:     values[3],
+			transmittedBytes:   values[8],
+			transmittedPackets: values[9],
+			transmitErrors:     values[10],
+			transmitDropped:    values[11],
+		}
+		return nil
+	})
+	return interfaces, err
+}
+
+// mount is a mounted filesystem listed in /proc/mounts
+type mount struct {
+	device     string
+	mountpoint string
+	fsType     string
+}
+
+// virtualFilesystems are filesystem types that hold no disk data
+var virtualFilesystems = map[string]bool{
+	"autofs": true, "binfmt_misc": true, "bpf": true, "cgroup": true, "cgroup2": true,
+	"configfs": true, "debugfs": true, "devpts": true, "devtmpfs": true, "fusectl": true,
+	"hugetlbfs": true, "mqueue": true, "nsfs": true, "proc": true, "pstore": true,
+	"rpc_pipefs": true, "securityfs": true, "selinuxfs": true, "squashfs": true,
+	"sysfs": true, "tracefs": true,
+}
+
+// readMounts reads <root>/mounts, skipping virtual filesystems and mount
+// points that are listed more than once
+func readMounts(root string) ([]mount, error) {
+	var mounts []mount
+	seen := make(map[string]bool)
+
+	err := scanProcFile(filepath.Join(root, "mounts"), func(fields []string) error {
+		if len(fields) < 3 || virtualFilesystems[fields[2]] {
+			return nil
+		}
+
+		mountpoint := unescapeMountPath(fields[1])
+		if seen[mountpoint] {
+			return nil
+		}
+		seen[mountpoint] = true
+
+		mounts = append(mounts, mount{device: fields[0], mountpoint: mountpoint, fsType: fields[2]})
+		return nil
+	})
+	return mounts, err
+}
+
+// unescapeMountPath decodes the octal escapes /proc/mounts uses for spaces,
+// tabs, newlines and backslashes in paths
+func unescapeMountPath(path string) string {
+	if !strings.Contains(path, `\`) {
+		return path
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] == '\\' && i+3 < len(path) {
+			if c, err := strconv.ParseUint(path[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(path[i])
+	}
+	return b.String()
+}
+
+// scanProcFile calls fn with the whitespace-separated fields of each line of a file
+func scanProcFile(path string, fn func(fields []string) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if err := fn(strings.Fields(scanner.Text())); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,419 @@
+package inputs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sliink/collector/internal/model"
+	"github.com/sliink/collector/internal/plugin"
+)
+
+// hostMetricsScrapers are the metric groups the host metrics input can collect
+var hostMetricsScrapers = []string{"cpu", "memory", "load", "disk", "network", "filesystem"}
+
+// HostMetricsInput reports the health of the machine the collector runs on
+// from /proc and filesystem statistics, once per collection interval
+type HostMetricsInput struct {
+	plugin.BasePlugin
+	hostMetricsInputSettings
+	hostname     string
+	last         time.Time // When metrics were last collected
+	prevStat     *procStat // CPU counters of the previous collection
+	prevStatTime time.Time
+	prevNet      map[string]netStats // Network counters of the previous collection
+	prevNetTime  time.Time
+	now          func() time.Time
+	mutex        sync.Mutex
+}
+
+// hostMetricsInputSettings holds the host metrics input configuration
+type hostMetricsInputSettings struct {
+	interval time.Duration
+	procPath string
+	rootPath string
+	scrapers map[string]bool
+}
+
+func init() {
+	plugin.RegisterStandardInput("hostmetrics", func(id string) model.InputPlugin {
+		return NewHostMetricsInput(id)
+	})
+}
+
+// NewHostMetricsInput creates a new host metrics input plugin
+func NewHostMetricsInput(id string) *HostMetricsInput {
+	return &HostMetricsInput{
+		BasePlugin: plugin.NewBasePlugin(id, "Host Metrics Input", model.InputPluginType),
+		now:        time.Now,
+	}
+}
+
+// parseHostMetricsInputSettings reads the host metrics input configuration
+func parseHostMetricsInputSettings(config map[string]interface{}) (hostMetricsInputSettings, error) {
+	settings := hostMetricsInputSettings{
+		procPath: "/proc",
+		scrapers: make(map[string]bool),
+	}
+
+	interval, err := durationOption(config, "collection_interval", 10*time.Second)
+	if err != nil {
+		return settings, err
+	}
+	settings.interval = interval
+
+	if procPath, ok := config["proc_path"].(string); ok && procPath != "" {
+		settings.procPath = procPath
+	}
+	if rootPath, ok := config["root_path"].(string); ok {
+		settings.rootPath = rootPath
+	}
+
+	scrapers, ok := config["collect"].([]interface{})
+	if !ok {
+		for _, name := range hostMetricsScrapers {
+			settings.scrapers[name] = true
+		}
+		return settings, nil
+	}
+
+	for _, item := range scrapers {
+		name, _ := item.(string)
+		known := false
+		for _, scraper := range hostMetricsScrapers {
+			known = known || name == scraper
+		}
+		if !known {
+			return settings, fmt.Errorf("unknown metric group: %v", item)
+		}
+		settings.scrapers[name] = true
+	}
+	if len(settings.scrapers) == 0 {
+		return settings, fmt.Errorf("collect must name at least one metric group")
+	}
+
+	return settings, nil
+}
+
+// Initialize prepares the host metrics input for operation
+func (h *HostMetricsInput) Initialize() bool {
+	settings, err := parseHostMetricsInputSettings(h.Config)
+	if err != nil {
+		return false
+	}
+	h.hostMetricsInputSettings = settings
+
+	h.hostname, _ = os.Hostname()
+	if h.hostname == "" {
+		h.hostname = "localhost"
+	}
+
+	h.SetStatus(model.StatusInitialized)
+	return true
+}
+
+// Validate checks if the host metrics input is properly configured
+func (h *HostMetricsInput) Validate() bool {
+	_, err := parseHostMetricsInputSettings(h.Config)
+	return err == nil
+}
+
+// Start begins collecting host metrics
+func (h *HostMetricsInput) Start() bool {
+	h.SetStatus(model.StatusRunning)
+	return true
+}
+
+// Stop stops collecting host metrics
+func (h *HostMetricsInput) Stop() bool {
+	h.SetStatus(model.StatusStopped)
+	return true
+}
+
+// Collect reads host metrics once the collection interval has passed since
+// the last collection. A metric group that cannot be read is reported as an
+// error event and the others are still returned.
+func (h *HostMetricsInput) Collect() []*model.DataBatch {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	now := h.now()
+	if !h.last.IsZero() && now.Sub(h.last) < h.interval {
+		return nil
+	}
+	h.last = now
+
+	var points []model.DataPoint
+	for _, name := range hostMetricsScrapers {
+		if !h.scrapers[name] {
+			continue
+		}
+
+		var scraped []model.DataPoint
+		var err error
+		switch name {
+		case "cpu":
+			scraped, err = h.scrapeCPU(now)
+		case "memory":
+			scraped, err = h.scrapeMemory(now)
+		case "load":
+			scraped, err = h.scrapeLoad(now)
+		case "disk":
+			scraped, err = h.scrapeDisk(now)
+		case "network":
+			scraped, err = h.scrapeNetwork(now)
+		case "filesystem":
+			scraped, err = h.scrapeFilesystem(now)
+		}
+		if err != nil {
+			if core := h.Core(); core != nil {
+				core.PublishEvent(model.EventError, h.ID(), fmt.Errorf("failed to collect %s metrics: %w", name, err))
+			}
+			continue
+		}
+		points = append(points, scraped...)
+	}
+	if len(points) == 0 {
+		return nil
+	}
+
+	batch := model.NewDataBatch(model.MetricTelemetryType)
+	batch.SourceID = h.ID()
+	for _, point := range points {
+		batch.AddPoint(point)
+	}
+	return []*model.DataBatch{batch}
+}
+
+// scrapeCPU reports the share of time spent in each CPU state and the rates
+// of context switches and process creation since the previous collection,
+// so the first collection only reports process counts
+func (h *HostMetricsInput) scrapeCPU(now time.Time) ([]model.DataPoint, error) {
+	stat, err := readProcStat(h.procPath)
+	if err != nil {
+		return nil, err
+	}
+
+	points := []model.DataPoint{
+		h.point(now, "system.processes.count", stat.running, "gauge", map[string]string{"status": "running"}),
+		h.point(now, "system.processes.count", stat.blocked, "gauge", map[string]string{"status": "blocked"}),
+	}
+
+	prev, elapsed := h.prevStat, now.Sub(h.prevStatTime).Seconds()
+	h.prevStat, h.prevStatTime = stat, now
+	if prev == nil || elapsed <= 0 {
+		return points, nil
+	}
+
+	var total float64
+	deltas := make(map[string]float64, len(stat.cpu))
+	for state, value := range stat.cpu {
+		deltas[state] = value - prev.cpu[state]
+		total += deltas[state]
+	}
+	if total > 0 {
+		for _, state := range cpuStates {
+			if delta, ok := deltas[state]; ok && delta >= 0 {
+				points = append(points, h.point(now, "system.cpu.utilization", delta/total, "gauge", map[string]string{"state": state}))
+			}
+		}
+	}
+
+	if delta := stat.contextSwitches - prev.contextSwitches; delta >= 0 {
+		points = append(points, h.point(now, "system.cpu.context_switches", delta/elapsed, "gauge", nil))
+	}
+	if delta := stat.forks - prev.forks; delta >= 0 {
+		points = append(points, h.point(now, "system.processes.created", delta/elapsed, "gauge", nil))
+	}
+	return points, nil
+}
+
+// scrapeMemory reports memory and swap usage in bytes
+func (h *HostMetricsInput) scrapeMemory(now time.Time) ([]model.DataPoint, error) {
+	meminfo, err := readMeminfo(h.procPath)
+	if err != nil {
+		return nil, err
+	}
+
+	total := meminfo["MemTotal"]
+	free, buffered, cached := meminfo["MemFree"], meminfo["Buffers"], meminfo["Cached"]+meminfo["SReclaimable"]
+	used := total - free - buffered - cached
+
+	var points []model.DataPoint
+	for _, usage := range []struct {
+		state string
+		value float64
+	}{{"used", used}, {"free", free}, {"buffered", buffered}, {"cached", cached}} {
+		points = append(points, h.point(now, "system.memory.usage", usage.value, "gauge", map[string]string{"state": usage.state}))
+	}
+	if total > 0 {
+		points = append(points, h.point(now, "system.memory.utilization", used/total, "gauge", nil))
+	}
+	if available, ok := meminfo["MemAvailable"]; ok {
+		points = append(points, h.point(now, "system.memory.available", available, "gauge", nil))
+	}
+
+	if swapTotal := meminfo["SwapTotal"]; swapTotal > 0 {
+		swapFree := meminfo["SwapFree"]
+		points = append(points,
+			h.point(now, "system.paging.usage", swapTotal-swapFree, "gauge", map[string]string{"state": "used"}),
+			h.point(now, "system.paging.usage", swapFree, "gauge", map[string]string{"state": "free"}),
+		)
+	}
+	return points, nil
+}
+
+// scrapeLoad reports the 1, 5 and 15 minute load averages
+func (h *HostMetricsInput) scrapeLoad(now time.Time) ([]model.DataPoint, error) {
+	loads, err := readLoadavg(h.procPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return []model.DataPoint{
+		h.point(now, "system.cpu.load_average.1m", loads[0], "gauge", nil),
+		h.point(now, "system.cpu.load_average.5m", loads[1], "gauge", nil),
+		h.point(now, "system.cpu.load_average.15m", loads[2], "gauge", nil),
+	}, nil
+}
+
+// scrapeDisk reports the running totals of bytes, operations and time spent
+// on I/O by each block device
+func (h *HostMetricsInput) scrapeDisk(now time.Time) ([]model.DataPoint, error) {
+	disks, err := readDiskstats(h.procPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var points []model.DataPoint
+	for _, device := range sortedKeys(disks) {
+		disk := disks[device]
+		read := map[string]string{"device": device, "direction": "read"}
+		write := map[string]string{"device": device, "direction": "write"}
+		points = append(points,
+			h.point(now, "system.disk.io", disk.readBytes, "counter", read),
+			h.point(now, "system.disk.io", disk.writtenBytes, "counter", write),
+			h.point(now, "system.disk.operations", disk.reads, "counter", read),
+			h.point(now, "system.disk.operations", disk.writes, "counter", write),
+			h.point(now, "system.disk.io_time", disk.ioTime/1000, "counter", map[string]string{"device": device}),
+		)
+	}
+	return points, nil
+}
+
+// scrapeNetwork reports the per-second rates of bytes, packets, errors and
+// drops on each interface since the previous collection. Interfaces whose
+// counters went backwards, as after a reset, are skipped for one collection.
+func (h *HostMetricsInput) scrapeNetwork(now time.Time) ([]model.DataPoint, error) {
+	interfaces, err := readNetDev(h.procPath)
+	if err != nil {
+		return nil, err
+	}
+
+	prev, elapsed := h.prevNet, now.Sub(h.prevNetTime).Seconds()
+	h.prevNet, h.prevNetTime = interfaces, now
+	if prev == nil || elapsed <= 0 {
+		return nil, nil
+	}
+
+	var points []model.DataPoint
+	for _, device := range sortedKeys(interfaces) {
+		before, ok := prev[device]
+		if !ok {
+			continue
+		}
+		stats := interfaces[device]
+
+		rates := []struct {
+			name          string
+			direction     string
+			value, before float64
+		}{
+			{"system.network.io", "receive", stats.receivedBytes, before.receivedBytes},
+			{"system.network.io", "transmit", stats.transmittedBytes, before.transmittedBytes},
+			{"system.network.packets", "receive", stats.receivedPackets, before.receivedPackets},
+			{"system.network.packets", "transmit", stats.transmittedPackets, before.transmittedPackets},
+			{"system.network.errors", "receive", stats.receiveErrors, before.receiveErrors},
+			{"system.network.errors", "transmit", stats.transmitErrors, before.transmitErrors},
+			{"system.network.dropped", "receive", stats.receiveDropped, before.receiveDropped},
+			{"system.network.dropped", "transmit", stats.transmitDropped, before.transmitDropped},
+		}
+
+		var devicePoints []model.DataPoint
+		for _, rate := range rates {
+			if rate.value < rate.before {
+				devicePoints = nil
+				break
+			}
+			devicePoints = append(devicePoints, h.point(now, rate.name, (rate.value-rate.before)/elapsed, "gauge", map[string]string{"device": device, "direction": rate.direction}))
+		}
+		points = append(points, devicePoints...)
+	}
+	return points, nil
+}
+
+// scrapeFilesystem reports the space used, free and reserved for root on
+// each mounted disk filesystem. Mount points are looked up under root_path,
+// and those that cannot be read are skipped.
+func (h *HostMetricsInput) scrapeFilesystem(now time.Time) ([]model.DataPoint, error) {
+	mounts, err := readMounts(h.procPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var points []model.DataPoint
+	for _, mount := range mounts {
+		total, free, available, err := filesystemUsage(filepath.Join(h.rootPath, mount.mountpoint))
+		if err != nil || total == 0 {
+			continue
+		}
+
+		used := total - free
+		dimensions := func(state string) map[string]string {
+			d := map[string]string{"device": mount.device, "mountpoint": mount.mountpoint, "type": mount.fsType}
+			if state != "" {
+				d["state"] = state
+			}
+			return d
+		}
+		points = append(points,
+			h.point(now, "system.filesystem.usage", used, "gauge", dimensions("used")),
+			h.point(now, "system.filesystem.usage", available, "gauge", dimensions("free")),
+			h.point(now, "system.filesystem.usage", free-available, "gauge", dimensions("reserved")),
+			h.point(now, "system.filesystem.utilization", used/(used+available), "gauge", dimensions("")),
+		)
+	}
+	return points, nil
+}
+
+// point builds a host metric point
+func (h *HostMetricsInput) point(now time.Time, name string, value float64, metricType string, dimensions map[string]string) *model.MetricPoint {
+	if dimensions == nil {
+		dimensions = map[string]string{}
+	}
+
+	return &model.MetricPoint{
+		BaseDataPoint: model.BaseDataPoint{
+			Timestamp: now,
+			Origin:    h.hostname,
+			Labels:    map[string]string{"source": "hostmetrics"},
+		},
+		Name:       name,
+		Value:      value,
+		MetricType: metricType,
+		Dimensions: dimensions,
+	}
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
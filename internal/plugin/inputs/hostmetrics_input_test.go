@@ -0,0 +1,133 @@
+package inputs
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/sliink/collector/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHostMetricsInputValidate(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{"collection_interval": "0s"},
+		{"collection_interval": "often"},
+		{"collect": []interface{}{"cpu", "gpu"}},
+		{"collect": []interface{}{}},
+	}
+
+	for _, config := range invalid {
+		input := NewHostMetricsInput("host_input")
+		input.Configure(config)
+		assert.False(t, input.Validate(), "%v", config)
+		assert.False(t, input.Initialize(), "%v", config)
+	}
+}
+
+// collectHostMetrics collects from a host metrics input and indexes the
+// points by name and dimensions
+func collectHostMetrics(input *HostMetricsInput) map[string]*model.MetricPoint {
+	points := make(map[string]*model.MetricPoint)
+	for _, batch := range input.Collect() {
+		for _, point := range batch.Points {
+			metric := point.(*model.MetricPoint)
+			points[metric.Name+"{"+statsdTagKey(metric.Dimensions)+"}"] = metric
+		}
+	}
+	return points
+}
+
+func TestHostMetricsInputCollect(t *testing.T) {
+	root := t.TempDir()
+	writeProcFixture(t, root, map[string]string{
+		"stat":      "cpu  100 0 100 800 0 0 0 0\nctxt 1000\nprocesses 50\nprocs_running 2\nprocs_blocked 0\n",
+		"meminfo":   "MemTotal: 1000 kB\nMemFree: 400 kB\nMemAvailable: 600 kB\nBuffers: 100 kB\nCached: 100 kB\nSwapTotal: 500 kB\nSwapFree: 500 kB\n",
+		"loadavg":   "1.00 0.50 0.25 1/100 1000\n",
+		"diskstats": "8 0 sda 10 0 100 0 20 0 200 0 0 3000 0\n",
+		"net/dev":   "eth0: 1000 10 0 0 0 0 0 0 2000 20 0 0 0 0 0 0\n",
+		"mounts":    "tmp " + root + " ext4 rw 0 0\nproc /proc proc rw 0 0\n",
+	})
+
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	input := NewHostMetricsInput("host_input")
+	input.now = func() time.Time { return now }
+	assert.True(t, input.Configure(map[string]interface{}{
+		"collection_interval": "10s",
+		"proc_path":           root,
+	}))
+	assert.True(t, input.Validate())
+	assert.True(t, input.Initialize())
+	assert.True(t, input.Start())
+	defer input.Stop()
+
+	// Rates need a previous collection, so the first reports gauges and totals only
+	points := collectHostMetrics(input)
+	assert.Equal(t, 2.0, points["system.processes.count{status=running}"].Value)
+	assert.Equal(t, 400.0*1024, points["system.memory.usage{state=used}"].Value)
+	assert.Equal(t, 0.4, points["system.memory.utilization{}"].Value)
+	assert.Equal(t, 0.0, points["system.paging.usage{state=used}"].Value)
+	assert.Equal(t, 0.5, points["system.cpu.load_average.5m{}"].Value)
+	assert.Equal(t, 100.0*512, points["system.disk.io{device=sda,direction=read}"].Value)
+	assert.Equal(t, "counter", points["system.disk.io{device=sda,direction=read}"].MetricType)
+	assert.Equal(t, 3.0, points["system.disk.io_time{device=sda}"].Value)
+	assert.NotContains(t, points, "system.cpu.utilization{state=user}")
+	assert.NotContains(t, points, "system.network.io{device=eth0,direction=receive}")
+
+	load := points["system.cpu.load_average.1m{}"]
+	assert.Equal(t, now, load.Timestamp)
+	assert.Equal(t, input.hostname, load.Origin)
+	assert.Equal(t, map[string]string{"source": "hostmetrics"}, load.Labels)
+	assert.Equal(t, "gauge", load.MetricType)
+
+	if runtime.GOOS == "linux" {
+		usage := points["system.filesystem.utilization{device=tmp,mountpoint="+root+",type=ext4}"]
+		if assert.NotNil(t, usage) {
+			assert.True(t, usage.Value >= 0 && usage.Value <= 1)
+		}
+		assert.NotContains(t, points, "system.filesystem.utilization{device=proc,mountpoint=/proc,type=proc}")
+	}
+
+	// Nothing is collected until the interval has passed
+	now = now.Add(5 * time.Second)
+	assert.Empty(t, input.Collect())
+
+	now = now.Add(5 * time.Second)
+	writeProcFixture(t, root, map[string]string{
+		"stat":    "cpu  150 0 150 900 0 0 0 0\nctxt 1500\nprocesses 60\nprocs_running 1\nprocs_blocked 0\n",
+		"net/dev": "eth0: 11000 20 0 0 0 0 0 0 2500 25 0 0 0 0 0 0\n",
+	})
+
+	points = collectHostMetrics(input)
+	assert.Equal(t, 0.25, points["system.cpu.utilization{state=user}"].Value)
+	assert.Equal(t, 0.25, points["system.cpu.utilization{state=system}"].Value)
+	assert.Equal(t, 0.5, points["system.cpu.utilization{state=idle}"].Value)
+	assert.Equal(t, 50.0, points["system.cpu.context_switches{}"].Value)
+	assert.Equal(t, 1.0, points["system.processes.created{}"].Value)
+	assert.Equal(t, 1000.0, points["system.network.io{device=eth0,direction=receive}"].Value)
+	assert.Equal(t, 50.0, points["system.network.io{device=eth0,direction=transmit}"].Value)
+	assert.Equal(t, 0.5, points["system.network.packets{device=eth0,direction=transmit}"].Value)
+
+	// A counter reset skips the interface for one collection
+	now = now.Add(10 * time.Second)
+	writeProcFixture(t, root, map[string]string{"net/dev": "eth0: 10 1 0 0 0 0 0 0 20 2 0 0 0 0 0 0\n"})
+	points = collectHostMetrics(input)
+	assert.NotContains(t, points, "system.network.io{device=eth0,direction=receive}")
+}
+
+func TestHostMetricsInputCollectGroups(t *testing.T) {
+	root := t.TempDir()
+	writeProcFixture(t, root, map[string]string{"loadavg": "1.00 0.50 0.25 1/100 1000\n"})
+
+	input := NewHostMetricsInput("host_input")
+	assert.True(t, input.Configure(map[string]interface{}{
+		"proc_path": root,
+		"collect":   []interface{}{"load", "memory"},
+	}))
+	assert.True(t, input.Initialize())
+
+	// The missing meminfo does not stop the load averages from being reported
+	points := collectHostMetrics(input)
+	assert.Len(t, points, 3)
+	assert.Contains(t, points, "system.cpu.load_average.15m{}")
+}
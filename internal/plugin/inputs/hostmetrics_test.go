@@ -0,0 +1,98 @@
+package inputs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeProcFixture writes files under a temporary /proc root
+func writeProcFixture(t *testing.T, root string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+func TestReadProcFiles(t *testing.T) {
+	root := t.TempDir()
+	writeProcFixture(t, root, map[string]string{
+		"stat":    "cpu  100 5 50 800 20 3 2 0 0 0\ncpu0 50 2 25 400 10 1 1 0 0 0\nintr 1234 0 0\nctxt 5000\nbtime 1700000000\nprocesses 300\nprocs_running 3\nprocs_blocked 1\n",
+		"meminfo": "MemTotal:       16384 kB\nMemFree:         4096 kB\nMemAvailable:    8192 kB\nBuffers:         1024 kB\nCached:          2048 kB\nHugePages_Total:    0\n",
+		"loadavg": "0.52 0.58 0.59 2/1234 56789\n",
+		"diskstats": "   7       0 loop0 10 0 20 0 0 0 0 0 0 0 0 0 0 0 0 0 0\n" +
+			"   8       0 sda 100 10 2000 50 200 20 4000 80 0 1500 130 0 0 0 0 0 0\n",
+		"net/dev": "Inter-|   Receive                                                |  Transmit\n" +
+			" face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed\n" +
+			"    lo:    1000      10    0    0    0     0          0         0     1000      10    0    0    0     0       0          0\n" +
+			"  eth0:50000 400 1 2 0 0 0 0 20000 300 3 4 0 0 0 0\n",
+		"mounts": "sysfs /sys sysfs rw 0 0\n/dev/sda1 / ext4 rw 0 0\n/dev/sda2 /mnt/my\\040data ext4 rw 0 0\n/dev/sda1 / ext4 rw 0 0\n",
+	})
+
+	stat, err := readProcStat(root)
+	assert.NoError(t, err)
+	assert.Equal(t, 100.0, stat.cpu["user"])
+	assert.Equal(t, 800.0, stat.cpu["idle"])
+	assert.Equal(t, 0.0, stat.cpu["steal"])
+	assert.Equal(t, 5000.0, stat.contextSwitches)
+	assert.Equal(t, 300.0, stat.forks)
+	assert.Equal(t, 3.0, stat.running)
+	assert.Equal(t, 1.0, stat.blocked)
+
+	meminfo, err := readMeminfo(root)
+	assert.NoError(t, err)
+	assert.Equal(t, 16384.0*1024, meminfo["MemTotal"])
+	assert.Equal(t, 0.0, meminfo["HugePages_Total"])
+
+	loads, err := readLoadavg(root)
+	assert.NoError(t, err)
+	assert.Equal(t, [3]float64{0.52, 0.58, 0.59}, loads)
+
+	disks, err := readDiskstats(root)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]diskStats{
+		"sda": {reads: 100, readBytes: 2000 * 512, writes: 200, writtenBytes: 4000 * 512, ioTime: 1500},
+	}, disks)
+
+	interfaces, err := readNetDev(root)
+	assert.NoError(t, err)
+	assert.Len(t, interfaces, 2)
+	assert.Equal(t, netStats{
+		receivedBytes: 50000, receivedPackets: 400, receiveErrors: 1, receiveDropped: 2,
+		transmittedBytes: 20000, transmittedPackets: 300, transmitErrors: 3, transmitDropped: 4,
+	}, interfaces["eth0"])
+
+	mounts, err := readMounts(root)
+	assert.NoError(t, err)
+	assert.Equal(t, []mount{
+		{device: "/dev/sda1", mountpoint: "/", fsType: "ext4"},
+		{device: "/dev/sda2", mountpoint: "/mnt/my data", fsType: "ext4"},
+	}, mounts)
+}
+
+func TestReadProcFilesErrors(t *testing.T) {
+	root := t.TempDir()
+
+	_, err := readProcStat(root)
+	assert.Error(t, err)
+
+	writeProcFixture(t, root, map[string]string{
+		"stat":    "intr 1234\n",
+		"meminfo": "MemFree: 4096 kB\n",
+		"loadavg": "0.52\n",
+	})
+
+	_, err = readProcStat(root)
+	assert.Error(t, err)
+	_, err = readMeminfo(root)
+	assert.Error(t, err)
+	_, err = readLoadavg(root)
+	assert.Error(t, err)
+
+	writeProcFixture(t, root, map[string]string{"stat": "cpu 100 five 50 800\n"})
+	_, err = readProcStat(root)
+	assert.Error(t, err)
+}
@@ -0,0 +1,569 @@
+package inputs
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sliink/collector/internal/model"
+	"github.com/sliink/collector/internal/plugin"
+)
+
+// HTTPInput accepts JSON arrays or NDJSON bodies POSTed by services and maps
+// each record onto a log, metric or trace point
+type HTTPInput struct {
+	plugin.BasePlugin
+	httpInputSettings
+	server   *http.Server
+	listener net.Listener
+	queue    *pushQueue
+	mutex    sync.Mutex
+}
+
+// httpInputSettings holds the HTTP input configuration
+type httpInputSettings struct {
+	recordMapping
+	address     string
+	path        string
+	maxBodySize int64
+	maxPending  int
+	tlsConfig   *tls.Config
+}
+
+// recordMapping maps JSON records onto points of one telemetry type
+type recordMapping struct {
+	dataType model.TelemetryType
+	fields   map[string]string // Point fields to dotted record paths
+}
+
+// httpDataTypes maps the data_type option to telemetry types
+var httpDataTypes = map[string]model.TelemetryType{
+	"logs":    model.LogTelemetryType,
+	"metrics": model.MetricTelemetryType,
+	"traces":  model.TraceTelemetryType,
+}
+
+// httpDefaultFields are the record fields read for each point field unless
+// the fields option maps them elsewhere
+var httpDefaultFields = map[model.TelemetryType]map[string]string{
+	model.LogTelemetryType: {
+		"timestamp": "timestamp", "origin": "origin", "labels": "labels",
+		"message": "message", "level": "level",
+	},
+	model.MetricTelemetryType: {
+		"timestamp": "timestamp", "origin": "origin", "labels": "labels",
+		"name": "name", "value": "value", "metric_type": "metric_type", "dimensions": "dimensions",
+	},
+	model.TraceTelemetryType: {
+		"timestamp": "timestamp", "origin": "origin", "labels": "labels",
+		"name": "name", "trace_id": "trace_id", "span_id": "span_id", "parent_span_id": "parent_span_id",
+		"start_time": "start_time", "end_time": "end_time",
+	},
+}
+
+func init() {
+	plugin.RegisterStandardInput("http", func(id string) model.InputPlugin {
+		return NewHTTPInput(id)
+	})
+}
+
+// NewHTTPInput creates a new HTTP input plugin
+func NewHTTPInput(id string) *HTTPInput {
+	return &HTTPInput{
+		BasePlugin: plugin.NewBasePlugin(id, "HTTP Input", model.InputPluginType),
+	}
+}
+
+// parseHTTPInputSettings reads the HTTP input configuration
+func parseHTTPInputSettings(config map[string]interface{}) (httpInputSettings, error) {
+	settings := httpInputSettings{
+		address:     "localhost:8088",
+		path:        "/ingest",
+		maxBodySize: 10 * 1024 * 1024,
+		maxPending:  100000,
+	}
+
+	if address, ok := config["address"].(string); ok && address != "" {
+		settings.address = address
+	}
+
+	if path, ok := config["path"].(string); ok && path != "" {
+		if !strings.HasPrefix(path, "/") {
+			return settings, fmt.Errorf("path must start with /: %s", path)
+		}
+		settings.path = path
+	}
+
+	mapping, err := parseRecordMapping(config)
+	if err != nil {
+		return settings, err
+	}
+	settings.recordMapping = mapping
+
+	if size, ok := plugin.IntOption(config["max_body_size"]); ok {
+		if size <= 0 {
+			return settings, fmt.Errorf("max_body_size must be positive")
+		}
+		settings.maxBodySize = int64(size)
+	}
+
+	if size, ok := plugin.IntOption(config["max_pending"]); ok {
+		if size <= 0 {
+			return settings, fmt.Errorf("max_pending must be positive")
+		}
+		settings.maxPending = int(size)
+	}
+
+	if tlsConf, ok := config["tls"].(map[string]interface{}); ok {
+		tlsConfig, err := plugin.ServerTLSConfig(tlsConf)
+		if err != nil {
+			return settings, err
+		}
+		settings.tlsConfig = tlsConfig
+	}
+
+	return settings, nil
+}
+
+// parseRecordMapping reads the data_type and fields options
+func parseRecordMapping(config map[string]interface{}) (recordMapping, error) {
+	mapping := recordMapping{dataType: model.LogTelemetryType}
+
+	if name, ok := config["data_type"].(string); ok {
+		dataType, exists := httpDataTypes[name]
+		if !exists {
+			return mapping, fmt.Errorf("invalid data_type: %s", name)
+		}
+		mapping.dataType = dataType
+	}
+
+	mapping.fields = make(map[string]string)
+	for field, path := range httpDefaultFields[mapping.dataType] {
+		mapping.fields[field] = path
+	}
+	if fields, ok := config["fields"].(map[string]interface{}); ok {
+		for field, value := range fields {
+			if _, known := mapping.fields[field]; !known {
+				return mapping, fmt.Errorf("unknown field for %s: %s", mapping.dataType, field)
+			}
+			path, ok := value.(string)
+			if !ok || path == "" {
+				return mapping, fmt.Errorf("field %s must map to a record path", field)
+			}
+			mapping.fields[field] = path
+		}
+	}
+
+	return mapping, nil
+}
+
+// Initialize prepares the HTTP input for operation
+func (h *HTTPInput) Initialize() bool {
+	settings, err := parseHTTPInputSettings(h.Config)
+	if err != nil {
+		return false
+	}
+	h.httpInputSettings = settings
+	h.queue = newPushQueue(settings.maxPending)
+
+	h.SetStatus(model.StatusInitialized)
+	return true
+}
+
+// Validate checks if the HTTP input is properly configured
+func (h *HTTPInput) Validate() bool {
+	_, err := parseHTTPInputSettings(h.Config)
+	return err == nil
+}
+
+// Start begins listening for requests
+func (h *HTTPInput) Start() bool {
+	listener, err := net.Listen("tcp", h.address)
+	if err != nil {
+		return false
+	}
+
+	if h.tlsConfig != nil {
+		listener = tls.NewListener(listener, h.tlsConfig)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(h.path, h)
+
+	h.mutex.Lock()
+	h.listener = listener
+	h.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	server := h.server
+	h.mutex.Unlock()
+
+	go server.Serve(listener)
+
+	h.SetStatus(model.StatusRunning)
+	return true
+}
+
+// Stop shuts down the server, waiting briefly for requests in progress
+func (h *HTTPInput) Stop() bool {
+	h.mutex.Lock()
+	server := h.server
+	h.server = nil
+	h.mutex.Unlock()
+
+	if server != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+	}
+
+	h.SetStatus(model.StatusStopped)
+	return true
+}
+
+// Collect returns the points accepted since the last call
+func (h *HTTPInput) Collect() []*model.DataBatch {
+	if h.GetStatus() != model.StatusRunning {
+		return nil
+	}
+
+	return h.queue.Drain(h.ID())
+}
+
+// ServeHTTP accepts a JSON array or NDJSON body of records
+func (h *HTTPInput) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeHTTPError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	// Turn senders away before reading the body when outputs cannot keep up
+	if backpressured(h.Core(), h.ID()) {
+		w.Header().Set("Retry-After", "1")
+		writeHTTPError(w, http.StatusTooManyRequests, "output buffers are full")
+		return
+	}
+
+	body, status, err := openBody(w, r, h.maxBodySize)
+	if err != nil {
+		writeHTTPError(w, status, err.Error())
+		return
+	}
+	defer body.Close()
+
+	records, err := decodeRecords(body)
+	if err != nil {
+		if isBodyTooLarge(err) {
+			writeHTTPError(w, http.StatusRequestEntityTooLarge, "body exceeds max_body_size")
+			return
+		}
+		writeHTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	origin := clientHost(r)
+
+	now := time.Now()
+	points := make([]model.DataPoint, 0, len(records))
+	for i, record := range records {
+		point, err := h.newPoint(record, model.BaseDataPoint{
+			Timestamp: now,
+			Origin:    origin,
+			Labels:    map[string]string{"source": "http"},
+		})
+		if err != nil {
+			writeHTTPError(w, http.StatusBadRequest, fmt.Sprintf("record %d: %v", i, err))
+			return
+		}
+		points = append(points, point)
+	}
+
+	// Accept all of the records or none of them, so retries do not duplicate
+	if !h.queue.Add(h.dataType, points) {
+		w.Header().Set("Retry-After", "1")
+		writeHTTPError(w, http.StatusTooManyRequests, "too many pending records")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]int{"accepted": len(points)})
+}
+
+// decodeRecords reads a JSON array of objects, or a stream of objects such as NDJSON
+func decodeRecords(body io.Reader) ([]map[string]interface{}, error) {
+	var records []map[string]interface{}
+	err := streamRecords(body, func(record map[string]interface{}) error {
+		records = append(records, record)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// streamRecords calls fn with each object of a JSON array or of a stream of
+// objects as they are read, stopping at the first error
+func streamRecords(body io.Reader, fn func(map[string]interface{}) error) error {
+	reader := bufio.NewReader(body)
+
+	// Peek past whitespace to tell an array from a stream of objects
+	var first byte
+	for {
+		c, err := reader.ReadByte()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+			first = c
+			reader.UnreadByte()
+			break
+		}
+	}
+
+	decoder := json.NewDecoder(reader)
+
+	if first == '[' {
+		if _, err := decoder.Token(); err != nil {
+			return fmt.Errorf("invalid JSON array: %w", err)
+		}
+		for n := 0; decoder.More(); n++ {
+			var record map[string]interface{}
+			if err := decoder.Decode(&record); err != nil {
+				return fmt.Errorf("invalid JSON record %d: %w", n, err)
+			}
+			if err := fn(record); err != nil {
+				return err
+			}
+		}
+		if _, err := decoder.Token(); err != nil {
+			return fmt.Errorf("invalid JSON array: %w", err)
+		}
+		return nil
+	}
+
+	for n := 0; ; n++ {
+		var record map[string]interface{}
+		err := decoder.Decode(&record)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("invalid JSON record %d: %w", n, err)
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+}
+
+// newPoint maps a record onto a point of the mapping's data type, starting
+// from base. Unmapped fields of log and trace records become attributes.
+func (m recordMapping) newPoint(record map[string]interface{}, base model.BaseDataPoint) (model.DataPoint, error) {
+	if value, ok := lookupField(record, m.fields["timestamp"]); ok {
+		timestamp, err := parseTimestamp(value)
+		if err != nil {
+			return nil, fmt.Errorf("timestamp: %w", err)
+		}
+		base.Timestamp = timestamp
+	}
+	if value, ok := lookupField(record, m.fields["origin"]); ok {
+		base.Origin = fmt.Sprint(value)
+	}
+	if value, ok := lookupField(record, m.fields["labels"]); ok {
+		labels, err := stringMap(value)
+		if err != nil {
+			return nil, fmt.Errorf("labels: %w", err)
+		}
+		for k, v := range labels {
+			base.Labels[k] = v
+		}
+	}
+
+	switch m.dataType {
+	case model.MetricTelemetryType:
+		return m.newMetricPoint(record, base)
+	case model.TraceTelemetryType:
+		return m.newTracePoint(record, base)
+	default:
+		return m.newLogPoint(record, base), nil
+	}
+}
+
+// newLogPoint maps a record onto a log point
+func (m recordMapping) newLogPoint(record map[string]interface{}, base model.BaseDataPoint) *model.LogPoint {
+	point := &model.LogPoint{
+		BaseDataPoint: base,
+		Level:         "INFO",
+		Attributes:    m.unmappedFields(record),
+	}
+
+	if value, ok := lookupField(record, m.fields["message"]); ok {
+		point.Message = fmt.Sprint(value)
+	}
+	if value, ok := lookupField(record, m.fields["level"]); ok {
+		point.Level = strings.ToUpper(fmt.Sprint(value))
+	}
+
+	return point
+}
+
+// unmappedFields returns the top-level fields of a record that no point field reads
+func (m recordMapping) unmappedFields(record map[string]interface{}) map[string]interface{} {
+	mapped := make(map[string]bool)
+	for _, path := range m.fields {
+		mapped[strings.SplitN(path, ".", 2)[0]] = true
+	}
+
+	fields := make(map[string]interface{})
+	for k, v := range record {
+		if !mapped[k] {
+			fields[k] = v
+		}
+	}
+	return fields
+}
+
+// newMetricPoint maps a record onto a metric point
+func (m recordMapping) newMetricPoint(record map[string]interface{}, base model.BaseDataPoint) (*model.MetricPoint, error) {
+	point := &model.MetricPoint{
+		BaseDataPoint: base,
+		MetricType:    "gauge",
+		Dimensions:    make(map[string]string),
+	}
+
+	name, ok := lookupField(record, m.fields["name"])
+	if !ok {
+		return nil, fmt.Errorf("missing metric name")
+	}
+	point.Name = fmt.Sprint(name)
+
+	value, ok := lookupField(record, m.fields["value"])
+	if !ok {
+		return nil, fmt.Errorf("missing metric value")
+	}
+	if point.Value, ok = value.(float64); !ok {
+		return nil, fmt.Errorf("metric value must be a number")
+	}
+
+	if metricType, ok := lookupField(record, m.fields["metric_type"]); ok {
+		point.MetricType = fmt.Sprint(metricType)
+	}
+	if value, ok := lookupField(record, m.fields["dimensions"]); ok {
+		dimensions, err := stringMap(value)
+		if err != nil {
+			return nil, fmt.Errorf("dimensions: %w", err)
+		}
+		point.Dimensions = dimensions
+	}
+
+	return point, nil
+}
+
+// newTracePoint maps a record onto a trace point
+func (m recordMapping) newTracePoint(record map[string]interface{}, base model.BaseDataPoint) (*model.TracePoint, error) {
+	point := &model.TracePoint{
+		BaseDataPoint: base,
+		Attributes:    m.unmappedFields(record),
+	}
+
+	for field, target := range map[string]*string{
+		"name":           &point.Name,
+		"trace_id":       &point.TraceID,
+		"span_id":        &point.SpanID,
+		"parent_span_id": &point.ParentSpanID,
+	} {
+		if value, ok := lookupField(record, m.fields[field]); ok {
+			*target = fmt.Sprint(value)
+		}
+	}
+	if point.TraceID == "" || point.SpanID == "" {
+		return nil, fmt.Errorf("missing trace_id or span_id")
+	}
+
+	for field, target := range map[string]*time.Time{
+		"start_time": &point.StartTime,
+		"end_time":   &point.EndTime,
+	} {
+		if value, ok := lookupField(record, m.fields[field]); ok {
+			timestamp, err := parseTimestamp(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", field, err)
+			}
+			*target = timestamp
+		}
+	}
+
+	return point, nil
+}
+
+// lookupField follows a dotted path through nested objects
+func lookupField(record map[string]interface{}, path string) (interface{}, bool) {
+	var value interface{} = record
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = object[key]; !ok {
+			return nil, false
+		}
+	}
+	return value, value != nil
+}
+
+// stringMap converts an object to a map of strings
+func stringMap(value interface{}) (map[string]string, error) {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected an object")
+	}
+
+	result := make(map[string]string, len(object))
+	for k, v := range object {
+		result[k] = fmt.Sprint(v)
+	}
+	return result, nil
+}
+
+// parseTimestamp reads an RFC 3339 string, or a Unix time in seconds,
+// milliseconds, microseconds or nanoseconds judged by its magnitude
+func parseTimestamp(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case string:
+		timestamp, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid time: %q", v)
+		}
+		return timestamp, nil
+	case float64:
+		abs := math.Abs(v)
+		switch {
+		case abs < 1e11:
+			return time.Unix(0, int64(v*1e9)), nil
+		case abs < 1e14:
+			return time.Unix(0, int64(v*1e6)), nil
+		case abs < 1e17:
+			return time.Unix(0, int64(v*1e3)), nil
+		default:
+			return time.Unix(0, int64(v)), nil
+		}
+	default:
+		return time.Time{}, fmt.Errorf("expected a string or number")
+	}
+}
@@ -0,0 +1,196 @@
+package inputs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sliink/collector/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func postRecords(input *HTTPInput, body string, headers map[string]string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, "/ingest", strings.NewReader(body))
+	for k, v := range headers {
+		request.Header.Set(k, v)
+	}
+	recorder := httptest.NewRecorder()
+	input.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestHTTPInputValidate(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{"path": "ingest"},
+		{"data_type": "events"},
+		{"fields": map[string]interface{}{"name": "metric"}},
+		{"fields": map[string]interface{}{"message": float64(1)}},
+		{"max_body_size": float64(0)},
+		{"tls": map[string]interface{}{"cert_file": "/missing.pem"}},
+	}
+
+	for _, config := range invalid {
+		input := NewHTTPInput("http_input")
+		input.Configure(config)
+		assert.False(t, input.Validate(), "%v", config)
+		assert.False(t, input.Initialize(), "%v", config)
+	}
+}
+
+func TestHTTPInputLogs(t *testing.T) {
+	input := startTestInput(t, NewHTTPInput("http_input"), map[string]interface{}{
+		"address": "127.0.0.1:0",
+		"fields":  map[string]interface{}{"message": "msg", "level": "severity", "origin": "host.name"},
+	})
+
+	t.Run("NDJSON records become log points", func(t *testing.T) {
+		body := `{"msg": "started", "severity": "warn", "host": {"name": "web-1"}, "ts": 1, "labels": {"service": "api"}, "timestamp": "2024-05-01T10:00:00Z"}
+{"msg": "second", "user": "alice"}
+`
+		response := postRecords(input, body, nil)
+		assert.Equal(t, http.StatusAccepted, response.Code)
+		assert.JSONEq(t, `{"accepted": 2}`, response.Body.String())
+
+		batches := input.Collect()
+		assert.Len(t, batches, 1)
+		assert.Equal(t, model.LogTelemetryType, batches[0].BatchType)
+		assert.Equal(t, "http_input", batches[0].SourceID)
+		assert.Len(t, batches[0].Points, 2)
+
+		first := batches[0].Points[0].(*model.LogPoint)
+		assert.Equal(t, "started", first.Message)
+		assert.Equal(t, "WARN", first.Level)
+		assert.Equal(t, "web-1", first.Origin)
+		assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), first.Timestamp)
+		assert.Equal(t, map[string]string{"source": "http", "service": "api"}, first.Labels)
+		assert.Equal(t, map[string]interface{}{"ts": float64(1)}, first.Attributes)
+
+		second := batches[0].Points[1].(*model.LogPoint)
+		assert.Equal(t, "INFO", second.Level)
+		assert.Equal(t, "192.0.2.1", second.Origin)
+		assert.Equal(t, "alice", second.Attributes["user"])
+
+		assert.Empty(t, input.Collect())
+	})
+
+	t.Run("Gzipped JSON arrays are accepted", func(t *testing.T) {
+		var body bytes.Buffer
+		writer := gzip.NewWriter(&body)
+		writer.Write([]byte(` [{"msg": "one"}, {"msg": "two"}]`))
+		writer.Close()
+
+		response := postRecords(input, body.String(), map[string]string{"Content-Encoding": "gzip"})
+		assert.Equal(t, http.StatusAccepted, response.Code)
+
+		batches := input.Collect()
+		assert.Len(t, batches, 1)
+		assert.Len(t, batches[0].Points, 2)
+	})
+
+	t.Run("Bad requests are rejected whole", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, postRecords(input, `{"msg": "ok"}`+"\n"+`not json`, nil).Code)
+		assert.Equal(t, http.StatusBadRequest, postRecords(input, `[1, 2]`, nil).Code)
+		assert.Equal(t, http.StatusBadRequest, postRecords(input, `{"msg": "ok"}`+"\n"+`{"timestamp": true}`, nil).Code)
+		assert.Equal(t, http.StatusBadRequest, postRecords(input, "not gzip", map[string]string{"Content-Encoding": "gzip"}).Code)
+		assert.Equal(t, http.StatusUnsupportedMediaType, postRecords(input, "{}", map[string]string{"Content-Encoding": "br"}).Code)
+		assert.Empty(t, input.Collect())
+
+		recorder := httptest.NewRecorder()
+		input.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ingest", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+	})
+}
+
+func TestHTTPInputMetricsAndTraces(t *testing.T) {
+	t.Run("Metrics", func(t *testing.T) {
+		input := startTestInput(t, NewHTTPInput("http_input"), map[string]interface{}{
+			"address":   "127.0.0.1:0",
+			"data_type": "metrics",
+			"fields":    map[string]interface{}{"name": "metric", "dimensions": "tags"},
+		})
+
+		response := postRecords(input, `{"metric": "cpu", "value": 0.5, "tags": {"core": 1}, "timestamp": 1714557600000}`, nil)
+		assert.Equal(t, http.StatusAccepted, response.Code)
+
+		batches := input.Collect()
+		assert.Len(t, batches, 1)
+		assert.Equal(t, model.MetricTelemetryType, batches[0].BatchType)
+		point := batches[0].Points[0].(*model.MetricPoint)
+		assert.Equal(t, "cpu", point.Name)
+		assert.Equal(t, 0.5, point.Value)
+		assert.Equal(t, "gauge", point.MetricType)
+		assert.Equal(t, map[string]string{"core": "1"}, point.Dimensions)
+		assert.True(t, point.Timestamp.Equal(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)))
+
+		assert.Equal(t, http.StatusBadRequest, postRecords(input, `{"metric": "cpu", "value": "high"}`, nil).Code)
+		assert.Equal(t, http.StatusBadRequest, postRecords(input, `{"value": 1}`, nil).Code)
+	})
+
+	t.Run("Traces", func(t *testing.T) {
+		input := startTestInput(t, NewHTTPInput("http_input"), map[string]interface{}{"address": "127.0.0.1:0", "data_type": "traces"})
+
+		response := postRecords(input, `{"trace_id": "abc", "span_id": "def", "name": "GET /", "start_time": "2024-05-01T10:00:00Z", "end_time": 1714557601, "http.status": 200}`, nil)
+		assert.Equal(t, http.StatusAccepted, response.Code)
+
+		point := input.Collect()[0].Points[0].(*model.TracePoint)
+		assert.Equal(t, "abc", point.TraceID)
+		assert.Equal(t, "def", point.SpanID)
+		assert.Equal(t, "GET /", point.Name)
+		assert.Equal(t, map[string]interface{}{"http.status": float64(200)}, point.Attributes)
+		assert.Equal(t, time.Second, point.EndTime.Sub(point.StartTime))
+
+		assert.Equal(t, http.StatusBadRequest, postRecords(input, `{"trace_id": "abc"}`, nil).Code)
+	})
+}
+
+func TestHTTPInputBackpressure(t *testing.T) {
+	t.Run("Full output buffers return 429", func(t *testing.T) {
+		input := startTestInput(t, NewHTTPInput("http_input"), map[string]interface{}{"address": "127.0.0.1:0"})
+		core := &testCore{full: true}
+		input.RegisterWithCore(core)
+
+		response := postRecords(input, `{"message": "hi"}`, nil)
+		assert.Equal(t, http.StatusTooManyRequests, response.Code)
+		assert.Equal(t, "1", response.Header().Get("Retry-After"))
+
+		core.full = false
+		assert.Equal(t, http.StatusAccepted, postRecords(input, `{"message": "hi"}`, nil).Code)
+	})
+
+	t.Run("Pending and body limits", func(t *testing.T) {
+		input := startTestInput(t, NewHTTPInput("http_input"), map[string]interface{}{"address": "127.0.0.1:0", "max_pending": float64(2), "max_body_size": float64(64)})
+
+		assert.Equal(t, http.StatusAccepted, postRecords(input, `{"message": "one"}`, nil).Code)
+		assert.Equal(t, http.StatusTooManyRequests, postRecords(input, `[{"message": "two"}, {"message": "three"}]`, nil).Code)
+		assert.Len(t, input.Collect()[0].Points, 1)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, postRecords(input, `{"message": "`+strings.Repeat("x", 100)+`"}`, nil).Code)
+
+		var body bytes.Buffer
+		writer := gzip.NewWriter(&body)
+		writer.Write([]byte(`{"message": "` + strings.Repeat("x", 1000) + `"}`))
+		writer.Close()
+		assert.Equal(t, http.StatusRequestEntityTooLarge, postRecords(input, body.String(), map[string]string{"Content-Encoding": "gzip"}).Code)
+	})
+
+	t.Run("Requests are served on the configured path", func(t *testing.T) {
+		input := startTestInput(t, NewHTTPInput("http_input"), map[string]interface{}{"address": "127.0.0.1:0", "path": "/logs"})
+		url := "http://" + input.listener.Addr().String()
+
+		response, err := http.Post(url+"/logs", "application/x-ndjson", strings.NewReader(`{"message": "over the wire"}`))
+		assert.NoError(t, err)
+		response.Body.Close()
+		assert.Equal(t, http.StatusAccepted, response.StatusCode)
+
+		response, err = http.Post(url+"/other", "application/x-ndjson", strings.NewReader(`{}`))
+		assert.NoError(t, err)
+		response.Body.Close()
+		assert.Equal(t, http.StatusNotFound, response.StatusCode)
+
+		assert.Equal(t, "over the wire", input.Collect()[0].Points[0].(*model.LogPoint).Message)
+	})
+}
@@ -0,0 +1,162 @@
+package inputs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// influxLine is one line of the InfluxDB line protocol
+type influxLine struct {
+	measurement string
+	tags        map[string]string
+	fields      map[string]interface{} // float64, int64, uint64, string or bool
+	timestamp   time.Time              // Zero unless the line carries its own timestamp
+}
+
+// parseInfluxLine parses a line of the form
+// measurement[,tag=value...] field=value[,field=value...] [timestamp], with
+// a timestamp in nanoseconds. Integers end in i, unsigned integers in u and
+// strings are double-quoted; other values are floats or booleans.
+func parseInfluxLine(line string) (influxLine, error) {
+	parsed := influxLine{
+		tags:   make(map[string]string),
+		fields: make(map[string]interface{}),
+	}
+
+	sections := splitInflux(line, ' ', true)
+	if len(sections) < 2 || len(sections) > 3 {
+		return parsed, fmt.Errorf("expected a measurement, fields and optional timestamp")
+	}
+
+	key := splitInflux(sections[0], ',', false)
+	parsed.measurement = unescapeInflux(key[0], ", ")
+	if parsed.measurement == "" {
+		return parsed, fmt.Errorf("missing measurement")
+	}
+	for _, tag := range key[1:] {
+		pair := splitInflux(tag, '=', false)
+		if len(pair) != 2 || pair[0] == "" || pair[1] == "" {
+			return parsed, fmt.Errorf("invalid tag: %s", tag)
+		}
+		parsed.tags[unescapeInflux(pair[0], ",= ")] = unescapeInflux(pair[1], ",= ")
+	}
+
+	for _, field := range splitInflux(sections[1], ',', true) {
+		pair := splitInflux(field, '=', true)
+		if len(pair) != 2 || pair[0] == "" {
+			return parsed, fmt.Errorf("invalid field: %s", field)
+		}
+		name := unescapeInflux(pair[0], ",= ")
+		value, err := parseInfluxValue(pair[1])
+		if err != nil {
+			return parsed, fmt.Errorf("field %s: %w", name, err)
+		}
+		parsed.fields[name] = value
+	}
+
+	if len(sections) == 3 {
+		ns, err := strconv.ParseInt(sections[2], 10, 64)
+		if err != nil {
+			return parsed, fmt.Errorf("invalid timestamp: %s", sections[2])
+		}
+		parsed.timestamp = time.Unix(0, ns)
+	}
+
+	return parsed, nil
+}
+
+// parseInfluxValue parses a field value
+func parseInfluxValue(s string) (interface{}, error) {
+	switch s {
+	case "t", "T", "true", "True", "TRUE":
+		return true, nil
+	case "f", "F", "false", "False", "FALSE":
+		return false, nil
+	}
+
+	switch {
+	case s == "":
+		return nil, fmt.Errorf("missing value")
+	case s[0] == '"':
+		if len(s) < 2 || s[len(s)-1] != '"' {
+			return nil, fmt.Errorf("unterminated string")
+		}
+		return unescapeInflux(s[1:len(s)-1], `"\`), nil
+	case strings.HasSuffix(s, "i"):
+		value, err := strconv.ParseInt(s[:len(s)-1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer: %s", s)
+		}
+		return value, nil
+	case strings.HasSuffix(s, "u"):
+		value, err := strconv.ParseUint(s[:len(s)-1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid unsigned integer: %s", s)
+		}
+		return value, nil
+	default:
+		value, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value: %s", s)
+		}
+		return value, nil
+	}
+}
+
+// splitInflux splits s on sep where it is not escaped with a backslash and,
+// if quotes is set, not inside a double-quoted string. Escapes are kept.
+func splitInflux(s string, sep byte, quotes bool) []string {
+	var parts []string
+	start := 0
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\':
+			i++
+		case s[i] == '"' && quotes:
+			quoted = !quoted
+		case s[i] == sep && !quoted:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// unescapeInflux removes the backslashes before any of chars. Other
+// backslashes are kept, as the line protocol does.
+func unescapeInflux(s, chars string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && strings.IndexByte(chars, s[i+1]) >= 0 {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// influxMetricValue converts a field value to a metric value. Strings have none.
+func influxMetricValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,59 @@
+package inputs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseInfluxLine(t *testing.T) {
+	t.Run("Field types", func(t *testing.T) {
+		line, err := parseInfluxLine(`disk,host=web-1,path=/var used=12.5,inodes=3400i,total=100u,ok=t,status="mounted" 1714557600000000000`)
+		assert.NoError(t, err)
+		assert.Equal(t, influxLine{
+			measurement: "disk",
+			tags:        map[string]string{"host": "web-1", "path": "/var"},
+			fields: map[string]interface{}{
+				"used":   12.5,
+				"inodes": int64(3400),
+				"total":  uint64(100),
+				"ok":     true,
+				"status": "mounted",
+			},
+			timestamp: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC).Local(),
+		}, line)
+
+		line, err = parseInfluxLine("load value=0.5")
+		assert.NoError(t, err)
+		assert.Empty(t, line.tags)
+		assert.True(t, line.timestamp.IsZero())
+	})
+
+	t.Run("Escapes", func(t *testing.T) {
+		line, err := parseInfluxLine(`my\ metric,data\ center=eu\,west\=1 msg="say \"hi\", then go",a\=b=1`)
+		assert.NoError(t, err)
+		assert.Equal(t, "my metric", line.measurement)
+		assert.Equal(t, map[string]string{"data center": "eu,west=1"}, line.tags)
+		assert.Equal(t, map[string]interface{}{"msg": `say "hi", then go`, "a=b": 1.0}, line.fields)
+	})
+
+	t.Run("Malformed lines are rejected", func(t *testing.T) {
+		for _, line := range []string{
+			"measurement_only",
+			",host=a value=1",
+			"cpu,host value=1",
+			"cpu value",
+			"cpu value=",
+			"cpu value=abc",
+			"cpu value=1.5i",
+			"cpu value=-1u",
+			`cpu msg="unterminated`,
+			"cpu value=1 yesterday",
+			"cpu value=1 1 2",
+		} {
+			_, err := parseInfluxLine(line)
+			assert.Error(t, err, line)
+		}
+	})
+}
@@ -0,0 +1,96 @@
+package inputs
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// maxJournalFieldSize bounds the size of a binary journal field
+const maxJournalFieldSize = 64 * 1024 * 1024
+
+// journalReader reads entries in the journal export format: fields of the
+// form NAME=value on their own line, or for values that may contain
+// newlines, NAME on its own line followed by a 64-bit little-endian length,
+// the raw value and a newline. Entries are separated by a blank line.
+type journalReader struct {
+	reader *bufio.Reader
+}
+
+// newJournalReader creates a reader of export-format entries
+func newJournalReader(r io.Reader) *journalReader {
+	return &journalReader{reader: bufio.NewReader(r)}
+}
+
+// Next returns the next entry, or io.EOF once the stream ends between
+// entries. An entry cut short by the end of the stream is still returned.
+func (j *journalReader) Next() (map[string]string, error) {
+	entry := make(map[string]string)
+	for {
+		line, err := j.reader.ReadBytes('\n')
+		if err != nil {
+			if err == io.EOF && len(entry) > 0 && len(line) == 0 {
+				return entry, nil
+			}
+			if err == io.EOF && len(line) > 0 {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		line = line[:len(line)-1]
+
+		if len(line) == 0 {
+			// Blank lines between entries are skipped
+			if len(entry) == 0 {
+				continue
+			}
+			return entry, nil
+		}
+
+		if i := bytes.IndexByte(line, '='); i >= 0 {
+			entry[string(line[:i])] = string(line[i+1:])
+			continue
+		}
+
+		value, err := j.readBinaryField()
+		if err != nil {
+			return nil, fmt.Errorf("invalid field %s: %w", line, err)
+		}
+		entry[string(line)] = value
+	}
+}
+
+// readBinaryField reads the length-prefixed value of a binary field
+func (j *journalReader) readBinaryField() (string, error) {
+	var size uint64
+	if err := binary.Read(j.reader, binary.LittleEndian, &size); err != nil {
+		return "", err
+	}
+	if size > maxJournalFieldSize {
+		return "", fmt.Errorf("field of %d bytes is too large", size)
+	}
+
+	value := make([]byte, size+1)
+	if _, err := io.ReadFull(j.reader, value); err != nil {
+		return "", err
+	}
+	if value[size] != '\n' {
+		return "", fmt.Errorf("missing newline after field value")
+	}
+	return string(value[:size]), nil
+}
+
+// journalTimestamp returns the time an entry was logged, from the
+// microsecond timestamp the journal or the logging process recorded
+func journalTimestamp(entry map[string]string) (time.Time, bool) {
+	for _, field := range []string{"_SOURCE_REALTIME_TIMESTAMP", "__REALTIME_TIMESTAMP"} {
+		if micros, err := strconv.ParseInt(entry[field], 10, 64); err == nil {
+			return time.UnixMicro(micros).UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,485 @@
+package inputs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sliink/collector/internal/model"
+	"github.com/sliink/collector/internal/plugin"
+)
+
+// journalctlSource is the cursor key of entries read from journalctl
+const journalctlSource = "journalctl"
+
+// JournaldInput reads systemd journal entries in the export format, either
+// by following journalctl or by reading export files. The cursor of the last
+// entry collected from each source is saved so a restart continues after it.
+type JournaldInput struct {
+	plugin.BasePlugin
+	journaldInputSettings
+	queue       *pushQueue
+	cursors     map[string]string // Cursor of the last entry queued, by source
+	savedCursor []byte            // Last cursors written, to skip unchanged writes
+	command     func(ctx context.Context, args ...string) *exec.Cmd
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+	mutex       sync.Mutex
+}
+
+// journaldInputSettings holds the journald input configuration
+type journaldInputSettings struct {
+	files      []string
+	units      []string
+	directory  string
+	startAt    string
+	cursorFile string
+	maxPending int
+}
+
+func init() {
+	plugin.RegisterStandardInput("journald", func(id string) model.InputPlugin {
+		return NewJournaldInput(id)
+	})
+}
+
+// NewJournaldInput creates a new journald input plugin
+func NewJournaldInput(id string) *JournaldInput {
+	return &JournaldInput{
+		BasePlugin: plugin.NewBasePlugin(id, "Journald Input", model.InputPluginType),
+		cursors:    make(map[string]string),
+		command: func(ctx context.Context, args ...string) *exec.Cmd {
+			return exec.CommandContext(ctx, "journalctl", args...)
+		},
+		minBackoff: time.Second,
+		maxBackoff: 30 * time.Second,
+	}
+}
+
+// parseJournaldInputSettings reads the journald input configuration
+func parseJournaldInputSettings(config map[string]interface{}) (journaldInputSettings, error) {
+	settings := journaldInputSettings{
+		startAt:    "end",
+		maxPending: 100000,
+	}
+
+	for name, target := range map[string]*[]string{"files": &settings.files, "units": &settings.units} {
+		items, ok := config[name].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, item := range items {
+			value, ok := item.(string)
+			if !ok || value == "" {
+				return settings, fmt.Errorf("%s must be a list of names", name)
+			}
+			*target = append(*target, value)
+		}
+	}
+
+	if directory, ok := config["directory"].(string); ok {
+		settings.directory = directory
+	}
+
+	if startAt, ok := config["start_at"].(string); ok {
+		if startAt != "beginning" && startAt != "end" {
+			return settings, fmt.Errorf("start_at must be beginning or end")
+		}
+		settings.startAt = startAt
+	}
+
+	if cursorFile, ok := config["cursor_file"].(string); ok {
+		settings.cursorFile = cursorFile
+	}
+
+	if size, ok := plugin.IntOption(config["max_pending"]); ok {
+		if size <= 0 {
+			return settings, fmt.Errorf("max_pending must be positive")
+		}
+		settings.maxPending = int(size)
+	}
+
+	return settings, nil
+}
+
+// Initialize prepares the journald input for operation, loading the saved cursors
+func (j *JournaldInput) Initialize() bool {
+	settings, err := parseJournaldInputSettings(j.Config)
+	if err != nil {
+		return false
+	}
+	j.journaldInputSettings = settings
+	j.queue = newPushQueue(settings.maxPending)
+
+	if err := j.loadCursors(); err != nil {
+		return false
+	}
+
+	j.SetStatus(model.StatusInitialized)
+	return true
+}
+
+// Validate checks if the journald input is properly configured
+func (j *JournaldInput) Validate() bool {
+	_, err := parseJournaldInputSettings(j.Config)
+	return err == nil
+}
+
+// Start begins reading journal entries
+func (j *JournaldInput) Start() bool {
+	j.mutex.Lock()
+	if j.cancel == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		j.cancel = cancel
+
+		j.wg.Add(1)
+		if len(j.files) > 0 {
+			go j.readFiles(ctx)
+		} else {
+			go j.followJournal(ctx)
+		}
+	}
+	j.mutex.Unlock()
+
+	j.SetStatus(model.StatusRunning)
+	return true
+}
+
+// Stop stops reading journal entries. Entries already read stay queued
+// until they are collected.
+func (j *JournaldInput) Stop() bool {
+	j.mutex.Lock()
+	cancel := j.cancel
+	j.cancel = nil
+	j.mutex.Unlock()
+
+	if cancel != nil {
+		cancel()
+		j.wg.Wait()
+	}
+
+	j.SetStatus(model.StatusStopped)
+	return true
+}
+
+// Collect returns the entries read since the last call and saves the cursor
+// of the last one, so entries are neither replayed nor skipped on restart
+func (j *JournaldInput) Collect() []*model.DataBatch {
+	if j.queue == nil {
+		return nil
+	}
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	batches := j.queue.Drain(j.ID())
+	if len(batches) > 0 {
+		if err := j.saveCursors(); err != nil {
+			if core := j.Core(); core != nil {
+				core.PublishEvent(model.EventError, j.ID(), err)
+			}
+		}
+	}
+	return batches
+}
+
+// followJournal runs journalctl until the context is cancelled, restarting
+// it with exponential backoff whenever it exits
+func (j *JournaldInput) followJournal(ctx context.Context) {
+	defer j.wg.Done()
+
+	run := func(ctx context.Context) error {
+		if err := j.runJournalctl(ctx); err != nil {
+			return err
+		}
+		return fmt.Errorf("journalctl exited")
+	}
+	superviseWithBackoff(ctx, j.minBackoff, j.maxBackoff, run, func(err error) {
+		if core := j.Core(); core != nil {
+			core.PublishEvent(model.EventError, j.ID(), err)
+		}
+	})
+}
+
+// journalctlArgs returns the arguments to follow the journal after a cursor,
+// or from start_at when there is none
+func (j *JournaldInput) journalctlArgs(cursor string) []string {
+	args := []string{"--output=export", "--follow", "--no-pager"}
+	if j.directory != "" {
+		args = append(args, "--directory="+j.directory)
+	}
+	for _, unit := range j.units {
+		args = append(args, "--unit="+unit)
+	}
+
+	switch {
+	case cursor != "":
+		args = append(args, "--after-cursor="+cursor)
+	case j.startAt == "beginning":
+		args = append(args, "--lines=all")
+	default:
+		args = append(args, "--lines=0")
+	}
+	return args
+}
+
+// runJournalctl follows the journal with journalctl until it exits
+func (j *JournaldInput) runJournalctl(ctx context.Context) error {
+	j.mutex.Lock()
+	cursor := j.cursors[journalctlSource]
+	j.mutex.Unlock()
+
+	cmd := j.command(ctx, j.journalctlArgs(cursor)...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start journalctl: %w", err)
+	}
+
+	// journalctl is ended if its output cannot be read, so it does not block
+	// on a full pipe. A cancelled context has already killed it.
+	readErr := j.readEntries(ctx, journalctlSource, stdout, "")
+	if readErr != nil {
+		_ = cmd.Process.Kill()
+	}
+	waitErr := cmd.Wait()
+	if readErr != nil {
+		return fmt.Errorf("failed to read journalctl output: %w", readErr)
+	}
+	if waitErr != nil {
+		return fmt.Errorf("journalctl failed: %w", waitErr)
+	}
+	return nil
+}
+
+// readFiles reads each export file to its end, starting after the entry
+// with the saved cursor when the file contains it
+func (j *JournaldInput) readFiles(ctx context.Context) {
+	defer j.wg.Done()
+
+	for _, path := range j.files {
+		j.mutex.Lock()
+		cursor := j.cursors[path]
+		j.mutex.Unlock()
+
+		err := j.readFile(ctx, path, cursor)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			if core := j.Core(); core != nil {
+				core.PublishEvent(model.EventError, j.ID(), fmt.Errorf("failed to read %s: %w", path, err))
+			}
+		}
+	}
+}
+
+// readFile reads the entries of an export file. When the saved cursor is
+// found, only the entries after it are kept; when it is not, the file is
+// read from the start.
+func (j *JournaldInput) readFile(ctx context.Context, path, cursor string) error {
+	if cursor != "" {
+		found, err := journalFileHasCursor(path, cursor)
+		if err != nil {
+			return err
+		}
+		if !found {
+			cursor = ""
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return j.readEntries(ctx, path, file, cursor)
+}
+
+// journalFileHasCursor reports whether an export file contains an entry with a cursor
+func journalFileHasCursor(path, cursor string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	reader := newJournalReader(file)
+	for {
+		entry, err := reader.Next()
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if entry["__CURSOR"] == cursor {
+			return true, nil
+		}
+	}
+}
+
+// readEntries queues the entries of an export stream as log points,
+// skipping those up to and including the entry with skipTo when it is set.
+// Each entry's cursor is recorded as the source's position along with it.
+func (j *JournaldInput) readEntries(ctx context.Context, source string, r io.Reader, skipTo string) error {
+	reader := newJournalReader(r)
+	for {
+		entry, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if skipTo != "" {
+			if entry["__CURSOR"] == skipTo {
+				skipTo = ""
+			}
+			continue
+		}
+
+		point := journalLogPoint(entry)
+		for {
+			added, drained := j.queueEntry(source, entry["__CURSOR"], point)
+			if added {
+				break
+			}
+
+			// Reading waits while the queue is full
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-drained:
+			}
+		}
+	}
+}
+
+// queueEntry queues a point and records its cursor together, so a cursor is
+// only saved once its entry has been collected. If the queue is full, it
+// returns a channel that is closed when the queue is next drained.
+func (j *JournaldInput) queueEntry(source, cursor string, point *model.LogPoint) (bool, <-chan struct{}) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	added, drained := j.queue.add(model.LogTelemetryType, []model.DataPoint{point})
+	if added && cursor != "" {
+		j.cursors[source] = cursor
+	}
+	return added, drained
+}
+
+// journalLogPoint builds a log point from a journal entry. PRIORITY gives
+// the level, and the unit, process and host the entry came from become
+// labels.
+func journalLogPoint(entry map[string]string) *model.LogPoint {
+	timestamp, ok := journalTimestamp(entry)
+	if !ok {
+		timestamp = time.Now()
+	}
+
+	point := &model.LogPoint{
+		BaseDataPoint: model.BaseDataPoint{
+			Timestamp: timestamp,
+			Origin:    "journald",
+			Labels:    map[string]string{"source": "journald"},
+		},
+		Message:    entry["MESSAGE"],
+		Level:      "INFO",
+		Attributes: map[string]interface{}{},
+	}
+
+	for field, label := range map[string]string{
+		"_SYSTEMD_UNIT":     "systemd_unit",
+		"_PID":              "pid",
+		"_HOSTNAME":         "hostname",
+		"SYSLOG_IDENTIFIER": "syslog_identifier",
+	} {
+		if value := entry[field]; value != "" {
+			point.Labels[label] = value
+		}
+	}
+	if hostname := entry["_HOSTNAME"]; hostname != "" {
+		point.Origin = hostname
+	}
+
+	if priority, err := strconv.Atoi(entry["PRIORITY"]); err == nil && priority >= 0 && priority < len(syslogLevels) {
+		point.Level = syslogLevels[priority]
+		point.Attributes["priority"] = priority
+		point.Attributes["severity_name"] = syslogSeverities[priority]
+	}
+	if cursor := entry["__CURSOR"]; cursor != "" {
+		point.Attributes["cursor"] = cursor
+	}
+
+	return point
+}
+
+// loadCursors reads the saved cursors from the cursor file
+func (j *JournaldInput) loadCursors() error {
+	if j.cursorFile == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(j.cursorFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read cursor file: %w", err)
+	}
+
+	cursors := make(map[string]string)
+	if err := json.Unmarshal(data, &cursors); err != nil {
+		return fmt.Errorf("failed to parse cursor file: %w", err)
+	}
+
+	j.cursors = cursors
+	j.savedCursor = data
+	return nil
+}
+
+// saveCursors writes the cursors to the cursor file
+func (j *JournaldInput) saveCursors() error {
+	if j.cursorFile == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(j.cursors)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(data, j.savedCursor) {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(j.cursorFile), 0755); err != nil {
+		return fmt.Errorf("failed to create cursor directory: %w", err)
+	}
+
+	tmpPath := j.cursorFile + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write cursor file: %w", err)
+	}
+	if err := os.Rename(tmpPath, j.cursorFile); err != nil {
+		return fmt.Errorf("failed to write cursor file: %w", err)
+	}
+
+	j.savedCursor = data
+	return nil
+}
@@ -0,0 +1,174 @@
+package inputs
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sliink/collector/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// journalEntry encodes an entry in the export format
+func journalEntry(cursor, message string, extra ...string) string {
+	entry := "__CURSOR=" + cursor + "\n__REALTIME_TIMESTAMP=1714557600000000\n" + journalBinaryField("MESSAGE", message)
+	for _, field := range extra {
+		entry += field + "\n"
+	}
+	return entry + "\n"
+}
+
+// collectJournal collects from a journald input until count points arrive
+// or a few seconds pass
+func collectJournal(input *JournaldInput, count int) []*model.LogPoint {
+	var points []*model.LogPoint
+	deadline := time.Now().Add(3 * time.Second)
+	for len(points) < count && time.Now().Before(deadline) {
+		for _, batch := range input.Collect() {
+			for _, point := range batch.Points {
+				points = append(points, point.(*model.LogPoint))
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return points
+}
+
+func TestJournaldInputValidate(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{"units": []interface{}{float64(1)}},
+		{"files": []interface{}{""}},
+		{"start_at": "middle"},
+		{"max_pending": float64(0)},
+	}
+
+	for _, config := range invalid {
+		input := NewJournaldInput("journald_input")
+		input.Configure(config)
+		assert.False(t, input.Validate(), "%v", config)
+		assert.False(t, input.Initialize(), "%v", config)
+	}
+
+	input := NewJournaldInput("journald_input")
+	input.Configure(map[string]interface{}{"cursor_file": filepath.Join(t.TempDir(), "missing", "cursor")})
+	assert.True(t, input.Initialize())
+}
+
+func TestJournaldInputFiles(t *testing.T) {
+	dir := t.TempDir()
+	exportPath := filepath.Join(dir, "system.export")
+	cursorPath := filepath.Join(dir, "journald.cursor")
+
+	assert.NoError(t, os.WriteFile(exportPath, []byte(
+		journalEntry("c1", "started", "PRIORITY=6", "_SYSTEMD_UNIT=nginx.service", "_PID=1234", "_HOSTNAME=web-1", "SYSLOG_IDENTIFIER=nginx")+
+			journalEntry("c2", "disk failing\nsector 42", "PRIORITY=2"),
+	), 0644))
+
+	read := func() []*model.LogPoint {
+		input := NewJournaldInput("journald_input")
+		assert.True(t, input.Configure(map[string]interface{}{
+			"files":       []interface{}{exportPath},
+			"cursor_file": cursorPath,
+		}))
+		assert.True(t, input.Validate())
+		assert.True(t, input.Initialize())
+		assert.True(t, input.Start())
+		defer input.Stop()
+
+		points := collectJournal(input, 10)
+		return points
+	}
+
+	points := read()
+	if assert.Len(t, points, 2) {
+		assert.Equal(t, "started", points[0].Message)
+		assert.Equal(t, "INFO", points[0].Level)
+		assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), points[0].Timestamp)
+		assert.Equal(t, "web-1", points[0].Origin)
+		assert.Equal(t, map[string]string{
+			"source":            "journald",
+			"systemd_unit":      "nginx.service",
+			"pid":               "1234",
+			"hostname":          "web-1",
+			"syslog_identifier": "nginx",
+		}, points[0].Labels)
+		assert.Equal(t, "c1", points[0].Attributes["cursor"])
+
+		assert.Equal(t, "disk failing\nsector 42", points[1].Message)
+		assert.Equal(t, "FATAL", points[1].Level)
+		assert.Equal(t, "crit", points[1].Attributes["severity_name"])
+	}
+
+	// A restart continues after the last collected entry
+	file, err := os.OpenFile(exportPath, os.O_APPEND|os.O_WRONLY, 0644)
+	assert.NoError(t, err)
+	file.WriteString(journalEntry("c3", "stopped"))
+	file.Close()
+
+	points = read()
+	if assert.Len(t, points, 1) {
+		assert.Equal(t, "stopped", points[0].Message)
+	}
+
+	// A file without the saved cursor is read from the start
+	assert.NoError(t, os.WriteFile(exportPath, []byte(journalEntry("d1", "new journal")), 0644))
+	points = read()
+	if assert.Len(t, points, 1) {
+		assert.Equal(t, "new journal", points[0].Message)
+	}
+}
+
+func TestJournaldInputJournalctl(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "first"), []byte(journalEntry("c1", "one")+journalEntry("c2", "two")), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "after"), []byte(journalEntry("c3", "three")), 0644))
+	calls := filepath.Join(dir, "calls")
+
+	// A stand-in for journalctl that honors --after-cursor
+	script := `echo "$*" >> "$DIR/calls"
+case "$*" in
+*--after-cursor=c2*) cat "$DIR/after" ;;
+*--after-cursor=*) ;;
+*) cat "$DIR/first" ;;
+esac`
+	t.Setenv("DIR", dir)
+
+	input := NewJournaldInput("journald_input")
+	input.command = func(ctx context.Context, args ...string) *exec.Cmd {
+		return exec.CommandContext(ctx, "sh", append([]string{"-c", script, "sh"}, args...)...)
+	}
+	input.minBackoff = 10 * time.Millisecond
+	input.maxBackoff = 20 * time.Millisecond
+
+	assert.True(t, input.Configure(map[string]interface{}{
+		"units":    []interface{}{"nginx.service"},
+		"start_at": "beginning",
+	}))
+	assert.True(t, input.Initialize())
+	assert.True(t, input.Start())
+
+	points := collectJournal(input, 3)
+	time.Sleep(50 * time.Millisecond)
+	points = append(points, collectJournal(input, 0)...)
+	assert.True(t, input.Stop())
+
+	var messages []string
+	for _, point := range points {
+		messages = append(messages, point.Message)
+	}
+	assert.Equal(t, []string{"one", "two", "three"}, messages)
+
+	data, err := os.ReadFile(calls)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Equal(t, "--output=export --follow --no-pager --unit=nginx.service --lines=all", lines[0])
+	assert.Equal(t, "--output=export --follow --no-pager --unit=nginx.service --after-cursor=c2", lines[1])
+}
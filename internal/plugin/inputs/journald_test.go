@@ -0,0 +1,80 @@
+package inputs
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// journalBinaryField encodes a field in the binary form of the export format
+func journalBinaryField(name, value string) string {
+	size := make([]byte, 8)
+	binary.LittleEndian.PutUint64(size, uint64(len(value)))
+	return name + "\n" + string(size) + value + "\n"
+}
+
+func TestJournalReader(t *testing.T) {
+	stream := "__CURSOR=s=1;i=1\n__REALTIME_TIMESTAMP=1714557600123456\nMESSAGE=hello=world\nPRIORITY=6\n\n" +
+		"\n" +
+		"__CURSOR=s=1;i=2\n" + journalBinaryField("MESSAGE", "line one\nline two") + "_PID=42\n\n" +
+		"__CURSOR=s=1;i=3\nMESSAGE=no trailing blank line\n"
+
+	reader := newJournalReader(strings.NewReader(stream))
+
+	entry, err := reader.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"__CURSOR":             "s=1;i=1",
+		"__REALTIME_TIMESTAMP": "1714557600123456",
+		"MESSAGE":              "hello=world",
+		"PRIORITY":             "6",
+	}, entry)
+
+	entry, err = reader.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "line one\nline two", entry["MESSAGE"])
+	assert.Equal(t, "42", entry["_PID"])
+
+	entry, err = reader.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "no trailing blank line", entry["MESSAGE"])
+
+	_, err = reader.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestJournalReaderErrors(t *testing.T) {
+	// A binary field cut short
+	field := journalBinaryField("MESSAGE", "truncated")
+	_, err := newJournalReader(strings.NewReader(field[:len(field)-4])).Next()
+	assert.Error(t, err)
+
+	// A binary field without the newline after its value
+	var stream bytes.Buffer
+	stream.WriteString("MESSAGE\n")
+	binary.Write(&stream, binary.LittleEndian, uint64(2))
+	stream.WriteString("hi!\n")
+	_, err = newJournalReader(&stream).Next()
+	assert.Error(t, err)
+
+	// A field line cut short
+	_, err = newJournalReader(strings.NewReader("MESSAGE=hello\nPRIO")).Next()
+	assert.Error(t, err)
+}
+
+func TestJournalTimestamp(t *testing.T) {
+	timestamp, ok := journalTimestamp(map[string]string{
+		"__REALTIME_TIMESTAMP":       "1714557601000000",
+		"_SOURCE_REALTIME_TIMESTAMP": "1714557600500000",
+	})
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 500000000, time.UTC), timestamp)
+
+	_, ok = journalTimestamp(map[string]string{"MESSAGE": "no time"})
+	assert.False(t, ok)
+}
@@ -0,0 +1,235 @@
+package inputs
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sliink/collector/internal/model"
+	"github.com/sliink/collector/internal/plugin"
+)
+
+// maxPartialLogSize bounds a message reassembled from partial lines; a
+// longer message is emitted in parts
+const maxPartialLogSize = 1024 * 1024
+
+// partialLogTimeout is how long a message waits for its last part before
+// what has been read of it is emitted
+const partialLogTimeout = 5 * time.Minute
+
+// KubernetesLogsInput tails container log files written by the kubelet or
+// by Docker's json-file driver, reassembling messages split over partial
+// lines and labelling them with the pod they came from
+type KubernetesLogsInput struct {
+	plugin.BasePlugin
+	files          *FileInput
+	partial        map[string]*partialLog // Messages awaiting their last part, by container and stream
+	partialTimeout time.Duration
+	mutex          sync.Mutex
+}
+
+// partialLog is a message whose last part has not been read yet. Its parts
+// are only held in memory, while the file checkpoints move past them.
+type partialLog struct {
+	labels    map[string]string
+	timestamp time.Time
+	parts     []string
+	size      int
+	updated   time.Time // When the latest part was read
+}
+
+func init() {
+	plugin.RegisterStandardInput("kubernetes_logs", func(id string) model.InputPlugin {
+		return NewKubernetesLogsInput(id)
+	})
+}
+
+// NewKubernetesLogsInput creates a new Kubernetes container log input plugin
+func NewKubernetesLogsInput(id string) *KubernetesLogsInput {
+	return &KubernetesLogsInput{
+		BasePlugin:     plugin.NewBasePlugin(id, "Kubernetes Logs Input", model.InputPluginType),
+		files:          NewFileInput(id),
+		partial:        make(map[string]*partialLog),
+		partialTimeout: partialLogTimeout,
+	}
+}
+
+// fileConfig returns the file input configuration the log files are tailed with
+func (k *KubernetesLogsInput) fileConfig() map[string]interface{} {
+	config := map[string]interface{}{
+		"paths": []interface{}{"/var/log/pods/*/*/*.log"},
+	}
+	for _, key := range []string{"paths", "start_at", "checkpoint_file", "enabled"} {
+		if value, ok := k.Config[key]; ok {
+			config[key] = value
+		}
+	}
+	return config
+}
+
+// Initialize prepares the Kubernetes logs input for operation
+func (k *KubernetesLogsInput) Initialize() bool {
+	if !k.files.Configure(k.fileConfig()) || !k.files.Initialize() {
+		return false
+	}
+
+	k.SetStatus(model.StatusInitialized)
+	return true
+}
+
+// Validate checks if the Kubernetes logs input is properly configured
+func (k *KubernetesLogsInput) Validate() bool {
+	files := NewFileInput(k.ID())
+	return files.Configure(k.fileConfig()) && files.Validate()
+}
+
+// Start begins tailing the log files
+func (k *KubernetesLogsInput) Start() bool {
+	if !k.files.Start() {
+		return false
+	}
+
+	k.SetStatus(model.StatusRunning)
+	return true
+}
+
+// Stop closes the log files and saves their read positions
+func (k *KubernetesLogsInput) Stop() bool {
+	k.files.Stop()
+
+	k.SetStatus(model.StatusStopped)
+	return true
+}
+
+// Collect reads the lines written to the log files since the last call and
+// turns them into log points. Partial lines are held until the line that
+// completes them is read, or emitted as they are once the rest of the
+// message is overdue.
+func (k *KubernetesLogsInput) Collect() []*model.DataBatch {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	var results []*model.DataBatch
+	batch := model.NewDataBatch(model.LogTelemetryType)
+	batch.SourceID = k.ID()
+	addPoint := func(point *model.LogPoint) {
+		batch.AddPoint(point)
+
+		// Create a new batch if current one is full
+		if batch.Size() >= 1000 {
+			results = append(results, batch)
+			batch = model.NewDataBatch(model.LogTelemetryType)
+			batch.SourceID = k.ID()
+		}
+	}
+
+	for _, fileBatch := range k.files.Collect() {
+		for _, point := range fileBatch.Points {
+			line, ok := point.(*model.LogPoint)
+			if !ok {
+				continue
+			}
+			if point := k.logPoint(line.Labels["path"], line.Message, line.Timestamp); point != nil {
+				addPoint(point)
+			}
+		}
+	}
+
+	for _, point := range k.expirePartial(time.Now()) {
+		addPoint(point)
+	}
+
+	// Add the last batch if it has any points
+	if batch.Size() > 0 {
+		results = append(results, batch)
+	}
+
+	return results
+}
+
+// expirePartial removes the messages that have waited longer than the
+// partial timeout for their next part, such as those whose last part was
+// lost, and returns what was read of them
+func (k *KubernetesLogsInput) expirePartial(now time.Time) []*model.LogPoint {
+	var keys []string
+	for key, pending := range k.partial {
+		if now.Sub(pending.updated) > k.partialTimeout {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	points := make([]*model.LogPoint, 0, len(keys))
+	for _, key := range keys {
+		pending := k.partial[key]
+		points = append(points, newKubernetesLogPoint(pending.labels, strings.Join(pending.parts, ""), pending.timestamp))
+		delete(k.partial, key)
+	}
+	return points
+}
+
+// logPoint builds a log point from a line of a container log file, returning
+// nil while the message is incomplete. Lines in neither format are kept
+// whole, with the time they were read.
+func (k *KubernetesLogsInput) logPoint(path, text string, readAt time.Time) *model.LogPoint {
+	labels := kubernetesPathLabels(path)
+	labels["source"] = "kubernetes"
+	labels["path"] = path
+
+	line, err := parseContainerLogLine(text)
+	if err != nil {
+		return newKubernetesLogPoint(labels, text, readAt)
+	}
+	labels["stream"] = line.stream
+
+	// Messages are reassembled per container, so a message split across a
+	// rotation is still joined
+	key := path
+	if labels["pod_uid"] != "" || labels["container_id"] != "" {
+		key = labels["namespace"] + "/" + labels["pod"] + "/" + labels["container"]
+	}
+	key += "/" + line.stream
+
+	pending := k.partial[key]
+	if line.partial {
+		if pending == nil {
+			pending = &partialLog{labels: labels, timestamp: line.timestamp}
+			k.partial[key] = pending
+		}
+		pending.parts = append(pending.parts, line.message)
+		pending.size += len(line.message)
+		pending.updated = readAt
+		if pending.size < maxPartialLogSize {
+			return nil
+		}
+	} else if pending != nil {
+		pending.parts = append(pending.parts, line.message)
+	}
+
+	message, timestamp := line.message, line.timestamp
+	if pending != nil {
+		message, timestamp = strings.Join(pending.parts, ""), pending.timestamp
+		delete(k.partial, key)
+	}
+	return newKubernetesLogPoint(labels, message, timestamp)
+}
+
+// newKubernetesLogPoint creates a log point for a container's message
+func newKubernetesLogPoint(labels map[string]string, message string, timestamp time.Time) *model.LogPoint {
+	origin := labels["path"]
+	if labels["pod"] != "" {
+		origin = labels["namespace"] + "/" + labels["pod"]
+	}
+
+	return &model.LogPoint{
+		BaseDataPoint: model.BaseDataPoint{
+			Timestamp: timestamp,
+			Origin:    origin,
+			Labels:    labels,
+		},
+		Message:    message,
+		Level:      "INFO", // Default level, would be parsed from content
+		Attributes: map[string]interface{}{},
+	}
+}
@@ -0,0 +1,144 @@
+package inputs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sliink/collector/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// collectKubernetesLogs collects the log points of a Kubernetes logs input
+func collectKubernetesLogs(input *KubernetesLogsInput) []*model.LogPoint {
+	var points []*model.LogPoint
+	for _, batch := range input.Collect() {
+		for _, point := range batch.Points {
+			points = append(points, point.(*model.LogPoint))
+		}
+	}
+	return points
+}
+
+// appendFile appends text to a file, creating it and its directory if needed
+func appendFile(t *testing.T, path, text string) {
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	assert.NoError(t, err)
+	defer file.Close()
+	_, err = file.WriteString(text)
+	assert.NoError(t, err)
+}
+
+func TestKubernetesLogsInputValidate(t *testing.T) {
+	input := NewKubernetesLogsInput("k8s_input")
+	input.Configure(map[string]interface{}{"paths": []interface{}{}})
+	assert.False(t, input.Validate())
+
+	input = NewKubernetesLogsInput("k8s_input")
+	input.Configure(map[string]interface{}{"start_at": "middle"})
+	assert.False(t, input.Initialize())
+
+	// The kubelet's pod log directory is read by default
+	input = NewKubernetesLogsInput("k8s_input")
+	input.Configure(map[string]interface{}{})
+	assert.True(t, input.Validate())
+	assert.Equal(t, []interface{}{"/var/log/pods/*/*/*.log"}, input.fileConfig()["paths"])
+}
+
+func TestKubernetesLogsInputCollect(t *testing.T) {
+	root := t.TempDir()
+	criPath := filepath.Join(root, "pods", "shop_web-1_1234-abcd", "nginx", "0.log")
+	jsonPath := filepath.Join(root, "pods", "shop_worker-1_5678-efgh", "worker", "1.log")
+
+	appendFile(t, criPath, "2024-05-01T10:00:00Z stdout F GET /\n"+
+		"2024-05-01T10:00:01Z stderr P a message \n"+
+		"2024-05-01T10:00:02Z stdout F GET /health\n"+
+		"2024-05-01T10:00:02Z stderr F in two parts\n"+
+		"2024-05-01T10:00:03Z stderr P split over \n")
+	appendFile(t, jsonPath, `{"log":"job started\n","stream":"stdout","time":"2024-05-01T10:00:00Z"}`+"\n"+
+		`{"log":"long ","stream":"stdout","time":"2024-05-01T10:00:01Z"}`+"\n"+
+		`{"log":"line\n","stream":"stdout","time":"2024-05-01T10:00:02Z"}`+"\n"+
+		"not a container log line\n")
+
+	input := NewKubernetesLogsInput("k8s_input")
+	assert.True(t, input.Configure(map[string]interface{}{
+		"paths": []interface{}{filepath.Join(root, "pods", "*", "*", "*.log")},
+	}))
+	assert.True(t, input.Validate())
+	assert.True(t, input.Initialize())
+	assert.True(t, input.Start())
+	defer input.Stop()
+
+	messages := make(map[string]*model.LogPoint)
+	for _, point := range collectKubernetesLogs(input) {
+		messages[point.Message] = point
+	}
+	assert.Len(t, messages, 6)
+
+	get := messages["GET /"]
+	if assert.NotNil(t, get) {
+		assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), get.Timestamp)
+		assert.Equal(t, "shop/web-1", get.Origin)
+		assert.Equal(t, map[string]string{
+			"source":        "kubernetes",
+			"path":          criPath,
+			"namespace":     "shop",
+			"pod":           "web-1",
+			"pod_uid":       "1234-abcd",
+			"container":     "nginx",
+			"restart_count": "0",
+			"stream":        "stdout",
+		}, get.Labels)
+	}
+	assert.Contains(t, messages, "GET /health")
+	assert.Contains(t, messages, "job started")
+	if assert.Contains(t, messages, "long line") {
+		assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 1, 0, time.UTC), messages["long line"].Timestamp)
+		assert.Equal(t, "worker", messages["long line"].Labels["container"])
+	}
+	// Lines of the other stream may come between the parts of a message
+	if assert.Contains(t, messages, "a message in two parts") {
+		assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 1, 0, time.UTC), messages["a message in two parts"].Timestamp)
+		assert.Equal(t, "stderr", messages["a message in two parts"].Labels["stream"])
+	}
+	assert.Contains(t, messages, "not a container log line")
+
+	// The partial line is completed by a later write, even after the
+	// container restarts into a new file
+	appendFile(t, filepath.Join(filepath.Dir(criPath), "1.log"), "2024-05-01T10:00:04Z stderr P two \n2024-05-01T10:00:05Z stderr F writes\n")
+	points := collectKubernetesLogs(input)
+	if assert.Len(t, points, 1) {
+		assert.Equal(t, "split over two writes", points[0].Message)
+		assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 3, 0, time.UTC), points[0].Timestamp)
+		assert.Equal(t, "stderr", points[0].Labels["stream"])
+	}
+}
+
+func TestKubernetesLogsInputPartialTimeout(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "pods", "shop_web-1_1234-abcd", "nginx", "0.log")
+	appendFile(t, path, "2024-05-01T10:00:00Z stdout P never \n2024-05-01T10:00:01Z stdout P finished\n")
+
+	input := NewKubernetesLogsInput("k8s_input")
+	input.partialTimeout = 50 * time.Millisecond
+	assert.True(t, input.Configure(map[string]interface{}{
+		"paths": []interface{}{filepath.Join(root, "pods", "*", "*", "*.log")},
+	}))
+	assert.True(t, input.Initialize())
+	assert.True(t, input.Start())
+	defer input.Stop()
+
+	assert.Empty(t, collectKubernetesLogs(input))
+
+	// A message whose last part never comes is emitted as it is
+	time.Sleep(100 * time.Millisecond)
+	points := collectKubernetesLogs(input)
+	if assert.Len(t, points, 1) {
+		assert.Equal(t, "never finished", points[0].Message)
+		assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), points[0].Timestamp)
+		assert.Equal(t, "nginx", points[0].Labels["container"])
+	}
+	assert.Empty(t, input.partial)
+}
@@ -0,0 +1,239 @@
+package inputs
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sliink/collector/internal/plugin"
+)
+
+// multilinePresets are ready-made settings for common stack traces
+var multilinePresets = map[string]map[string]interface{}{
+	// Indented "at ..." and "... N more" frames and "Caused by:" lines
+	"java": {
+		"pattern": `^(\s|Caused by:)`,
+		"match":   "after",
+	},
+	// "Traceback" headers, indented frames, chained exception notes and the
+	// final "SomeError: message" line
+	"python": {
+		"pattern": `^(\s|$|Traceback \(most recent call last\):|During handling of the above exception|The above exception was the direct cause|[A-Za-z_][\w.]*(Error|Exception|Warning|Exit|Interrupt)\b)`,
+		"match":   "after",
+	},
+	// Goroutine headers, function calls, indented file positions and the exit status
+	"go": {
+		"pattern": `^(\s|$|goroutine \d+ \[|[\w./-]+(\.\(\*?[\w]+\))?\.[\w.]+\(.*\)$|created by |exit status \d+|\[signal )`,
+		"match":   "after",
+	},
+}
+
+// multilineSettings decides how lines are grouped into events
+type multilineSettings struct {
+	// startPattern and continuePattern mark the first and following lines of an event
+	startPattern    *regexp.Regexp
+	continuePattern *regexp.Regexp
+	// pattern, negate and match group lines the way Filebeat does: lines that
+	// match the pattern (or don't, when negated) are joined after the previous
+	// line or before the next one
+	pattern  *regexp.Regexp
+	negate   bool
+	match    string
+	maxLines int
+	maxBytes int
+	timeout  time.Duration
+}
+
+// parseMultiline reads multiline settings from the file input's multiline config
+func parseMultiline(config map[string]interface{}) (*multilineSettings, error) {
+	settings := &multilineSettings{
+		match:    "after",
+		maxLines: 500,
+		maxBytes: 10 * 1024 * 1024,
+		timeout:  5 * time.Second,
+	}
+
+	// Presets provide defaults that the rest of the config can override
+	if preset, ok := config["preset"].(string); ok {
+		presetConfig, exists := multilinePresets[preset]
+		if !exists {
+			return nil, fmt.Errorf("unknown multiline preset: %s", preset)
+		}
+		merged := make(map[string]interface{})
+		for k, v := range presetConfig {
+			merged[k] = v
+		}
+		for k, v := range config {
+			merged[k] = v
+		}
+		config = merged
+	}
+
+	var err error
+	if settings.startPattern, err = compileOption(config, "start_pattern"); err != nil {
+		return nil, err
+	}
+	if settings.continuePattern, err = compileOption(config, "continue_pattern"); err != nil {
+		return nil, err
+	}
+	if settings.pattern, err = compileOption(config, "pattern"); err != nil {
+		return nil, err
+	}
+
+	if settings.pattern == nil && settings.startPattern == nil {
+		return nil, fmt.Errorf("multiline requires pattern or start_pattern")
+	}
+	if settings.pattern != nil && (settings.startPattern != nil || settings.continuePattern != nil) {
+		return nil, fmt.Errorf("multiline pattern cannot be combined with start_pattern or continue_pattern")
+	}
+
+	if negate, ok := config["negate"].(bool); ok {
+		settings.negate = negate
+	}
+
+	if match, ok := config["match"].(string); ok {
+		if match != "after" && match != "before" {
+			return nil, fmt.Errorf("invalid multiline match: %s", match)
+		}
+		settings.match = match
+	}
+
+	if maxLines, ok := plugin.IntOption(config["max_lines"]); ok {
+		if maxLines < 1 {
+			return nil, fmt.Errorf("multiline max_lines must be at least 1")
+		}
+		settings.maxLines = int(maxLines)
+	}
+
+	if maxBytes, ok := plugin.IntOption(config["max_bytes"]); ok {
+		if maxBytes < 1 {
+			return nil, fmt.Errorf("multiline max_bytes must be at least 1")
+		}
+		settings.maxBytes = int(maxBytes)
+	}
+
+	if timeoutStr, ok := config["timeout"].(string); ok {
+		timeout, err := time.ParseDuration(timeoutStr)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("invalid multiline timeout: %s", timeoutStr)
+		}
+		settings.timeout = timeout
+	}
+
+	return settings, nil
+}
+
+// compileOption compiles an optional regular expression from the config
+func compileOption(config map[string]interface{}, key string) (*regexp.Regexp, error) {
+	expr, ok := config[key].(string)
+	if !ok || expr == "" {
+		return nil, nil
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid multiline %s: %w", key, err)
+	}
+
+	return re, nil
+}
+
+// multilineAggregator groups the lines of one file into events
+type multilineAggregator struct {
+	settings *multilineSettings
+	lines    []string
+	size     int
+	start    int64 // File offset of the first pending line
+	updated  time.Time
+}
+
+// newMultilineAggregator creates an aggregator with no pending event
+func newMultilineAggregator(settings *multilineSettings) *multilineAggregator {
+	return &multilineAggregator{settings: settings}
+}
+
+// Add feeds the line found at a file offset and returns the events it completes
+func (a *multilineAggregator) Add(line string, offset int64, now time.Time) []string {
+	var events []string
+	s := a.settings
+
+	if s.pattern != nil {
+		matched := s.pattern.MatchString(line) != s.negate
+		if s.match == "after" {
+			// Matching lines continue the previous line
+			if !matched {
+				events = a.flushInto(events)
+			}
+			events = a.append(events, line, offset, now)
+		} else {
+			// Matching lines continue onto the next line
+			events = a.append(events, line, offset, now)
+			if !matched {
+				events = a.flushInto(events)
+			}
+		}
+		return events
+	}
+
+	switch {
+	case s.startPattern.MatchString(line):
+		events = a.flushInto(events)
+		events = a.append(events, line, offset, now)
+	case len(a.lines) > 0 && (s.continuePattern == nil || s.continuePattern.MatchString(line)):
+		events = a.append(events, line, offset, now)
+	default:
+		// Lines outside an event stand alone
+		events = a.flushInto(events)
+		events = append(events, line)
+	}
+
+	return events
+}
+
+// append adds a line to the pending event, first completing the event if the
+// line would take it past max_lines or max_bytes
+func (a *multilineAggregator) append(events []string, line string, offset int64, now time.Time) []string {
+	if len(a.lines) > 0 && (len(a.lines) >= a.settings.maxLines || a.size+1+len(line) > a.settings.maxBytes) {
+		events = a.flushInto(events)
+	}
+
+	if len(a.lines) == 0 {
+		a.start = offset
+		a.size = len(line)
+	} else {
+		a.size += 1 + len(line)
+	}
+	a.lines = append(a.lines, line)
+	a.updated = now
+
+	return events
+}
+
+// Expired reports whether the pending event has waited longer than the timeout
+func (a *multilineAggregator) Expired(now time.Time) bool {
+	return len(a.lines) > 0 && now.Sub(a.updated) >= a.settings.timeout
+}
+
+// Pending returns the file offset of the pending event's first line
+func (a *multilineAggregator) Pending() (int64, bool) {
+	return a.start, len(a.lines) > 0
+}
+
+// Flush returns the pending event, if any
+func (a *multilineAggregator) Flush() []string {
+	return a.flushInto(nil)
+}
+
+// flushInto appends the pending event to events and clears it
+func (a *multilineAggregator) flushInto(events []string) []string {
+	if len(a.lines) == 0 {
+		return events
+	}
+
+	events = append(events, strings.Join(a.lines, "\n"))
+	a.lines = nil
+	a.size = 0
+
+	return events
+}
@@ -0,0 +1,152 @@
+package inputs
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func aggregate(t *testing.T, config map[string]interface{}, lines ...string) []string {
+	settings, err := parseMultiline(config)
+	assert.NoError(t, err)
+
+	aggregator := newMultilineAggregator(settings)
+	var events []string
+	for i, line := range lines {
+		events = append(events, aggregator.Add(line, int64(i), time.Now())...)
+	}
+	return append(events, aggregator.Flush()...)
+}
+
+func TestParseMultiline(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{},
+		{"pattern": "("},
+		{"pattern": "^x", "start_pattern": "^y"},
+		{"pattern": "^x", "match": "around"},
+		{"pattern": "^x", "max_lines": float64(0)},
+		{"pattern": "^x", "timeout": "soon"},
+		{"preset": "cobol"},
+	}
+
+	for _, config := range invalid {
+		_, err := parseMultiline(config)
+		assert.Error(t, err, "%v", config)
+	}
+
+	settings, err := parseMultiline(map[string]interface{}{"preset": "java", "max_lines": float64(20)})
+	assert.NoError(t, err)
+	assert.NotNil(t, settings.pattern)
+	assert.Equal(t, 20, settings.maxLines)
+
+	// Configs built in Go or decoded from YAML hold ints
+	settings, err = parseMultiline(map[string]interface{}{"pattern": "^x", "max_lines": 5, "max_bytes": 100})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, settings.maxLines)
+	assert.Equal(t, 100, settings.maxBytes)
+}
+
+func TestMultilineAggregator(t *testing.T) {
+	t.Run("Negated pattern joins lines after the start line", func(t *testing.T) {
+		events := aggregate(t, map[string]interface{}{"pattern": `^\d{4}-`, "negate": true},
+			"2024-05-01 first", "  detail", "2024-05-01 second", "2024-05-01 third", "  more", "  and more")
+
+		assert.Equal(t, []string{
+			"2024-05-01 first\n  detail",
+			"2024-05-01 second",
+			"2024-05-01 third\n  more\n  and more",
+		}, events)
+	})
+
+	t.Run("Match before joins lines onto the next line", func(t *testing.T) {
+		events := aggregate(t, map[string]interface{}{"pattern": `\\$`, "match": "before"},
+			`one \`, `two \`, "three", "four")
+
+		assert.Equal(t, []string{"one \\\ntwo \\\nthree", "four"}, events)
+	})
+
+	t.Run("Start and continue patterns", func(t *testing.T) {
+		events := aggregate(t, map[string]interface{}{"start_pattern": `^BEGIN`, "continue_pattern": `^\+`},
+			"BEGIN a", "+ 1", "+ 2", "stray", "BEGIN b", "+ 3")
+
+		assert.Equal(t, []string{"BEGIN a\n+ 1\n+ 2", "stray", "BEGIN b\n+ 3"}, events)
+	})
+
+	t.Run("Events are split at max_lines and max_bytes", func(t *testing.T) {
+		events := aggregate(t, map[string]interface{}{"pattern": `^\s`, "max_lines": float64(2)},
+			"start", " 1", " 2", " 3")
+		assert.Equal(t, []string{"start\n 1", " 2\n 3"}, events)
+
+		events = aggregate(t, map[string]interface{}{"pattern": `^\s`, "max_bytes": float64(11)},
+			"start", " 1234", " 5678")
+		assert.Equal(t, []string{"start\n 1234", " 5678"}, events)
+	})
+
+	t.Run("Pending events expire after the timeout", func(t *testing.T) {
+		settings, err := parseMultiline(map[string]interface{}{"pattern": `^\s`, "timeout": "1s"})
+		assert.NoError(t, err)
+
+		aggregator := newMultilineAggregator(settings)
+		now := time.Now()
+		aggregator.Add("start", 0, now)
+		aggregator.Add(" more", 6, now)
+
+		assert.False(t, aggregator.Expired(now.Add(500*time.Millisecond)))
+		assert.True(t, aggregator.Expired(now.Add(time.Second)))
+
+		start, pending := aggregator.Pending()
+		assert.True(t, pending)
+		assert.Equal(t, int64(0), start)
+	})
+}
+
+func TestMultilinePresets(t *testing.T) {
+	t.Run("Java", func(t *testing.T) {
+		trace := []string{
+			"Exception in thread \"main\" java.lang.IllegalStateException: boom",
+			"\tat com.example.App.run(App.java:10)",
+			"\tat com.example.App.main(App.java:5)",
+			"Caused by: java.io.IOException: closed",
+			"\tat com.example.Io.read(Io.java:42)",
+			"\t... 2 more",
+		}
+		events := aggregate(t, map[string]interface{}{"preset": "java"}, append(trace, "next log line")...)
+		assert.Equal(t, []string{strings.Join(trace, "\n"), "next log line"}, events)
+	})
+
+	t.Run("Python", func(t *testing.T) {
+		trace := []string{
+			"ERROR request failed",
+			"Traceback (most recent call last):",
+			"  File \"app.py\", line 3, in <module>",
+			"    main()",
+			"KeyError: 'id'",
+			"",
+			"During handling of the above exception, another exception occurred:",
+			"",
+			"Traceback (most recent call last):",
+			"  File \"app.py\", line 5, in <module>",
+			"ValueError: bad value",
+		}
+		events := aggregate(t, map[string]interface{}{"preset": "python"}, append(trace, "INFO next request")...)
+		assert.Equal(t, []string{strings.Join(trace, "\n"), "INFO next request"}, events)
+	})
+
+	t.Run("Go", func(t *testing.T) {
+		trace := []string{
+			"panic: runtime error: index out of range [5] with length 3",
+			"",
+			"goroutine 1 [running]:",
+			"main.(*Server).handle(0xc000010000, {0x4b2f00, 0x3})",
+			"\t/app/server.go:42 +0x1d",
+			"main.main()",
+			"\t/app/main.go:8 +0x25",
+			"created by net/http.(*Server).Serve in goroutine 1",
+			"exit status 2",
+		}
+		events := aggregate(t, map[string]interface{}{"preset": "go"}, append(trace, "server restarted")...)
+		assert.Equal(t, []string{strings.Join(trace, "\n"), "server restarted"}, events)
+	})
+}
@@ -0,0 +1,442 @@
+package inputs
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sliink/collector/internal/model"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// otlpSeverityLevels maps OTLP severity number ranges to log levels
+var otlpSeverityLevels = []struct {
+	max   logspb.SeverityNumber
+	level string
+}{
+	{logspb.SeverityNumber_SEVERITY_NUMBER_TRACE4, "TRACE"},
+	{logspb.SeverityNumber_SEVERITY_NUMBER_DEBUG4, "DEBUG"},
+	{logspb.SeverityNumber_SEVERITY_NUMBER_INFO4, "INFO"},
+	{logspb.SeverityNumber_SEVERITY_NUMBER_WARN4, "WARN"},
+	{logspb.SeverityNumber_SEVERITY_NUMBER_ERROR4, "ERROR"},
+	{logspb.SeverityNumber_SEVERITY_NUMBER_FATAL4, "FATAL"},
+}
+
+// otlpResource is the part of a point shared by everything under one
+// resource and instrumentation scope
+type otlpResource struct {
+	origin string
+	labels map[string]string
+}
+
+// newOTLPResource turns resource and scope attributes into labels. The
+// service name, or else the host name, becomes the origin.
+func newOTLPResource(resource *resourcepb.Resource, scope *commonpb.InstrumentationScope, origin string) otlpResource {
+	labels := map[string]string{"source": "otlp"}
+
+	attributes := attributeMap(resource.GetAttributes())
+	for k, v := range attributes {
+		labels[k] = labelValue(v)
+	}
+	for k, v := range attributeMap(scope.GetAttributes()) {
+		labels[k] = labelValue(v)
+	}
+	if name := scope.GetName(); name != "" {
+		labels["otel.scope.name"] = name
+	}
+	if version := scope.GetVersion(); version != "" {
+		labels["otel.scope.version"] = version
+	}
+
+	if name, ok := attributes["service.name"].(string); ok && name != "" {
+		origin = name
+	} else if name, ok := attributes["host.name"].(string); ok && name != "" {
+		origin = name
+	}
+
+	return otlpResource{origin: origin, labels: labels}
+}
+
+// base returns the common fields of a point with its own copy of the labels
+func (r otlpResource) base(timestamp time.Time) model.BaseDataPoint {
+	labels := make(map[string]string, len(r.labels))
+	for k, v := range r.labels {
+		labels[k] = v
+	}
+
+	return model.BaseDataPoint{
+		Timestamp: timestamp,
+		Origin:    r.origin,
+		Labels:    labels,
+	}
+}
+
+// otlpLogPoints converts exported log records to log points. Record
+// attributes become point attributes.
+func otlpLogPoints(request *collogspb.ExportLogsServiceRequest, origin string, now time.Time) []model.DataPoint {
+	var points []model.DataPoint
+
+	for _, resourceLogs := range request.GetResourceLogs() {
+		for _, scopeLogs := range resourceLogs.GetScopeLogs() {
+			resource := newOTLPResource(resourceLogs.GetResource(), scopeLogs.GetScope(), origin)
+
+			for _, record := range scopeLogs.GetLogRecords() {
+				timestamp := unixNano(record.GetTimeUnixNano(), unixNano(record.GetObservedTimeUnixNano(), now))
+
+				point := &model.LogPoint{
+					BaseDataPoint: resource.base(timestamp),
+					Message:       bodyString(record.GetBody()),
+					Level:         severityLevel(record),
+					Attributes:    attributeMap(record.GetAttributes()),
+				}
+
+				if number := record.GetSeverityNumber(); number != logspb.SeverityNumber_SEVERITY_NUMBER_UNSPECIFIED {
+					point.Attributes["severity_number"] = int(number)
+				}
+				if text := record.GetSeverityText(); text != "" {
+					point.Attributes["severity_text"] = text
+				}
+				if traceID := record.GetTraceId(); len(traceID) > 0 {
+					point.Attributes["trace_id"] = hex.EncodeToString(traceID)
+				}
+				if spanID := record.GetSpanId(); len(spanID) > 0 {
+					point.Attributes["span_id"] = hex.EncodeToString(spanID)
+				}
+
+				points = append(points, point)
+			}
+		}
+	}
+
+	return points
+}
+
+// severityLevel picks a log level from a record's severity number, falling
+// back to its severity text
+func severityLevel(record *logspb.LogRecord) string {
+	number := record.GetSeverityNumber()
+	if number != logspb.SeverityNumber_SEVERITY_NUMBER_UNSPECIFIED {
+		for _, severity := range otlpSeverityLevels {
+			if number <= severity.max {
+				return severity.level
+			}
+		}
+	}
+
+	if text := record.GetSeverityText(); text != "" {
+		return strings.ToUpper(text)
+	}
+	return "INFO"
+}
+
+// bodyString returns a log body as a message, encoding structured bodies as JSON
+func bodyString(body *commonpb.AnyValue) string {
+	if body == nil {
+		return ""
+	}
+	if s, ok := body.GetValue().(*commonpb.AnyValue_StringValue); ok {
+		return s.StringValue
+	}
+	return labelValue(anyValue(body))
+}
+
+// otlpMetricPoints converts exported metrics to metric points. Data point
+// attributes become dimensions. Histograms and summaries are flattened into
+// _bucket, _count, _sum and quantile series the way Prometheus exposes them.
+func otlpMetricPoints(request *colmetricspb.ExportMetricsServiceRequest, origin string, now time.Time) []model.DataPoint {
+	var points []model.DataPoint
+
+	for _, resourceMetrics := range request.GetResourceMetrics() {
+		for _, scopeMetrics := range resourceMetrics.GetScopeMetrics() {
+			resource := newOTLPResource(resourceMetrics.GetResource(), scopeMetrics.GetScope(), origin)
+
+			for _, metric := range scopeMetrics.GetMetrics() {
+				points = append(points, convertMetric(metric, resource, now)...)
+			}
+		}
+	}
+
+	return points
+}
+
+// convertMetric converts the data points of one metric
+func convertMetric(metric *metricspb.Metric, resource otlpResource, now time.Time) []model.DataPoint {
+	var points []model.DataPoint
+	name := metric.GetName()
+
+	add := func(name string, value float64, metricType string, timestamp uint64, attributes []*commonpb.KeyValue, extra ...string) {
+		dimensions := make(map[string]string)
+		for k, v := range attributeMap(attributes) {
+			dimensions[k] = labelValue(v)
+		}
+		for i := 0; i+1 < len(extra); i += 2 {
+			dimensions[extra[i]] = extra[i+1]
+		}
+
+		points = append(points, &model.MetricPoint{
+			BaseDataPoint: resource.base(unixNano(timestamp, now)),
+			Name:          name,
+			Value:         value,
+			MetricType:    metricType,
+			Dimensions:    dimensions,
+		})
+	}
+
+	switch data := metric.GetData().(type) {
+	case *metricspb.Metric_Gauge:
+		for _, dp := range data.Gauge.GetDataPoints() {
+			if !noRecordedValue(dp.GetFlags()) {
+				add(name, numberValue(dp), "gauge", dp.GetTimeUnixNano(), dp.GetAttributes())
+			}
+		}
+	case *metricspb.Metric_Sum:
+		metricType := "gauge"
+		if data.Sum.GetIsMonotonic() {
+			metricType = "counter"
+		}
+		for _, dp := range data.Sum.GetDataPoints() {
+			if !noRecordedValue(dp.GetFlags()) {
+				add(name, numberValue(dp), metricType, dp.GetTimeUnixNano(), dp.GetAttributes())
+			}
+		}
+	case *metricspb.Metric_Histogram:
+		for _, dp := range data.Histogram.GetDataPoints() {
+			if noRecordedValue(dp.GetFlags()) {
+				continue
+			}
+			timestamp, attributes := dp.GetTimeUnixNano(), dp.GetAttributes()
+
+			// Buckets are cumulative, ending with +Inf
+			var cumulative uint64
+			bounds := dp.GetExplicitBounds()
+			for i, count := range dp.GetBucketCounts() {
+				cumulative += count
+				le := "+Inf"
+				if i < len(bounds) {
+					le = strconv.FormatFloat(bounds[i], 'g', -1, 64)
+				}
+				add(name+"_bucket", float64(cumulative), "histogram", timestamp, attributes, "le", le)
+			}
+			add(name+"_count", float64(dp.GetCount()), "histogram", timestamp, attributes)
+			if dp.Sum != nil {
+				add(name+"_sum", dp.GetSum(), "histogram", timestamp, attributes)
+			}
+		}
+	case *metricspb.Metric_ExponentialHistogram:
+		for _, dp := range data.ExponentialHistogram.GetDataPoints() {
+			if noRecordedValue(dp.GetFlags()) {
+				continue
+			}
+			add(name+"_count", float64(dp.GetCount()), "histogram", dp.GetTimeUnixNano(), dp.GetAttributes())
+			if dp.Sum != nil {
+				add(name+"_sum", dp.GetSum(), "histogram", dp.GetTimeUnixNano(), dp.GetAttributes())
+			}
+		}
+	case *metricspb.Metric_Summary:
+		for _, dp := range data.Summary.GetDataPoints() {
+			if noRecordedValue(dp.GetFlags()) {
+				continue
+			}
+			timestamp, attributes := dp.GetTimeUnixNano(), dp.GetAttributes()
+
+			for _, quantile := range dp.GetQuantileValues() {
+				add(name, quantile.GetValue(), "summary", timestamp, attributes,
+					"quantile", strconv.FormatFloat(quantile.GetQuantile(), 'g', -1, 64))
+			}
+			add(name+"_count", float64(dp.GetCount()), "summary", timestamp, attributes)
+			add(name+"_sum", dp.GetSum(), "summary", timestamp, attributes)
+		}
+	}
+
+	return points
+}
+
+// numberValue returns the value of a gauge or sum data point
+func numberValue(dp *metricspb.NumberDataPoint) float64 {
+	if v, ok := dp.GetValue().(*metricspb.NumberDataPoint_AsInt); ok {
+		return float64(v.AsInt)
+	}
+	return dp.GetAsDouble()
+}
+
+// noRecordedValue reports whether a data point is flagged as having no value
+func noRecordedValue(flags uint32) bool {
+	return flags&uint32(metricspb.DataPointFlags_DATA_POINT_FLAGS_NO_RECORDED_VALUE_MASK) != 0
+}
+
+// otlpTracePoints converts exported spans to trace points. Span attributes,
+// kind, status, events and links become point attributes.
+func otlpTracePoints(request *coltracepb.ExportTraceServiceRequest, origin string, now time.Time) []model.DataPoint {
+	var points []model.DataPoint
+
+	for _, resourceSpans := range request.GetResourceSpans() {
+		for _, scopeSpans := range resourceSpans.GetScopeSpans() {
+			resource := newOTLPResource(resourceSpans.GetResource(), scopeSpans.GetScope(), origin)
+
+			for _, span := range scopeSpans.GetSpans() {
+				start := unixNano(span.GetStartTimeUnixNano(), now)
+
+				point := &model.TracePoint{
+					BaseDataPoint: resource.base(start),
+					TraceID:       hex.EncodeToString(span.GetTraceId()),
+					SpanID:        hex.EncodeToString(span.GetSpanId()),
+					ParentSpanID:  hex.EncodeToString(span.GetParentSpanId()),
+					Name:          span.GetName(),
+					StartTime:     start,
+					EndTime:       unixNano(span.GetEndTimeUnixNano(), start),
+					Attributes:    attributeMap(span.GetAttributes()),
+				}
+
+				if kind := span.GetKind(); kind != tracepb.Span_SPAN_KIND_UNSPECIFIED {
+					point.Attributes["span.kind"] = strings.ToLower(strings.TrimPrefix(kind.String(), "SPAN_KIND_"))
+				}
+				if code := span.GetStatus().GetCode(); code != tracepb.Status_STATUS_CODE_UNSET {
+					point.Attributes["status.code"] = strings.ToLower(strings.TrimPrefix(code.String(), "STATUS_CODE_"))
+				}
+				if message := span.GetStatus().GetMessage(); message != "" {
+					point.Attributes["status.message"] = message
+				}
+				if state := span.GetTraceState(); state != "" {
+					point.Attributes["trace_state"] = state
+				}
+				if events := spanEvents(span.GetEvents()); len(events) > 0 {
+					point.Attributes["events"] = events
+				}
+				if links := spanLinks(span.GetLinks()); len(links) > 0 {
+					point.Attributes["links"] = links
+				}
+
+				points = append(points, point)
+			}
+		}
+	}
+
+	return points
+}
+
+// spanEvents converts span events to attribute values
+func spanEvents(events []*tracepb.Span_Event) []interface{} {
+	var result []interface{}
+	for _, event := range events {
+		result = append(result, map[string]interface{}{
+			"name":       event.GetName(),
+			"time":       unixNano(event.GetTimeUnixNano(), time.Time{}).UTC().Format(time.RFC3339Nano),
+			"attributes": attributeMap(event.GetAttributes()),
+		})
+	}
+	return result
+}
+
+// spanLinks converts span links to attribute values
+func spanLinks(links []*tracepb.Span_Link) []interface{} {
+	var result []interface{}
+	for _, link := range links {
+		result = append(result, map[string]interface{}{
+			"trace_id":   hex.EncodeToString(link.GetTraceId()),
+			"span_id":    hex.EncodeToString(link.GetSpanId()),
+			"attributes": attributeMap(link.GetAttributes()),
+		})
+	}
+	return result
+}
+
+// unixNano converts an OTLP timestamp, using fallback when it is unset
+func unixNano(nanos uint64, fallback time.Time) time.Time {
+	if nanos == 0 || nanos > math.MaxInt64 {
+		return fallback
+	}
+	return time.Unix(0, int64(nanos))
+}
+
+// attributeMap converts OTLP attributes to plain values
+func attributeMap(attributes []*commonpb.KeyValue) map[string]interface{} {
+	result := make(map[string]interface{}, len(attributes))
+	for _, kv := range attributes {
+		result[kv.GetKey()] = anyValue(kv.GetValue())
+	}
+	return result
+}
+
+// anyValue converts an OTLP value to a string, bool, int64, float64, slice or
+// map. Bytes become base64 strings.
+func anyValue(value *commonpb.AnyValue) interface{} {
+	switch v := value.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return v.StringValue
+	case *commonpb.AnyValue_BoolValue:
+		return v.BoolValue
+	case *commonpb.AnyValue_IntValue:
+		return v.IntValue
+	case *commonpb.AnyValue_DoubleValue:
+		return v.DoubleValue
+	case *commonpb.AnyValue_BytesValue:
+		return base64.StdEncoding.EncodeToString(v.BytesValue)
+	case *commonpb.AnyValue_ArrayValue:
+		values := make([]interface{}, 0, len(v.ArrayValue.GetValues()))
+		for _, item := range v.ArrayValue.GetValues() {
+			values = append(values, anyValue(item))
+		}
+		return values
+	case *commonpb.AnyValue_KvlistValue:
+		return attributeMap(v.KvlistValue.GetValues())
+	default:
+		return nil
+	}
+}
+
+// labelValue formats an attribute value as a label, encoding slices and maps as JSON
+func labelValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	case []interface{}, map[string]interface{}:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// otlpIDFields are the JSON fields that OTLP encodes as hex rather than base64
+var otlpIDFields = map[string]bool{
+	"traceId": true, "spanId": true, "parentSpanId": true,
+	"trace_id": true, "span_id": true, "parent_span_id": true,
+}
+
+// fixOTLPJSONIDs rewrites the hex trace and span IDs of OTLP/JSON as the
+// base64 that protojson expects for bytes fields
+func fixOTLPJSONIDs(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			if s, ok := item.(string); ok && otlpIDFields[k] {
+				if id, err := hex.DecodeString(s); err == nil {
+					v[k] = base64.StdEncoding.EncodeToString(id)
+				}
+				continue
+			}
+			fixOTLPJSONIDs(item)
+		}
+	case []interface{}:
+		for _, item := range v {
+			fixOTLPJSONIDs(item)
+		}
+	}
+}
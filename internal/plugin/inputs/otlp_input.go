@@ -0,0 +1,337 @@
+package inputs
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sliink/collector/internal/model"
+	"github.com/sliink/collector/internal/plugin"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// OTLP/HTTP content types
+const (
+	otlpProtobuf = "application/x-protobuf"
+	otlpJSON     = "application/json"
+)
+
+// OTLPInput receives OpenTelemetry logs, metrics and traces over OTLP/HTTP
+// in protobuf or JSON encoding
+type OTLPInput struct {
+	plugin.BasePlugin
+	otlpInputSettings
+	server   *http.Server
+	listener net.Listener
+	queue    *pushQueue
+	mutex    sync.Mutex
+}
+
+// otlpInputSettings holds the OTLP input configuration
+type otlpInputSettings struct {
+	address     string
+	maxBodySize int64
+	maxPending  int
+	tlsConfig   *tls.Config
+}
+
+// otlpSignal decodes and converts the export requests of one signal
+type otlpSignal struct {
+	dataType model.TelemetryType
+	// newRequest and newResponse create empty export messages
+	newRequest  func() proto.Message
+	newResponse func() proto.Message
+	// convert turns a decoded request into points
+	convert func(request proto.Message, origin string, now time.Time) []model.DataPoint
+}
+
+// otlpSignals maps OTLP/HTTP paths to their signals
+var otlpSignals = map[string]otlpSignal{
+	"/v1/logs": {
+		dataType:    model.LogTelemetryType,
+		newRequest:  func() proto.Message { return &collogspb.ExportLogsServiceRequest{} },
+		newResponse: func() proto.Message { return &collogspb.ExportLogsServiceResponse{} },
+		convert: func(request proto.Message, origin string, now time.Time) []model.DataPoint {
+			return otlpLogPoints(request.(*collogspb.ExportLogsServiceRequest), origin, now)
+		},
+	},
+	"/v1/metrics": {
+		dataType:    model.MetricTelemetryType,
+		newRequest:  func() proto.Message { return &colmetricspb.ExportMetricsServiceRequest{} },
+		newResponse: func() proto.Message { return &colmetricspb.ExportMetricsServiceResponse{} },
+		convert: func(request proto.Message, origin string, now time.Time) []model.DataPoint {
+			return otlpMetricPoints(request.(*colmetricspb.ExportMetricsServiceRequest), origin, now)
+		},
+	},
+	"/v1/traces": {
+		dataType:    model.TraceTelemetryType,
+		newRequest:  func() proto.Message { return &coltracepb.ExportTraceServiceRequest{} },
+		newResponse: func() proto.Message { return &coltracepb.ExportTraceServiceResponse{} },
+		convert: func(request proto.Message, origin string, now time.Time) []model.DataPoint {
+			return otlpTracePoints(request.(*coltracepb.ExportTraceServiceRequest), origin, now)
+		},
+	},
+}
+
+func init() {
+	plugin.RegisterStandardInput("otlp", func(id string) model.InputPlugin {
+		return NewOTLPInput(id)
+	})
+}
+
+// NewOTLPInput creates a new OTLP input plugin
+func NewOTLPInput(id string) *OTLPInput {
+	return &OTLPInput{
+		BasePlugin: plugin.NewBasePlugin(id, "OTLP Input", model.InputPluginType),
+	}
+}
+
+// parseOTLPInputSettings reads the OTLP input configuration
+func parseOTLPInputSettings(config map[string]interface{}) (otlpInputSettings, error) {
+	settings := otlpInputSettings{
+		address:     "localhost:4318",
+		maxBodySize: 20 * 1024 * 1024,
+		maxPending:  100000,
+	}
+
+	if address, ok := config["address"].(string); ok && address != "" {
+		settings.address = address
+	}
+
+	if size, ok := plugin.IntOption(config["max_body_size"]); ok {
+		if size <= 0 {
+			return settings, fmt.Errorf("max_body_size must be positive")
+		}
+		settings.maxBodySize = int64(size)
+	}
+
+	if size, ok := plugin.IntOption(config["max_pending"]); ok {
+		if size <= 0 {
+			return settings, fmt.Errorf("max_pending must be positive")
+		}
+		settings.maxPending = int(size)
+	}
+
+	if tlsConf, ok := config["tls"].(map[string]interface{}); ok {
+		tlsConfig, err := plugin.ServerTLSConfig(tlsConf)
+		if err != nil {
+			return settings, err
+		}
+		settings.tlsConfig = tlsConfig
+	}
+
+	return settings, nil
+}
+
+// Initialize prepares the OTLP input for operation
+func (o *OTLPInput) Initialize() bool {
+	settings, err := parseOTLPInputSettings(o.Config)
+	if err != nil {
+		return false
+	}
+	o.otlpInputSettings = settings
+	o.queue = newPushQueue(settings.maxPending)
+
+	o.SetStatus(model.StatusInitialized)
+	return true
+}
+
+// Validate checks if the OTLP input is properly configured
+func (o *OTLPInput) Validate() bool {
+	_, err := parseOTLPInputSettings(o.Config)
+	return err == nil
+}
+
+// Start begins listening for export requests
+func (o *OTLPInput) Start() bool {
+	listener, err := net.Listen("tcp", o.address)
+	if err != nil {
+		return false
+	}
+
+	if o.tlsConfig != nil {
+		listener = tls.NewListener(listener, o.tlsConfig)
+	}
+
+	mux := http.NewServeMux()
+	for path := range otlpSignals {
+		mux.Handle(path, o)
+	}
+
+	o.mutex.Lock()
+	o.listener = listener
+	o.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	server := o.server
+	o.mutex.Unlock()
+
+	go server.Serve(listener)
+
+	o.SetStatus(model.StatusRunning)
+	return true
+}
+
+// Stop shuts down the server, waiting briefly for requests in progress
+func (o *OTLPInput) Stop() bool {
+	o.mutex.Lock()
+	server := o.server
+	o.server = nil
+	o.mutex.Unlock()
+
+	if server != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+	}
+
+	o.SetStatus(model.StatusStopped)
+	return true
+}
+
+// Collect returns the points received since the last call, batched by type
+func (o *OTLPInput) Collect() []*model.DataBatch {
+	if o.GetStatus() != model.StatusRunning {
+		return nil
+	}
+
+	return o.queue.Drain(o.ID())
+}
+
+// ServeHTTP handles an export request for the signal of its path
+func (o *OTLPInput) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	signal, exists := otlpSignals[r.URL.Path]
+	if !exists {
+		http.NotFound(w, r)
+		return
+	}
+
+	contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if contentType != otlpProtobuf && contentType != otlpJSON {
+		writeOTLPError(w, otlpJSON, http.StatusUnsupportedMediaType, "unsupported content type: "+contentType)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeOTLPError(w, contentType, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	// Ask the sender to retry later when outputs cannot keep up
+	if backpressured(o.Core(), o.ID()) {
+		w.Header().Set("Retry-After", "1")
+		writeOTLPError(w, contentType, http.StatusTooManyRequests, "output buffers are full")
+		return
+	}
+
+	body, status, err := openBody(w, r, o.maxBodySize)
+	if err != nil {
+		writeOTLPError(w, contentType, status, err.Error())
+		return
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		if isBodyTooLarge(err) {
+			writeOTLPError(w, contentType, http.StatusRequestEntityTooLarge, "body exceeds max_body_size")
+			return
+		}
+		writeOTLPError(w, contentType, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	request := signal.newRequest()
+	if err := unmarshalOTLP(contentType, data, request); err != nil {
+		writeOTLPError(w, contentType, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	points := signal.convert(request, clientHost(r), time.Now())
+	if !o.queue.Add(signal.dataType, points) {
+		w.Header().Set("Retry-After", "1")
+		writeOTLPError(w, contentType, http.StatusTooManyRequests, "too many pending points")
+		return
+	}
+
+	writeOTLP(w, contentType, http.StatusOK, signal.newResponse())
+}
+
+// unmarshalOTLP decodes an export request in protobuf or OTLP/JSON encoding
+func unmarshalOTLP(contentType string, data []byte, request proto.Message) error {
+	if contentType == otlpProtobuf {
+		if err := proto.Unmarshal(data, request); err != nil {
+			return fmt.Errorf("invalid protobuf payload: %w", err)
+		}
+		return nil
+	}
+
+	// OTLP/JSON writes trace and span IDs as hex instead of base64
+	var generic interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&generic); err != nil {
+		return fmt.Errorf("invalid JSON payload: %w", err)
+	}
+	fixOTLPJSONIDs(generic)
+
+	data, err := json.Marshal(generic)
+	if err != nil {
+		return fmt.Errorf("invalid JSON payload: %w", err)
+	}
+
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, request); err != nil {
+		return fmt.Errorf("invalid JSON payload: %w", err)
+	}
+	return nil
+}
+
+// writeOTLP writes a message in the request's encoding
+func writeOTLP(w http.ResponseWriter, contentType string, code int, message proto.Message) {
+	var data []byte
+	if contentType == otlpProtobuf {
+		data, _ = proto.Marshal(message)
+	} else {
+		data, _ = protojson.Marshal(message)
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(code)
+	w.Write(data)
+}
+
+// writeOTLPError writes a google.rpc.Status error as OTLP/HTTP requires
+func writeOTLPError(w http.ResponseWriter, contentType string, code int, message string) {
+	writeOTLP(w, contentType, code, &status.Status{Code: int32(grpcCode(code)), Message: message})
+}
+
+// grpcCode returns the gRPC status code matching an HTTP status
+func grpcCode(code int) int {
+	switch code {
+	case http.StatusBadRequest, http.StatusUnsupportedMediaType:
+		return 3 // INVALID_ARGUMENT
+	case http.StatusRequestEntityTooLarge:
+		return 11 // OUT_OF_RANGE
+	case http.StatusTooManyRequests:
+		return 8 // RESOURCE_EXHAUSTED
+	case http.StatusMethodNotAllowed:
+		return 12 // UNIMPLEMENTED
+	default:
+		return 2 // UNKNOWN
+	}
+}
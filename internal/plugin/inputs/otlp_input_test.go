@@ -0,0 +1,282 @@
+package inputs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sliink/collector/internal/model"
+	"github.com/stretchr/testify/assert"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func stringAttribute(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}}}
+}
+
+func intAttribute(key string, value int64) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: value}}}
+}
+
+var (
+	testResource = &resourcepb.Resource{Attributes: []*commonpb.KeyValue{
+		stringAttribute("service.name", "checkout"),
+		stringAttribute("host.name", "node-1"),
+	}}
+	testScope = &commonpb.InstrumentationScope{Name: "io.opentelemetry.http", Version: "1.2.0"}
+	testTime  = time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+)
+
+func postOTLP(input *OTLPInput, path string, contentType string, body []byte) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+	request.Header.Set("Content-Type", contentType)
+	recorder := httptest.NewRecorder()
+	input.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func marshalProto(t *testing.T, message proto.Message) []byte {
+	data, err := proto.Marshal(message)
+	assert.NoError(t, err)
+	return data
+}
+
+func TestOTLPInputValidate(t *testing.T) {
+	for _, config := range []map[string]interface{}{
+		{"max_body_size": float64(-1)},
+		{"max_pending": float64(0)},
+		{"tls": map[string]interface{}{}},
+	} {
+		input := NewOTLPInput("otlp_input")
+		input.Configure(config)
+		assert.False(t, input.Validate(), "%v", config)
+	}
+}
+
+func TestOTLPInputLogs(t *testing.T) {
+	input := startTestInput(t, NewOTLPInput("otlp_input"), map[string]interface{}{"address": "127.0.0.1:0"})
+
+	request := &collogspb.ExportLogsServiceRequest{ResourceLogs: []*logspb.ResourceLogs{{
+		Resource: testResource,
+		ScopeLogs: []*logspb.ScopeLogs{{
+			Scope: testScope,
+			LogRecords: []*logspb.LogRecord{
+				{
+					TimeUnixNano:   uint64(testTime.UnixNano()),
+					SeverityNumber: logspb.SeverityNumber_SEVERITY_NUMBER_WARN2,
+					SeverityText:   "Warning",
+					Body:           &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "payment slow"}},
+					Attributes:     []*commonpb.KeyValue{intAttribute("http.status_code", 200)},
+					TraceId:        []byte{0x5b, 0x8e, 0xff, 0xf7, 0x98, 0x03, 0x81, 0x03, 0xd2, 0x69, 0xb6, 0x33, 0x81, 0x3f, 0xc6, 0x0c},
+					SpanId:         []byte{0xee, 0xe1, 0x9b, 0x7e, 0xc3, 0xc1, 0xb1, 0x74},
+				},
+				{
+					ObservedTimeUnixNano: uint64(testTime.UnixNano()),
+					Body: &commonpb.AnyValue{Value: &commonpb.AnyValue_KvlistValue{KvlistValue: &commonpb.KeyValueList{
+						Values: []*commonpb.KeyValue{stringAttribute("event", "login")},
+					}}},
+				},
+			},
+		}},
+	}}}
+
+	response := postOTLP(input, "/v1/logs", otlpProtobuf, marshalProto(t, request))
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, otlpProtobuf, response.Header().Get("Content-Type"))
+	assert.NoError(t, proto.Unmarshal(response.Body.Bytes(), &collogspb.ExportLogsServiceResponse{}))
+
+	batches := input.Collect()
+	assert.Len(t, batches, 1)
+	assert.Equal(t, model.LogTelemetryType, batches[0].BatchType)
+	assert.Len(t, batches[0].Points, 2)
+
+	first := batches[0].Points[0].(*model.LogPoint)
+	assert.Equal(t, "payment slow", first.Message)
+	assert.Equal(t, "WARN", first.Level)
+	assert.Equal(t, "checkout", first.Origin)
+	assert.True(t, first.Timestamp.Equal(testTime))
+	assert.Equal(t, map[string]string{
+		"source":             "otlp",
+		"service.name":       "checkout",
+		"host.name":          "node-1",
+		"otel.scope.name":    "io.opentelemetry.http",
+		"otel.scope.version": "1.2.0",
+	}, first.Labels)
+	assert.Equal(t, int64(200), first.Attributes["http.status_code"])
+	assert.Equal(t, "5b8efff798038103d269b633813fc60c", first.Attributes["trace_id"])
+	assert.Equal(t, "eee19b7ec3c1b174", first.Attributes["span_id"])
+	assert.Equal(t, 14, first.Attributes["severity_number"])
+
+	second := batches[0].Points[1].(*model.LogPoint)
+	assert.Equal(t, `{"event":"login"}`, second.Message)
+	assert.Equal(t, "INFO", second.Level)
+	assert.True(t, second.Timestamp.Equal(testTime))
+}
+
+func TestOTLPInputJSON(t *testing.T) {
+	input := startTestInput(t, NewOTLPInput("otlp_input"), map[string]interface{}{"address": "127.0.0.1:0"})
+
+	body := `{"resourceSpans": [{
+		"resource": {"attributes": [{"key": "service.name", "value": {"stringValue": "frontend"}}]},
+		"scopeSpans": [{"spans": [{
+			"traceId": "5b8efff798038103d269b633813fc60c",
+			"spanId": "eee19b7ec3c1b174",
+			"parentSpanId": "eee19b7ec3c1b173",
+			"name": "GET /cart",
+			"kind": 2,
+			"startTimeUnixNano": "1714557600000000000",
+			"endTimeUnixNano": 1714557600250000000,
+			"attributes": [{"key": "http.method", "value": {"stringValue": "GET"}}],
+			"status": {"code": 2, "message": "timeout"},
+			"events": [{"name": "retry", "timeUnixNano": "1714557600100000000"}],
+			"unknownField": true
+		}]}]
+	}]}`
+
+	response := postOTLP(input, "/v1/traces", "application/json; charset=utf-8", []byte(body))
+	assert.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	assert.Equal(t, otlpJSON, response.Header().Get("Content-Type"))
+
+	batches := input.Collect()
+	assert.Len(t, batches, 1)
+	assert.Equal(t, model.TraceTelemetryType, batches[0].BatchType)
+
+	span := batches[0].Points[0].(*model.TracePoint)
+	assert.Equal(t, "5b8efff798038103d269b633813fc60c", span.TraceID)
+	assert.Equal(t, "eee19b7ec3c1b174", span.SpanID)
+	assert.Equal(t, "eee19b7ec3c1b173", span.ParentSpanID)
+	assert.Equal(t, "GET /cart", span.Name)
+	assert.Equal(t, "frontend", span.Origin)
+	assert.True(t, span.StartTime.Equal(testTime))
+	assert.Equal(t, 250*time.Millisecond, span.EndTime.Sub(span.StartTime))
+	assert.Equal(t, "GET", span.Attributes["http.method"])
+	assert.Equal(t, "server", span.Attributes["span.kind"])
+	assert.Equal(t, "error", span.Attributes["status.code"])
+	assert.Equal(t, "timeout", span.Attributes["status.message"])
+	assert.Len(t, span.Attributes["events"], 1)
+}
+
+func TestOTLPInputMetrics(t *testing.T) {
+	input := startTestInput(t, NewOTLPInput("otlp_input"), map[string]interface{}{"address": "127.0.0.1:0"})
+	timestamp := uint64(testTime.UnixNano())
+	sum := 12.5
+
+	request := &colmetricspb.ExportMetricsServiceRequest{ResourceMetrics: []*metricspb.ResourceMetrics{{
+		Resource: testResource,
+		ScopeMetrics: []*metricspb.ScopeMetrics{{
+			Scope: testScope,
+			Metrics: []*metricspb.Metric{
+				{Name: "queue_depth", Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{DataPoints: []*metricspb.NumberDataPoint{
+					{TimeUnixNano: timestamp, Value: &metricspb.NumberDataPoint_AsInt{AsInt: 7}, Attributes: []*commonpb.KeyValue{stringAttribute("queue", "orders")}},
+					{TimeUnixNano: timestamp, Flags: uint32(metricspb.DataPointFlags_DATA_POINT_FLAGS_NO_RECORDED_VALUE_MASK)},
+				}}}},
+				{Name: "requests_total", Data: &metricspb.Metric_Sum{Sum: &metricspb.Sum{IsMonotonic: true, DataPoints: []*metricspb.NumberDataPoint{
+					{TimeUnixNano: timestamp, Value: &metricspb.NumberDataPoint_AsDouble{AsDouble: 42}},
+				}}}},
+				{Name: "latency", Data: &metricspb.Metric_Histogram{Histogram: &metricspb.Histogram{DataPoints: []*metricspb.HistogramDataPoint{
+					{TimeUnixNano: timestamp, Count: 5, Sum: &sum, BucketCounts: []uint64{1, 3, 1}, ExplicitBounds: []float64{0.5, 2.5}},
+				}}}},
+				{Name: "rpc_duration", Data: &metricspb.Metric_Summary{Summary: &metricspb.Summary{DataPoints: []*metricspb.SummaryDataPoint{
+					{TimeUnixNano: timestamp, Count: 3, Sum: 9, QuantileValues: []*metricspb.SummaryDataPoint_ValueAtQuantile{{Quantile: 0.99, Value: 4}}},
+				}}}},
+			},
+		}},
+	}}}
+
+	response := postOTLP(input, "/v1/metrics", otlpProtobuf, marshalProto(t, request))
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	batches := input.Collect()
+	assert.Len(t, batches, 1)
+
+	type series struct {
+		name, metricType string
+		value            float64
+		dimensions       map[string]string
+	}
+	var got []series
+	for _, point := range batches[0].Points {
+		metric := point.(*model.MetricPoint)
+		assert.Equal(t, "checkout", metric.Origin)
+		assert.True(t, metric.Timestamp.Equal(testTime))
+		got = append(got, series{metric.Name, metric.MetricType, metric.Value, metric.Dimensions})
+	}
+
+	assert.Equal(t, []series{
+		{"queue_depth", "gauge", 7, map[string]string{"queue": "orders"}},
+		{"requests_total", "counter", 42, map[string]string{}},
+		{"latency_bucket", "histogram", 1, map[string]string{"le": "0.5"}},
+		{"latency_bucket", "histogram", 4, map[string]string{"le": "2.5"}},
+		{"latency_bucket", "histogram", 5, map[string]string{"le": "+Inf"}},
+		{"latency_count", "histogram", 5, map[string]string{}},
+		{"latency_sum", "histogram", 12.5, map[string]string{}},
+		{"rpc_duration", "summary", 4, map[string]string{"quantile": "0.99"}},
+		{"rpc_duration_count", "summary", 3, map[string]string{}},
+		{"rpc_duration_sum", "summary", 9, map[string]string{}},
+	}, got)
+}
+
+func TestOTLPInputErrors(t *testing.T) {
+	input := startTestInput(t, NewOTLPInput("otlp_input"), map[string]interface{}{"address": "127.0.0.1:0", "max_pending": float64(1), "max_body_size": float64(1024)})
+
+	t.Run("Bad requests return a status message", func(t *testing.T) {
+		response := postOTLP(input, "/v1/logs", otlpProtobuf, []byte("not protobuf"))
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		var errStatus status.Status
+		assert.NoError(t, proto.Unmarshal(response.Body.Bytes(), &errStatus))
+		assert.Contains(t, errStatus.Message, "invalid protobuf payload")
+
+		assert.Equal(t, http.StatusBadRequest, postOTLP(input, "/v1/logs", otlpJSON, []byte("{")).Code)
+		assert.Equal(t, http.StatusUnsupportedMediaType, postOTLP(input, "/v1/logs", "text/plain", []byte("{}")).Code)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, postOTLP(input, "/v1/logs", otlpJSON, bytes.Repeat([]byte(" "), 2048)).Code)
+	})
+
+	t.Run("Backpressure returns 429", func(t *testing.T) {
+		core := &testCore{full: true}
+		input.RegisterWithCore(core)
+		defer input.RegisterWithCore(nil)
+
+		response := postOTLP(input, "/v1/logs", otlpJSON, []byte("{}"))
+		assert.Equal(t, http.StatusTooManyRequests, response.Code)
+		assert.Equal(t, "1", response.Header().Get("Retry-After"))
+	})
+
+	t.Run("Too many pending points return 429", func(t *testing.T) {
+		body := `{"resourceLogs": [{"scopeLogs": [{"logRecords": [{"body": {"stringValue": "a"}}, {"body": {"stringValue": "b"}}]}]}]}`
+		assert.Equal(t, http.StatusTooManyRequests, postOTLP(input, "/v1/logs", otlpJSON, []byte(body)).Code)
+		assert.Empty(t, input.Collect())
+	})
+
+	t.Run("Gzipped requests over the wire", func(t *testing.T) {
+		var body bytes.Buffer
+		writer := gzip.NewWriter(&body)
+		writer.Write([]byte(`{"resourceLogs": [{"scopeLogs": [{"logRecords": [{"body": {"stringValue": "zipped"}}]}]}]}`))
+		writer.Close()
+
+		request, err := http.NewRequest(http.MethodPost, "http://"+input.listener.Addr().String()+"/v1/logs", &body)
+		assert.NoError(t, err)
+		request.Header.Set("Content-Type", otlpJSON)
+		request.Header.Set("Content-Encoding", "gzip")
+
+		response, err := http.DefaultClient.Do(request)
+		assert.NoError(t, err)
+		response.Body.Close()
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+
+		batches := input.Collect()
+		assert.Len(t, batches, 1)
+		point := batches[0].Points[0].(*model.LogPoint)
+		assert.Equal(t, "zipped", point.Message)
+		assert.Equal(t, "127.0.0.1", point.Origin)
+	})
+}
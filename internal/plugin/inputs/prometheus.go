@@ -0,0 +1,228 @@
+package inputs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// promSample is one sample of the Prometheus text exposition format
+type promSample struct {
+	name       string
+	labels     map[string]string
+	value      float64
+	metricType string
+	timestamp  time.Time // Zero unless the sample carries its own timestamp
+}
+
+// promTypes are the metric types a TYPE line can declare
+var promTypes = map[string]bool{
+	"counter": true, "gauge": true, "histogram": true, "summary": true, "untyped": true,
+}
+
+// parsePrometheusText parses the Prometheus text exposition format. Samples
+// take the type declared for their metric family, so the _bucket, _count and
+// _sum series of a histogram are typed "histogram"; samples of undeclared
+// families are "untyped". Any malformed line fails the whole exposition, as
+// it does for Prometheus.
+func parsePrometheusText(r io.Reader) ([]promSample, error) {
+	var samples []promSample
+	types := make(map[string]string)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "#") {
+			fields := strings.Fields(line[1:])
+			if len(fields) >= 3 && fields[0] == "TYPE" {
+				if !promTypes[fields[2]] {
+					return nil, fmt.Errorf("line %d: unknown metric type: %s", lineNumber, fields[2])
+				}
+				types[fields[1]] = fields[2]
+			}
+			// HELP and other comments carry nothing a metric point can hold
+			continue
+		}
+
+		sample, err := parsePromSample(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		sample.metricType = promSampleType(sample.name, types)
+		samples = append(samples, sample)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return samples, nil
+}
+
+// promSampleType returns the declared type of the family a sample belongs to
+func promSampleType(name string, types map[string]string) string {
+	if metricType, ok := types[name]; ok {
+		return metricType
+	}
+
+	for _, suffix := range []string{"_bucket", "_count", "_sum"} {
+		family := strings.TrimSuffix(name, suffix)
+		if family == name {
+			continue
+		}
+		metricType := types[family]
+		if metricType == "histogram" || (metricType == "summary" && suffix != "_bucket") {
+			return metricType
+		}
+	}
+
+	return "untyped"
+}
+
+// parsePromSample parses a line of the form name{label="value",...} value [timestamp]
+func parsePromSample(line string) (promSample, error) {
+	sample := promSample{labels: make(map[string]string)}
+
+	end := strings.IndexAny(line, "{ \t")
+	if end == -1 {
+		return sample, fmt.Errorf("missing value")
+	}
+	sample.name = line[:end]
+	if !isPromName(sample.name) {
+		return sample, fmt.Errorf("invalid metric name: %q", sample.name)
+	}
+	rest := line[end:]
+
+	if strings.HasPrefix(rest, "{") {
+		var err error
+		rest, err = parsePromLabels(rest[1:], sample.labels)
+		if err != nil {
+			return sample, err
+		}
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 || len(fields) > 2 {
+		return sample, fmt.Errorf("expected a value and optional timestamp, got %q", strings.TrimSpace(rest))
+	}
+
+	value, err := parsePromValue(fields[0])
+	if err != nil {
+		return sample, err
+	}
+	sample.value = value
+
+	if len(fields) == 2 {
+		ms, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return sample, fmt.Errorf("invalid timestamp: %s", fields[1])
+		}
+		sample.timestamp = time.UnixMilli(ms)
+	}
+
+	return sample, nil
+}
+
+// parsePromLabels parses labels up to the closing brace into labels,
+// returning the rest of the line
+func parsePromLabels(s string, labels map[string]string) (string, error) {
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if strings.HasPrefix(s, "}") {
+			return s[1:], nil
+		}
+
+		eq := strings.IndexByte(s, '=')
+		if eq == -1 {
+			return "", fmt.Errorf("invalid label set")
+		}
+		name := strings.TrimSpace(s[:eq])
+		if !isPromLabelName(name) {
+			return "", fmt.Errorf("invalid label name: %q", name)
+		}
+
+		s = strings.TrimLeft(s[eq+1:], " \t")
+		if !strings.HasPrefix(s, `"`) {
+			return "", fmt.Errorf("label %s: value must be quoted", name)
+		}
+
+		// Read the value up to its closing quote, unescaping \\, \" and \n
+		var value strings.Builder
+		i := 1
+		for ; i < len(s) && s[i] != '"'; i++ {
+			if s[i] == '\\' && i+1 < len(s) {
+				i++
+				switch s[i] {
+				case 'n':
+					value.WriteByte('\n')
+				case '\\', '"':
+					value.WriteByte(s[i])
+				default:
+					value.WriteByte('\\')
+					value.WriteByte(s[i])
+				}
+				continue
+			}
+			value.WriteByte(s[i])
+		}
+		if i >= len(s) {
+			return "", fmt.Errorf("label %s: unterminated value", name)
+		}
+		labels[name] = value.String()
+
+		s = strings.TrimLeft(s[i+1:], " \t")
+		if strings.HasPrefix(s, ",") {
+			s = s[1:]
+		} else if !strings.HasPrefix(s, "}") {
+			return "", fmt.Errorf("invalid label set")
+		}
+	}
+}
+
+// parsePromValue parses a sample value, including +Inf, -Inf and NaN
+func parsePromValue(s string) (float64, error) {
+	switch s {
+	case "+Inf", "Inf":
+		return math.Inf(1), nil
+	case "-Inf":
+		return math.Inf(-1), nil
+	case "NaN":
+		return math.NaN(), nil
+	}
+
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value: %s", s)
+	}
+	return value, nil
+}
+
+// isPromName reports whether s is a valid metric name
+func isPromName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		if !(c == '_' || c == ':' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 0 && c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// isPromLabelName reports whether s is a valid label name
+func isPromLabelName(s string) bool {
+	return isPromName(s) && !strings.Contains(s, ":")
+}
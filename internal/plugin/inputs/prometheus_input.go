@@ -0,0 +1,363 @@
+package inputs
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sliink/collector/internal/model"
+	"github.com/sliink/collector/internal/plugin"
+)
+
+// promAccept is the Accept header sent with scrapes, asking for the text format
+const promAccept = "text/plain;version=0.0.4;q=0.9,*/*;q=0.1"
+
+// PrometheusInput scrapes metrics from static targets exposing the Prometheus
+// text format, each on its own interval
+type PrometheusInput struct {
+	plugin.BasePlugin
+	prometheusInputSettings
+	client *http.Client
+	queue  *pushQueue
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	mutex  sync.Mutex
+}
+
+// prometheusInputSettings holds the Prometheus input configuration
+type prometheusInputSettings struct {
+	targets    []promTarget
+	maxPending int
+	tlsConfig  *tls.Config
+}
+
+// promTarget is an endpoint to scrape
+type promTarget struct {
+	url      string
+	interval time.Duration
+	timeout  time.Duration
+	labels   map[string]string // job, instance and the target's static labels
+}
+
+func init() {
+	plugin.RegisterStandardInput("prometheus", func(id string) model.InputPlugin {
+		return NewPrometheusInput(id)
+	})
+}
+
+// NewPrometheusInput creates a new Prometheus scrape input plugin
+func NewPrometheusInput(id string) *PrometheusInput {
+	return &PrometheusInput{
+		BasePlugin: plugin.NewBasePlugin(id, "Prometheus Input", model.InputPluginType),
+	}
+}
+
+// parsePrometheusInputSettings reads the Prometheus input configuration.
+// Targets are URLs or objects with url, interval, timeout and labels; the
+// top-level interval, timeout and job apply to targets that do not set them.
+func parsePrometheusInputSettings(config map[string]interface{}, id string) (prometheusInputSettings, error) {
+	settings := prometheusInputSettings{
+		maxPending: 100000,
+	}
+
+	interval, err := durationOption(config, "interval", 15*time.Second)
+	if err != nil {
+		return settings, err
+	}
+	timeout, err := durationOption(config, "timeout", 10*time.Second)
+	if err != nil {
+		return settings, err
+	}
+
+	job := id
+	if name, ok := config["job"].(string); ok && name != "" {
+		job = name
+	}
+
+	targets, ok := config["targets"].([]interface{})
+	if !ok || len(targets) == 0 {
+		return settings, fmt.Errorf("at least one target is required")
+	}
+
+	for _, item := range targets {
+		options, ok := item.(map[string]interface{})
+		if !ok {
+			options = map[string]interface{}{"url": item}
+		}
+
+		target, err := parsePromTarget(options, job, interval, timeout)
+		if err != nil {
+			return settings, err
+		}
+		settings.targets = append(settings.targets, target)
+	}
+
+	if size, ok := plugin.IntOption(config["max_pending"]); ok {
+		if size <= 0 {
+			return settings, fmt.Errorf("max_pending must be positive")
+		}
+		settings.maxPending = int(size)
+	}
+
+	if tlsConf, ok := config["tls"].(map[string]interface{}); ok {
+		tlsConfig, err := plugin.ClientTLSConfig(tlsConf)
+		if err != nil {
+			return settings, err
+		}
+		settings.tlsConfig = tlsConfig
+	}
+
+	return settings, nil
+}
+
+// parsePromTarget reads one target. A URL without a scheme is scraped over
+// HTTP, and one without a path at /metrics.
+func parsePromTarget(options map[string]interface{}, job string, interval, timeout time.Duration) (promTarget, error) {
+	rawURL, _ := options["url"].(string)
+	if rawURL == "" {
+		return promTarget{}, fmt.Errorf("target url is required")
+	}
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return promTarget{}, fmt.Errorf("invalid target url: %s", rawURL)
+	}
+	if u.Path == "" {
+		u.Path = "/metrics"
+	}
+
+	target := promTarget{
+		url:    u.String(),
+		labels: map[string]string{"job": job, "instance": u.Host},
+	}
+
+	if target.interval, err = durationOption(options, "interval", interval); err != nil {
+		return target, err
+	}
+	if target.timeout, err = durationOption(options, "timeout", timeout); err != nil {
+		return target, err
+	}
+	// A scrape must finish before the next one is due
+	if target.timeout > target.interval {
+		target.timeout = target.interval
+	}
+
+	if labels, ok := options["labels"].(map[string]interface{}); ok {
+		for name, value := range labels {
+			s, ok := value.(string)
+			if !ok || !isPromLabelName(name) {
+				return target, fmt.Errorf("invalid target label: %s", name)
+			}
+			target.labels[name] = s
+		}
+	}
+
+	return target, nil
+}
+
+// durationOption reads a positive duration option, using fallback when it is missing
+func durationOption(config map[string]interface{}, name string, fallback time.Duration) (time.Duration, error) {
+	value, ok := config[name].(string)
+	if !ok {
+		return fallback, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("invalid %s: %s", name, value)
+	}
+	return duration, nil
+}
+
+// Initialize prepares the Prometheus input for operation
+func (p *PrometheusInput) Initialize() bool {
+	settings, err := parsePrometheusInputSettings(p.Config, p.ID())
+	if err != nil {
+		return false
+	}
+	p.prometheusInputSettings = settings
+	p.queue = newPushQueue(settings.maxPending)
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if settings.tlsConfig != nil {
+		transport.TLSClientConfig = settings.tlsConfig
+	}
+	p.client = &http.Client{Transport: transport}
+
+	p.SetStatus(model.StatusInitialized)
+	return true
+}
+
+// Validate checks if the Prometheus input is properly configured
+func (p *PrometheusInput) Validate() bool {
+	_, err := parsePrometheusInputSettings(p.Config, p.ID())
+	return err == nil
+}
+
+// Start begins scraping every target
+func (p *PrometheusInput) Start() bool {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	p.mutex.Lock()
+	p.cancel = cancel
+	p.mutex.Unlock()
+
+	for _, target := range p.targets {
+		p.wg.Add(1)
+		go p.run(ctx, target)
+	}
+
+	p.SetStatus(model.StatusRunning)
+	return true
+}
+
+// Stop cancels scrapes in progress and waits for them to finish
+func (p *PrometheusInput) Stop() bool {
+	p.mutex.Lock()
+	cancel := p.cancel
+	p.cancel = nil
+	p.mutex.Unlock()
+
+	if cancel != nil {
+		cancel()
+		p.wg.Wait()
+	}
+
+	if p.client != nil {
+		p.client.CloseIdleConnections()
+	}
+
+	p.SetStatus(model.StatusStopped)
+	return true
+}
+
+// Collect returns the points scraped since the last call
+func (p *PrometheusInput) Collect() []*model.DataBatch {
+	if p.GetStatus() != model.StatusRunning {
+		return nil
+	}
+
+	return p.queue.Drain(p.ID())
+}
+
+// run scrapes a target immediately and then on its interval until stopped
+func (p *PrometheusInput) run(ctx context.Context, target promTarget) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(target.interval)
+	defer ticker.Stop()
+
+	for {
+		p.scrape(ctx, target)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// scrape fetches a target and queues its samples along with up and
+// scrape_duration_seconds. A failed scrape reports up as 0 and no samples.
+func (p *PrometheusInput) scrape(ctx context.Context, target promTarget) {
+	start := time.Now()
+	samples, err := p.fetch(ctx, target)
+	duration := time.Since(start)
+
+	// Scrapes cut short by Stop are not reported as failures
+	if ctx.Err() != nil {
+		return
+	}
+
+	up := 1.0
+	if err != nil {
+		up = 0
+		if core := p.Core(); core != nil {
+			core.PublishEvent(model.EventError, p.ID(), fmt.Errorf("failed to scrape %s: %w", target.url, err))
+		}
+	}
+
+	points := make([]model.DataPoint, 0, len(samples)+2)
+	for _, sample := range samples {
+		timestamp := sample.timestamp
+		if timestamp.IsZero() {
+			timestamp = start
+		}
+		points = append(points, target.point(sample.name, sample.value, sample.metricType, timestamp, sample.labels))
+	}
+	points = append(points,
+		target.point("up", up, "gauge", start, nil),
+		target.point("scrape_duration_seconds", duration.Seconds(), "gauge", start, nil),
+	)
+
+	// A full queue drops the scrape; the next one reports fresh values
+	p.queue.Add(model.MetricTelemetryType, points)
+}
+
+// fetch requests a target's metrics and parses them
+func (p *PrometheusInput) fetch(ctx context.Context, target promTarget) ([]promSample, error) {
+	ctx, cancel := context.WithTimeout(ctx, target.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", promAccept)
+	req.Header.Set("X-Prometheus-Scrape-Timeout-Seconds", strconv.FormatFloat(target.timeout.Seconds(), 'f', -1, 64))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return parsePrometheusText(resp.Body)
+}
+
+// point builds a metric point for a target. Sample labels become dimensions
+// next to the target's labels; a sample label that clashes with a target
+// label is kept as exported_<name>, as Prometheus does.
+func (t promTarget) point(name string, value float64, metricType string, timestamp time.Time, labels map[string]string) *model.MetricPoint {
+	dimensions := make(map[string]string, len(labels)+len(t.labels))
+	for k, v := range labels {
+		if _, clash := t.labels[k]; clash {
+			k = "exported_" + k
+		}
+		dimensions[k] = v
+	}
+
+	pointLabels := map[string]string{"source": "prometheus"}
+	for k, v := range t.labels {
+		dimensions[k] = v
+		pointLabels[k] = v
+	}
+
+	return &model.MetricPoint{
+		BaseDataPoint: model.BaseDataPoint{
+			Timestamp: timestamp,
+			Origin:    t.labels["instance"],
+			Labels:    pointLabels,
+		},
+		Name:       name,
+		Value:      value,
+		MetricType: metricType,
+		Dimensions: dimensions,
+	}
+}
@@ -0,0 +1,141 @@
+package inputs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sliink/collector/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// collectMetrics waits for scraped points and returns them by name
+func collectMetrics(t *testing.T, input *PrometheusInput, count int) map[string][]*model.MetricPoint {
+	metrics := make(map[string][]*model.MetricPoint)
+	received := 0
+
+	deadline := time.Now().Add(5 * time.Second)
+	for received < count && time.Now().Before(deadline) {
+		for _, batch := range input.Collect() {
+			for _, point := range batch.Points {
+				metric := point.(*model.MetricPoint)
+				metrics[metric.Name] = append(metrics[metric.Name], metric)
+				received++
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	assert.GreaterOrEqual(t, received, count)
+	return metrics
+}
+
+func TestPrometheusInputValidate(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{},
+		{"targets": []interface{}{}},
+		{"targets": []interface{}{"ftp://localhost:9100"}},
+		{"targets": []interface{}{map[string]interface{}{"interval": "10s"}}},
+		{"targets": []interface{}{"localhost:9100"}, "interval": "often"},
+		{"targets": []interface{}{map[string]interface{}{"url": "localhost:9100", "timeout": "-1s"}}},
+		{"targets": []interface{}{map[string]interface{}{"url": "localhost:9100", "labels": map[string]interface{}{"bad-name": "x"}}}},
+		{"targets": []interface{}{"localhost:9100"}, "max_pending": float64(0)},
+	}
+
+	for _, config := range invalid {
+		input := NewPrometheusInput("prometheus_input")
+		input.Configure(config)
+		assert.False(t, input.Validate(), "%v", config)
+		assert.False(t, input.Initialize(), "%v", config)
+	}
+
+	input := NewPrometheusInput("prometheus_input")
+	input.Configure(map[string]interface{}{
+		"targets": []interface{}{
+			"localhost:9100",
+			map[string]interface{}{"url": "https://db:9187/stats", "interval": "1m", "timeout": "2m"},
+		},
+		"interval": "5s",
+		"job":      "node",
+	})
+	assert.True(t, input.Initialize())
+	assert.Equal(t, "http://localhost:9100/metrics", input.targets[0].url)
+	assert.Equal(t, 5*time.Second, input.targets[0].interval)
+	assert.Equal(t, 5*time.Second, input.targets[0].timeout)
+	assert.Equal(t, map[string]string{"job": "node", "instance": "localhost:9100"}, input.targets[0].labels)
+	assert.Equal(t, "https://db:9187/stats", input.targets[1].url)
+	assert.Equal(t, time.Minute, input.targets[1].interval)
+	assert.Equal(t, time.Minute, input.targets[1].timeout)
+}
+
+func TestPrometheusInputScrape(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/metrics":
+			assert.Contains(t, r.Header.Get("Accept"), "text/plain")
+			w.Write([]byte(`# TYPE http_requests_total counter
+http_requests_total{code="200",job="scraped"} 12
+# TYPE temperature gauge
+temperature 21.5
+`))
+		case "/broken":
+			w.Write([]byte("not a metric line\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	instance := strings.TrimPrefix(server.URL, "http://")
+
+	t.Run("Samples are scraped with up and duration", func(t *testing.T) {
+		input := startTestInput(t, NewPrometheusInput("prometheus_input"), map[string]interface{}{
+			"targets": []interface{}{
+				map[string]interface{}{"url": server.URL, "labels": map[string]interface{}{"env": "test"}},
+			},
+		})
+
+		metrics := collectMetrics(t, input, 4)
+
+		requests := metrics["http_requests_total"][0]
+		assert.Equal(t, 12.0, requests.Value)
+		assert.Equal(t, "counter", requests.MetricType)
+		assert.Equal(t, instance, requests.Origin)
+		assert.Equal(t, map[string]string{
+			"code": "200", "exported_job": "scraped", "job": "prometheus_input", "instance": instance, "env": "test",
+		}, requests.Dimensions)
+		assert.Equal(t, map[string]string{
+			"source": "prometheus", "job": "prometheus_input", "instance": instance, "env": "test",
+		}, requests.Labels)
+
+		assert.Equal(t, "gauge", metrics["temperature"][0].MetricType)
+		assert.Equal(t, 1.0, metrics["up"][0].Value)
+		assert.Greater(t, metrics["scrape_duration_seconds"][0].Value, 0.0)
+	})
+
+	t.Run("Failed scrapes report up as 0", func(t *testing.T) {
+		input := startTestInput(t, NewPrometheusInput("prometheus_input"), map[string]interface{}{
+			"targets": []interface{}{server.URL + "/missing", server.URL + "/broken"},
+		})
+
+		metrics := collectMetrics(t, input, 4)
+		assert.Len(t, metrics, 2)
+		assert.Len(t, metrics["up"], 2)
+		for _, up := range metrics["up"] {
+			assert.Equal(t, 0.0, up.Value)
+		}
+	})
+
+	t.Run("Targets are scraped on their own interval", func(t *testing.T) {
+		input := startTestInput(t, NewPrometheusInput("prometheus_input"), map[string]interface{}{
+			"targets": []interface{}{
+				map[string]interface{}{"url": server.URL, "interval": "50ms"},
+			},
+			"interval": "1h",
+		})
+
+		metrics := collectMetrics(t, input, 12)
+		assert.GreaterOrEqual(t, len(metrics["up"]), 3)
+	})
+}
@@ -0,0 +1,88 @@
+package inputs
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePrometheusText(t *testing.T) {
+	t.Run("Metric types", func(t *testing.T) {
+		samples, err := parsePrometheusText(strings.NewReader(`# HELP http_requests_total Total requests.
+# TYPE http_requests_total counter
+http_requests_total{method="post",code="200"} 1027 1395066363000
+http_requests_total{method="post",code="400"}    3 1395066363000
+
+# TYPE queue_depth gauge
+queue_depth 4.5
+# TYPE request_duration_seconds histogram
+request_duration_seconds_bucket{le="0.1"} 2
+request_duration_seconds_bucket{le="+Inf"} 3
+request_duration_seconds_sum 0.42
+request_duration_seconds_count 3
+# TYPE rpc_duration_seconds summary
+rpc_duration_seconds{quantile="0.5"} 0.05
+rpc_duration_seconds_sum 1.7
+rpc_duration_seconds_count 32
+no_type_declared NaN
+`))
+		assert.NoError(t, err)
+		assert.Len(t, samples, 11)
+
+		assert.Equal(t, "http_requests_total", samples[0].name)
+		assert.Equal(t, map[string]string{"method": "post", "code": "200"}, samples[0].labels)
+		assert.Equal(t, 1027.0, samples[0].value)
+		assert.Equal(t, "counter", samples[0].metricType)
+		assert.Equal(t, time.UnixMilli(1395066363000), samples[0].timestamp)
+
+		assert.Equal(t, "gauge", samples[2].metricType)
+		assert.True(t, samples[2].timestamp.IsZero())
+
+		for _, sample := range samples[3:7] {
+			assert.Equal(t, "histogram", sample.metricType, sample.name)
+		}
+		assert.Equal(t, map[string]string{"le": "+Inf"}, samples[4].labels)
+
+		for _, sample := range samples[7:10] {
+			assert.Equal(t, "summary", sample.metricType, sample.name)
+		}
+
+		assert.Equal(t, "untyped", samples[10].metricType)
+		assert.True(t, math.IsNaN(samples[10].value))
+	})
+
+	t.Run("Label escapes and special values", func(t *testing.T) {
+		samples, err := parsePrometheusText(strings.NewReader(`msdos_file_access_time_seconds{path="C:\\DIR\\FILE.TXT",error="Cannot find file:\n\"FILE.TXT\"",} 1.458255915e9
+min_value -Inf
+max_value +Inf
+`))
+		assert.NoError(t, err)
+		assert.Len(t, samples, 3)
+		assert.Equal(t, `C:\DIR\FILE.TXT`, samples[0].labels["path"])
+		assert.Equal(t, "Cannot find file:\n\"FILE.TXT\"", samples[0].labels["error"])
+		assert.Equal(t, 1.458255915e9, samples[0].value)
+		assert.True(t, math.IsInf(samples[1].value, -1))
+		assert.True(t, math.IsInf(samples[2].value, 1))
+	})
+
+	t.Run("Malformed lines fail the exposition", func(t *testing.T) {
+		for _, text := range []string{
+			"metric",
+			"metric abc",
+			"metric 1 2 3",
+			"metric 1 soon",
+			"1metric 1",
+			`metric{label="unterminated} 1`,
+			`metric{label=unquoted} 1`,
+			`metric{la-bel="value"} 1`,
+			`metric{a="1" b="2"} 1`,
+			"# TYPE metric meter",
+		} {
+			_, err := parsePrometheusText(strings.NewReader(text))
+			assert.Error(t, err, text)
+		}
+	})
+}
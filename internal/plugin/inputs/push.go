@@ -0,0 +1,180 @@
+package inputs
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/sliink/collector/internal/model"
+)
+
+// pushQueue holds points pushed to an input until the next collection. Pushes
+// are accepted whole or not at all, so senders can retry without duplicates.
+type pushQueue struct {
+	points  map[model.TelemetryType][]model.DataPoint
+	count   int
+	max     int
+	drained chan struct{} // Closed by the next Drain
+	mutex   sync.Mutex
+}
+
+// newPushQueue creates a queue holding at most max points
+func newPushQueue(max int) *pushQueue {
+	return &pushQueue{
+		points:  make(map[model.TelemetryType][]model.DataPoint),
+		max:     max,
+		drained: make(chan struct{}),
+	}
+}
+
+// Add queues points of a telemetry type, returning false if they do not fit
+func (q *pushQueue) Add(dataType model.TelemetryType, points []model.DataPoint) bool {
+	added, _ := q.add(dataType, points)
+	return added
+}
+
+// AddWait queues points of a telemetry type, waiting for Drain to make room
+// while they do not fit. It returns the context's error if it is cancelled
+// first.
+func (q *pushQueue) AddWait(ctx context.Context, dataType model.TelemetryType, points []model.DataPoint) error {
+	for {
+		added, drained := q.add(dataType, points)
+		if added {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-drained:
+		}
+	}
+}
+
+// add queues points of a telemetry type if they fit. If they do not, it
+// returns a channel that the next Drain closes.
+func (q *pushQueue) add(dataType model.TelemetryType, points []model.DataPoint) (bool, <-chan struct{}) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.count+len(points) > q.max {
+		return false, q.drained
+	}
+
+	q.points[dataType] = append(q.points[dataType], points...)
+	q.count += len(points)
+	return true, nil
+}
+
+// Drain returns the queued points in batches of at most 1000, waking
+// senders waiting for room
+func (q *pushQueue) Drain(sourceID string) []*model.DataBatch {
+	q.mutex.Lock()
+	queued := q.points
+	q.points = make(map[model.TelemetryType][]model.DataPoint)
+	q.count = 0
+	close(q.drained)
+	q.drained = make(chan struct{})
+	q.mutex.Unlock()
+
+	var results []*model.DataBatch
+	for _, dataType := range []model.TelemetryType{model.LogTelemetryType, model.MetricTelemetryType, model.TraceTelemetryType} {
+		points := queued[dataType]
+		for len(points) > 0 {
+			n := len(points)
+			if n > 1000 {
+				n = 1000
+			}
+
+			batch := model.NewDataBatch(dataType)
+			batch.SourceID = sourceID
+			for _, point := range points[:n] {
+				batch.AddPoint(point)
+			}
+			results = append(results, batch)
+			points = points[n:]
+		}
+	}
+
+	return results
+}
+
+// backpressured reports whether the core wants an input to turn senders away
+func backpressured(core model.CoreAPI, inputID string) bool {
+	backpressure, ok := core.(model.BackpressureAPI)
+	return ok && backpressure.InputBackpressured(inputID)
+}
+
+// clientHost returns the host part of a request's remote address
+func clientHost(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// errBodyTooLarge is returned when a decompressed body exceeds its limit
+var errBodyTooLarge = errors.New("body too large")
+
+// openBody returns a request's body, decompressed if it was gzipped and
+// bounded to maxSize bytes both before and after decompression. On failure
+// it also returns the HTTP status to answer with.
+func openBody(w http.ResponseWriter, r *http.Request, maxSize int64) (io.ReadCloser, int, error) {
+	body := http.MaxBytesReader(w, r.Body, maxSize)
+
+	switch encoding := r.Header.Get("Content-Encoding"); encoding {
+	case "", "identity":
+		return body, http.StatusOK, nil
+	case "gzip":
+		reader, err := gzip.NewReader(body)
+		if err != nil {
+			if isBodyTooLarge(err) {
+				return nil, http.StatusRequestEntityTooLarge, err
+			}
+			return nil, http.StatusBadRequest, fmt.Errorf("invalid gzip body")
+		}
+		return &limitedReader{reader: reader, remaining: maxSize}, http.StatusOK, nil
+	default:
+		return nil, http.StatusUnsupportedMediaType, fmt.Errorf("unsupported content encoding: %s", encoding)
+	}
+}
+
+// isBodyTooLarge reports whether reading a body failed on its size limit
+func isBodyTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr) || errors.Is(err, errBodyTooLarge)
+}
+
+// limitedReader fails once more than the remaining bytes are read
+type limitedReader struct {
+	reader    io.ReadCloser
+	remaining int64
+}
+
+// Read reads from the underlying reader until the limit is passed
+func (l *limitedReader) Read(p []byte) (int, error) {
+	n, err := l.reader.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n, errBodyTooLarge
+	}
+	return n, err
+}
+
+// Close closes the underlying reader
+func (l *limitedReader) Close() error {
+	return l.reader.Close()
+}
+
+// writeHTTPError writes a JSON error response
+func writeHTTPError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
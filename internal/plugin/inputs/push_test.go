@@ -0,0 +1,48 @@
+package inputs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sliink/collector/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPushQueueAddWait(t *testing.T) {
+	queue := newPushQueue(2)
+	points := []model.DataPoint{&model.LogPoint{Message: "one"}, &model.LogPoint{Message: "two"}}
+	assert.NoError(t, queue.AddWait(context.Background(), model.LogTelemetryType, points))
+	assert.False(t, queue.Add(model.LogTelemetryType, points[:1]))
+
+	// A full queue makes the sender wait for the next drain
+	added := make(chan error)
+	go func() {
+		added <- queue.AddWait(context.Background(), model.LogTelemetryType, points[:1])
+	}()
+	select {
+	case <-added:
+		t.Fatal("AddWait returned while the queue was full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	batches := queue.Drain("input")
+	if assert.Len(t, batches, 1) {
+		assert.Len(t, batches[0].Points, 2)
+	}
+	select {
+	case err := <-added:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("AddWait did not return after the drain")
+	}
+
+	// Waiting ends when the context is cancelled
+	assert.True(t, queue.Add(model.LogTelemetryType, points[:1]))
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, queue.AddWait(ctx, model.LogTelemetryType, points), context.DeadlineExceeded)
+	if batches := queue.Drain("input"); assert.Len(t, batches, 1) {
+		assert.Len(t, batches[0].Points, 2)
+	}
+}
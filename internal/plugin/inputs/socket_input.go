@@ -1,11 +1,18 @@
 package inputs
 
 import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"fmt"
 	"net"
+	"os"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/sliink/collector/internal/model"
+	"github.com/sliink/collector/internal/plugin"
 )
 
 // SocketInput represents a socket input plugin for collecting data from network sockets
@@ -13,26 +20,59 @@ type SocketInput struct {
 	id            string
 	status        model.Status
 	config        map[string]interface{}
+	options    socketOptions
 	listener      net.Listener
 	listeners     []net.Listener
+	packetConn net.PacketConn
+	conns      map[net.Conn]struct{}
+	nextConnID uint64
 	mu            sync.Mutex
 	done          chan struct{}
 	statusMu      sync.RWMutex
-	recordsChan   chan model.Record
-	pendingRecords []model.Record
-	recordsMu     sync.Mutex
+	pointsChan chan model.DataPoint
+	// parseFrame lets inputs built on this listener fill in a point from its
+	// frame. Returning false drops the frame.
+	parseFrame func(point *model.LogPoint) bool
+}
+
+// socketOptions are the listening options read from the configuration
+type socketOptions struct {
+	protocol       string
+	address        string
+	split          bufio.SplitFunc
+	bufferSize     int
+	maxBufferSize  int
+	maxPending     int
+	maxConnections int
+	idleTimeout    time.Duration
+	tlsConfig      *tls.Config
+}
+
+const (
+	// defaultMaxFrameSize is the largest frame accepted unless configured otherwise
+	defaultMaxFrameSize = 64 * 1024
+	// defaultMaxPending is how many frames are held between collections
+	defaultMaxPending = 10000
+	// maxDatagramSize is the largest UDP or unixgram datagram read
+	maxDatagramSize = 65536
+)
+
+func init() {
+	plugin.RegisterStandardInput("socket", func(id string) model.InputPlugin {
+		return NewSocketInput(id)
+	})
 }
 
 // NewSocketInput creates a new socket input instance
 func NewSocketInput(id string) *SocketInput {
 	return &SocketInput{
-		id:             id,
-		status:         model.StatusStopped,
-		config:         make(map[string]interface{}),
-		listeners:      make([]net.Listener, 0),
-		done:           make(chan struct{}),
-		recordsChan:    make(chan model.Record, 100),
-		pendingRecords: make([]model.Record, 0),
+		id:         id,
+		status:     model.StatusStopped,
+		config:     make(map[string]interface{}),
+		listeners:  make([]net.Listener, 0),
+		conns:      make(map[net.Conn]struct{}),
+		done:       make(chan struct{}),
+		pointsChan: make(chan model.DataPoint, defaultMaxPending),
 	}
 }
 
@@ -82,23 +122,94 @@ func (p *SocketInput) Validate() bool {
 		return true
 	}
 	
-	// Check for required fields
-	protocol, ok := p.config["protocol"].(string)
-	if !ok || protocol == "" {
-		protocol = "tcp" // Default to TCP if not specified
+	_, err := p.parseOptions()
+	return err == nil
+}
+
+// parseOptions reads the listening options from the configuration. Callers must hold p.mu.
+func (p *SocketInput) parseOptions() (socketOptions, error) {
+	options := socketOptions{
+		protocol:   "tcp",            // Default to TCP if not specified
+		address:    "localhost:8888", // Default address if not specified
+		bufferSize: 4096,
+		maxPending: defaultMaxPending,
 	}
 	
-	address, ok := p.config["address"].(string)
-	if !ok || address == "" {
-		address = "localhost:8888" // Default address if not specified
+	if protocol, ok := p.config["protocol"].(string); ok && protocol != "" {
+		options.protocol = protocol
 	}
 	
-	// Validate protocol is either tcp or udp
-	if protocol != "tcp" && protocol != "udp" {
-		return false
+	if address, ok := p.config["address"].(string); ok && address != "" {
+		options.address = address
 	}
 	
-	return true
+	// Validate protocol
+	switch options.protocol {
+	case "tcp", "udp", "unix", "unixgram":
+	default:
+		return options, fmt.Errorf("invalid protocol: %s", options.protocol)
+	}
+
+	// Validate framing
+	framing, _ := p.config["framing"].(string)
+
+	maxFrameSize := defaultMaxFrameSize
+	if size, ok := plugin.IntOption(p.config["max_frame_size"]); ok {
+		maxFrameSize = int(size)
+	}
+
+	if size, ok := plugin.IntOption(p.config["buffer_size"]); ok && size > 0 {
+		options.bufferSize = int(size)
+	}
+
+	split, err := frameSplitter(framing, maxFrameSize)
+	if err != nil {
+		return options, err
+	}
+	options.split = split
+
+	// Datagrams are messages in their own right when framing is detected, as
+	// syslog senders over UDP do not frame them
+	if framing == FramingAuto && (options.protocol == "udp" || options.protocol == "unixgram") {
+		options.split = splitDatagram(maxFrameSize)
+	}
+
+	// The buffer must hold a whole frame and its header
+	options.maxBufferSize = maxFrameSize + maxOctetCountDigits + 1
+	if options.maxBufferSize < options.bufferSize {
+		options.maxBufferSize = options.bufferSize
+	}
+
+	if size, ok := plugin.IntOption(p.config["max_pending"]); ok && size > 0 {
+		options.maxPending = int(size)
+	}
+
+	if maxConnections, ok := plugin.IntOption(p.config["max_connections"]); ok {
+		if maxConnections < 0 {
+			return options, fmt.Errorf("max_connections must not be negative")
+		}
+		options.maxConnections = int(maxConnections)
+	}
+
+	if timeoutStr, ok := p.config["idle_timeout"].(string); ok {
+		timeout, err := time.ParseDuration(timeoutStr)
+		if err != nil || timeout < 0 {
+			return options, fmt.Errorf("invalid idle_timeout: %s", timeoutStr)
+		}
+		options.idleTimeout = timeout
+	}
+
+	if tlsConf, ok := p.config["tls"].(map[string]interface{}); ok {
+		if options.protocol != "tcp" {
+			return options, fmt.Errorf("tls is only supported for tcp")
+		}
+		options.tlsConfig, err = plugin.ServerTLSConfig(tlsConf)
+		if err != nil {
+			return options, err
+		}
+	}
+
+	return options, nil
 }
 
 // Configure configures the plugin
@@ -124,43 +235,51 @@ func (p *SocketInput) Initialize() bool {
 
 // Start begins plugin operation
 func (p *SocketInput) Start() bool {
-	p.done = make(chan struct{})
-	p.recordsChan = make(chan model.Record, 100)
-	
 	// Get configuration parameters
 	p.mu.Lock()
-	
-	protocol, _ := p.config["protocol"].(string)
-	if protocol == "" {
-		protocol = "tcp"
-	}
-	
-	address, _ := p.config["address"].(string)
-	if address == "" {
-		address = "localhost:8888"
+	options, err := p.parseOptions()
+	if err != nil {
+		p.mu.Unlock()
+		return false
 	}
 	
+	p.options = options
+	p.done = make(chan struct{})
+	p.pointsChan = make(chan model.DataPoint, options.maxPending)
 	p.mu.Unlock()
-	
-	// Start a goroutine to collect records from the channel
-	go func() {
-		for {
-			select {
-			case <-p.done:
-				return
-			case record := <-p.recordsChan:
-				p.recordsMu.Lock()
-				p.pendingRecords = append(p.pendingRecords, record)
-				p.recordsMu.Unlock()
-			}
+
+	// Remove a socket file left behind by a previous run
+	if options.protocol == "unix" || options.protocol == "unixgram" {
+		if info, err := os.Stat(options.address); err == nil && info.Mode()&os.ModeSocket != 0 {
+			os.Remove(options.address)
 		}
-	}()
+	}
 	
+	// Datagram protocols read packets, stream protocols accept connections
+	if options.protocol == "udp" || options.protocol == "unixgram" {
+		packetConn, err := net.ListenPacket(options.protocol, options.address)
+		if err != nil {
+			return false
+		}
+
+		p.mu.Lock()
+		p.packetConn = packetConn
+		p.mu.Unlock()
+
+		p.SetStatus(model.StatusRunning)
+		go p.handlePackets(packetConn)
+		return true
+	}
+
 	// Start the socket listener
-	listener, err := net.Listen(protocol, address)
+	listener, err := net.Listen(options.protocol, options.address)
 	if err != nil {
 		return false
 	}
+
+	if options.tlsConfig != nil {
+		listener = tls.NewListener(listener, options.tlsConfig)
+	}
 	
 	p.mu.Lock()
 	p.listener = listener
@@ -178,8 +297,12 @@ func (p *SocketInput) Start() bool {
 
 // Stop halts plugin operation
 func (p *SocketInput) Stop() bool {
-	// Signal goroutines to stop
-	close(p.done)
+	// Signal goroutines to stop, unless already stopped
+	select {
+	case <-p.done:
+	default:
+		close(p.done)
+	}
 	
 	// Close all listeners
 	p.mu.Lock()
@@ -189,6 +312,21 @@ func (p *SocketInput) Stop() bool {
 		}
 	}
 	p.listeners = make([]net.Listener, 0)
+
+	if p.packetConn != nil {
+		p.packetConn.Close()
+		p.packetConn = nil
+
+		// Unlike unix listeners, unixgram sockets leave their file behind
+		if p.options.protocol == "unixgram" {
+			os.Remove(p.options.address)
+		}
+	}
+
+	// Close open connections to unblock their readers
+	for conn := range p.conns {
+		conn.Close()
+	}
 	p.mu.Unlock()
 	
 	p.SetStatus(model.StatusStopped)
@@ -214,36 +352,34 @@ func (p *SocketInput) Collect() []*model.DataBatch {
 		return nil
 	}
 	
-	// Get any pending records
-	p.recordsMu.Lock()
-	records := make([]model.Record, len(p.pendingRecords))
-	copy(records, p.pendingRecords)
-	p.pendingRecords = p.pendingRecords[:0] // Clear the pending records
-	p.recordsMu.Unlock()
+	// Drain the frames received since the last call
+	var results []*model.DataBatch
+	batch := model.NewDataBatch(model.LogTelemetryType)
+	batch.SourceID = p.id
 	
-	// If no records, return an empty batch
-	if len(records) == 0 {
-		return []*model.DataBatch{
-			{
-				SourceID:    p.id,
-				BatchType:   model.LogTelemetryType,
-				Timestamp:   time.Now(),
-				Records:     []model.Record{},
-				Attributes:  map[string]interface{}{},
-			},
+drain:
+	for {
+		select {
+		case point := <-p.pointsChan:
+			batch.AddPoint(point)
+
+			// Create a new batch if current one is full
+			if batch.Size() >= 1000 {
+				results = append(results, batch)
+				batch = model.NewDataBatch(model.LogTelemetryType)
+				batch.SourceID = p.id
+			}
+		default:
+			break drain
 		}
 	}
 	
-	// Return a batch with the collected records
-	return []*model.DataBatch{
-		{
-			SourceID:    p.id,
-			BatchType:   model.LogTelemetryType,
-			Timestamp:   time.Now(),
-			Records:     records,
-			Attributes:  map[string]interface{}{},
-		},
+	// Add the last batch if it has any points
+	if batch.Size() > 0 {
+		results = append(results, batch)
 	}
+
+	return results
 }
 
 // Accept connections and handle data
@@ -273,62 +409,158 @@ func (p *SocketInput) handleConnections() {
 				}
 			}
 			
+			// Turn away connections over the limit
+			p.mu.Lock()
+			if p.options.maxConnections > 0 && len(p.conns) >= p.options.maxConnections {
+				p.mu.Unlock()
+				conn.Close()
+				continue
+			}
+			p.conns[conn] = struct{}{}
+			p.nextConnID++
+			connID := strconv.FormatUint(p.nextConnID, 10)
+			p.mu.Unlock()
+
 			// Handle each connection in a goroutine
-			go p.handleConnection(conn)
+			go p.handleConnection(conn, connID)
 		}
 	}
 }
 
-// Handle a single connection
-func (p *SocketInput) handleConnection(conn net.Conn) {
-	defer conn.Close()
+// Handle a single connection, turning each frame into a log point
+func (p *SocketInput) handleConnection(conn net.Conn, connID string) {
+	defer func() {
+		p.mu.Lock()
+		delete(p.conns, conn)
+		p.mu.Unlock()
+		conn.Close()
+	}()
 	
-	// Buffer for reading data
-	buffer := make([]byte, 4096)
+	p.mu.Lock()
+	options := p.options
+	p.mu.Unlock()
 	
-	for {
-		select {
-		case <-p.done:
+	remoteAddr := conn.RemoteAddr().String()
+	labels := map[string]string{
+		"connection_id": connID,
+	}
+
+	// Complete the TLS handshake up front to label the client's certificate
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		tlsConn.SetDeadline(time.Now().Add(10 * time.Second))
+		if err := tlsConn.Handshake(); err != nil {
 			return
-		default:
-			// Set read deadline to periodically check for done signal
-			conn.SetReadDeadline(time.Now().Add(1 * time.Second))
-			
-			n, err := conn.Read(buffer)
-			if err != nil {
-				// Check if it's a timeout and we should continue
-				if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
-					continue
-				}
-				
-				// Any other error means we're done with this connection
+		}
+		tlsConn.SetDeadline(time.Time{})
+
+		if certs := tlsConn.ConnectionState().PeerCertificates; len(certs) > 0 {
+			labels["client_cn"] = certs[0].Subject.CommonName
+		}
+	}
+
+	// The scanner reassembles frames that span several reads
+	scanner := bufio.NewScanner(idleTimeoutReader{conn: conn, timeout: options.idleTimeout})
+	scanner.Buffer(make([]byte, options.bufferSize), options.maxBufferSize)
+	scanner.Split(options.split)
+
+	for scanner.Scan() {
+		// Wait for room rather than drop, which pushes back on the sender
+		if !p.emitFrame(scanner.Text(), remoteAddr, labels) {
+			return
+		}
+	}
+}
+
+// handlePackets reads datagrams, splitting each one into frames
+func (p *SocketInput) handlePackets(packetConn net.PacketConn) {
+	p.mu.Lock()
+	options := p.options
+	p.mu.Unlock()
+
+	buffer := make([]byte, maxDatagramSize)
+	for {
+		n, addr, err := packetConn.ReadFrom(buffer)
+		if err != nil {
+			select {
+			case <-p.done:
 				return
+			default:
+				continue
 			}
-			
-			if n > 0 {
-				// Process the received data
-				data := buffer[:n]
-				
-				// Create a record with the received data
-				record := model.Record{
-					Source:     p.id,
-					Timestamp:  time.Now(),
-					RawData:    data,
-					Attributes: map[string]interface{}{
-						"protocol": p.config["protocol"],
-						"address":  p.config["address"],
-					},
-				}
-				
-				// In a real implementation, we would pass this to a channel
-				// Send the record to the records channel for processing
-				select {
-				case p.recordsChan <- record:
-					// Record sent successfully
-				default:
-					// Channel is full, drop the record
-				}
+		}
+
+		// Unbound unixgram senders have no address
+		remoteAddr := "unknown"
+		if addr != nil && addr.String() != "" {
+			remoteAddr = addr.String()
+		}
+
+		scanner := bufio.NewScanner(bytes.NewReader(buffer[:n]))
+		scanner.Buffer(make([]byte, 0, n), options.maxBufferSize)
+		scanner.Split(options.split)
+
+		for scanner.Scan() {
+			if !p.emitFrame(scanner.Text(), remoteAddr, nil) {
+				return
 			}
 		}
 	}
-}
\ No newline at end of file
+}
+
+// newPoint creates a log point for a frame received from a remote address
+func (p *SocketInput) newPoint(message string, remoteAddr string, extraLabels map[string]string) *model.LogPoint {
+	labels := map[string]string{
+		"source":      "socket",
+		"protocol":    p.options.protocol,
+		"remote_addr": remoteAddr,
+	}
+	for k, v := range extraLabels {
+		labels[k] = v
+	}
+
+	return &model.LogPoint{
+		BaseDataPoint: model.BaseDataPoint{
+			Timestamp: time.Now(),
+			Origin:    remoteAddr,
+			Labels:    labels,
+		},
+		Message:    message,
+		Level:      "INFO", // Default level, would be parsed from content
+		Attributes: map[string]interface{}{},
+	}
+}
+
+// emitFrame turns a frame into a point and queues it. It returns false if the
+// input stopped while waiting.
+func (p *SocketInput) emitFrame(message string, remoteAddr string, extraLabels map[string]string) bool {
+	point := p.newPoint(message, remoteAddr, extraLabels)
+	if p.parseFrame != nil && !p.parseFrame(point) {
+		return true
+	}
+	return p.emit(point)
+}
+
+// emit queues a point for the next collection, waiting while the queue is
+// full. It returns false if the input stopped while waiting.
+func (p *SocketInput) emit(point model.DataPoint) bool {
+	select {
+	case p.pointsChan <- point:
+		return true
+	case <-p.done:
+		return false
+	}
+}
+
+// idleTimeoutReader fails a read that waits longer than the idle timeout
+type idleTimeoutReader struct {
+	conn    net.Conn
+	timeout time.Duration
+}
+
+// Read reads from the connection, extending the deadline first
+func (r idleTimeoutReader) Read(b []byte) (int, error) {
+	if r.timeout > 0 {
+		r.conn.SetReadDeadline(time.Now().Add(r.timeout))
+	}
+	return r.conn.Read(b)
+}
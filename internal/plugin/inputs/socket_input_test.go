@@ -0,0 +1,316 @@
+package inputs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sliink/collector/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func startSocketInput(t *testing.T, config map[string]interface{}) (*SocketInput, string) {
+	if _, ok := config["address"]; !ok {
+		config["address"] = "127.0.0.1:0"
+	}
+	input := NewSocketInput("socket_input")
+	assert.True(t, input.Configure(config))
+	assert.True(t, input.Validate())
+	assert.True(t, input.Initialize())
+	assert.True(t, input.Start())
+	t.Cleanup(func() { input.Stop() })
+
+	if input.packetConn != nil {
+		return input, input.packetConn.LocalAddr().String()
+	}
+	return input, input.listener.Addr().String()
+}
+
+func collectPoints(t *testing.T, input *SocketInput, count int) []*model.LogPoint {
+	var points []*model.LogPoint
+	deadline := time.Now().Add(2 * time.Second)
+	for len(points) < count && time.Now().Before(deadline) {
+		for _, batch := range input.Collect() {
+			for _, point := range batch.Points {
+				points = append(points, point.(*model.LogPoint))
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return points
+}
+
+func TestSocketInputValidate(t *testing.T) {
+	input := NewSocketInput("socket_input")
+
+	input.Configure(map[string]interface{}{"protocol": "tcp"})
+	assert.True(t, input.Validate())
+
+	input.Configure(map[string]interface{}{"protocol": "tcp", "framing": "unknown"})
+	assert.False(t, input.Validate())
+
+	input.Configure(map[string]interface{}{"protocol": "tcp", "max_frame_size": float64(0)})
+	assert.False(t, input.Validate())
+
+	input.Configure(map[string]interface{}{"protocol": "sctp"})
+	assert.False(t, input.Validate())
+
+	input.Configure(map[string]interface{}{"protocol": "udp", "tls": map[string]interface{}{}})
+	assert.False(t, input.Validate())
+
+	input.Configure(map[string]interface{}{"protocol": "tcp", "tls": map[string]interface{}{"cert_file": "/missing.pem", "key_file": "/missing.key"}})
+	assert.False(t, input.Validate())
+}
+
+func TestSocketInputCollect(t *testing.T) {
+	t.Run("Collect returns nothing without data", func(t *testing.T) {
+		input, _ := startSocketInput(t, map[string]interface{}{})
+		assert.Empty(t, input.Collect())
+	})
+
+	t.Run("Lines split across writes become log points", func(t *testing.T) {
+		input, address := startSocketInput(t, map[string]interface{}{})
+
+		conn, err := net.Dial("tcp", address)
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		conn.Write([]byte("first li"))
+		time.Sleep(20 * time.Millisecond)
+		conn.Write([]byte("ne\nsecond line\n"))
+
+		points := collectPoints(t, input, 2)
+		assert.Len(t, points, 2)
+		assert.Equal(t, "first line", points[0].Message)
+		assert.Equal(t, "second line", points[1].Message)
+		assert.Equal(t, "socket", points[0].Labels["source"])
+		assert.Equal(t, conn.LocalAddr().String(), points[0].Labels["remote_addr"])
+		assert.NotEmpty(t, points[0].Labels["connection_id"])
+	})
+
+	t.Run("Connections are labeled separately", func(t *testing.T) {
+		input, address := startSocketInput(t, map[string]interface{}{"framing": "octet_counted"})
+
+		for _, message := range []string{"5 first", "6 second"} {
+			conn, err := net.Dial("tcp", address)
+			assert.NoError(t, err)
+			conn.Write([]byte(message))
+			conn.Close()
+		}
+
+		points := collectPoints(t, input, 2)
+		assert.Len(t, points, 2)
+		assert.NotEqual(t, points[0].Labels["connection_id"], points[1].Labels["connection_id"])
+	})
+
+	t.Run("Stop closes open connections", func(t *testing.T) {
+		input, address := startSocketInput(t, map[string]interface{}{})
+
+		conn, err := net.Dial("tcp", address)
+		assert.NoError(t, err)
+		defer conn.Close()
+		conn.Write([]byte("hello\n"))
+		collectPoints(t, input, 1)
+
+		input.Stop()
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		_, err = conn.Read(make([]byte, 1))
+		assert.Equal(t, io.EOF, err)
+	})
+}
+
+func TestSocketInputProtocols(t *testing.T) {
+	t.Run("UDP datagrams are split into frames", func(t *testing.T) {
+		input, address := startSocketInput(t, map[string]interface{}{"protocol": "udp"})
+
+		conn, err := net.Dial("udp", address)
+		assert.NoError(t, err)
+		defer conn.Close()
+		conn.Write([]byte("first\nsecond"))
+		conn.Write([]byte("third\n"))
+
+		points := collectPoints(t, input, 3)
+		assert.Len(t, points, 3)
+		assert.Equal(t, []string{"first", "second", "third"}, []string{points[0].Message, points[1].Message, points[2].Message})
+		assert.Equal(t, "udp", points[0].Labels["protocol"])
+		assert.Equal(t, conn.LocalAddr().String(), points[0].Labels["remote_addr"])
+	})
+
+	t.Run("Unix stream sockets", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "input.sock")
+		input, _ := startSocketInput(t, map[string]interface{}{"protocol": "unix", "address": path})
+
+		conn, err := net.Dial("unix", path)
+		assert.NoError(t, err)
+		conn.Write([]byte("over unix\n"))
+		conn.Close()
+
+		points := collectPoints(t, input, 1)
+		assert.Len(t, points, 1)
+		assert.Equal(t, "over unix", points[0].Message)
+
+		input.Stop()
+		_, err = os.Stat(path)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("Unix datagram sockets replace stale socket files", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "input.sock")
+		stale, err := net.ListenPacket("unixgram", path)
+		assert.NoError(t, err)
+		stale.Close()
+
+		input, _ := startSocketInput(t, map[string]interface{}{"protocol": "unixgram", "address": path})
+
+		conn, err := net.Dial("unixgram", path)
+		assert.NoError(t, err)
+		conn.Write([]byte("datagram"))
+		conn.Close()
+
+		points := collectPoints(t, input, 1)
+		assert.Len(t, points, 1)
+		assert.Equal(t, "datagram", points[0].Message)
+
+		input.Stop()
+		_, err = os.Stat(path)
+		assert.True(t, os.IsNotExist(err))
+	})
+}
+
+func TestSocketInputLimits(t *testing.T) {
+	t.Run("Connections over the limit are closed", func(t *testing.T) {
+		input, address := startSocketInput(t, map[string]interface{}{"max_connections": float64(1)})
+
+		first, err := net.Dial("tcp", address)
+		assert.NoError(t, err)
+		defer first.Close()
+		first.Write([]byte("first\n"))
+		assert.Len(t, collectPoints(t, input, 1), 1)
+
+		second, err := net.Dial("tcp", address)
+		assert.NoError(t, err)
+		defer second.Close()
+		second.SetReadDeadline(time.Now().Add(time.Second))
+		_, err = second.Read(make([]byte, 1))
+		assert.Equal(t, io.EOF, err)
+	})
+
+	t.Run("Idle connections are closed", func(t *testing.T) {
+		_, address := startSocketInput(t, map[string]interface{}{"idle_timeout": "50ms"})
+
+		conn, err := net.Dial("tcp", address)
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		_, err = conn.Read(make([]byte, 1))
+		assert.Equal(t, io.EOF, err)
+	})
+}
+
+// testCertificate creates a certificate signed by parent, or self-signed when parent is nil
+func testCertificate(t *testing.T, cn string, parent *tls.Certificate, isCA bool) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	signer, signerKey := template, interface{}(key)
+	if parent != nil {
+		signer, signerKey = parent.Leaf, parent.PrivateKey
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	assert.NoError(t, err)
+	leaf, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+// writeCertificate writes a certificate and its key as PEM files
+func writeCertificate(t *testing.T, dir string, name string, cert tls.Certificate) (string, string) {
+	certFile := filepath.Join(dir, name+".pem")
+	keyFile := filepath.Join(dir, name+".key")
+
+	assert.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0600))
+	keyDER, err := x509.MarshalECPrivateKey(cert.PrivateKey.(*ecdsa.PrivateKey))
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+
+	return certFile, keyFile
+}
+
+func TestSocketInputTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := testCertificate(t, "test-ca", nil, true)
+	server := testCertificate(t, "server", &ca, false)
+	client := testCertificate(t, "client-1", &ca, false)
+
+	caFile, _ := writeCertificate(t, dir, "ca", ca)
+	certFile, keyFile := writeCertificate(t, dir, "server", server)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Leaf)
+
+	t.Run("TLS connections are decrypted", func(t *testing.T) {
+		input, address := startSocketInput(t, map[string]interface{}{
+			"tls": map[string]interface{}{"cert_file": certFile, "key_file": keyFile},
+		})
+
+		conn, err := tls.Dial("tcp", address, &tls.Config{RootCAs: roots})
+		assert.NoError(t, err)
+		conn.Write([]byte("secret\n"))
+		conn.Close()
+
+		points := collectPoints(t, input, 1)
+		assert.Len(t, points, 1)
+		assert.Equal(t, "secret", points[0].Message)
+	})
+
+	t.Run("mTLS requires and labels client certificates", func(t *testing.T) {
+		input, address := startSocketInput(t, map[string]interface{}{
+			"tls": map[string]interface{}{"cert_file": certFile, "key_file": keyFile, "client_ca_file": caFile},
+		})
+
+		// Without a client certificate the handshake is rejected
+		conn, err := tls.Dial("tcp", address, &tls.Config{RootCAs: roots})
+		if err == nil {
+			conn.SetReadDeadline(time.Now().Add(time.Second))
+			_, err = conn.Read(make([]byte, 1))
+			conn.Close()
+		}
+		assert.Error(t, err)
+
+		conn, err = tls.Dial("tcp", address, &tls.Config{RootCAs: roots, Certificates: []tls.Certificate{client}})
+		assert.NoError(t, err)
+		conn.Write([]byte("authenticated\n"))
+		conn.Close()
+
+		points := collectPoints(t, input, 1)
+		assert.Len(t, points, 1)
+		assert.Equal(t, "authenticated", points[0].Message)
+		assert.Equal(t, "client-1", points[0].Labels["client_cn"])
+	})
+}
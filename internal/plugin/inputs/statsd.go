@@ -0,0 +1,285 @@
+package inputs
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sliink/collector/internal/model"
+)
+
+// statsdMetric is one value of a StatsD line
+type statsdMetric struct {
+	name       string
+	kind       string // c, g, ms, h, d or s
+	value      float64
+	relative   bool   // Gauge values with a sign adjust the current value
+	member     string // Set values are kept as sent
+	sampleRate float64
+	tags       map[string]string
+}
+
+// statsdKinds are the metric types a StatsD line can carry
+var statsdKinds = map[string]bool{"c": true, "g": true, "ms": true, "h": true, "d": true, "s": true}
+
+// parseStatsDLine parses a StatsD or DogStatsD line of the form
+// name:value[:value...]|type[|@rate][|#tag:value,tag]. DogStatsD container
+// IDs and timestamps are accepted and ignored.
+func parseStatsDLine(line string) ([]statsdMetric, error) {
+	nameEnd := strings.IndexByte(line, ':')
+	if nameEnd <= 0 {
+		return nil, fmt.Errorf("missing metric name")
+	}
+	name := line[:nameEnd]
+
+	fields := strings.Split(line[nameEnd+1:], "|")
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("missing metric type")
+	}
+
+	kind := fields[1]
+	if !statsdKinds[kind] {
+		return nil, fmt.Errorf("unknown metric type: %s", kind)
+	}
+
+	sampleRate := 1.0
+	tags := make(map[string]string)
+	for _, field := range fields[2:] {
+		switch {
+		case strings.HasPrefix(field, "@"):
+			rate, err := strconv.ParseFloat(field[1:], 64)
+			if err != nil || rate <= 0 || rate > 1 {
+				return nil, fmt.Errorf("invalid sample rate: %s", field[1:])
+			}
+			sampleRate = rate
+		case strings.HasPrefix(field, "#"):
+			for _, tag := range strings.Split(field[1:], ",") {
+				if tag == "" {
+					continue
+				}
+				// Tags without a value are kept as flags
+				if k, v, found := strings.Cut(tag, ":"); found {
+					tags[k] = v
+				} else {
+					tags[tag] = "true"
+				}
+			}
+		case strings.HasPrefix(field, "c:"), strings.HasPrefix(field, "T"):
+		default:
+			return nil, fmt.Errorf("invalid field: %s", field)
+		}
+	}
+
+	var metrics []statsdMetric
+	for _, raw := range strings.Split(fields[0], ":") {
+		metric := statsdMetric{name: name, kind: kind, sampleRate: sampleRate, tags: tags}
+
+		if kind == "s" {
+			metric.member = raw
+			metrics = append(metrics, metric)
+			continue
+		}
+
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+			return nil, fmt.Errorf("invalid value: %s", raw)
+		}
+		metric.value = value
+		metric.relative = kind == "g" && (strings.HasPrefix(raw, "+") || strings.HasPrefix(raw, "-"))
+		metrics = append(metrics, metric)
+	}
+
+	return metrics, nil
+}
+
+// statsdSeries is the aggregated state of one metric name, type and tag set
+type statsdSeries struct {
+	name    string
+	kind    string
+	tags    map[string]string
+	value   float64         // Counter total or gauge value
+	count   float64         // Timer observations, cumulative
+	sum     float64         // Timer sum, cumulative
+	samples []float64       // Timer values since the last flush
+	members map[string]bool // Set members since the last flush
+	updated bool            // Whether the series changed since the last flush
+	flushed time.Time       // When the series was last reported
+}
+
+// statsdAggregator folds StatsD metrics into series between flushes
+type statsdAggregator struct {
+	series      map[string]*statsdSeries
+	percentiles []float64
+	expiry      time.Duration // How long an idle series is kept, or 0 to keep it forever
+	mutex       sync.Mutex
+}
+
+// newStatsDAggregator creates an aggregator reporting the given timer
+// percentiles and dropping series idle for longer than expiry
+func newStatsDAggregator(percentiles []float64, expiry time.Duration) *statsdAggregator {
+	return &statsdAggregator{
+		series:      make(map[string]*statsdSeries),
+		percentiles: percentiles,
+		expiry:      expiry,
+	}
+}
+
+// Add folds metrics into their series, all at once so that a flush never
+// splits a packet. Counters are scaled up by their sample rate, as are timer
+// counts and sums.
+func (a *statsdAggregator) Add(metrics ...statsdMetric) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	for _, metric := range metrics {
+		a.add(metric)
+	}
+}
+
+// add folds a metric into its series
+func (a *statsdAggregator) add(metric statsdMetric) {
+	// Histograms and distributions are aggregated like timers
+	kind := metric.kind
+	if kind == "h" || kind == "d" {
+		kind = "ms"
+	}
+
+	key := kind + "\x00" + metric.name + "\x00" + statsdTagKey(metric.tags)
+	series, exists := a.series[key]
+	if !exists {
+		series = &statsdSeries{name: metric.name, kind: kind, tags: metric.tags}
+		a.series[key] = series
+	}
+	series.updated = true
+
+	switch kind {
+	case "c":
+		series.value += metric.value / metric.sampleRate
+	case "g":
+		if metric.relative {
+			series.value += metric.value
+		} else {
+			series.value = metric.value
+		}
+	case "ms":
+		series.samples = append(series.samples, metric.value)
+		series.count += 1 / metric.sampleRate
+		series.sum += metric.value / metric.sampleRate
+	case "s":
+		if series.members == nil {
+			series.members = make(map[string]bool)
+		}
+		series.members[metric.member] = true
+	}
+}
+
+// Flush returns points for the series updated since the last flush. Counters
+// are running totals and gauges keep their value between flushes. Timers
+// become summaries: percentiles of this interval's values plus running
+// _count and _sum series. Sets report the number of distinct members seen
+// in the interval.
+func (a *statsdAggregator) Flush(now time.Time) []model.DataPoint {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	keys := make([]string, 0, len(a.series))
+	for key, series := range a.series {
+		switch {
+		case series.updated:
+			keys = append(keys, key)
+		case a.expiry > 0 && now.Sub(series.flushed) > a.expiry:
+			// Counter and timer totals restart if the series comes back
+			delete(a.series, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var points []model.DataPoint
+	for _, key := range keys {
+		series := a.series[key]
+		series.updated = false
+		series.flushed = now
+
+		switch series.kind {
+		case "c":
+			points = append(points, statsdPoint(series, series.name, series.value, "counter", now, nil))
+		case "g":
+			points = append(points, statsdPoint(series, series.name, series.value, "gauge", now, nil))
+		case "ms":
+			sort.Float64s(series.samples)
+			for _, percentile := range a.percentiles {
+				quantile := strconv.FormatFloat(percentile/100, 'g', -1, 64)
+				points = append(points, statsdPoint(series, series.name, nearestRank(series.samples, percentile), "summary", now, map[string]string{"quantile": quantile}))
+			}
+			points = append(points,
+				statsdPoint(series, series.name+"_count", series.count, "summary", now, nil),
+				statsdPoint(series, series.name+"_sum", series.sum, "summary", now, nil),
+			)
+			series.samples = nil
+		case "s":
+			points = append(points, statsdPoint(series, series.name, float64(len(series.members)), "gauge", now, nil))
+			series.members = nil
+		}
+	}
+
+	return points
+}
+
+// nearestRank returns a percentile of sorted values by the nearest-rank method
+func nearestRank(sorted []float64, percentile float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := int(math.Ceil(percentile / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
+
+// statsdPoint builds a metric point for a series. Tags become dimensions,
+// and a DogStatsD host tag becomes the origin.
+func statsdPoint(series *statsdSeries, name string, value float64, metricType string, now time.Time, extra map[string]string) *model.MetricPoint {
+	dimensions := make(map[string]string, len(series.tags)+len(extra))
+	for k, v := range series.tags {
+		dimensions[k] = v
+	}
+	for k, v := range extra {
+		dimensions[k] = v
+	}
+
+	origin := "statsd"
+	if host := series.tags["host"]; host != "" {
+		origin = host
+	}
+
+	return &model.MetricPoint{
+		BaseDataPoint: model.BaseDataPoint{
+			Timestamp: now,
+			Origin:    origin,
+			Labels:    map[string]string{"source": "statsd"},
+		},
+		Name:       name,
+		Value:      value,
+		MetricType: metricType,
+		Dimensions: dimensions,
+	}
+}
+
+// statsdTagKey returns a string identifying a tag set
+func statsdTagKey(tags map[string]string) string {
+	parts := make([]string, 0, len(tags))
+	for k, v := range tags {
+		parts = append(parts, k+"="+v)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
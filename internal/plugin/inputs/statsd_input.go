@@ -0,0 +1,226 @@
+package inputs
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sliink/collector/internal/model"
+	"github.com/sliink/collector/internal/plugin"
+)
+
+// StatsDInput listens for StatsD and DogStatsD metrics over UDP and
+// aggregates them into metric points once per flush interval
+type StatsDInput struct {
+	plugin.BasePlugin
+	statsdInputSettings
+	conn       net.PacketConn
+	aggregator *statsdAggregator
+	queue      *pushQueue
+	done       chan struct{}
+	wg         sync.WaitGroup
+	mutex      sync.Mutex
+}
+
+// statsdInputSettings holds the StatsD input configuration
+type statsdInputSettings struct {
+	address       string
+	flushInterval time.Duration
+	percentiles   []float64
+	expiry        time.Duration
+	maxPending    int
+}
+
+func init() {
+	plugin.RegisterStandardInput("statsd", func(id string) model.InputPlugin {
+		return NewStatsDInput(id)
+	})
+}
+
+// NewStatsDInput creates a new StatsD input plugin
+func NewStatsDInput(id string) *StatsDInput {
+	return &StatsDInput{
+		BasePlugin: plugin.NewBasePlugin(id, "StatsD Input", model.InputPluginType),
+	}
+}
+
+// parseStatsDInputSettings reads the StatsD input configuration
+func parseStatsDInputSettings(config map[string]interface{}) (statsdInputSettings, error) {
+	settings := statsdInputSettings{
+		address:     "localhost:8125",
+		percentiles: []float64{50, 90, 99},
+		expiry:      5 * time.Minute,
+		maxPending:  100000,
+	}
+
+	if address, ok := config["address"].(string); ok && address != "" {
+		settings.address = address
+	}
+
+	interval, err := durationOption(config, "flush_interval", 10*time.Second)
+	if err != nil {
+		return settings, err
+	}
+	settings.flushInterval = interval
+
+	if percentiles, ok := config["percentiles"].([]interface{}); ok {
+		settings.percentiles = nil
+		for _, item := range percentiles {
+			percentile, ok := item.(float64)
+			if !ok || percentile <= 0 || percentile > 100 {
+				return settings, fmt.Errorf("percentiles must be numbers between 0 and 100")
+			}
+			settings.percentiles = append(settings.percentiles, percentile)
+		}
+	}
+
+	if expiryStr, ok := config["expiry"].(string); ok {
+		expiry, err := time.ParseDuration(expiryStr)
+		if err != nil || expiry < 0 {
+			return settings, fmt.Errorf("invalid expiry: %s", expiryStr)
+		}
+		settings.expiry = expiry
+	}
+
+	if size, ok := plugin.IntOption(config["max_pending"]); ok {
+		if size <= 0 {
+			return settings, fmt.Errorf("max_pending must be positive")
+		}
+		settings.maxPending = int(size)
+	}
+
+	return settings, nil
+}
+
+// Initialize prepares the StatsD input for operation
+func (s *StatsDInput) Initialize() bool {
+	settings, err := parseStatsDInputSettings(s.Config)
+	if err != nil {
+		return false
+	}
+	s.statsdInputSettings = settings
+	s.aggregator = newStatsDAggregator(settings.percentiles, settings.expiry)
+	s.queue = newPushQueue(settings.maxPending)
+
+	s.SetStatus(model.StatusInitialized)
+	return true
+}
+
+// Validate checks if the StatsD input is properly configured
+func (s *StatsDInput) Validate() bool {
+	_, err := parseStatsDInputSettings(s.Config)
+	return err == nil
+}
+
+// Start begins listening for metrics and flushing them on the interval
+func (s *StatsDInput) Start() bool {
+	conn, err := net.ListenPacket("udp", s.address)
+	if err != nil {
+		return false
+	}
+
+	s.mutex.Lock()
+	s.conn = conn
+	s.done = make(chan struct{})
+	done := s.done
+	s.mutex.Unlock()
+
+	s.wg.Add(2)
+	go s.readPackets(conn)
+	go s.flushLoop(done)
+
+	s.SetStatus(model.StatusRunning)
+	return true
+}
+
+// Stop closes the listener and flushes what was aggregated so far
+func (s *StatsDInput) Stop() bool {
+	s.mutex.Lock()
+	conn := s.conn
+	s.conn = nil
+	if conn != nil {
+		close(s.done)
+	}
+	s.mutex.Unlock()
+
+	if conn != nil {
+		conn.Close()
+		s.wg.Wait()
+		s.flush()
+	}
+
+	s.SetStatus(model.StatusStopped)
+	return true
+}
+
+// Collect returns the points flushed since the last call
+func (s *StatsDInput) Collect() []*model.DataBatch {
+	if s.queue == nil {
+		return nil
+	}
+
+	return s.queue.Drain(s.ID())
+}
+
+// readPackets parses each packet's lines into the aggregator until the
+// listener is closed. Malformed lines are skipped without affecting the
+// rest of the packet.
+func (s *StatsDInput) readPackets(conn net.PacketConn) {
+	defer s.wg.Done()
+
+	buffer := make([]byte, maxDatagramSize)
+	for {
+		n, _, err := conn.ReadFrom(buffer)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+
+		var metrics []statsdMetric
+		for _, line := range strings.Split(string(buffer[:n]), "\n") {
+			line = strings.TrimSpace(line)
+			// DogStatsD events and service checks are not metrics
+			if line == "" || strings.HasPrefix(line, "_e{") || strings.HasPrefix(line, "_sc|") {
+				continue
+			}
+
+			parsed, err := parseStatsDLine(line)
+			if err != nil {
+				continue
+			}
+			metrics = append(metrics, parsed...)
+		}
+		s.aggregator.Add(metrics...)
+	}
+}
+
+// flushLoop flushes the aggregator every flush interval until stopped
+func (s *StatsDInput) flushLoop(done chan struct{}) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			s.flush()
+		}
+	}
+}
+
+// flush queues the points of the series updated since the last flush. When
+// the queue is full the interval's points are dropped.
+func (s *StatsDInput) flush() {
+	points := s.aggregator.Flush(time.Now())
+	if len(points) > 0 {
+		s.queue.Add(model.MetricTelemetryType, points)
+	}
+}
@@ -0,0 +1,84 @@
+package inputs
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/sliink/collector/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatsDInputValidate(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{"flush_interval": "0s"},
+		{"flush_interval": "sometimes"},
+		{"percentiles": []interface{}{float64(50), float64(101)}},
+		{"percentiles": []interface{}{"p99"}},
+		{"expiry": "-1m"},
+		{"max_pending": float64(-1)},
+	}
+
+	for _, config := range invalid {
+		input := NewStatsDInput("statsd_input")
+		input.Configure(config)
+		assert.False(t, input.Validate(), "%v", config)
+		assert.False(t, input.Initialize(), "%v", config)
+	}
+}
+
+func TestStatsDInputReceive(t *testing.T) {
+	input := NewStatsDInput("statsd_input")
+	assert.True(t, input.Configure(map[string]interface{}{
+		"address":        "127.0.0.1:0",
+		"flush_interval": "50ms",
+	}))
+	assert.True(t, input.Validate())
+	assert.True(t, input.Initialize())
+	assert.True(t, input.Start())
+	defer input.Stop()
+
+	conn, err := net.Dial("udp", input.conn.LocalAddr().String())
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	// Several metrics in one packet, with a malformed line among them
+	_, err = conn.Write([]byte("jobs.done:1|c|#queue:mail\njobs.done:2|c|#queue:mail\nbroken line\n_e{5,4}:title|text\nqueue.size:7|g\n"))
+	assert.NoError(t, err)
+
+	var points []*model.MetricPoint
+	deadline := time.Now().Add(5 * time.Second)
+	for len(points) < 2 && time.Now().Before(deadline) {
+		for _, batch := range input.Collect() {
+			assert.Equal(t, model.MetricTelemetryType, batch.BatchType)
+			assert.Equal(t, "statsd_input", batch.SourceID)
+			for _, point := range batch.Points {
+				points = append(points, point.(*model.MetricPoint))
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if assert.Len(t, points, 2) {
+		assert.Equal(t, "jobs.done", points[0].Name)
+		assert.Equal(t, 3.0, points[0].Value)
+		assert.Equal(t, map[string]string{"queue": "mail"}, points[0].Dimensions)
+		assert.Equal(t, "queue.size", points[1].Name)
+		assert.Equal(t, 7.0, points[1].Value)
+	}
+
+	// Stopping flushes what has been aggregated since the last interval
+	conn.Write([]byte("jobs.done:1|c|#queue:mail"))
+	time.Sleep(10 * time.Millisecond)
+	input.Stop()
+
+	var final []*model.MetricPoint
+	for _, batch := range input.Collect() {
+		for _, point := range batch.Points {
+			final = append(final, point.(*model.MetricPoint))
+		}
+	}
+	if assert.Len(t, final, 1) {
+		assert.Equal(t, 4.0, final[0].Value)
+	}
+}
@@ -0,0 +1,143 @@
+package inputs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sliink/collector/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStatsDLine(t *testing.T) {
+	t.Run("Metric types", func(t *testing.T) {
+		metrics, err := parseStatsDLine("page.views:1|c")
+		assert.NoError(t, err)
+		assert.Equal(t, []statsdMetric{{name: "page.views", kind: "c", value: 1, sampleRate: 1, tags: map[string]string{}}}, metrics)
+
+		metrics, err = parseStatsDLine("users.online:-3|g")
+		assert.NoError(t, err)
+		assert.True(t, metrics[0].relative)
+		assert.Equal(t, -3.0, metrics[0].value)
+
+		metrics, err = parseStatsDLine("users.online:3|g")
+		assert.NoError(t, err)
+		assert.False(t, metrics[0].relative)
+
+		metrics, err = parseStatsDLine("uniques:alice|s")
+		assert.NoError(t, err)
+		assert.Equal(t, "alice", metrics[0].member)
+
+		for _, kind := range []string{"ms", "h", "d"} {
+			metrics, err = parseStatsDLine("latency:320|" + kind)
+			assert.NoError(t, err)
+			assert.Equal(t, kind, metrics[0].kind)
+		}
+	})
+
+	t.Run("DogStatsD extensions", func(t *testing.T) {
+		metrics, err := parseStatsDLine("request.time:10:20:30|ms|@0.5|#env:prod,canary,host:web-1|c:abc123|T1656581400")
+		assert.NoError(t, err)
+		assert.Len(t, metrics, 3)
+		assert.Equal(t, 30.0, metrics[2].value)
+		assert.Equal(t, 0.5, metrics[0].sampleRate)
+		assert.Equal(t, map[string]string{"env": "prod", "canary": "true", "host": "web-1"}, metrics[0].tags)
+	})
+
+	t.Run("Malformed lines are rejected", func(t *testing.T) {
+		for _, line := range []string{
+			"no_value",
+			":1|c",
+			"metric:1",
+			"metric:1|x",
+			"metric:abc|c",
+			"metric:1|c|@2",
+			"metric:1|c|@rate",
+			"metric:1|c|unknown",
+		} {
+			_, err := parseStatsDLine(line)
+			assert.Error(t, err, line)
+		}
+	})
+}
+
+func TestStatsDAggregator(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	aggregator := newStatsDAggregator([]float64{50, 90}, time.Minute)
+
+	add := func(lines ...string) {
+		for _, line := range lines {
+			metrics, err := parseStatsDLine(line)
+			assert.NoError(t, err)
+			aggregator.Add(metrics...)
+		}
+	}
+
+	byName := func(points []model.DataPoint) map[string][]*model.MetricPoint {
+		result := make(map[string][]*model.MetricPoint)
+		for _, point := range points {
+			metric := point.(*model.MetricPoint)
+			result[metric.Name] = append(result[metric.Name], metric)
+		}
+		return result
+	}
+
+	add(
+		"hits:1|c|#route:/", "hits:2|c|#route:/", "hits:1|c|@0.1|#route:/",
+		"hits:5|c|#route:/login",
+		"temp:20|g", "temp:+2|g",
+		"latency:1:2:3:4:5:6:7:8:9:10|ms",
+		"visitors:alice|s", "visitors:bob|s", "visitors:alice|s",
+	)
+
+	metrics := byName(aggregator.Flush(now))
+	assert.Len(t, metrics["hits"], 2)
+	assert.Equal(t, 13.0, metrics["hits"][0].Value)
+	assert.Equal(t, "counter", metrics["hits"][0].MetricType)
+	assert.Equal(t, map[string]string{"route": "/"}, metrics["hits"][0].Dimensions)
+	assert.Equal(t, 5.0, metrics["hits"][1].Value)
+
+	assert.Equal(t, 22.0, metrics["temp"][0].Value)
+	assert.Equal(t, "gauge", metrics["temp"][0].MetricType)
+	assert.Equal(t, now, metrics["temp"][0].Timestamp)
+	assert.Equal(t, "statsd", metrics["temp"][0].Origin)
+	assert.Equal(t, map[string]string{"source": "statsd"}, metrics["temp"][0].Labels)
+
+	assert.Len(t, metrics["latency"], 2)
+	assert.Equal(t, map[string]string{"quantile": "0.5"}, metrics["latency"][0].Dimensions)
+	assert.Equal(t, 5.0, metrics["latency"][0].Value)
+	assert.Equal(t, 9.0, metrics["latency"][1].Value)
+	assert.Equal(t, "summary", metrics["latency"][0].MetricType)
+	assert.Equal(t, 10.0, metrics["latency_count"][0].Value)
+	assert.Equal(t, 55.0, metrics["latency_sum"][0].Value)
+
+	assert.Equal(t, 2.0, metrics["visitors"][0].Value)
+	assert.Equal(t, "gauge", metrics["visitors"][0].MetricType)
+
+	// Only series updated since the last flush are reported
+	assert.Empty(t, aggregator.Flush(now))
+
+	// Counters, gauges and timer totals carry over between flushes
+	add("hits:1|c|#route:/", "temp:-1|g", "latency:100|h", "visitors:carol|s|#host:web-1")
+	metrics = byName(aggregator.Flush(now))
+	assert.Len(t, metrics["hits"], 1)
+	assert.Equal(t, 14.0, metrics["hits"][0].Value)
+	assert.Equal(t, 21.0, metrics["temp"][0].Value)
+	assert.Equal(t, 100.0, metrics["latency"][0].Value)
+	assert.Equal(t, 11.0, metrics["latency_count"][0].Value)
+	assert.Equal(t, 155.0, metrics["latency_sum"][0].Value)
+	assert.Equal(t, 1.0, metrics["visitors"][0].Value)
+	assert.Equal(t, "web-1", metrics["visitors"][0].Origin)
+
+	// Series idle for longer than the expiry are dropped, and counters
+	// that come back start again from zero
+	add("temp:5|g")
+	assert.Len(t, aggregator.Flush(now.Add(30*time.Second)), 1)
+	assert.Empty(t, aggregator.Flush(now.Add(90*time.Second)))
+	assert.Len(t, aggregator.series, 1)
+	assert.Empty(t, aggregator.Flush(now.Add(2*time.Minute)))
+	assert.Empty(t, aggregator.series)
+
+	add("hits:1|c|#route:/")
+	metrics = byName(aggregator.Flush(now.Add(3 * time.Minute)))
+	assert.Equal(t, 1.0, metrics["hits"][0].Value)
+}
@@ -0,0 +1,40 @@
+package inputs
+
+import (
+	"context"
+	"time"
+)
+
+// superviseWithBackoff calls run until the context is cancelled, passing
+// each error it returns to onErr and waiting before the next call. The wait
+// doubles from minBackoff up to maxBackoff, and goes back to minBackoff
+// after a run that lasted longer than maxBackoff, as that run was healthy.
+func superviseWithBackoff(ctx context.Context, minBackoff, maxBackoff time.Duration, run func(context.Context) error, onErr func(error)) {
+	backoff := minBackoff
+	for {
+		started := time.Now()
+		err := run(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err != nil {
+			onErr(err)
+		}
+
+		if time.Since(started) > maxBackoff {
+			backoff = minBackoff
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package inputs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuperviseWithBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls []time.Time
+	var errs []error
+	run := func(ctx context.Context) error {
+		calls = append(calls, time.Now())
+		switch len(calls) {
+		case 1:
+			return errors.New("failed")
+		case 4:
+			cancel()
+			return errors.New("cancelled")
+		}
+		return nil
+	}
+	superviseWithBackoff(ctx, 20*time.Millisecond, 50*time.Millisecond, run, func(err error) {
+		errs = append(errs, err)
+	})
+
+	// Runs are retried with growing waits until the context is cancelled,
+	// and only errors are reported
+	assert.Len(t, calls, 4)
+	assert.Equal(t, []error{errors.New("failed")}, errs)
+	assert.GreaterOrEqual(t, calls[1].Sub(calls[0]), 20*time.Millisecond)
+	assert.GreaterOrEqual(t, calls[2].Sub(calls[1]), 40*time.Millisecond)
+	assert.GreaterOrEqual(t, calls[3].Sub(calls[2]), 50*time.Millisecond)
+}
@@ -0,0 +1,281 @@
+package inputs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// syslogFacilities names the facility codes of RFC 5424 section 6.2.1
+var syslogFacilities = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+// syslogSeverities names the severity codes of RFC 5424 section 6.2.1
+var syslogSeverities = []string{
+	"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug",
+}
+
+// syslogLevels maps severity codes to log levels
+var syslogLevels = []string{
+	"FATAL", "FATAL", "FATAL", "ERROR", "WARN", "INFO", "INFO", "DEBUG",
+}
+
+// syslogNil is the RFC 5424 placeholder for an absent field
+const syslogNil = "-"
+
+// syslogMessage is a parsed RFC 3164 or RFC 5424 message
+type syslogMessage struct {
+	facility  int
+	severity  int
+	version   int // 0 for RFC 3164
+	timestamp time.Time
+	hostname  string
+	appName   string
+	procID    string
+	msgID     string
+	// structuredData maps SD-IDs to their parameters
+	structuredData map[string]map[string]string
+	message        string
+}
+
+// parseSyslog parses an RFC 5424 message, falling back to RFC 3164 for
+// messages without a version. RFC 3164 timestamps have no year or zone, so
+// they are read in loc and given the year that puts them closest before now.
+func parseSyslog(data string, now time.Time, loc *time.Location) (*syslogMessage, error) {
+	pri, rest, err := parsePriority(data)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &syslogMessage{facility: pri / 8, severity: pri % 8}
+
+	if strings.HasPrefix(rest, "1 ") {
+		msg.version = 1
+		if err := parseRFC5424(msg, rest[2:]); err != nil {
+			return nil, err
+		}
+		return msg, nil
+	}
+
+	parseRFC3164(msg, rest, now, loc)
+	return msg, nil
+}
+
+// parsePriority reads the "<PRI>" header
+func parsePriority(data string) (int, string, error) {
+	if !strings.HasPrefix(data, "<") {
+		return 0, "", fmt.Errorf("missing syslog priority")
+	}
+
+	end := strings.IndexByte(data, '>')
+	if end < 2 || end > 4 {
+		return 0, "", fmt.Errorf("invalid syslog priority")
+	}
+
+	pri, err := strconv.Atoi(data[1:end])
+	if err != nil || pri < 0 || pri > 191 {
+		return 0, "", fmt.Errorf("invalid syslog priority: %q", data[1:end])
+	}
+
+	return pri, data[end+1:], nil
+}
+
+// parseRFC5424 reads "TIMESTAMP HOSTNAME APP-NAME PROCID MSGID SD [MSG]"
+func parseRFC5424(msg *syslogMessage, rest string) error {
+	fields := make([]string, 5)
+	for i := range fields {
+		end := strings.IndexByte(rest, ' ')
+		if end < 0 {
+			return fmt.Errorf("truncated RFC 5424 header")
+		}
+		fields[i], rest = rest[:end], rest[end+1:]
+	}
+
+	if fields[0] != syslogNil {
+		timestamp, err := time.Parse(time.RFC3339Nano, fields[0])
+		if err != nil {
+			return fmt.Errorf("invalid RFC 5424 timestamp: %q", fields[0])
+		}
+		msg.timestamp = timestamp
+	}
+
+	msg.hostname = nilValue(fields[1])
+	msg.appName = nilValue(fields[2])
+	msg.procID = nilValue(fields[3])
+	msg.msgID = nilValue(fields[4])
+
+	sd, rest, err := parseStructuredData(rest)
+	if err != nil {
+		return err
+	}
+	msg.structuredData = sd
+
+	if strings.HasPrefix(rest, " ") {
+		// Drop the byte order mark that flags UTF-8 messages
+		msg.message = strings.TrimPrefix(rest[1:], "\ufeff")
+	} else if rest != "" {
+		return fmt.Errorf("expected space before RFC 5424 message")
+	}
+
+	return nil
+}
+
+// parseStructuredData reads "-" or one or more "[SD-ID PARAM="VALUE" ...]"
+// elements, returning the rest of the message
+func parseStructuredData(data string) (map[string]map[string]string, string, error) {
+	if strings.HasPrefix(data, syslogNil) {
+		return nil, data[1:], nil
+	}
+	if !strings.HasPrefix(data, "[") {
+		return nil, "", fmt.Errorf("invalid structured data")
+	}
+
+	sd := make(map[string]map[string]string)
+	for strings.HasPrefix(data, "[") {
+		end := strings.IndexAny(data, " ]")
+		if end < 0 {
+			return nil, "", fmt.Errorf("unterminated structured data element")
+		}
+
+		id := data[1:end]
+		params := make(map[string]string)
+		sd[id] = params
+		data = data[end:]
+
+		for strings.HasPrefix(data, " ") {
+			eq := strings.Index(data, "=\"")
+			if eq < 0 {
+				return nil, "", fmt.Errorf("invalid structured data parameter in %s", id)
+			}
+			name := data[1:eq]
+
+			value, n, err := readParamValue(data[eq+2:])
+			if err != nil {
+				return nil, "", fmt.Errorf("%w in %s", err, id)
+			}
+			params[name] = value
+			data = data[eq+2+n:]
+		}
+
+		if !strings.HasPrefix(data, "]") {
+			return nil, "", fmt.Errorf("unterminated structured data element %s", id)
+		}
+		data = data[1:]
+	}
+
+	return sd, data, nil
+}
+
+// readParamValue reads a parameter value up to its closing quote, undoing the
+// \", \\ and \] escapes. It returns the value and the bytes consumed.
+func readParamValue(data string) (string, int, error) {
+	var value strings.Builder
+	for i := 0; i < len(data); i++ {
+		switch c := data[i]; c {
+		case '"':
+			return value.String(), i + 1, nil
+		case '\\':
+			if i+1 < len(data) && strings.IndexByte(`"\]`, data[i+1]) >= 0 {
+				i++
+				value.WriteByte(data[i])
+			} else {
+				value.WriteByte(c)
+			}
+		default:
+			value.WriteByte(c)
+		}
+	}
+
+	return "", 0, fmt.Errorf("unterminated structured data value")
+}
+
+// parseRFC3164 reads the loosely defined "TIMESTAMP HOSTNAME TAG[PID]: MSG".
+// Senders often leave out parts, so whatever cannot be recognized stays in the message.
+func parseRFC3164(msg *syslogMessage, rest string, now time.Time, loc *time.Location) {
+	rest = strings.TrimPrefix(rest, " ")
+
+	timestamp, rest, ok := parseRFC3164Timestamp(rest, now, loc)
+	if ok {
+		msg.timestamp = timestamp
+
+		// A hostname follows the timestamp unless the next word is already the tag
+		if end := strings.IndexByte(rest, ' '); end > 0 && !isSyslogTag(rest[:end]) {
+			msg.hostname = rest[:end]
+			rest = rest[end+1:]
+		}
+	}
+
+	msg.message = rest
+
+	// The tag ends at a colon, or at the bracketed process ID before it
+	end := strings.IndexByte(rest, ' ')
+	if end < 0 || !isSyslogTag(rest[:end]) {
+		return
+	}
+
+	tag := strings.TrimSuffix(rest[:end], ":")
+	if open := strings.IndexByte(tag, '['); open > 0 && strings.HasSuffix(tag, "]") {
+		msg.procID = tag[open+1 : len(tag)-1]
+		tag = tag[:open]
+	}
+	msg.appName = tag
+	msg.message = rest[end+1:]
+}
+
+// isSyslogTag reports whether a word looks like "tag:" or "tag[pid]:"
+func isSyslogTag(word string) bool {
+	if !strings.HasSuffix(word, ":") || len(word) < 2 {
+		return false
+	}
+
+	for _, c := range word[:len(word)-1] {
+		if c == '[' || c == ']' || c == '-' || c == '_' || c == '.' || c == '/' ||
+			(c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+			continue
+		}
+		return false
+	}
+
+	return true
+}
+
+// parseRFC3164Timestamp reads "Mmm dd hh:mm:ss", or an RFC 3339 timestamp
+// as sent by many newer daemons in the older format
+func parseRFC3164Timestamp(data string, now time.Time, loc *time.Location) (time.Time, string, bool) {
+	if end := strings.IndexByte(data, ' '); end > 0 {
+		if timestamp, err := time.Parse(time.RFC3339Nano, data[:end]); err == nil {
+			return timestamp, data[end+1:], true
+		}
+	}
+
+	const layout = "Jan _2 15:04:05"
+	if len(data) < len(layout) {
+		return time.Time{}, data, false
+	}
+
+	timestamp, err := time.ParseInLocation(layout, data[:len(layout)], loc)
+	if err != nil {
+		return time.Time{}, data, false
+	}
+
+	// Use the current year, unless that would put the message well in the future
+	now = now.In(loc)
+	timestamp = timestamp.AddDate(now.Year(), 0, 0)
+	if timestamp.After(now.Add(24 * time.Hour)) {
+		timestamp = timestamp.AddDate(-1, 0, 0)
+	}
+
+	return timestamp, strings.TrimPrefix(data[len(layout):], " "), true
+}
+
+// nilValue returns an empty string for the RFC 5424 nil value
+func nilValue(field string) string {
+	if field == syslogNil {
+		return ""
+	}
+	return field
+}
@@ -0,0 +1,142 @@
+package inputs
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sliink/collector/internal/model"
+	"github.com/sliink/collector/internal/plugin"
+)
+
+// SyslogInput receives RFC 3164 and RFC 5424 syslog messages over TCP, UDP,
+// TLS or Unix sockets, using the socket input's listener
+type SyslogInput struct {
+	*SocketInput
+	location *time.Location
+}
+
+func init() {
+	plugin.RegisterStandardInput("syslog", func(id string) model.InputPlugin {
+		return NewSyslogInput(id)
+	})
+}
+
+// NewSyslogInput creates a new syslog input instance
+func NewSyslogInput(id string) *SyslogInput {
+	input := &SyslogInput{
+		SocketInput: NewSocketInput(id),
+		location:    time.Local,
+	}
+	input.SocketInput.parseFrame = input.parseFrame
+	return input
+}
+
+// Name returns the plugin's human-readable name
+func (p *SyslogInput) Name() string {
+	return "Syslog Input"
+}
+
+// Configure configures the plugin, defaulting to UDP on port 5514 with
+// framing detected per message
+func (p *SyslogInput) Configure(config map[string]interface{}) bool {
+	merged := map[string]interface{}{
+		"protocol": "udp",
+		"address":  "localhost:5514",
+		"framing":  FramingAuto,
+	}
+	for k, v := range config {
+		merged[k] = v
+	}
+
+	return p.SocketInput.Configure(merged)
+}
+
+// Validate validates the plugin configuration
+func (p *SyslogInput) Validate() bool {
+	if _, err := p.parseLocation(); err != nil {
+		return false
+	}
+	return p.SocketInput.Validate()
+}
+
+// Start begins plugin operation
+func (p *SyslogInput) Start() bool {
+	location, err := p.parseLocation()
+	if err != nil {
+		return false
+	}
+
+	p.mu.Lock()
+	p.location = location
+	p.mu.Unlock()
+
+	return p.SocketInput.Start()
+}
+
+// parseLocation reads the time zone of RFC 3164 timestamps
+func (p *SyslogInput) parseLocation() (*time.Location, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	name, ok := p.config["timezone"].(string)
+	if !ok || name == "" {
+		return time.Local, nil
+	}
+
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone: %s", name)
+	}
+	return location, nil
+}
+
+// parseFrame fills in a point from the syslog message in its frame. Messages
+// that cannot be parsed are kept as they are, flagged with a parse error.
+func (p *SyslogInput) parseFrame(point *model.LogPoint) bool {
+	if strings.TrimSpace(point.Message) == "" {
+		return false
+	}
+
+	point.Labels["source"] = "syslog"
+
+	p.mu.Lock()
+	location := p.location
+	p.mu.Unlock()
+
+	msg, err := parseSyslog(point.Message, time.Now(), location)
+	if err != nil {
+		point.Attributes["syslog_parse_error"] = err.Error()
+		return true
+	}
+
+	point.Message = msg.message
+	point.Level = syslogLevels[msg.severity]
+	point.Labels["facility"] = syslogFacilities[msg.facility]
+	point.Attributes["facility"] = msg.facility
+	point.Attributes["severity"] = msg.severity
+	point.Attributes["severity_name"] = syslogSeverities[msg.severity]
+	point.Attributes["syslog_version"] = msg.version
+
+	if !msg.timestamp.IsZero() {
+		point.Timestamp = msg.timestamp
+	}
+	if msg.hostname != "" {
+		point.Labels["hostname"] = msg.hostname
+		point.Origin = msg.hostname
+	}
+	if msg.appName != "" {
+		point.Labels["app_name"] = msg.appName
+	}
+	if msg.procID != "" {
+		point.Attributes["proc_id"] = msg.procID
+	}
+	if msg.msgID != "" {
+		point.Attributes["msg_id"] = msg.msgID
+	}
+	if len(msg.structuredData) > 0 {
+		point.Attributes["structured_data"] = msg.structuredData
+	}
+
+	return true
+}
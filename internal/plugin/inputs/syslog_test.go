@@ -0,0 +1,160 @@
+package inputs
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSyslog(t *testing.T) {
+	now := time.Date(2024, 1, 5, 12, 0, 0, 0, time.UTC)
+
+	t.Run("RFC 5424 with structured data", func(t *testing.T) {
+		msg, err := parseSyslog(`<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut="3" eventSource="Application" eventID="1011"][examplePriority@32473 class="high \"quoted\" \]"] `+"\ufeff"+`An application event`, now, time.UTC)
+		assert.NoError(t, err)
+		assert.Equal(t, 20, msg.facility)
+		assert.Equal(t, 5, msg.severity)
+		assert.Equal(t, 1, msg.version)
+		assert.Equal(t, time.Date(2003, 10, 11, 22, 14, 15, 3000000, time.UTC), msg.timestamp)
+		assert.Equal(t, "mymachine.example.com", msg.hostname)
+		assert.Equal(t, "evntslog", msg.appName)
+		assert.Equal(t, "", msg.procID)
+		assert.Equal(t, "ID47", msg.msgID)
+		assert.Equal(t, map[string]map[string]string{
+			"exampleSDID@32473":     {"iut": "3", "eventSource": "Application", "eventID": "1011"},
+			"examplePriority@32473": {"class": `high "quoted" ]`},
+		}, msg.structuredData)
+		assert.Equal(t, "An application event", msg.message)
+	})
+
+	t.Run("RFC 5424 with nil values and no message", func(t *testing.T) {
+		msg, err := parseSyslog("<34>1 - - - - - -", now, time.UTC)
+		assert.NoError(t, err)
+		assert.True(t, msg.timestamp.IsZero())
+		assert.Equal(t, "", msg.hostname)
+		assert.Nil(t, msg.structuredData)
+		assert.Equal(t, "", msg.message)
+	})
+
+	t.Run("RFC 5424 rejects malformed headers", func(t *testing.T) {
+		for _, data := range []string{
+			"<34>1 2003-10-11T22:14:15Z host",
+			"<34>1 yesterday host app - - - message",
+			"<34>1 - host app - - [unterminated message",
+			"<34>1 - host app - - [id key=\"value] message",
+		} {
+			_, err := parseSyslog(data, now, time.UTC)
+			assert.Error(t, err, data)
+		}
+	})
+
+	t.Run("RFC 3164", func(t *testing.T) {
+		msg, err := parseSyslog("<34>Oct 11 22:14:15 mymachine su[230]: 'su root' failed for lonvick on /dev/pts/8", now, time.UTC)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, msg.facility)
+		assert.Equal(t, 2, msg.severity)
+		assert.Equal(t, 0, msg.version)
+		// October is after January, so the message is from last year
+		assert.Equal(t, time.Date(2023, 10, 11, 22, 14, 15, 0, time.UTC), msg.timestamp)
+		assert.Equal(t, "mymachine", msg.hostname)
+		assert.Equal(t, "su", msg.appName)
+		assert.Equal(t, "230", msg.procID)
+		assert.Equal(t, "'su root' failed for lonvick on /dev/pts/8", msg.message)
+	})
+
+	t.Run("RFC 3164 without hostname or timestamp", func(t *testing.T) {
+		msg, err := parseSyslog("<13>Jan  5 11:59:00 cron: job done", now, time.UTC)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 1, 5, 11, 59, 0, 0, time.UTC), msg.timestamp)
+		assert.Equal(t, "", msg.hostname)
+		assert.Equal(t, "cron", msg.appName)
+		assert.Equal(t, "job done", msg.message)
+
+		msg, err = parseSyslog("<13>just some text", now, time.UTC)
+		assert.NoError(t, err)
+		assert.True(t, msg.timestamp.IsZero())
+		assert.Equal(t, "", msg.appName)
+		assert.Equal(t, "just some text", msg.message)
+	})
+
+	t.Run("RFC 3164 with an RFC 3339 timestamp", func(t *testing.T) {
+		msg, err := parseSyslog("<30>2024-01-05T10:00:00+01:00 web nginx: started", now, time.UTC)
+		assert.NoError(t, err)
+		assert.True(t, msg.timestamp.Equal(time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC)))
+		assert.Equal(t, "web", msg.hostname)
+		assert.Equal(t, "nginx", msg.appName)
+	})
+
+	t.Run("Invalid priority", func(t *testing.T) {
+		for _, data := range []string{"no priority", "<192>too high", "<x>bad", "<>empty"} {
+			_, err := parseSyslog(data, now, time.UTC)
+			assert.Error(t, err, data)
+		}
+	})
+}
+
+func TestSyslogInput(t *testing.T) {
+	t.Run("Validate", func(t *testing.T) {
+		input := NewSyslogInput("syslog_input")
+		input.Configure(map[string]interface{}{})
+		assert.True(t, input.Validate())
+
+		input.Configure(map[string]interface{}{"timezone": "Mars/Olympus_Mons"})
+		assert.False(t, input.Validate())
+	})
+
+	t.Run("Messages over UDP become log points", func(t *testing.T) {
+		input := NewSyslogInput("syslog_input")
+		assert.True(t, input.Configure(map[string]interface{}{"address": "127.0.0.1:0"}))
+		assert.True(t, input.Validate())
+		assert.True(t, input.Initialize())
+		assert.True(t, input.Start())
+		defer input.Stop()
+
+		conn, err := net.Dial("udp", input.packetConn.LocalAddr().String())
+		assert.NoError(t, err)
+		defer conn.Close()
+		conn.Write([]byte("<11>1 2024-01-05T10:00:00Z host1 app 42 MSG1 [meta env=\"prod\"] disk failure\non /dev/sda\n"))
+		conn.Write([]byte("42 is not syslog"))
+
+		points := collectPoints(t, input.SocketInput, 2)
+		assert.Len(t, points, 2)
+
+		// Each datagram is one message, whatever it contains
+		assert.Equal(t, "disk failure\non /dev/sda", points[0].Message)
+		assert.Equal(t, "ERROR", points[0].Level)
+		assert.Equal(t, "host1", points[0].Origin)
+		assert.Equal(t, time.Date(2024, 1, 5, 10, 0, 0, 0, time.UTC), points[0].Timestamp)
+		assert.Equal(t, "syslog", points[0].Labels["source"])
+		assert.Equal(t, "user", points[0].Labels["facility"])
+		assert.Equal(t, "host1", points[0].Labels["hostname"])
+		assert.Equal(t, "app", points[0].Labels["app_name"])
+		assert.Equal(t, "42", points[0].Attributes["proc_id"])
+		assert.Equal(t, "MSG1", points[0].Attributes["msg_id"])
+		assert.Equal(t, "err", points[0].Attributes["severity_name"])
+		assert.Equal(t, map[string]map[string]string{"meta": {"env": "prod"}}, points[0].Attributes["structured_data"])
+
+		assert.Equal(t, "42 is not syslog", points[1].Message)
+		assert.Contains(t, points[1].Attributes, "syslog_parse_error")
+	})
+
+	t.Run("Octet-counted and newline frames over TCP", func(t *testing.T) {
+		input := NewSyslogInput("syslog_input")
+		assert.True(t, input.Configure(map[string]interface{}{"protocol": "tcp", "address": "127.0.0.1:0"}))
+		assert.True(t, input.Start())
+		defer input.Stop()
+
+		conn, err := net.Dial("tcp", input.listener.Addr().String())
+		assert.NoError(t, err)
+		conn.Write([]byte("28 <14>1 - - - - - - multi\nline\n<14>plain: one line\n"))
+		conn.Close()
+
+		points := collectPoints(t, input.SocketInput, 2)
+		assert.Len(t, points, 2)
+		assert.Equal(t, "multi\nline", points[0].Message)
+		assert.Equal(t, "one line", points[1].Message)
+		assert.Equal(t, "plain", points[1].Labels["app_name"])
+	})
+}
@@ -0,0 +1,16 @@
+package plugin
+
+// IntOption reads an integer config value. JSON decodes numbers as float64,
+// while configs built in Go or decoded from YAML hold ints.
+func IntOption(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int64:
+		return v, true
+	case float64:
+		return int64(v), true
+	default:
+		return 0, false
+	}
+}
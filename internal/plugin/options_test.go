@@ -0,0 +1,20 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntOption(t *testing.T) {
+	for _, value := range []interface{}{5, int64(5), float64(5)} {
+		n, ok := IntOption(value)
+		assert.True(t, ok, "%T", value)
+		assert.Equal(t, int64(5), n)
+	}
+
+	for _, value := range []interface{}{nil, "5", true} {
+		_, ok := IntOption(value)
+		assert.False(t, ok, "%T", value)
+	}
+}
@@ -0,0 +1,668 @@
+package outputs
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sliink/collector/internal/model"
+	"github.com/sliink/collector/internal/plugin"
+)
+
+// fileCheckInterval is how often open files are checked for rotation and
+// old files pruned between writes
+const fileCheckInterval = 10 * time.Second
+
+// labelPattern matches ${label} placeholders in a filename pattern
+var labelPattern = regexp.MustCompile(`\$\{([A-Za-z0-9_.-]+)\}`)
+
+// FileOutput writes data to files named from a pattern of labels and time.
+// Each distinct file has its own stream that is rotated, compressed and pruned
+// independently.
+type FileOutput struct {
+	plugin.BasePlugin
+	fileOutputSettings
+	files         map[string]*outputFile // Open files keyed by the label-expanded pattern
+	now           func() time.Time
+	checkInterval time.Duration
+	done          chan struct{}
+	wg            sync.WaitGroup
+	mutex         sync.Mutex
+}
+
+// fileOutputSettings holds the file output configuration
+type fileOutputSettings struct {
+	outputDir       string
+	filenamePattern string
+	format          string
+	rotateInterval  time.Duration
+	rotateSize      int64
+	compress        bool
+	maxFiles        int
+	maxAge          time.Duration
+}
+
+// outputFile is an open file being written by the file output
+type outputFile struct {
+	path     string
+	file     *os.File
+	writer   *bufio.Writer
+	size     int64
+	deadline time.Time // Zero when time-based rotation is disabled
+}
+
+func init() {
+	plugin.RegisterStandardOutput("file", func(id string) model.OutputPlugin {
+		return NewFileOutput(id)
+	})
+}
+
+// NewFileOutput creates a new file output plugin
+func NewFileOutput(id string) *FileOutput {
+	return &FileOutput{
+		BasePlugin:         plugin.NewBasePlugin(id, "File Output", model.OutputPluginType),
+		fileOutputSettings: defaultFileOutputSettings(id),
+		files:              make(map[string]*outputFile),
+		now:                time.Now,
+		checkInterval:      fileCheckInterval,
+	}
+}
+
+// defaultFileOutputSettings returns the settings used for missing options
+func defaultFileOutputSettings(id string) fileOutputSettings {
+	return fileOutputSettings{
+		outputDir:       "./output",
+		filenamePattern: id + ".log",
+		format:          "text",
+	}
+}
+
+// Initialize prepares the file output for operation
+func (f *FileOutput) Initialize() bool {
+	settings, err := parseFileOutputSettings(f.Config, f.ID())
+	if err != nil {
+		return false
+	}
+	f.fileOutputSettings = settings
+
+	if err := os.MkdirAll(f.outputDir, 0755); err != nil {
+		return false
+	}
+
+	f.SetStatus(model.StatusInitialized)
+	return true
+}
+
+// Start begins file output operation, checking open files for rotation in
+// the background so that a file is rotated on time even if no more data
+// arrives for it
+func (f *FileOutput) Start() bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.done == nil {
+		f.done = make(chan struct{})
+		f.wg.Add(1)
+		go f.maintain(f.done)
+	}
+
+	f.SetStatus(model.StatusRunning)
+	return true
+}
+
+// Stop halts file output operation, flushing and closing open files
+func (f *FileOutput) Stop() bool {
+	f.mutex.Lock()
+	done := f.done
+	f.done = nil
+	f.mutex.Unlock()
+
+	if done != nil {
+		close(done)
+		f.wg.Wait()
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for stream, file := range f.files {
+		file.close()
+		delete(f.files, stream)
+	}
+
+	f.SetStatus(model.StatusStopped)
+	return true
+}
+
+// Validate checks if the file output is properly configured
+func (f *FileOutput) Validate() bool {
+	_, err := parseFileOutputSettings(f.Config, f.ID())
+	return err == nil
+}
+
+// Send writes a data batch to its files
+func (f *FileOutput) Send(batch *model.DataBatch) bool {
+	if batch == nil || batch.Size() == 0 {
+		return true
+	}
+
+	if f.GetStatus() != model.StatusRunning {
+		return false
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	var buf bytes.Buffer
+	written := make(map[*outputFile]bool)
+	success := true
+
+	for _, point := range batch.Points {
+		buf.Reset()
+		if err := f.encode(&buf, point); err != nil {
+			continue // Skip points that cannot be encoded
+		}
+
+		file, err := f.fileFor(expandLabels(f.filenamePattern, point), int64(buf.Len()))
+		if err != nil {
+			success = false
+			break
+		}
+
+		if _, err := file.writer.Write(buf.Bytes()); err != nil {
+			success = false
+			break
+		}
+		file.size += int64(buf.Len())
+		written[file] = true
+	}
+
+	for file := range written {
+		if err := file.writer.Flush(); err != nil {
+			success = false
+		}
+	}
+
+	return success
+}
+
+// maintain checks the open files every check interval until done is closed
+func (f *FileOutput) maintain(done <-chan struct{}) {
+	defer f.wg.Done()
+
+	ticker := time.NewTicker(f.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			for _, err := range f.rotateDue() {
+				if core := f.Core(); core != nil {
+					core.PublishEvent(model.EventError, f.ID(), err)
+				}
+			}
+		}
+	}
+}
+
+// rotateDue rotates the open files that have passed their rotation time or
+// size, and prunes the rotated files of the others when max_age is set
+func (f *FileOutput) rotateDue() []error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	now := f.now()
+	var errs []error
+	for stream, file := range f.files {
+		var err error
+		if f.needsRotation(file, now, 0) {
+			err = f.rotate(stream, file, now)
+		} else if f.maxAge > 0 {
+			err = f.prune(stream, file.path, now)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// encode writes a data point in the configured format
+func (f *FileOutput) encode(w io.Writer, point model.DataPoint) error {
+	switch f.format {
+	case "json":
+		return writeJSON(w, point, true)
+	case "ndjson":
+		return writeJSON(w, point, false)
+	default:
+		return writeText(w, point, false)
+	}
+}
+
+// fileFor returns the open file for a stream, rotating it first if the next
+// write of size bytes is due for rotation
+func (f *FileOutput) fileFor(stream string, size int64) (*outputFile, error) {
+	now := f.now()
+
+	file := f.files[stream]
+	if file != nil && f.needsRotation(file, now, size) {
+		if err := f.rotate(stream, file, now); err != nil {
+			return nil, err
+		}
+		file = nil
+	}
+
+	if file == nil {
+		var err error
+		file, err = f.open(f.resolvePath(stream, now), now)
+		if err != nil {
+			return nil, err
+		}
+		f.files[stream] = file
+	}
+
+	return file, nil
+}
+
+// needsRotation checks whether a file has passed its rotation time or would
+// grow past the rotation size
+func (f *FileOutput) needsRotation(file *outputFile, now time.Time, size int64) bool {
+	if !file.deadline.IsZero() && !now.Before(file.deadline) {
+		return true
+	}
+
+	return f.rotateSize > 0 && file.size > 0 && file.size+size > f.rotateSize
+}
+
+// open opens a file for appending
+func (f *FileOutput) open(path string, now time.Time) (*outputFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open output file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to open output file: %w", err)
+	}
+
+	out := &outputFile{
+		path:   path,
+		file:   file,
+		writer: bufio.NewWriter(file),
+		size:   info.Size(),
+	}
+	if f.rotateInterval > 0 {
+		out.deadline = rotationDeadline(now, f.rotateInterval)
+	}
+
+	return out, nil
+}
+
+// rotationDeadline returns the end of the rotation period that contains now.
+// Periods are counted from local midnight so they line up with the local
+// times in file names, and periods shorter than a day end at midnight at the
+// latest.
+func rotationDeadline(now time.Time, interval time.Duration) time.Time {
+	year, month, day := now.Date()
+	midnight := time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+
+	const fullDay = 24 * time.Hour
+	if interval >= fullDay {
+		if interval%fullDay == 0 {
+			return midnight.AddDate(0, 0, int(interval/fullDay))
+		}
+		return midnight.Add(interval)
+	}
+
+	deadline := midnight.Add(now.Sub(midnight).Truncate(interval) + interval)
+	if next := midnight.AddDate(0, 0, 1); deadline.After(next) {
+		return next
+	}
+	return deadline
+}
+
+// rotate closes a stream's file, moves it aside if the stream would reopen
+// the same path, compresses it if configured and prunes old files
+func (f *FileOutput) rotate(stream string, file *outputFile, now time.Time) error {
+	delete(f.files, stream)
+	if err := file.close(); err != nil {
+		return err
+	}
+
+	nextPath := f.resolvePath(stream, now)
+	rotated := file.path
+	if nextPath == file.path {
+		rotated = backupPath(file.path, now)
+		if err := os.Rename(file.path, rotated); err != nil {
+			return fmt.Errorf("failed to rotate output file: %w", err)
+		}
+	}
+
+	if f.compress {
+		if err := compressFile(rotated); err != nil {
+			return err
+		}
+	}
+
+	return f.prune(stream, nextPath, now)
+}
+
+// prune removes a stream's rotated files beyond max_files or older than max_age
+func (f *FileOutput) prune(stream string, activePath string, now time.Time) error {
+	if f.maxFiles <= 0 && f.maxAge <= 0 {
+		return nil
+	}
+
+	pattern := filepath.Join(f.outputDir, stream)
+	ext := filepath.Ext(pattern)
+	if strings.Contains(ext, "%") {
+		ext = ""
+	}
+	base := strings.TrimSuffix(pattern, ext)
+
+	candidates, err := filepath.Glob(timeGlob(base) + "*" + globEscape(ext) + "*")
+	if err != nil {
+		return err
+	}
+
+	matcher, err := regexp.Compile("^" + timeRegexp(base) + `(-\d{8}T\d{6}(-\d+)?)?` + regexp.QuoteMeta(ext) + `(\.gz)?$`)
+	if err != nil {
+		return err
+	}
+
+	type rotatedFile struct {
+		path    string
+		modTime time.Time
+	}
+
+	var files []rotatedFile
+	for _, path := range candidates {
+		if path == activePath || !matcher.MatchString(path) {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		files = append(files, rotatedFile{path: path, modTime: info.ModTime()})
+	}
+
+	// Newest first
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.After(files[j].modTime)
+	})
+
+	for i, file := range files {
+		expired := f.maxAge > 0 && now.Sub(file.modTime) > f.maxAge
+		if (f.maxFiles > 0 && i >= f.maxFiles) || expired {
+			if err := os.Remove(file.path); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove old output file: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolvePath returns the file path for a stream at a given time
+func (f *FileOutput) resolvePath(stream string, now time.Time) string {
+	return filepath.Join(f.outputDir, formatTime(stream, now))
+}
+
+// close flushes and closes an output file
+func (o *outputFile) close() error {
+	err := o.writer.Flush()
+	if closeErr := o.file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to close output file: %w", err)
+	}
+
+	return nil
+}
+
+// parseFileOutputSettings reads the file output configuration
+func parseFileOutputSettings(config map[string]interface{}, id string) (fileOutputSettings, error) {
+	settings := defaultFileOutputSettings(id)
+
+	if outputDir, ok := config["output_dir"].(string); ok {
+		settings.outputDir = outputDir
+	}
+	if settings.outputDir == "" {
+		return settings, fmt.Errorf("output_dir is required")
+	}
+
+	if pattern, ok := config["filename_pattern"].(string); ok {
+		settings.filenamePattern = pattern
+	}
+	if settings.filenamePattern == "" || !filepath.IsLocal(settings.filenamePattern) {
+		return settings, fmt.Errorf("filename_pattern must be a relative path inside output_dir")
+	}
+
+	if format, ok := config["format"].(string); ok {
+		settings.format = format
+	}
+	switch settings.format {
+	case "text", "json", "ndjson":
+	default:
+		return settings, fmt.Errorf("invalid format: %s", settings.format)
+	}
+
+	if intervalStr, ok := config["rotate_interval"].(string); ok {
+		interval, err := time.ParseDuration(intervalStr)
+		if err != nil || interval < 0 {
+			return settings, fmt.Errorf("invalid rotate_interval: %s", intervalStr)
+		}
+		settings.rotateInterval = interval
+	}
+
+	if rotateSize, ok := plugin.IntOption(config["rotate_size"]); ok {
+		if rotateSize < 0 {
+			return settings, fmt.Errorf("rotate_size must not be negative")
+		}
+		settings.rotateSize = rotateSize
+	}
+
+	if compress, ok := config["compress"].(bool); ok {
+		settings.compress = compress
+	}
+
+	if maxFiles, ok := plugin.IntOption(config["max_files"]); ok {
+		if maxFiles < 0 {
+			return settings, fmt.Errorf("max_files must not be negative")
+		}
+		settings.maxFiles = int(maxFiles)
+	}
+
+	if maxAgeStr, ok := config["max_age"].(string); ok {
+		maxAge, err := time.ParseDuration(maxAgeStr)
+		if err != nil || maxAge < 0 {
+			return settings, fmt.Errorf("invalid max_age: %s", maxAgeStr)
+		}
+		settings.maxAge = maxAge
+	}
+
+	return settings, nil
+}
+
+// expandLabels replaces ${label} placeholders with the point's label values.
+// ${origin} falls back to the point's origin when there is no such label.
+func expandLabels(pattern string, point model.DataPoint) string {
+	return labelPattern.ReplaceAllStringFunc(pattern, func(match string) string {
+		name := match[2 : len(match)-1]
+		value, ok := point.GetLabels()[name]
+		if !ok && name == "origin" {
+			value = point.GetOrigin()
+		}
+		return sanitizePathValue(value)
+	})
+}
+
+// sanitizePathValue makes a label value safe to use as part of a file name
+func sanitizePathValue(value string) string {
+	value = strings.NewReplacer("/", "_", "\\", "_", "%", "_").Replace(value)
+	switch value {
+	case "":
+		return "unknown"
+	case ".", "..":
+		return "_"
+	}
+
+	return value
+}
+
+// formatTime expands strftime-style %Y, %m, %d, %H, %M, %S and %j directives
+func formatTime(pattern string, t time.Time) string {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '%' || i+1 == len(pattern) {
+			b.WriteByte(pattern[i])
+			continue
+		}
+
+		i++
+		switch pattern[i] {
+		case 'Y':
+			fmt.Fprintf(&b, "%04d", t.Year())
+		case 'm':
+			fmt.Fprintf(&b, "%02d", int(t.Month()))
+		case 'd':
+			fmt.Fprintf(&b, "%02d", t.Day())
+		case 'H':
+			fmt.Fprintf(&b, "%02d", t.Hour())
+		case 'M':
+			fmt.Fprintf(&b, "%02d", t.Minute())
+		case 'S':
+			fmt.Fprintf(&b, "%02d", t.Second())
+		case 'j':
+			fmt.Fprintf(&b, "%03d", t.YearDay())
+		case '%':
+			b.WriteByte('%')
+		default:
+			b.WriteByte('%')
+			b.WriteByte(pattern[i])
+		}
+	}
+
+	return b.String()
+}
+
+// timeGlob turns a pattern into a glob matching any time it expands to
+func timeGlob(pattern string) string {
+	return convertTimePattern(pattern, globEscape, func(byte) string { return "*" })
+}
+
+// timeRegexp turns a pattern into a regular expression matching any time it expands to
+func timeRegexp(pattern string) string {
+	return convertTimePattern(pattern, regexp.QuoteMeta, func(directive byte) string {
+		switch directive {
+		case 'Y':
+			return `\d{4}`
+		case 'j':
+			return `\d{3}`
+		default:
+			return `\d{2}`
+		}
+	})
+}
+
+// convertTimePattern rewrites the literal text and time directives of a pattern
+func convertTimePattern(pattern string, literal func(string) string, directive func(byte) string) string {
+	var b strings.Builder
+	start := 0
+	for i := 0; i+1 < len(pattern); i++ {
+		if pattern[i] != '%' || !strings.ContainsRune("YmdHMSj%", rune(pattern[i+1])) {
+			continue
+		}
+
+		b.WriteString(literal(pattern[start:i]))
+		if pattern[i+1] == '%' {
+			b.WriteString(literal("%"))
+		} else {
+			b.WriteString(directive(pattern[i+1]))
+		}
+		i++
+		start = i + 1
+	}
+	b.WriteString(literal(pattern[start:]))
+
+	return b.String()
+}
+
+// globEscape escapes the glob metacharacters in a literal path
+func globEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`).Replace(s)
+}
+
+// backupPath returns an unused name for a rotated file, stamped with the rotation time
+func backupPath(path string, now time.Time) string {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext) + "-" + now.Format("20060102T150405")
+
+	candidate := base + ext
+	for i := 1; fileExists(candidate) || fileExists(candidate+".gz"); i++ {
+		candidate = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
+
+	return candidate
+}
+
+// fileExists checks whether a path exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// compressFile gzips a file in place, replacing it with path.gz
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to compress output file: %w", err)
+	}
+	defer src.Close()
+
+	gzPath := path + ".gz"
+	dst, err := os.Create(gzPath)
+	if err != nil {
+		return fmt.Errorf("failed to compress output file: %w", err)
+	}
+
+	writer := gzip.NewWriter(dst)
+	_, err = io.Copy(writer, src)
+	if closeErr := writer.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(gzPath)
+		return fmt.Errorf("failed to compress output file: %w", err)
+	}
+
+	src.Close()
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove compressed output file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,295 @@
+package outputs
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sliink/collector/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func createServiceBatch(service string, messages ...string) *model.DataBatch {
+	batch := model.NewDataBatch(model.LogTelemetryType)
+	for _, message := range messages {
+		batch.AddPoint(&model.LogPoint{
+			BaseDataPoint: model.BaseDataPoint{
+				Timestamp: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+				Origin:    "test",
+				Labels:    map[string]string{"service": service},
+			},
+			Message: message,
+			Level:   "INFO",
+		})
+	}
+	return batch
+}
+
+func listFiles(t *testing.T, dir string) []string {
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			rel, _ := filepath.Rel(dir, path)
+			files = append(files, rel)
+		}
+		return err
+	})
+	assert.NoError(t, err)
+	sort.Strings(files)
+	return files
+}
+
+func TestFileOutputValidate(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{"output_dir": ""},
+		{"filename_pattern": "../escape.log"},
+		{"filename_pattern": "/var/log/out.log"},
+		{"format": "xml"},
+		{"rotate_interval": "hourly"},
+		{"rotate_size": float64(-1)},
+		{"max_age": "forever"},
+	}
+
+	for _, config := range invalid {
+		output := NewFileOutput("file_output")
+		output.Configure(config)
+		assert.False(t, output.Validate(), "%v", config)
+		assert.False(t, output.Initialize(), "%v", config)
+	}
+}
+
+func TestFileOutputSend(t *testing.T) {
+	t.Run("Send returns false when not running", func(t *testing.T) {
+		output := NewFileOutput("file_output")
+		output.Configure(map[string]interface{}{"output_dir": t.TempDir()})
+		output.Initialize()
+
+		assert.False(t, output.Send(createServiceBatch("api", "hello")))
+	})
+
+	t.Run("Files are named from labels and time", func(t *testing.T) {
+		dir := t.TempDir()
+		output := startTestOutput(t, NewFileOutput("file_output"), map[string]interface{}{
+			"output_dir":       dir,
+			"filename_pattern": "${service}/${service}-%Y%m%d-%H.log",
+		})
+		output.now = func() time.Time { return time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC) }
+
+		batch := createServiceBatch("api", "first")
+		batch.AddPoint(createServiceBatch("db", "second").Points[0])
+		batch.AddPoint(createServiceBatch("../etc", "third").Points[0])
+		assert.True(t, output.Send(batch))
+
+		assert.Equal(t, []string{
+			".._etc/.._etc-20240501-10.log",
+			"api/api-20240501-10.log",
+			"db/db-20240501-10.log",
+		}, listFiles(t, dir))
+
+		data, err := os.ReadFile(filepath.Join(dir, "api", "api-20240501-10.log"))
+		assert.NoError(t, err)
+		assert.Equal(t, "[2024-05-01T10:00:00Z] INFO: first\n", string(data))
+	})
+
+	t.Run("NDJSON writes one object per line", func(t *testing.T) {
+		dir := t.TempDir()
+		output := startTestOutput(t, NewFileOutput("file_output"), map[string]interface{}{
+			"output_dir":       dir,
+			"filename_pattern": "out.ndjson",
+			"format":           "ndjson",
+		})
+
+		assert.True(t, output.Send(createServiceBatch("api", "one", "two")))
+
+		data, err := os.ReadFile(filepath.Join(dir, "out.ndjson"))
+		assert.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		assert.Len(t, lines, 2)
+
+		var record map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(lines[1]), &record))
+		assert.Equal(t, "two", record["message"])
+	})
+
+	t.Run("Files are appended across restarts", func(t *testing.T) {
+		dir := t.TempDir()
+		config := map[string]interface{}{"output_dir": dir, "filename_pattern": "out.log"}
+
+		output := startTestOutput(t, NewFileOutput("file_output"), config)
+		assert.True(t, output.Send(createServiceBatch("api", "one")))
+		output.Stop()
+
+		output = startTestOutput(t, NewFileOutput("file_output"), config)
+		assert.True(t, output.Send(createServiceBatch("api", "two")))
+
+		data, err := os.ReadFile(filepath.Join(dir, "out.log"))
+		assert.NoError(t, err)
+		assert.Equal(t, 2, strings.Count(string(data), "\n"))
+	})
+}
+
+func TestFileOutputRotation(t *testing.T) {
+	t.Run("Time-based rotation starts a new file each interval", func(t *testing.T) {
+		dir := t.TempDir()
+		output := startTestOutput(t, NewFileOutput("file_output"), map[string]interface{}{
+			"output_dir":       dir,
+			"filename_pattern": "app-%H.log",
+			"rotate_interval":  "1h",
+		})
+
+		now := time.Date(2024, 5, 1, 10, 59, 0, 0, time.UTC)
+		output.now = func() time.Time { return now }
+		assert.True(t, output.Send(createServiceBatch("api", "before")))
+
+		now = now.Add(2 * time.Minute)
+		assert.True(t, output.Send(createServiceBatch("api", "after")))
+
+		assert.Equal(t, []string{"app-10.log", "app-11.log"}, listFiles(t, dir))
+	})
+
+	t.Run("Time-based rotation follows local time", func(t *testing.T) {
+		dir := t.TempDir()
+		output := startTestOutput(t, NewFileOutput("file_output"), map[string]interface{}{
+			"output_dir":       dir,
+			"filename_pattern": "app-%Y%m%d.log",
+			"rotate_interval":  "24h",
+		})
+
+		// Midnight UTC falls in the afternoon, which must not end the local day
+		pacific := time.FixedZone("UTC-8", -8*60*60)
+		now := time.Date(2024, 5, 1, 15, 30, 0, 0, pacific)
+		output.now = func() time.Time { return now }
+		assert.True(t, output.Send(createServiceBatch("api", "afternoon")))
+
+		now = time.Date(2024, 5, 1, 17, 0, 0, 0, pacific)
+		assert.True(t, output.Send(createServiceBatch("api", "evening")))
+
+		now = time.Date(2024, 5, 2, 0, 1, 0, 0, pacific)
+		assert.True(t, output.Send(createServiceBatch("api", "next day")))
+
+		assert.Equal(t, []string{"app-20240501.log", "app-20240502.log"}, listFiles(t, dir))
+
+		// Hours start on the hour in zones with a half-hour offset
+		india := time.FixedZone("UTC+5:30", 5*60*60+30*60)
+		assert.Equal(t, time.Date(2024, 5, 1, 11, 0, 0, 0, india), rotationDeadline(time.Date(2024, 5, 1, 10, 45, 0, 0, india), time.Hour))
+		assert.Equal(t, time.Date(2024, 5, 2, 0, 0, 0, 0, india), rotationDeadline(time.Date(2024, 5, 1, 22, 0, 0, 0, india), 7*time.Hour))
+	})
+
+	t.Run("Time-based rotation moves aside files without time in the name", func(t *testing.T) {
+		dir := t.TempDir()
+		output := startTestOutput(t, NewFileOutput("file_output"), map[string]interface{}{
+			"output_dir":       dir,
+			"filename_pattern": "app.log",
+			"rotate_interval":  "1h",
+		})
+
+		now := time.Date(2024, 5, 1, 10, 59, 0, 0, time.UTC)
+		output.now = func() time.Time { return now }
+		assert.True(t, output.Send(createServiceBatch("api", "before")))
+
+		now = now.Add(2 * time.Minute)
+		assert.True(t, output.Send(createServiceBatch("api", "after")))
+
+		assert.Equal(t, []string{"app-20240501T110100.log", "app.log"}, listFiles(t, dir))
+	})
+
+	t.Run("Size-based rotation compresses and prunes old files", func(t *testing.T) {
+		dir := t.TempDir()
+		output := startTestOutput(t, NewFileOutput("file_output"), map[string]interface{}{
+			"output_dir":       dir,
+			"filename_pattern": "${service}.log",
+			"rotate_size":      float64(40),
+			"compress":         true,
+			"max_files":        float64(2),
+		})
+
+		now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+		output.now = func() time.Time { return now }
+
+		for i := 0; i < 5; i++ {
+			now = now.Add(time.Second)
+			assert.True(t, output.Send(createServiceBatch("api", "a message that fills the file")))
+		}
+		assert.True(t, output.Send(createServiceBatch("api-web", "another service")))
+
+		files := listFiles(t, dir)
+		assert.Equal(t, []string{
+			"api-20240501T100004.log.gz",
+			"api-20240501T100005.log.gz",
+			"api-web.log",
+			"api.log",
+		}, files)
+
+		file, err := os.Open(filepath.Join(dir, files[0]))
+		assert.NoError(t, err)
+		defer file.Close()
+		reader, err := gzip.NewReader(file)
+		assert.NoError(t, err)
+		data, err := io.ReadAll(reader)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), "a message that fills the file")
+	})
+
+	t.Run("Rotated files older than max_age are removed", func(t *testing.T) {
+		dir := t.TempDir()
+		output := startTestOutput(t, NewFileOutput("file_output"), map[string]interface{}{
+			"output_dir":       dir,
+			"filename_pattern": "app-%H.log",
+			"rotate_interval":  "1h",
+			"max_age":          "90m",
+		})
+
+		stale := filepath.Join(dir, "app-07.log")
+		assert.NoError(t, os.WriteFile(stale, []byte("old\n"), 0644))
+		assert.NoError(t, os.Chtimes(stale, time.Now().Add(-3*time.Hour), time.Now().Add(-3*time.Hour)))
+
+		now := time.Now().Truncate(time.Hour).Add(30 * time.Minute)
+		output.now = func() time.Time { return now }
+		assert.True(t, output.Send(createServiceBatch("api", "before")))
+
+		now = now.Add(time.Hour)
+		assert.True(t, output.Send(createServiceBatch("api", "after")))
+
+		_, err := os.Stat(stale)
+		assert.True(t, os.IsNotExist(err))
+		assert.Len(t, listFiles(t, dir), 2)
+	})
+
+	t.Run("Idle files are rotated without waiting for a write", func(t *testing.T) {
+		dir := t.TempDir()
+		output := NewFileOutput("file_output")
+		output.checkInterval = 10 * time.Millisecond
+
+		var clock sync.Mutex
+		now := time.Date(2024, 5, 1, 10, 59, 0, 0, time.UTC)
+		output.now = func() time.Time {
+			clock.Lock()
+			defer clock.Unlock()
+			return now
+		}
+
+		startTestOutput(t, output, map[string]interface{}{
+			"output_dir":       dir,
+			"filename_pattern": "app.log",
+			"rotate_interval":  "1h",
+			"compress":         true,
+		})
+		assert.True(t, output.Send(createServiceBatch("api", "before")))
+
+		clock.Lock()
+		now = now.Add(2 * time.Minute)
+		clock.Unlock()
+
+		assert.Eventually(t, func() bool {
+			files, _ := os.ReadDir(dir)
+			return len(files) == 1 && files[0].Name() == "app-20240501T110100.log.gz"
+		}, 3*time.Second, 10*time.Millisecond)
+	})
+}
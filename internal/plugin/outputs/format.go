@@ -0,0 +1,111 @@
+package outputs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/sliink/collector/internal/model"
+)
+
+// writeJSON writes a data point as a JSON object followed by a newline
+func writeJSON(w io.Writer, point model.DataPoint, indent bool) error {
+	var data []byte
+	var err error
+	if indent {
+		data, err = json.MarshalIndent(point.ToMap(), "", "  ")
+	} else {
+		data, err = json.Marshal(point.ToMap())
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// writeText writes a data point in the human-readable text format
+func writeText(w io.Writer, point model.DataPoint, colorize bool) error {
+	switch p := point.(type) {
+	case *model.LogPoint:
+		return writeLogPoint(w, p, colorize)
+	case *model.MetricPoint:
+		return writeMetricPoint(w, p)
+	case *model.TracePoint:
+		return writeTracePoint(w, p)
+	default:
+		_, err := fmt.Fprintf(w, "Unknown point type: %T\n", point)
+		return err
+	}
+}
+
+// writeLogPoint writes a log point as text
+func writeLogPoint(w io.Writer, point *model.LogPoint, colorize bool) error {
+	timestamp := point.Timestamp.Format(time.RFC3339)
+
+	// Apply color if enabled
+	level := point.Level
+	if colorize {
+		switch point.Level {
+		case "ERROR", "FATAL":
+			level = "\033[31m" + level + "\033[0m" // Red
+		case "WARN", "WARNING":
+			level = "\033[33m" + level + "\033[0m" // Yellow
+		case "INFO":
+			level = "\033[32m" + level + "\033[0m" // Green
+		case "DEBUG":
+			level = "\033[36m" + level + "\033[0m" // Cyan
+		case "TRACE":
+			level = "\033[35m" + level + "\033[0m" // Magenta
+		}
+	}
+
+	if _, err := fmt.Fprintf(w, "[%s] %s: %s\n", timestamp, level, point.Message); err != nil {
+		return err
+	}
+
+	// Output attributes if any
+	if len(point.Attributes) > 0 {
+		attributesJSON, _ := json.Marshal(point.Attributes)
+		if _, err := fmt.Fprintf(w, "  %s\n", string(attributesJSON)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeMetricPoint writes a metric point as text
+func writeMetricPoint(w io.Writer, point *model.MetricPoint) error {
+	timestamp := point.Timestamp.Format(time.RFC3339)
+	if _, err := fmt.Fprintf(w, "[%s] METRIC %s: %f\n", timestamp, point.Name, point.Value); err != nil {
+		return err
+	}
+
+	// Output dimensions if any
+	if len(point.Dimensions) > 0 {
+		dimensionsJSON, _ := json.Marshal(point.Dimensions)
+		if _, err := fmt.Fprintf(w, "  %s\n", string(dimensionsJSON)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeTracePoint writes a trace point as text
+func writeTracePoint(w io.Writer, point *model.TracePoint) error {
+	timestamp := point.Timestamp.Format(time.RFC3339)
+	duration := point.EndTime.Sub(point.StartTime).Milliseconds()
+
+	name := ""
+	if point.Name != "" {
+		name = " " + point.Name
+	}
+
+	_, err := fmt.Fprintf(w, "[%s] TRACE %s (span: %s)%s: %dms\n",
+		timestamp, point.TraceID, point.SpanID, name, duration)
+	return err
+}
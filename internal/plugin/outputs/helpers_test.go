@@ -0,0 +1,36 @@
+package outputs
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/sliink/collector/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// testCore is a core that records the events published by an output
+type testCore struct {
+	events []interface{}
+	mutex  sync.Mutex
+}
+
+func (c *testCore) ProcessBatch(batch *model.DataBatch) *model.DataBatch {
+	return batch
+}
+
+func (c *testCore) PublishEvent(eventType model.EventType, sourceID string, data interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.events = append(c.events, data)
+}
+
+// startTestOutput configures, validates, initializes and starts an output,
+// stopping it when the test ends
+func startTestOutput[T model.OutputPlugin](t *testing.T, output T, config map[string]interface{}) T {
+	assert.True(t, output.Configure(config))
+	assert.True(t, output.Validate())
+	assert.True(t, output.Initialize())
+	assert.True(t, output.Start())
+	t.Cleanup(func() { output.Stop() })
+	return output
+}
@@ -0,0 +1,510 @@
+package outputs
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sliink/collector/internal/model"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// otlpSeverityNumbers maps log levels to the first OTLP severity number of their range
+var otlpSeverityNumbers = map[string]logspb.SeverityNumber{
+	"TRACE":   logspb.SeverityNumber_SEVERITY_NUMBER_TRACE,
+	"DEBUG":   logspb.SeverityNumber_SEVERITY_NUMBER_DEBUG,
+	"INFO":    logspb.SeverityNumber_SEVERITY_NUMBER_INFO,
+	"WARN":    logspb.SeverityNumber_SEVERITY_NUMBER_WARN,
+	"WARNING": logspb.SeverityNumber_SEVERITY_NUMBER_WARN,
+	"ERROR":   logspb.SeverityNumber_SEVERITY_NUMBER_ERROR,
+	"FATAL":   logspb.SeverityNumber_SEVERITY_NUMBER_FATAL,
+}
+
+// otlpGroup holds the points that share a resource and instrumentation scope
+type otlpGroup struct {
+	resource *resourcepb.Resource
+	scope    *commonpb.InstrumentationScope
+	points   []model.DataPoint
+}
+
+// groupOTLPPoints groups points by their labels and origin. Labels become
+// resource attributes, except otel.scope.name and otel.scope.version which
+// name the scope, and the origin becomes service.name unless one is set.
+func groupOTLPPoints(points []model.DataPoint) []*otlpGroup {
+	var groups []*otlpGroup
+	byKey := make(map[string]*otlpGroup)
+
+	for _, point := range points {
+		attributes := make(map[string]interface{}, len(point.GetLabels())+1)
+		scope := &commonpb.InstrumentationScope{}
+		for k, v := range point.GetLabels() {
+			switch k {
+			case "otel.scope.name":
+				scope.Name = v
+			case "otel.scope.version":
+				scope.Version = v
+			default:
+				attributes[k] = v
+			}
+		}
+		if _, ok := attributes["service.name"]; !ok && point.GetOrigin() != "" {
+			attributes["service.name"] = point.GetOrigin()
+		}
+
+		key := resourceKey(attributes, scope)
+		group, exists := byKey[key]
+		if !exists {
+			group = &otlpGroup{
+				resource: &resourcepb.Resource{Attributes: keyValues(attributes)},
+				scope:    scope,
+			}
+			byKey[key] = group
+			groups = append(groups, group)
+		}
+		group.points = append(group.points, point)
+	}
+
+	return groups
+}
+
+// resourceKey returns a string identifying a resource and scope
+func resourceKey(attributes map[string]interface{}, scope *commonpb.InstrumentationScope) string {
+	parts := make([]string, 0, len(attributes)+2)
+	for k, v := range attributes {
+		parts = append(parts, k+"="+fmt.Sprint(v))
+	}
+	sort.Strings(parts)
+	parts = append(parts, scope.GetName(), scope.GetVersion())
+	return strings.Join(parts, "\x00")
+}
+
+// otlpLogsRequest converts log points to an export request
+func otlpLogsRequest(points []model.DataPoint) *collogspb.ExportLogsServiceRequest {
+	request := &collogspb.ExportLogsServiceRequest{}
+
+	for _, group := range groupOTLPPoints(points) {
+		scopeLogs := &logspb.ScopeLogs{Scope: group.scope}
+		for _, point := range group.points {
+			if p, ok := point.(*model.LogPoint); ok {
+				scopeLogs.LogRecords = append(scopeLogs.LogRecords, otlpLogRecord(p))
+			}
+		}
+
+		request.ResourceLogs = append(request.ResourceLogs, &logspb.ResourceLogs{
+			Resource:  group.resource,
+			ScopeLogs: []*logspb.ScopeLogs{scopeLogs},
+		})
+	}
+
+	return request
+}
+
+// otlpLogRecord converts a log point. The severity_number, severity_text,
+// trace_id and span_id attributes set by the OTLP input are mapped back to
+// record fields.
+func otlpLogRecord(point *model.LogPoint) *logspb.LogRecord {
+	attributes := make(map[string]interface{}, len(point.Attributes))
+	for k, v := range point.Attributes {
+		attributes[k] = v
+	}
+
+	record := &logspb.LogRecord{
+		TimeUnixNano:   timeUnixNano(point.Timestamp),
+		SeverityNumber: otlpSeverityNumbers[strings.ToUpper(point.Level)],
+		SeverityText:   point.Level,
+		Body:           &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: point.Message}},
+	}
+
+	if number, ok := intValue(attributes["severity_number"]); ok {
+		record.SeverityNumber = logspb.SeverityNumber(number)
+		delete(attributes, "severity_number")
+	}
+	if text, ok := attributes["severity_text"].(string); ok {
+		record.SeverityText = text
+		delete(attributes, "severity_text")
+	}
+	if id, ok := hexID(attributes["trace_id"], 16); ok {
+		record.TraceId = id
+		delete(attributes, "trace_id")
+	}
+	if id, ok := hexID(attributes["span_id"], 8); ok {
+		record.SpanId = id
+		delete(attributes, "span_id")
+	}
+
+	record.Attributes = keyValues(attributes)
+	return record
+}
+
+// otlpMetricsRequest converts metric points to an export request. Points of
+// the same name and type within a resource share one metric.
+func otlpMetricsRequest(points []model.DataPoint) *colmetricspb.ExportMetricsServiceRequest {
+	request := &colmetricspb.ExportMetricsServiceRequest{}
+
+	for _, group := range groupOTLPPoints(points) {
+		scopeMetrics := &metricspb.ScopeMetrics{Scope: group.scope}
+		metrics := make(map[string]*metricspb.Metric)
+
+		for _, point := range group.points {
+			p, ok := point.(*model.MetricPoint)
+			if !ok {
+				continue
+			}
+
+			dataPoint := &metricspb.NumberDataPoint{
+				TimeUnixNano: timeUnixNano(p.Timestamp),
+				Value:        &metricspb.NumberDataPoint_AsDouble{AsDouble: p.Value},
+				Attributes:   keyValues(stringAttributes(p.Dimensions)),
+			}
+
+			cumulative := isCumulative(p)
+			key := fmt.Sprintf("%s\x00%t", p.Name, cumulative)
+			metric, exists := metrics[key]
+			if !exists {
+				metric = &metricspb.Metric{Name: p.Name}
+				if cumulative {
+					metric.Data = &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+						AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+						IsMonotonic:            true,
+					}}
+				} else {
+					metric.Data = &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{}}
+				}
+				metrics[key] = metric
+				scopeMetrics.Metrics = append(scopeMetrics.Metrics, metric)
+			}
+
+			if cumulative {
+				sum := metric.GetSum()
+				sum.DataPoints = append(sum.DataPoints, dataPoint)
+			} else {
+				gauge := metric.GetGauge()
+				gauge.DataPoints = append(gauge.DataPoints, dataPoint)
+			}
+		}
+
+		request.ResourceMetrics = append(request.ResourceMetrics, &metricspb.ResourceMetrics{
+			Resource:     group.resource,
+			ScopeMetrics: []*metricspb.ScopeMetrics{scopeMetrics},
+		})
+	}
+
+	return request
+}
+
+// isCumulative reports whether a metric point is a running total. Counters
+// and the flattened series of histograms are, as are the _count and _sum
+// series of summaries; everything else is exported as a gauge.
+func isCumulative(point *model.MetricPoint) bool {
+	switch point.MetricType {
+	case "counter", "histogram":
+		return true
+	case "summary":
+		return strings.HasSuffix(point.Name, "_count") || strings.HasSuffix(point.Name, "_sum")
+	default:
+		return false
+	}
+}
+
+// otlpTracesRequest converts trace points to an export request. Points whose
+// trace or span ID is not valid hex of the right length cannot be encoded and
+// are skipped.
+func otlpTracesRequest(points []model.DataPoint) *coltracepb.ExportTraceServiceRequest {
+	request := &coltracepb.ExportTraceServiceRequest{}
+
+	for _, group := range groupOTLPPoints(points) {
+		scopeSpans := &tracepb.ScopeSpans{Scope: group.scope}
+		for _, point := range group.points {
+			if p, ok := point.(*model.TracePoint); ok {
+				if span, ok := otlpSpan(p); ok {
+					scopeSpans.Spans = append(scopeSpans.Spans, span)
+				}
+			}
+		}
+
+		if len(scopeSpans.Spans) == 0 {
+			continue
+		}
+		request.ResourceSpans = append(request.ResourceSpans, &tracepb.ResourceSpans{
+			Resource:   group.resource,
+			ScopeSpans: []*tracepb.ScopeSpans{scopeSpans},
+		})
+	}
+
+	return request
+}
+
+// otlpSpan converts a trace point. The span.kind, status.code,
+// status.message, trace_state, events and links attributes set by the OTLP
+// input are mapped back to span fields.
+func otlpSpan(point *model.TracePoint) (*tracepb.Span, bool) {
+	traceID, ok := hexID(point.TraceID, 16)
+	if !ok {
+		return nil, false
+	}
+	spanID, ok := hexID(point.SpanID, 8)
+	if !ok {
+		return nil, false
+	}
+
+	attributes := make(map[string]interface{}, len(point.Attributes))
+	for k, v := range point.Attributes {
+		attributes[k] = v
+	}
+
+	start := point.StartTime
+	if start.IsZero() {
+		start = point.Timestamp
+	}
+	end := point.EndTime
+	if end.IsZero() {
+		end = start
+	}
+
+	span := &tracepb.Span{
+		TraceId:           traceID,
+		SpanId:            spanID,
+		Name:              point.Name,
+		StartTimeUnixNano: timeUnixNano(start),
+		EndTimeUnixNano:   timeUnixNano(end),
+	}
+	if parentID, ok := hexID(point.ParentSpanID, 8); ok {
+		span.ParentSpanId = parentID
+	}
+
+	if kind, ok := attributes["span.kind"].(string); ok {
+		if value, exists := tracepb.Span_SpanKind_value["SPAN_KIND_"+strings.ToUpper(kind)]; exists {
+			span.Kind = tracepb.Span_SpanKind(value)
+			delete(attributes, "span.kind")
+		}
+	}
+	if code, ok := attributes["status.code"].(string); ok {
+		if value, exists := tracepb.Status_StatusCode_value["STATUS_CODE_"+strings.ToUpper(code)]; exists {
+			span.Status = &tracepb.Status{Code: tracepb.Status_StatusCode(value)}
+			delete(attributes, "status.code")
+		}
+	}
+	if message, ok := attributes["status.message"].(string); ok {
+		if span.Status == nil {
+			span.Status = &tracepb.Status{}
+		}
+		span.Status.Message = message
+		delete(attributes, "status.message")
+	}
+	if state, ok := attributes["trace_state"].(string); ok {
+		span.TraceState = state
+		delete(attributes, "trace_state")
+	}
+	if events, ok := attributes["events"].([]interface{}); ok {
+		span.Events = otlpSpanEvents(events)
+		delete(attributes, "events")
+	}
+	if links, ok := attributes["links"].([]interface{}); ok {
+		span.Links = otlpSpanLinks(links)
+		delete(attributes, "links")
+	}
+
+	span.Attributes = keyValues(attributes)
+	return span, true
+}
+
+// otlpSpanEvents converts event attribute values back to span events
+func otlpSpanEvents(events []interface{}) []*tracepb.Span_Event {
+	var result []*tracepb.Span_Event
+	for _, item := range events {
+		event, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		spanEvent := &tracepb.Span_Event{}
+		spanEvent.Name, _ = event["name"].(string)
+		if timeStr, ok := event["time"].(string); ok {
+			if t, err := time.Parse(time.RFC3339Nano, timeStr); err == nil {
+				spanEvent.TimeUnixNano = timeUnixNano(t)
+			}
+		}
+		if attributes, ok := event["attributes"].(map[string]interface{}); ok {
+			spanEvent.Attributes = keyValues(attributes)
+		}
+		result = append(result, spanEvent)
+	}
+	return result
+}
+
+// otlpSpanLinks converts link attribute values back to span links, skipping
+// links without valid IDs
+func otlpSpanLinks(links []interface{}) []*tracepb.Span_Link {
+	var result []*tracepb.Span_Link
+	for _, item := range links {
+		link, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		traceID, ok := hexID(link["trace_id"], 16)
+		if !ok {
+			continue
+		}
+		spanID, ok := hexID(link["span_id"], 8)
+		if !ok {
+			continue
+		}
+
+		spanLink := &tracepb.Span_Link{TraceId: traceID, SpanId: spanID}
+		if attributes, ok := link["attributes"].(map[string]interface{}); ok {
+			spanLink.Attributes = keyValues(attributes)
+		}
+		result = append(result, spanLink)
+	}
+	return result
+}
+
+// timeUnixNano converts a time to an OTLP timestamp, leaving zero times unset
+func timeUnixNano(t time.Time) uint64 {
+	if t.IsZero() || t.UnixNano() < 0 {
+		return 0
+	}
+	return uint64(t.UnixNano())
+}
+
+// hexID decodes a hex trace or span ID of the given length in bytes
+func hexID(value interface{}, size int) ([]byte, bool) {
+	s, ok := value.(string)
+	if !ok || len(s) != size*2 {
+		return nil, false
+	}
+
+	id, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, false
+	}
+	return id, true
+}
+
+// intValue reads an integer attribute, which may have been decoded as any number type
+func intValue(value interface{}) (int32, bool) {
+	switch v := value.(type) {
+	case int:
+		return int32(v), true
+	case int32:
+		return v, true
+	case int64:
+		return int32(v), true
+	case float64:
+		return int32(v), true
+	default:
+		return 0, false
+	}
+}
+
+// stringAttributes widens a string map to attribute values
+func stringAttributes(values map[string]string) map[string]interface{} {
+	result := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		result[k] = v
+	}
+	return result
+}
+
+// keyValues converts attributes to OTLP key-values sorted by key
+func keyValues(attributes map[string]interface{}) []*commonpb.KeyValue {
+	if len(attributes) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(attributes))
+	for k := range attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	result := make([]*commonpb.KeyValue, 0, len(keys))
+	for _, k := range keys {
+		result = append(result, &commonpb.KeyValue{Key: k, Value: otlpValue(attributes[k])})
+	}
+	return result
+}
+
+// otlpValue converts a plain value to an OTLP value. Types OTLP has no
+// equivalent for are formatted as strings.
+func otlpValue(value interface{}) *commonpb.AnyValue {
+	switch v := value.(type) {
+	case nil:
+		return &commonpb.AnyValue{}
+	case string:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v}}
+	case bool:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: v}}
+	case int:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(v)}}
+	case int32:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(v)}}
+	case int64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: v}}
+	case uint32:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(v)}}
+	case float32:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: float64(v)}}
+	case float64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: v}}
+	case []byte:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BytesValue{BytesValue: v}}
+	case time.Time:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v.Format(time.RFC3339Nano)}}
+	case []interface{}:
+		values := make([]*commonpb.AnyValue, 0, len(v))
+		for _, item := range v {
+			values = append(values, otlpValue(item))
+		}
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_ArrayValue{ArrayValue: &commonpb.ArrayValue{Values: values}}}
+	case []string:
+		values := make([]*commonpb.AnyValue, 0, len(v))
+		for _, item := range v {
+			values = append(values, otlpValue(item))
+		}
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_ArrayValue{ArrayValue: &commonpb.ArrayValue{Values: values}}}
+	case map[string]interface{}:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_KvlistValue{KvlistValue: &commonpb.KeyValueList{Values: keyValues(v)}}}
+	case map[string]string:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_KvlistValue{KvlistValue: &commonpb.KeyValueList{Values: keyValues(stringAttributes(v))}}}
+	default:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: fmt.Sprint(v)}}
+	}
+}
+
+// otlpIDFields are the JSON fields that OTLP encodes as hex rather than base64
+var otlpIDFields = map[string]bool{
+	"traceId": true, "spanId": true, "parentSpanId": true,
+}
+
+// hexOTLPJSONIDs rewrites the base64 trace and span IDs written by protojson
+// as the hex that OTLP/JSON requires
+func hexOTLPJSONIDs(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			if s, ok := item.(string); ok && otlpIDFields[k] {
+				if id, err := base64.StdEncoding.DecodeString(s); err == nil {
+					v[k] = hex.EncodeToString(id)
+				}
+				continue
+			}
+			hexOTLPJSONIDs(item)
+		}
+	case []interface{}:
+		for _, item := range v {
+			hexOTLPJSONIDs(item)
+		}
+	}
+}
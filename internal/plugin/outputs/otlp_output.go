@@ -0,0 +1,376 @@
+package outputs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sliink/collector/internal/model"
+	"github.com/sliink/collector/internal/plugin"
+	"google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// otlpSignalPaths are the paths appended to the endpoint for each telemetry type
+var otlpSignalPaths = map[model.TelemetryType]string{
+	model.LogTelemetryType:    "/v1/logs",
+	model.MetricTelemetryType: "/v1/metrics",
+	model.TraceTelemetryType:  "/v1/traces",
+}
+
+// otlpEndpointOptions are the options overriding the URL of each telemetry type
+var otlpEndpointOptions = map[model.TelemetryType]string{
+	model.LogTelemetryType:    "logs_endpoint",
+	model.MetricTelemetryType: "metrics_endpoint",
+	model.TraceTelemetryType:  "traces_endpoint",
+}
+
+// OTLPOutput exports logs, metrics and traces to an OTLP/HTTP endpoint in
+// protobuf or JSON encoding
+type OTLPOutput struct {
+	plugin.BasePlugin
+	otlpOutputSettings
+	client *http.Client
+}
+
+// otlpOutputSettings holds the OTLP output configuration
+type otlpOutputSettings struct {
+	endpoints   map[model.TelemetryType]string
+	encoding    string
+	headers     map[string]string
+	compression string
+	timeout     time.Duration
+	tlsConfig   *tls.Config
+}
+
+func init() {
+	plugin.RegisterStandardOutput("otlp", func(id string) model.OutputPlugin {
+		return NewOTLPOutput(id)
+	})
+}
+
+// NewOTLPOutput creates a new OTLP output plugin
+func NewOTLPOutput(id string) *OTLPOutput {
+	return &OTLPOutput{
+		BasePlugin: plugin.NewBasePlugin(id, "OTLP Output", model.OutputPluginType),
+	}
+}
+
+// parseOTLPOutputSettings reads the OTLP output configuration
+func parseOTLPOutputSettings(config map[string]interface{}) (otlpOutputSettings, error) {
+	settings := otlpOutputSettings{
+		endpoints:   make(map[model.TelemetryType]string),
+		encoding:    "protobuf",
+		headers:     make(map[string]string),
+		compression: "gzip",
+		timeout:     10 * time.Second,
+	}
+
+	endpoint, _ := config["endpoint"].(string)
+	for dataType, path := range otlpSignalPaths {
+		signalEndpoint, _ := config[otlpEndpointOptions[dataType]].(string)
+		if signalEndpoint == "" && endpoint != "" {
+			signalEndpoint = strings.TrimSuffix(endpoint, "/") + path
+		}
+		if signalEndpoint == "" {
+			return settings, fmt.Errorf("endpoint or %s is required", otlpEndpointOptions[dataType])
+		}
+
+		u, err := url.Parse(signalEndpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return settings, fmt.Errorf("invalid endpoint: %s", signalEndpoint)
+		}
+		settings.endpoints[dataType] = signalEndpoint
+	}
+
+	if encoding, ok := config["encoding"].(string); ok {
+		settings.encoding = encoding
+	}
+	if settings.encoding != "protobuf" && settings.encoding != "json" {
+		return settings, fmt.Errorf("invalid encoding: %s", settings.encoding)
+	}
+
+	if headers, ok := config["headers"].(map[string]interface{}); ok {
+		for name, value := range headers {
+			s, ok := value.(string)
+			if !ok {
+				return settings, fmt.Errorf("header %s must be a string", name)
+			}
+			settings.headers[name] = s
+		}
+	}
+
+	if compression, ok := config["compression"].(string); ok {
+		settings.compression = compression
+	}
+	if settings.compression != "gzip" && settings.compression != "none" {
+		return settings, fmt.Errorf("invalid compression: %s", settings.compression)
+	}
+
+	if timeoutStr, ok := config["timeout"].(string); ok {
+		timeout, err := time.ParseDuration(timeoutStr)
+		if err != nil || timeout <= 0 {
+			return settings, fmt.Errorf("invalid timeout: %s", timeoutStr)
+		}
+		settings.timeout = timeout
+	}
+
+	if tlsConf, ok := config["tls"].(map[string]interface{}); ok {
+		tlsConfig, err := plugin.ClientTLSConfig(tlsConf)
+		if err != nil {
+			return settings, err
+		}
+		settings.tlsConfig = tlsConfig
+	}
+
+	return settings, nil
+}
+
+// Initialize prepares the OTLP output for operation
+func (o *OTLPOutput) Initialize() bool {
+	settings, err := parseOTLPOutputSettings(o.Config)
+	if err != nil {
+		return false
+	}
+	o.otlpOutputSettings = settings
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if settings.tlsConfig != nil {
+		transport.TLSClientConfig = settings.tlsConfig
+	}
+	o.client = &http.Client{
+		Transport: transport,
+		Timeout:   settings.timeout,
+	}
+
+	o.SetStatus(model.StatusInitialized)
+	return true
+}
+
+// Start begins OTLP output operation
+func (o *OTLPOutput) Start() bool {
+	o.SetStatus(model.StatusRunning)
+	return true
+}
+
+// Stop halts OTLP output operation, closing idle connections
+func (o *OTLPOutput) Stop() bool {
+	if o.client != nil {
+		o.client.CloseIdleConnections()
+	}
+
+	o.SetStatus(model.StatusStopped)
+	return true
+}
+
+// Validate checks if the OTLP output is properly configured
+func (o *OTLPOutput) Validate() bool {
+	_, err := parseOTLPOutputSettings(o.Config)
+	return err == nil
+}
+
+// Send exports a data batch to the endpoint
+func (o *OTLPOutput) Send(batch *model.DataBatch) bool {
+	return o.Export(batch) == nil
+}
+
+// Export exports a data batch, sending one request per telemetry type it
+// contains. Rejections that retrying cannot fix are returned as permanent
+// errors. When a request fails after others went through, a PartialError
+// holds the points that were not sent.
+func (o *OTLPOutput) Export(batch *model.DataBatch) error {
+	if batch == nil || batch.Size() == 0 {
+		return nil
+	}
+
+	if o.GetStatus() != model.StatusRunning {
+		return fmt.Errorf("output is not running")
+	}
+
+	points := make(map[model.TelemetryType][]model.DataPoint)
+	for _, point := range batch.Points {
+		if dataType, ok := otlpPointType(point); ok {
+			points[dataType] = append(points[dataType], point)
+		}
+	}
+
+	sent := make(map[model.TelemetryType]bool)
+	for _, dataType := range []model.TelemetryType{model.LogTelemetryType, model.MetricTelemetryType, model.TraceTelemetryType} {
+		if len(points[dataType]) == 0 {
+			continue
+		}
+
+		var request proto.Message
+		switch dataType {
+		case model.LogTelemetryType:
+			request = otlpLogsRequest(points[dataType])
+		case model.MetricTelemetryType:
+			request = otlpMetricsRequest(points[dataType])
+		case model.TraceTelemetryType:
+			traces := otlpTracesRequest(points[dataType])
+			if len(traces.ResourceSpans) == 0 {
+				sent[dataType] = true
+				continue
+			}
+			request = traces
+		}
+
+		if err := o.post(o.endpoints[dataType], request); err != nil {
+			if len(sent) == 0 {
+				return err
+			}
+			return &model.PartialError{Err: err, Failed: otlpUnsent(batch, sent)}
+		}
+		sent[dataType] = true
+	}
+
+	return nil
+}
+
+// otlpPointType returns the telemetry type a point is exported as
+func otlpPointType(point model.DataPoint) (model.TelemetryType, bool) {
+	switch point.(type) {
+	case *model.LogPoint:
+		return model.LogTelemetryType, true
+	case *model.MetricPoint:
+		return model.MetricTelemetryType, true
+	case *model.TracePoint:
+		return model.TraceTelemetryType, true
+	default:
+		return "", false
+	}
+}
+
+// otlpUnsent returns the points of a batch whose telemetry type was not sent
+func otlpUnsent(batch *model.DataBatch, sent map[model.TelemetryType]bool) *model.DataBatch {
+	part := model.NewDataBatch(batch.BatchType)
+	part.SourceID = batch.SourceID
+	for _, point := range batch.Points {
+		if dataType, ok := otlpPointType(point); ok && !sent[dataType] {
+			part.AddPoint(point)
+		}
+	}
+	return part
+}
+
+// post sends an export request and classifies its failure. Throttling,
+// unavailability and network errors can be retried; any other rejection is
+// permanent.
+func (o *OTLPOutput) post(endpoint string, request proto.Message) error {
+	body, contentType, err := o.encode(request)
+	if err != nil {
+		return &model.PermanentError{Err: fmt.Errorf("failed to encode export request: %w", err)}
+	}
+
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return &model.PermanentError{Err: fmt.Errorf("failed to create export request: %w", err)}
+	}
+	req.Header.Set("Content-Type", contentType)
+	if o.compression == "gzip" {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for name, value := range o.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send export request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	err = fmt.Errorf("export to %s rejected with status %d%s", endpoint, resp.StatusCode, otlpErrorMessage(resp))
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return err
+	default:
+		return &model.PermanentError{Err: err}
+	}
+}
+
+// encode marshals an export request in the configured encoding and
+// compression, returning the body and its content type
+func (o *OTLPOutput) encode(request proto.Message) ([]byte, string, error) {
+	var data []byte
+	var err error
+	contentType := "application/x-protobuf"
+
+	if o.encoding == "json" {
+		contentType = "application/json"
+		data, err = marshalOTLPJSON(request)
+	} else {
+		data, err = proto.Marshal(request)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	if o.compression != "gzip" {
+		return data, contentType, nil
+	}
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, "", err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), contentType, nil
+}
+
+// marshalOTLPJSON encodes a message as OTLP/JSON, which differs from
+// protojson in writing enums as numbers and trace and span IDs as hex
+func marshalOTLPJSON(message proto.Message) ([]byte, error) {
+	data, err := protojson.MarshalOptions{UseEnumNumbers: true}.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	hexOTLPJSONIDs(generic)
+
+	return json.Marshal(generic)
+}
+
+// otlpErrorMessage reads the google.rpc.Status message of an error response,
+// returning it with a leading separator or an empty string
+func otlpErrorMessage(resp *http.Response) string {
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil || len(data) == 0 {
+		return ""
+	}
+
+	var errStatus status.Status
+	contentType := resp.Header.Get("Content-Type")
+	switch {
+	case strings.HasPrefix(contentType, "application/x-protobuf"):
+		err = proto.Unmarshal(data, &errStatus)
+	case strings.HasPrefix(contentType, "application/json"):
+		err = protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, &errStatus)
+	default:
+		return ": " + strings.TrimSpace(string(data))
+	}
+	if err != nil || errStatus.GetMessage() == "" {
+		return ""
+	}
+	return ": " + errStatus.GetMessage()
+}
@@ -0,0 +1,334 @@
+package outputs
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sliink/collector/internal/model"
+	"github.com/stretchr/testify/assert"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// otlpRequest is an export request received by the test server
+type otlpRequest struct {
+	path   string
+	header http.Header
+	body   []byte
+}
+
+// otlpServer records export requests and answers with a configurable status
+type otlpServer struct {
+	*httptest.Server
+	requests []otlpRequest
+	status   int
+	delay    time.Duration
+	mutex    sync.Mutex
+}
+
+func newOTLPServer(t *testing.T) *otlpServer {
+	server := &otlpServer{status: http.StatusOK}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			reader, err := gzip.NewReader(r.Body)
+			if !assert.NoError(t, err) {
+				return
+			}
+			body = reader
+		}
+		data, _ := io.ReadAll(body)
+
+		server.mutex.Lock()
+		server.requests = append(server.requests, otlpRequest{path: r.URL.Path, header: r.Header, body: data})
+		status, delay := server.status, server.delay
+		server.mutex.Unlock()
+
+		time.Sleep(delay)
+		if status != http.StatusOK {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			w.Write([]byte(`{"code":3,"message":"rejected by test"}`))
+			return
+		}
+		w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (s *otlpServer) setResponse(status int, delay time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.status = status
+	s.delay = delay
+}
+
+func (s *otlpServer) takeRequests() []otlpRequest {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	requests := s.requests
+	s.requests = nil
+	return requests
+}
+
+func createOTLPBatch() *model.DataBatch {
+	timestamp := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	base := model.BaseDataPoint{
+		Timestamp: timestamp,
+		Origin:    "checkout",
+		Labels:    map[string]string{"env": "prod", "otel.scope.name": "shop"},
+	}
+
+	batch := model.NewDataBatch(model.LogTelemetryType)
+	batch.AddPoint(&model.LogPoint{
+		BaseDataPoint: base,
+		Message:       "payment failed",
+		Level:         "ERROR",
+		Attributes:    map[string]interface{}{"order_id": int64(42), "trace_id": "0102030405060708090a0b0c0d0e0f10"},
+	})
+	batch.AddPoint(&model.MetricPoint{
+		BaseDataPoint: base,
+		Name:          "requests_total",
+		Value:         7,
+		MetricType:    "counter",
+		Dimensions:    map[string]string{"method": "GET"},
+	})
+	batch.AddPoint(&model.MetricPoint{
+		BaseDataPoint: base,
+		Name:          "queue_depth",
+		Value:         3,
+		MetricType:    "gauge",
+	})
+	batch.AddPoint(&model.TracePoint{
+		BaseDataPoint: base,
+		TraceID:       "0102030405060708090a0b0c0d0e0f10",
+		SpanID:        "0102030405060708",
+		Name:          "charge",
+		StartTime:     timestamp,
+		EndTime:       timestamp.Add(time.Second),
+		Attributes:    map[string]interface{}{"span.kind": "server", "status.code": "error", "http.method": "POST"},
+	})
+	// Spans without valid IDs cannot be encoded
+	batch.AddPoint(&model.TracePoint{BaseDataPoint: base, TraceID: "abc", SpanID: "def"})
+	return batch
+}
+
+func TestOTLPOutputValidate(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{},
+		{"endpoint": "localhost:4318"},
+		{"endpoint": "ftp://localhost:4318"},
+		{"logs_endpoint": "http://localhost:4318/v1/logs"},
+		{"endpoint": "http://localhost:4318", "encoding": "xml"},
+		{"endpoint": "http://localhost:4318", "compression": "zstd"},
+		{"endpoint": "http://localhost:4318", "timeout": "soon"},
+		{"endpoint": "http://localhost:4318", "headers": map[string]interface{}{"x-count": 1.0}},
+		{"endpoint": "http://localhost:4318", "tls": map[string]interface{}{"cert_file": "client.pem"}},
+	}
+	for _, config := range invalid {
+		output := NewOTLPOutput("otlp_output")
+		output.Configure(config)
+		assert.False(t, output.Validate(), "config %v", config)
+	}
+
+	output := NewOTLPOutput("otlp_output")
+	output.Configure(map[string]interface{}{
+		"endpoint":      "http://localhost:4318/",
+		"logs_endpoint": "https://logs.example.com/ingest",
+	})
+	assert.True(t, output.Validate())
+	assert.True(t, output.Initialize())
+	assert.Equal(t, "https://logs.example.com/ingest", output.endpoints[model.LogTelemetryType])
+	assert.Equal(t, "http://localhost:4318/v1/metrics", output.endpoints[model.MetricTelemetryType])
+	assert.Equal(t, "http://localhost:4318/v1/traces", output.endpoints[model.TraceTelemetryType])
+}
+
+func TestOTLPOutputExport(t *testing.T) {
+	server := newOTLPServer(t)
+
+	t.Run("Protobuf with gzip and headers", func(t *testing.T) {
+		output := startTestOutput(t, NewOTLPOutput("otlp_output"), map[string]interface{}{
+			"endpoint": server.URL,
+			"headers":  map[string]interface{}{"Authorization": "Bearer token"},
+		})
+
+		assert.True(t, output.Send(createOTLPBatch()))
+
+		requests := server.takeRequests()
+		if !assert.Len(t, requests, 3) {
+			return
+		}
+		for _, request := range requests {
+			assert.Equal(t, "application/x-protobuf", request.header.Get("Content-Type"))
+			assert.Equal(t, "gzip", request.header.Get("Content-Encoding"))
+			assert.Equal(t, "Bearer token", request.header.Get("Authorization"))
+		}
+
+		assert.Equal(t, "/v1/logs", requests[0].path)
+		var logs collogspb.ExportLogsServiceRequest
+		assert.NoError(t, proto.Unmarshal(requests[0].body, &logs))
+		resourceLogs := logs.GetResourceLogs()[0]
+		assert.Equal(t, "shop", resourceLogs.GetScopeLogs()[0].GetScope().GetName())
+		assert.Len(t, resourceLogs.GetResource().GetAttributes(), 2)
+		record := resourceLogs.GetScopeLogs()[0].GetLogRecords()[0]
+		assert.Equal(t, "payment failed", record.GetBody().GetStringValue())
+		assert.Equal(t, logspb.SeverityNumber_SEVERITY_NUMBER_ERROR, record.GetSeverityNumber())
+		assert.Equal(t, "ERROR", record.GetSeverityText())
+		assert.Len(t, record.GetTraceId(), 16)
+		assert.Equal(t, "order_id", record.GetAttributes()[0].GetKey())
+		assert.Equal(t, int64(42), record.GetAttributes()[0].GetValue().GetIntValue())
+
+		assert.Equal(t, "/v1/metrics", requests[1].path)
+		var metrics colmetricspb.ExportMetricsServiceRequest
+		assert.NoError(t, proto.Unmarshal(requests[1].body, &metrics))
+		exported := metrics.GetResourceMetrics()[0].GetScopeMetrics()[0].GetMetrics()
+		assert.Len(t, exported, 2)
+		assert.True(t, exported[0].GetSum().GetIsMonotonic())
+		assert.Equal(t, 7.0, exported[0].GetSum().GetDataPoints()[0].GetAsDouble())
+		assert.Equal(t, "method", exported[0].GetSum().GetDataPoints()[0].GetAttributes()[0].GetKey())
+		assert.Equal(t, 3.0, exported[1].GetGauge().GetDataPoints()[0].GetAsDouble())
+
+		assert.Equal(t, "/v1/traces", requests[2].path)
+		var traces coltracepb.ExportTraceServiceRequest
+		assert.NoError(t, proto.Unmarshal(requests[2].body, &traces))
+		spans := traces.GetResourceSpans()[0].GetScopeSpans()[0].GetSpans()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, "charge", spans[0].GetName())
+		assert.Equal(t, tracepb.Span_SPAN_KIND_SERVER, spans[0].GetKind())
+		assert.Equal(t, tracepb.Status_STATUS_CODE_ERROR, spans[0].GetStatus().GetCode())
+		assert.Equal(t, uint64(time.Second), spans[0].GetEndTimeUnixNano()-spans[0].GetStartTimeUnixNano())
+		assert.Len(t, spans[0].GetAttributes(), 1)
+	})
+
+	t.Run("JSON without compression", func(t *testing.T) {
+		output := startTestOutput(t, NewOTLPOutput("otlp_output"), map[string]interface{}{
+			"endpoint":    server.URL,
+			"encoding":    "json",
+			"compression": "none",
+		})
+
+		batch := model.NewDataBatch(model.TraceTelemetryType)
+		for _, point := range createOTLPBatch().Points {
+			if trace, ok := point.(*model.TracePoint); ok {
+				batch.AddPoint(trace)
+			}
+		}
+		assert.True(t, output.Send(batch))
+
+		requests := server.takeRequests()
+		if !assert.Len(t, requests, 1) {
+			return
+		}
+		assert.Equal(t, "application/json", requests[0].header.Get("Content-Type"))
+		assert.Empty(t, requests[0].header.Get("Content-Encoding"))
+
+		var body map[string]interface{}
+		assert.NoError(t, json.Unmarshal(requests[0].body, &body))
+		span := body["resourceSpans"].([]interface{})[0].(map[string]interface{})["scopeSpans"].([]interface{})[0].(map[string]interface{})["spans"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, "0102030405060708090a0b0c0d0e0f10", span["traceId"])
+		assert.Equal(t, "0102030405060708", span["spanId"])
+		assert.Equal(t, 2.0, span["kind"])
+	})
+
+	t.Run("Empty batches are not sent", func(t *testing.T) {
+		output := startTestOutput(t, NewOTLPOutput("otlp_output"), map[string]interface{}{"endpoint": server.URL})
+		assert.NoError(t, output.Export(model.NewDataBatch(model.LogTelemetryType)))
+		assert.Empty(t, server.takeRequests())
+	})
+}
+
+func TestOTLPOutputErrors(t *testing.T) {
+	server := newOTLPServer(t)
+	output := startTestOutput(t, NewOTLPOutput("otlp_output"), map[string]interface{}{
+		"endpoint": server.URL,
+		"timeout":  "200ms",
+	})
+
+	retryable := []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+	for _, status := range retryable {
+		server.setResponse(status, 0)
+		err := output.Export(createServiceBatch("api", "hello"))
+		assert.Error(t, err)
+		assert.False(t, model.IsPermanent(err), "status %d", status)
+	}
+
+	permanent := []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusRequestEntityTooLarge, http.StatusInternalServerError}
+	for _, status := range permanent {
+		server.setResponse(status, 0)
+		err := output.Export(createServiceBatch("api", "hello"))
+		assert.Error(t, err)
+		assert.True(t, model.IsPermanent(err), "status %d", status)
+	}
+	assert.Contains(t, output.Export(createServiceBatch("api", "hello")).Error(), "rejected by test")
+
+	// Timeouts can be retried
+	server.setResponse(http.StatusOK, time.Second)
+	err := output.Export(createServiceBatch("api", "hello"))
+	assert.Error(t, err)
+	assert.False(t, model.IsPermanent(err))
+
+	// Unreachable endpoints can be retried
+	server.setResponse(http.StatusOK, 0)
+	unreachable := startTestOutput(t, NewOTLPOutput("otlp_output"), map[string]interface{}{"endpoint": "http://127.0.0.1:1"})
+	err = unreachable.Export(createServiceBatch("api", "hello"))
+	assert.Error(t, err)
+	assert.False(t, model.IsPermanent(err))
+	assert.False(t, unreachable.Send(createServiceBatch("api", "hello")))
+
+	// Stopped outputs fail without sending
+	output.Stop()
+	assert.False(t, output.Send(createServiceBatch("api", "hello")))
+}
+
+func TestOTLPOutputPartialFailure(t *testing.T) {
+	server := newOTLPServer(t)
+	failing := newOTLPServer(t)
+	failing.setResponse(http.StatusServiceUnavailable, 0)
+
+	output := startTestOutput(t, NewOTLPOutput("otlp_output"), map[string]interface{}{
+		"endpoint":         server.URL,
+		"metrics_endpoint": failing.URL + "/v1/metrics",
+	})
+
+	// Logs went through, so only the metrics and traces are returned
+	batch := createOTLPBatch()
+	err := output.Export(batch)
+	var partial *model.PartialError
+	if assert.True(t, errors.As(err, &partial)) {
+		assert.False(t, model.IsPermanent(err))
+		assert.Equal(t, batch.Points[1:], partial.Failed.Points)
+		assert.Equal(t, batch.BatchType, partial.Failed.BatchType)
+	}
+	assert.Len(t, server.takeRequests(), 1)
+	assert.Len(t, failing.takeRequests(), 1)
+
+	// Permanent rejections keep their classification
+	failing.setResponse(http.StatusBadRequest, 0)
+	err = output.Export(createOTLPBatch())
+	assert.True(t, errors.As(err, &partial))
+	assert.True(t, model.IsPermanent(err))
+	server.takeRequests()
+
+	// A failure before anything was sent covers the whole batch
+	output = startTestOutput(t, NewOTLPOutput("otlp_output"), map[string]interface{}{
+		"endpoint":      server.URL,
+		"logs_endpoint": failing.URL + "/v1/logs",
+	})
+	err = output.Export(createOTLPBatch())
+	assert.Error(t, err)
+	assert.False(t, errors.As(err, &partial))
+	assert.Empty(t, server.takeRequests())
+}
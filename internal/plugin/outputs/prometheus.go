@@ -0,0 +1,161 @@
+package outputs
+
+import (
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// promSeries is the latest value of one metric series
+type promSeries struct {
+	name       string
+	labels     map[string]string
+	value      float64
+	metricType string
+}
+
+// promFamilyTypes maps metric types to the type written on TYPE lines
+var promFamilyTypes = map[string]string{
+	"counter":   "counter",
+	"gauge":     "gauge",
+	"histogram": "histogram",
+	"summary":   "summary",
+}
+
+// promFamily returns the metric family a series belongs to. Histograms and
+// summaries arrive flattened, so their _bucket, _count and _sum series are
+// grouped back under one family name.
+func promFamily(series *promSeries) string {
+	var suffixes []string
+	switch series.metricType {
+	case "histogram":
+		suffixes = []string{"_bucket", "_count", "_sum"}
+	case "summary":
+		suffixes = []string{"_count", "_sum"}
+	}
+
+	for _, suffix := range suffixes {
+		if family := strings.TrimSuffix(series.name, suffix); family != series.name {
+			return family
+		}
+	}
+	return series.name
+}
+
+// writePrometheusText writes series in the Prometheus text exposition format,
+// grouped by family with a TYPE line each
+func writePrometheusText(w io.Writer, series []*promSeries) error {
+	families := make(map[string][]*promSeries)
+	for _, s := range series {
+		family := promFamily(s)
+		families[family] = append(families[family], s)
+	}
+
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		members := families[name]
+		sort.Slice(members, func(i, j int) bool {
+			if members[i].name != members[j].name {
+				return members[i].name < members[j].name
+			}
+			return promLabelString(members[i].labels) < promLabelString(members[j].labels)
+		})
+
+		familyType, ok := promFamilyTypes[members[0].metricType]
+		if !ok {
+			familyType = "untyped"
+		}
+		b.WriteString("# TYPE " + name + " " + familyType + "\n")
+
+		for _, s := range members {
+			b.WriteString(s.name)
+			b.WriteString(promLabelString(s.labels))
+			b.WriteByte(' ')
+			b.WriteString(formatPromValue(s.value))
+			b.WriteByte('\n')
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// promLabelString formats labels as {name="value",...} sorted by name, or
+// an empty string when there are none
+func promLabelString(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(name)
+		b.WriteString(`="`)
+		b.WriteString(promLabelEscaper.Replace(labels[name]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// promLabelEscaper escapes label values for the text format
+var promLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatPromValue formats a sample value, including +Inf, -Inf and NaN
+func formatPromValue(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+// sanitizePromName replaces characters that are not allowed in a metric
+// name with underscores, prefixing names that start with a digit. Label
+// names follow the same rules without colons.
+func sanitizePromName(name string, allowColon bool) string {
+	if name == "" {
+		return "_"
+	}
+
+	var b strings.Builder
+	for i, c := range name {
+		switch {
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			b.WriteRune(c)
+		case c >= '0' && c <= '9':
+			if i == 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(c)
+		case c == ':' && allowColon:
+			b.WriteRune(c)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
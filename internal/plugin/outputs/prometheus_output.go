@@ -0,0 +1,237 @@
+package outputs
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sliink/collector/internal/model"
+	"github.com/sliink/collector/internal/plugin"
+)
+
+// PrometheusOutput keeps the latest value of each metric series and serves
+// them in the Prometheus text format for a Prometheus server to scrape
+type PrometheusOutput struct {
+	plugin.BasePlugin
+	prometheusOutputSettings
+	series   map[string]*promEntry // Keyed by metric name and sorted labels
+	server   *http.Server
+	listener net.Listener
+	now      func() time.Time
+	mutex    sync.Mutex
+}
+
+// prometheusOutputSettings holds the Prometheus output configuration
+type prometheusOutputSettings struct {
+	address   string
+	path      string
+	namespace string
+	expiry    time.Duration
+	tlsConfig *tls.Config
+}
+
+// promEntry is a stored series with the time it was last updated
+type promEntry struct {
+	promSeries
+	updated time.Time
+}
+
+func init() {
+	plugin.RegisterStandardOutput("prometheus", func(id string) model.OutputPlugin {
+		return NewPrometheusOutput(id)
+	})
+}
+
+// NewPrometheusOutput creates a new Prometheus exposition output plugin
+func NewPrometheusOutput(id string) *PrometheusOutput {
+	return &PrometheusOutput{
+		BasePlugin: plugin.NewBasePlugin(id, "Prometheus Output", model.OutputPluginType),
+		series:     make(map[string]*promEntry),
+		now:        time.Now,
+	}
+}
+
+// parsePrometheusOutputSettings reads the Prometheus output configuration
+func parsePrometheusOutputSettings(config map[string]interface{}) (prometheusOutputSettings, error) {
+	settings := prometheusOutputSettings{
+		address: "localhost:9464",
+		path:    "/metrics",
+		expiry:  5 * time.Minute,
+	}
+
+	if address, ok := config["address"].(string); ok && address != "" {
+		settings.address = address
+	}
+
+	if path, ok := config["path"].(string); ok {
+		if !strings.HasPrefix(path, "/") {
+			return settings, fmt.Errorf("path must start with /")
+		}
+		settings.path = path
+	}
+
+	if namespace, ok := config["namespace"].(string); ok && namespace != "" {
+		settings.namespace = sanitizePromName(namespace, true) + "_"
+	}
+
+	if expiryStr, ok := config["expiry"].(string); ok {
+		expiry, err := time.ParseDuration(expiryStr)
+		if err != nil || expiry < 0 {
+			return settings, fmt.Errorf("invalid expiry: %s", expiryStr)
+		}
+		settings.expiry = expiry
+	}
+
+	if tlsConf, ok := config["tls"].(map[string]interface{}); ok {
+		tlsConfig, err := plugin.ServerTLSConfig(tlsConf)
+		if err != nil {
+			return settings, err
+		}
+		settings.tlsConfig = tlsConfig
+	}
+
+	return settings, nil
+}
+
+// Initialize prepares the Prometheus output for operation
+func (p *PrometheusOutput) Initialize() bool {
+	settings, err := parsePrometheusOutputSettings(p.Config)
+	if err != nil {
+		return false
+	}
+	p.prometheusOutputSettings = settings
+
+	p.SetStatus(model.StatusInitialized)
+	return true
+}
+
+// Validate checks if the Prometheus output is properly configured
+func (p *PrometheusOutput) Validate() bool {
+	_, err := parsePrometheusOutputSettings(p.Config)
+	return err == nil
+}
+
+// Start begins serving the exposition endpoint
+func (p *PrometheusOutput) Start() bool {
+	listener, err := net.Listen("tcp", p.address)
+	if err != nil {
+		return false
+	}
+
+	if p.tlsConfig != nil {
+		listener = tls.NewListener(listener, p.tlsConfig)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(p.path, p)
+
+	p.mutex.Lock()
+	p.listener = listener
+	p.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	server := p.server
+	p.mutex.Unlock()
+
+	go server.Serve(listener)
+
+	p.SetStatus(model.StatusRunning)
+	return true
+}
+
+// Stop shuts down the server, waiting briefly for scrapes in progress
+func (p *PrometheusOutput) Stop() bool {
+	p.mutex.Lock()
+	server := p.server
+	p.server = nil
+	p.mutex.Unlock()
+
+	if server != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+	}
+
+	p.SetStatus(model.StatusStopped)
+	return true
+}
+
+// Send stores the latest value of each metric series in a batch. Points
+// other than metrics cannot be exposed and are skipped.
+func (p *PrometheusOutput) Send(batch *model.DataBatch) bool {
+	if batch == nil || batch.Size() == 0 {
+		return true
+	}
+
+	if p.GetStatus() != model.StatusRunning {
+		return false
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := p.now()
+	for _, point := range batch.Points {
+		metric, ok := point.(*model.MetricPoint)
+		if !ok {
+			continue
+		}
+
+		labels := make(map[string]string, len(metric.Dimensions))
+		for k, v := range metric.Dimensions {
+			labels[sanitizePromName(k, false)] = v
+		}
+
+		series := promSeries{
+			name:       p.namespace + sanitizePromName(metric.Name, true),
+			labels:     labels,
+			value:      metric.Value,
+			metricType: metric.MetricType,
+		}
+		p.series[series.name+promLabelString(labels)] = &promEntry{promSeries: series, updated: now}
+	}
+
+	return true
+}
+
+// ServeHTTP writes the current series, dropping those not updated within the expiry
+func (p *PrometheusOutput) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	series := p.snapshot()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if r.Method == http.MethodHead {
+		return
+	}
+	writePrometheusText(w, series)
+}
+
+// snapshot expires stale series and copies the rest
+func (p *PrometheusOutput) snapshot() []*promSeries {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := p.now()
+	series := make([]*promSeries, 0, len(p.series))
+	for key, entry := range p.series {
+		if p.expiry > 0 && now.Sub(entry.updated) > p.expiry {
+			delete(p.series, key)
+			continue
+		}
+		s := entry.promSeries
+		series = append(series, &s)
+	}
+
+	return series
+}
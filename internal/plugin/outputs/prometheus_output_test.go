@@ -0,0 +1,117 @@
+package outputs
+
+import (
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sliink/collector/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func createMetricBatch(metrics ...*model.MetricPoint) *model.DataBatch {
+	batch := model.NewDataBatch(model.MetricTelemetryType)
+	for _, metric := range metrics {
+		batch.AddPoint(metric)
+	}
+	return batch
+}
+
+func scrapeOutput(output *PrometheusOutput) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	output.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	return recorder
+}
+
+func TestPrometheusOutputValidate(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{"path": "metrics"},
+		{"expiry": "-1m"},
+		{"expiry": "later"},
+		{"tls": map[string]interface{}{"cert_file": "/missing.pem"}},
+	}
+
+	for _, config := range invalid {
+		output := NewPrometheusOutput("prometheus_output")
+		output.Configure(config)
+		assert.False(t, output.Validate(), "%v", config)
+		assert.False(t, output.Initialize(), "%v", config)
+	}
+}
+
+func TestPrometheusOutputExposition(t *testing.T) {
+	output := startTestOutput(t, NewPrometheusOutput("prometheus_output"), map[string]interface{}{"address": "127.0.0.1:0", "namespace": "app"})
+
+	assert.True(t, output.Send(createMetricBatch(
+		&model.MetricPoint{Name: "http.requests", Value: 1, MetricType: "counter", Dimensions: map[string]string{"code": "200"}},
+		&model.MetricPoint{Name: "http.requests", Value: 4, MetricType: "counter", Dimensions: map[string]string{"code": "500"}},
+		&model.MetricPoint{Name: "latency_bucket", Value: 2, MetricType: "histogram", Dimensions: map[string]string{"le": "0.1"}},
+		&model.MetricPoint{Name: "latency_bucket", Value: 3, MetricType: "histogram", Dimensions: map[string]string{"le": "+Inf"}},
+		&model.MetricPoint{Name: "latency_count", Value: 3, MetricType: "histogram"},
+		&model.MetricPoint{Name: "latency_sum", Value: 0.25, MetricType: "histogram"},
+		&model.MetricPoint{Name: "1st-temp", Value: math.Inf(-1), MetricType: "gauge", Dimensions: map[string]string{"room.name": "a \"b\"\nc\\"}},
+		&model.MetricPoint{Name: "raw", Value: math.NaN()},
+	)))
+
+	// The latest value of a series replaces the previous one
+	assert.True(t, output.Send(createMetricBatch(
+		&model.MetricPoint{Name: "http.requests", Value: 7, MetricType: "counter", Dimensions: map[string]string{"code": "200"}},
+	)))
+
+	// Other point types are skipped
+	assert.True(t, output.Send(createServiceBatch("api", "hello")))
+
+	response := scrapeOutput(output)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", response.Header().Get("Content-Type"))
+	assert.Equal(t, `# TYPE app__1st_temp gauge
+app__1st_temp{room_name="a \"b\"\nc\\"} -Inf
+# TYPE app_http_requests counter
+app_http_requests{code="200"} 7
+app_http_requests{code="500"} 4
+# TYPE app_latency histogram
+app_latency_bucket{le="+Inf"} 3
+app_latency_bucket{le="0.1"} 2
+app_latency_count 3
+app_latency_sum 0.25
+# TYPE app_raw untyped
+app_raw NaN
+`, response.Body.String())
+
+	t.Run("Only GET and HEAD are allowed", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		output.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+	})
+
+	t.Run("Endpoint is served on the listener", func(t *testing.T) {
+		resp, err := http.Get("http://" + output.listener.Addr().String() + "/metrics")
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		assert.Contains(t, string(body), `app_http_requests{code="200"} 7`)
+	})
+}
+
+func TestPrometheusOutputExpiry(t *testing.T) {
+	output := startTestOutput(t, NewPrometheusOutput("prometheus_output"), map[string]interface{}{"address": "127.0.0.1:0", "expiry": "1m"})
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	output.now = func() time.Time { return now }
+
+	output.Send(createMetricBatch(&model.MetricPoint{Name: "stale", Value: 1, MetricType: "gauge"}))
+	now = now.Add(45 * time.Second)
+	output.Send(createMetricBatch(&model.MetricPoint{Name: "fresh", Value: 2, MetricType: "gauge"}))
+
+	now = now.Add(30 * time.Second)
+	assert.Equal(t, "# TYPE fresh gauge\nfresh 2\n", scrapeOutput(output).Body.String())
+
+	// Series that report again come back
+	output.Send(createMetricBatch(&model.MetricPoint{Name: "stale", Value: 3, MetricType: "gauge"}))
+	assert.Contains(t, scrapeOutput(output).Body.String(), "stale 3\n")
+	assert.Len(t, output.series, 2)
+}
@@ -0,0 +1,126 @@
+package outputs
+
+import (
+	"math"
+	"sort"
+
+	"github.com/sliink/collector/internal/model"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// rwLabel is a label of a remote-write time series
+type rwLabel struct {
+	name  string
+	value string
+}
+
+// rwSample is a sample of a remote-write time series
+type rwSample struct {
+	value     float64
+	timestamp int64 // Milliseconds since the epoch
+}
+
+// rwSeries is a remote-write time series with its labels sorted by name
+type rwSeries struct {
+	labels  []rwLabel
+	samples []rwSample
+	points  []model.DataPoint // The point each sample came from
+}
+
+// remoteWriteSeries converts metric points to time series. The metric name
+// becomes the __name__ label and dimensions the other labels, sanitized as
+// for the Prometheus output. Points of the same series are merged into one
+// time series ordered by timestamp, and series are keyed by their labels.
+func remoteWriteSeries(points []model.DataPoint) map[string]*rwSeries {
+	series := make(map[string]*rwSeries)
+
+	for _, point := range points {
+		metric, ok := point.(*model.MetricPoint)
+		if !ok {
+			continue
+		}
+
+		labels := make(map[string]string, len(metric.Dimensions)+1)
+		for k, v := range metric.Dimensions {
+			labels[sanitizePromName(k, false)] = v
+		}
+		labels["__name__"] = sanitizePromName(metric.Name, true)
+
+		key := promLabelString(labels)
+		s, exists := series[key]
+		if !exists {
+			s = &rwSeries{}
+			for name, value := range labels {
+				s.labels = append(s.labels, rwLabel{name: name, value: value})
+			}
+			sort.Slice(s.labels, func(i, j int) bool { return s.labels[i].name < s.labels[j].name })
+			series[key] = s
+		}
+
+		s.samples = append(s.samples, rwSample{value: metric.Value, timestamp: metric.Timestamp.UnixMilli()})
+		s.points = append(s.points, point)
+	}
+
+	for _, s := range series {
+		sort.Stable(samplesByTime{s})
+	}
+
+	return series
+}
+
+// samplesByTime sorts the samples of a series by timestamp, along with the
+// points they came from
+type samplesByTime struct {
+	*rwSeries
+}
+
+func (s samplesByTime) Len() int {
+	return len(s.samples)
+}
+
+func (s samplesByTime) Less(i, j int) bool {
+	return s.samples[i].timestamp < s.samples[j].timestamp
+}
+
+func (s samplesByTime) Swap(i, j int) {
+	s.samples[i], s.samples[j] = s.samples[j], s.samples[i]
+	s.points[i], s.points[j] = s.points[j], s.points[i]
+}
+
+// marshalWriteRequest encodes time series as a prometheus.WriteRequest
+// protobuf message:
+//
+//	WriteRequest { repeated TimeSeries timeseries = 1; }
+//	TimeSeries   { repeated Label labels = 1; repeated Sample samples = 2; }
+//	Label        { string name = 1; string value = 2; }
+//	Sample       { double value = 1; int64 timestamp = 2; }
+func marshalWriteRequest(series []*rwSeries) []byte {
+	var request []byte
+	for _, s := range series {
+		var ts []byte
+		for _, label := range s.labels {
+			var l []byte
+			l = protowire.AppendTag(l, 1, protowire.BytesType)
+			l = protowire.AppendString(l, label.name)
+			l = protowire.AppendTag(l, 2, protowire.BytesType)
+			l = protowire.AppendString(l, label.value)
+
+			ts = protowire.AppendTag(ts, 1, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, l)
+		}
+		for _, sample := range s.samples {
+			var sm []byte
+			sm = protowire.AppendTag(sm, 1, protowire.Fixed64Type)
+			sm = protowire.AppendFixed64(sm, math.Float64bits(sample.value))
+			sm = protowire.AppendTag(sm, 2, protowire.VarintType)
+			sm = protowire.AppendVarint(sm, uint64(sample.timestamp))
+
+			ts = protowire.AppendTag(ts, 2, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, sm)
+		}
+
+		request = protowire.AppendTag(request, 1, protowire.BytesType)
+		request = protowire.AppendBytes(request, ts)
+	}
+	return request
+}
@@ -0,0 +1,373 @@
+package outputs
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/sliink/collector/internal/model"
+	"github.com/sliink/collector/internal/plugin"
+)
+
+// RemoteWriteOutput sends metrics to a Prometheus remote-write endpoint.
+// Series are spread over queue shards that send in parallel, each series
+// always on the same shard so its samples arrive in order.
+type RemoteWriteOutput struct {
+	plugin.BasePlugin
+	remoteWriteSettings
+	client *http.Client
+	shards []chan []*rwRequest
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	mutex  sync.RWMutex
+}
+
+// remoteWriteSettings holds the remote-write output configuration
+type remoteWriteSettings struct {
+	url               string
+	headers           map[string]string
+	timeout           time.Duration
+	shards            int
+	maxSamplesPerSend int
+	tlsConfig         *tls.Config
+}
+
+// rwRequest is a write request sent by a shard, answered on result
+type rwRequest struct {
+	series  []*rwSeries
+	samples int
+	result  chan error
+}
+
+func init() {
+	plugin.RegisterStandardOutput("remote_write", func(id string) model.OutputPlugin {
+		return NewRemoteWriteOutput(id)
+	})
+}
+
+// NewRemoteWriteOutput creates a new Prometheus remote-write output plugin
+func NewRemoteWriteOutput(id string) *RemoteWriteOutput {
+	return &RemoteWriteOutput{
+		BasePlugin: plugin.NewBasePlugin(id, "Remote Write Output", model.OutputPluginType),
+	}
+}
+
+// parseRemoteWriteSettings reads the remote-write output configuration
+func parseRemoteWriteSettings(config map[string]interface{}) (remoteWriteSettings, error) {
+	settings := remoteWriteSettings{
+		headers:           make(map[string]string),
+		timeout:           30 * time.Second,
+		shards:            4,
+		maxSamplesPerSend: 2000,
+	}
+
+	settings.url, _ = config["url"].(string)
+	u, err := url.Parse(settings.url)
+	if settings.url == "" || err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return settings, fmt.Errorf("a valid http or https url is required")
+	}
+
+	if headers, ok := config["headers"].(map[string]interface{}); ok {
+		for name, value := range headers {
+			s, ok := value.(string)
+			if !ok {
+				return settings, fmt.Errorf("header %s must be a string", name)
+			}
+			settings.headers[name] = s
+		}
+	}
+
+	if value, ok := config["timeout"].(string); ok {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return settings, fmt.Errorf("invalid timeout: %s", value)
+		}
+		settings.timeout = timeout
+	}
+
+	if shards, ok := plugin.IntOption(config["shards"]); ok {
+		if shards <= 0 {
+			return settings, fmt.Errorf("shards must be positive")
+		}
+		settings.shards = int(shards)
+	}
+
+	if maxSamples, ok := plugin.IntOption(config["max_samples_per_send"]); ok {
+		if maxSamples <= 0 {
+			return settings, fmt.Errorf("max_samples_per_send must be positive")
+		}
+		settings.maxSamplesPerSend = int(maxSamples)
+	}
+
+	if tlsConf, ok := config["tls"].(map[string]interface{}); ok {
+		tlsConfig, err := plugin.ClientTLSConfig(tlsConf)
+		if err != nil {
+			return settings, err
+		}
+		settings.tlsConfig = tlsConfig
+	}
+
+	return settings, nil
+}
+
+// Initialize prepares the remote-write output for operation
+func (r *RemoteWriteOutput) Initialize() bool {
+	settings, err := parseRemoteWriteSettings(r.Config)
+	if err != nil {
+		return false
+	}
+	r.remoteWriteSettings = settings
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if settings.tlsConfig != nil {
+		transport.TLSClientConfig = settings.tlsConfig
+	}
+	r.client = &http.Client{
+		Transport: transport,
+		Timeout:   settings.timeout,
+	}
+
+	r.SetStatus(model.StatusInitialized)
+	return true
+}
+
+// Validate checks if the remote-write output is properly configured
+func (r *RemoteWriteOutput) Validate() bool {
+	_, err := parseRemoteWriteSettings(r.Config)
+	return err == nil
+}
+
+// Start starts the queue shards
+func (r *RemoteWriteOutput) Start() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+	r.shards = make([]chan []*rwRequest, r.remoteWriteSettings.shards)
+	for i := range r.shards {
+		r.shards[i] = make(chan []*rwRequest)
+		r.wg.Add(1)
+		go r.runShard(r.shards[i])
+	}
+
+	r.SetStatus(model.StatusRunning)
+	return true
+}
+
+// Stop abandons requests in progress and stops the queue shards
+func (r *RemoteWriteOutput) Stop() bool {
+	// Cancel first so exports waiting on a request give up their read lock
+	r.mutex.RLock()
+	cancel := r.cancel
+	r.mutex.RUnlock()
+	if cancel != nil {
+		cancel()
+	}
+
+	r.mutex.Lock()
+	for _, shard := range r.shards {
+		close(shard)
+	}
+	r.shards = nil
+	r.cancel = nil
+	r.mutex.Unlock()
+
+	r.wg.Wait()
+	if r.client != nil {
+		r.client.CloseIdleConnections()
+	}
+
+	r.SetStatus(model.StatusStopped)
+	return true
+}
+
+// Send sends the metrics of a data batch
+func (r *RemoteWriteOutput) Send(batch *model.DataBatch) bool {
+	return r.Export(batch) == nil
+}
+
+// Export sends the metrics of a data batch, split over the shards in
+// requests of at most max_samples_per_send samples, and waits for every
+// request. Requests the endpoint rejects as invalid are dropped. Other
+// failures are returned so the batch can be retried; when some requests went
+// through, a PartialError holds the points of the ones that did not.
+func (r *RemoteWriteOutput) Export(batch *model.DataBatch) error {
+	if batch == nil || batch.Size() == 0 {
+		return nil
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if r.GetStatus() != model.StatusRunning || r.shards == nil {
+		return fmt.Errorf("output is not running")
+	}
+
+	series := remoteWriteSeries(batch.Points)
+	keys := make([]string, 0, len(series))
+	for key := range series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// Group series by shard, then cut each shard's series into requests
+	shardSeries := make([][]*rwSeries, len(r.shards))
+	for _, key := range keys {
+		hash := fnv.New32a()
+		hash.Write([]byte(key))
+		shard := int(hash.Sum32() % uint32(len(r.shards)))
+		shardSeries[shard] = append(shardSeries[shard], series[key])
+	}
+
+	var requests []*rwRequest
+	for shard, seriesList := range shardSeries {
+		shardRequests := r.splitRequests(seriesList)
+		requests = append(requests, shardRequests...)
+
+		// Shards handle exports one at a time, in the order queued
+		if len(shardRequests) > 0 {
+			go func(shard chan []*rwRequest, shardRequests []*rwRequest) {
+				shard <- shardRequests
+			}(r.shards[shard], shardRequests)
+		}
+	}
+
+	var retryErr error
+	var failed []model.DataPoint
+	for _, request := range requests {
+		err := <-request.result
+		if err == nil {
+			continue
+		}
+		if model.IsPermanent(err) {
+			if core := r.Core(); core != nil {
+				core.PublishEvent(model.EventError, r.ID(), fmt.Errorf("dropped %d samples: %w", request.samples, err))
+			}
+			continue
+		}
+		retryErr = err
+		for _, s := range request.series {
+			failed = append(failed, s.points...)
+		}
+	}
+
+	if retryErr == nil || len(failed) == remoteWriteSamples(requests) {
+		return retryErr
+	}
+
+	part := model.NewDataBatch(batch.BatchType)
+	part.SourceID = batch.SourceID
+	for _, point := range failed {
+		part.AddPoint(point)
+	}
+	return &model.PartialError{Err: retryErr, Failed: part}
+}
+
+// remoteWriteSamples returns the number of samples in requests
+func remoteWriteSamples(requests []*rwRequest) int {
+	total := 0
+	for _, request := range requests {
+		total += request.samples
+	}
+	return total
+}
+
+// splitRequests cuts series into requests of at most max_samples_per_send
+// samples, splitting series with more samples than that across requests
+func (r *RemoteWriteOutput) splitRequests(series []*rwSeries) []*rwRequest {
+	var requests []*rwRequest
+	current := &rwRequest{result: make(chan error, 1)}
+
+	for _, s := range series {
+		samples, points := s.samples, s.points
+		for len(samples) > 0 {
+			n := r.maxSamplesPerSend - current.samples
+			if n > len(samples) {
+				n = len(samples)
+			}
+
+			current.series = append(current.series, &rwSeries{labels: s.labels, samples: samples[:n], points: points[:n]})
+			current.samples += n
+			samples = samples[n:]
+			points = points[n:]
+
+			if current.samples == r.maxSamplesPerSend {
+				requests = append(requests, current)
+				current = &rwRequest{result: make(chan error, 1)}
+			}
+		}
+	}
+
+	if current.samples > 0 {
+		requests = append(requests, current)
+	}
+	return requests
+}
+
+// runShard sends the requests of each export queued on a shard in order,
+// until the shard is closed. Once a request fails and will be retried, the
+// export's later requests are failed without being sent, as their samples
+// would otherwise arrive ahead of older ones.
+func (r *RemoteWriteOutput) runShard(exports <-chan []*rwRequest) {
+	defer r.wg.Done()
+
+	for requests := range exports {
+		var failed error
+		for _, request := range requests {
+			if failed != nil {
+				request.result <- failed
+				continue
+			}
+
+			err := r.write(request.series)
+			if err != nil && !model.IsPermanent(err) {
+				failed = err
+			}
+			request.result <- err
+		}
+	}
+}
+
+// write sends a write request. Throttling, server errors and network errors
+// can be retried; other rejections are permanent.
+func (r *RemoteWriteOutput) write(series []*rwSeries) error {
+	body := snappy.Encode(nil, marshalWriteRequest(series))
+	req, err := http.NewRequestWithContext(r.ctx, http.MethodPost, r.url, bytes.NewReader(body))
+	if err != nil {
+		return &model.PermanentError{Err: fmt.Errorf("failed to create write request: %w", err)}
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	for name, value := range r.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send write request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	err = fmt.Errorf("write to %s rejected with status %d: %s", r.url, resp.StatusCode, strings.TrimSpace(string(message)))
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return err
+	}
+	return &model.PermanentError{Err: err}
+}
@@ -0,0 +1,267 @@
+package outputs
+
+import (
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/sliink/collector/internal/model"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// writeServer is a remote-write endpoint that decodes requests and answers
+// with queued statuses, then 204
+type writeServer struct {
+	*httptest.Server
+	requests [][]*rwSeries
+	statuses []int
+	mutex    sync.Mutex
+}
+
+func newWriteServer(t *testing.T) *writeServer {
+	server := &writeServer{}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		assert.Equal(t, "0.1.0", r.Header.Get("X-Prometheus-Remote-Write-Version"))
+
+		compressed, _ := io.ReadAll(r.Body)
+		data, err := snappy.Decode(nil, compressed)
+		assert.NoError(t, err)
+
+		server.mutex.Lock()
+		status := http.StatusNoContent
+		if len(server.statuses) > 0 {
+			status, server.statuses = server.statuses[0], server.statuses[1:]
+		}
+		if status == http.StatusNoContent {
+			server.requests = append(server.requests, unmarshalWriteRequest(t, data))
+		}
+		server.mutex.Unlock()
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (s *writeServer) respond(statuses ...int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.statuses = statuses
+}
+
+func (s *writeServer) takeRequests() [][]*rwSeries {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	requests := s.requests
+	s.requests = nil
+	return requests
+}
+
+// unmarshalWriteRequest decodes the time series of a WriteRequest message
+func unmarshalWriteRequest(t *testing.T, data []byte) []*rwSeries {
+	var series []*rwSeries
+	forEachField(t, data, func(num protowire.Number, value []byte, _ uint64) {
+		s := &rwSeries{}
+		forEachField(t, value, func(num protowire.Number, value []byte, _ uint64) {
+			switch num {
+			case 1:
+				var label rwLabel
+				forEachField(t, value, func(num protowire.Number, value []byte, _ uint64) {
+					if num == 1 {
+						label.name = string(value)
+					} else {
+						label.value = string(value)
+					}
+				})
+				s.labels = append(s.labels, label)
+			case 2:
+				var sample rwSample
+				forEachField(t, value, func(num protowire.Number, _ []byte, scalar uint64) {
+					if num == 1 {
+						sample.value = math.Float64frombits(scalar)
+					} else {
+						sample.timestamp = int64(scalar)
+					}
+				})
+				s.samples = append(s.samples, sample)
+			}
+		})
+		series = append(series, s)
+	})
+	return series
+}
+
+// forEachField calls fn with each field of a message, passing length-delimited
+// values as bytes and fixed and varint values as a scalar
+func forEachField(t *testing.T, data []byte, fn func(num protowire.Number, value []byte, scalar uint64)) {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if !assert.True(t, n > 0) {
+			return
+		}
+		data = data[n:]
+
+		switch typ {
+		case protowire.BytesType:
+			value, n := protowire.ConsumeBytes(data)
+			fn(num, value, 0)
+			data = data[n:]
+		case protowire.Fixed64Type:
+			value, n := protowire.ConsumeFixed64(data)
+			fn(num, nil, value)
+			data = data[n:]
+		case protowire.VarintType:
+			value, n := protowire.ConsumeVarint(data)
+			fn(num, nil, value)
+			data = data[n:]
+		default:
+			t.Fatalf("unexpected wire type %d", typ)
+		}
+	}
+}
+
+func metricAt(name string, value float64, seconds int, dimensions map[string]string) *model.MetricPoint {
+	return &model.MetricPoint{
+		BaseDataPoint: model.BaseDataPoint{Timestamp: time.Unix(int64(seconds), 0)},
+		Name:          name,
+		Value:         value,
+		MetricType:    "gauge",
+		Dimensions:    dimensions,
+	}
+}
+
+func TestRemoteWriteOutputValidate(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{},
+		{"url": "localhost:9090/api/v1/write"},
+		{"url": "http://localhost:9090", "shards": float64(0)},
+		{"url": "http://localhost:9090", "max_samples_per_send": float64(-1)},
+		{"url": "http://localhost:9090", "timeout": "forever"},
+		{"url": "http://localhost:9090", "headers": map[string]interface{}{"X-Count": true}},
+	}
+
+	for _, config := range invalid {
+		output := NewRemoteWriteOutput("remote_write_output")
+		output.Configure(config)
+		assert.False(t, output.Validate(), "%v", config)
+		assert.False(t, output.Initialize(), "%v", config)
+	}
+}
+
+func TestRemoteWriteOutputSend(t *testing.T) {
+	server := newWriteServer(t)
+	output := startTestOutput(t, NewRemoteWriteOutput("remote_write_output"), map[string]interface{}{
+		"url":                  server.URL,
+		"shards":               float64(3),
+		"max_samples_per_send": float64(2),
+	})
+
+	batch := createMetricBatch(
+		metricAt("http.requests", 5, 20, map[string]string{"code": "200"}),
+		metricAt("http.requests", 3, 10, map[string]string{"code": "200"}),
+		metricAt("http.requests", 7, 30, map[string]string{"code": "200"}),
+		metricAt("temperature", 21.5, 10, map[string]string{"room-name": "lab"}),
+		metricAt("queue_depth", 4, 10, nil),
+	)
+	batch.AddPoint(createServiceBatch("api", "logs are skipped").Points[0])
+	assert.True(t, output.Send(batch))
+
+	samples := make(map[string][]rwSample)
+	for _, request := range server.takeRequests() {
+		count := 0
+		for _, s := range request {
+			count += len(s.samples)
+			assert.True(t, sort.SliceIsSorted(s.labels, func(i, j int) bool { return s.labels[i].name < s.labels[j].name }))
+			samples[labelsKey(s.labels)] = append(samples[labelsKey(s.labels)], s.samples...)
+		}
+		assert.LessOrEqual(t, count, 2)
+	}
+
+	// Samples of a series arrive in timestamp order, even across requests
+	assert.Equal(t, map[string][]rwSample{
+		`__name__=http_requests,code=200`:    {{3, 10000}, {5, 20000}, {7, 30000}},
+		`__name__=temperature,room_name=lab`: {{21.5, 10000}},
+		`__name__=queue_depth`:               {{4, 10000}},
+	}, samples)
+}
+
+func labelsKey(labels []rwLabel) string {
+	key := ""
+	for i, label := range labels {
+		if i > 0 {
+			key += ","
+		}
+		key += label.name + "=" + label.value
+	}
+	return key
+}
+
+func TestRemoteWriteOutputErrors(t *testing.T) {
+	server := newWriteServer(t)
+	core := &testCore{}
+	output := startTestOutput(t, NewRemoteWriteOutput("remote_write_output"), map[string]interface{}{
+		"url":                  server.URL,
+		"shards":               float64(1),
+		"max_samples_per_send": float64(1),
+	})
+	output.RegisterWithCore(core)
+	batch := createMetricBatch(metricAt("up", 1, 10, nil))
+
+	t.Run("Server errors and throttling are returned", func(t *testing.T) {
+		for _, status := range []int{http.StatusInternalServerError, http.StatusTooManyRequests} {
+			server.respond(status)
+			err := output.Export(batch)
+			assert.Error(t, err)
+			assert.False(t, model.IsPermanent(err))
+			assert.Empty(t, server.takeRequests())
+		}
+
+		// The batch goes through once the endpoint recovers
+		assert.True(t, output.Send(batch))
+		assert.Len(t, server.takeRequests(), 1)
+	})
+
+	t.Run("Only failed requests are retried", func(t *testing.T) {
+		batch := createMetricBatch(
+			metricAt("up", 1, 10, nil),
+			metricAt("up", 1, 20, nil),
+			metricAt("up", 0, 30, nil),
+		)
+
+		// The requests after a failure on the same shard are not sent
+		server.respond(http.StatusNoContent, http.StatusServiceUnavailable)
+		err := output.Export(batch)
+		var partial *model.PartialError
+		assert.Len(t, server.takeRequests(), 1)
+		if assert.ErrorAs(t, err, &partial) {
+			assert.Equal(t, batch.Points[1:], partial.Failed.Points)
+			assert.NoError(t, output.Export(partial.Failed))
+			assert.Len(t, server.takeRequests(), 2)
+		}
+	})
+
+	t.Run("Client errors are dropped", func(t *testing.T) {
+		server.respond(http.StatusBadRequest)
+		assert.NoError(t, output.Export(batch))
+		assert.Empty(t, server.takeRequests())
+
+		core.mutex.Lock()
+		defer core.mutex.Unlock()
+		assert.Len(t, core.events, 1)
+		assert.Contains(t, core.events[0].(error).Error(), "dropped 1 samples")
+	})
+
+	t.Run("Stopped outputs fail", func(t *testing.T) {
+		output.Stop()
+		assert.Error(t, output.Export(batch))
+	})
+}
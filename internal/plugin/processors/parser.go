@@ -14,6 +14,12 @@ type Parser struct {
 	patterns []*regexp.Regexp
 }
 
+func init() {
+	plugin.RegisterStandardProcessor("parser", func(id string) model.ProcessorPlugin {
+		return NewParser(id)
+	})
+}
+
 // NewParser creates a new parser plugin
 func NewParser(id string) *Parser {
 	return &Parser{
@@ -1,13 +1,53 @@
 package plugin
 
 import (
+	"fmt"
+
 	"github.com/sliink/collector/internal/model"
 )
 
+// Standard plugin creators, populated by the built-in plugin packages from init
+var (
+	standardInputs     = make(map[string]func(id string) model.InputPlugin)
+	standardProcessors = make(map[string]func(id string) model.ProcessorPlugin)
+	standardOutputs    = make(map[string]func(id string) model.OutputPlugin)
+)
+
+// PluginRegistrar accepts plugins created from configuration
+type PluginRegistrar interface {
+	// RegisterPlugin registers a configured plugin
+	RegisterPlugin(p model.Plugin) error
+}
+
+// RegisterStandardInput makes an input plugin available under a type name
+func RegisterStandardInput(name string, creator func(id string) model.InputPlugin) {
+	standardInputs[name] = creator
+}
+
+// RegisterStandardProcessor makes a processor plugin available under a type name
+func RegisterStandardProcessor(name string, creator func(id string) model.ProcessorPlugin) {
+	standardProcessors[name] = creator
+}
+
+// RegisterStandardOutput makes an output plugin available under a type name
+func RegisterStandardOutput(name string, creator func(id string) model.OutputPlugin) {
+	standardOutputs[name] = creator
+}
+
 // RegisterStandardPlugins registers all standard plugins with the factory
 func RegisterStandardPlugins(factory *PluginFactory) {
-	// Register standard plugins
-	// Implementations will be imported by specific modules
+	// Implementations register themselves when their package is imported
+	for name, creator := range standardInputs {
+		factory.RegisterInputPlugin(name, creator)
+	}
+
+	for name, creator := range standardProcessors {
+		factory.RegisterProcessorPlugin(name, creator)
+	}
+
+	for name, creator := range standardOutputs {
+		factory.RegisterOutputPlugin(name, creator)
+	}
 }
 
 // CreateStandardPlugins creates a set of standard plugins from configuration
@@ -16,63 +56,96 @@ func CreateStandardPlugins(config map[string]interface{}) ([]model.Plugin, error
 	RegisterStandardPlugins(factory)
 
 	var plugins []model.Plugin
+	seen := make(map[string]bool)
 
-	// Process input plugins
-	if inputsConf, ok := config["inputs"].([]interface{}); ok {
-		for _, inputConf := range inputsConf {
-			if inputMap, ok := inputConf.(map[string]interface{}); ok {
-				id, _ := inputMap["id"].(string)
-				typeName, _ := inputMap["type"].(string)
-				pluginConf, _ := inputMap["config"].(map[string]interface{})
-
-				plugin, err := factory.CreatePlugin(model.InputPluginType, typeName, id)
-				if err != nil {
-					continue
-				}
-
-				plugin.Configure(pluginConf)
-				plugins = append(plugins, plugin)
-			}
-		}
+	sections := []struct {
+		key        string
+		pluginType model.PluginType
+	}{
+		{"inputs", model.InputPluginType},
+		{"processors", model.ProcessorPluginType},
+		{"outputs", model.OutputPluginType},
 	}
 
-	// Process processor plugins
-	if processorsConf, ok := config["processors"].([]interface{}); ok {
-		for _, processorConf := range processorsConf {
-			if processorMap, ok := processorConf.(map[string]interface{}); ok {
-				id, _ := processorMap["id"].(string)
-				typeName, _ := processorMap["type"].(string)
-				pluginConf, _ := processorMap["config"].(map[string]interface{})
-
-				plugin, err := factory.CreatePlugin(model.ProcessorPluginType, typeName, id)
-				if err != nil {
-					continue
-				}
-
-				plugin.Configure(pluginConf)
-				plugins = append(plugins, plugin)
+	for _, section := range sections {
+		entries, exists := config[section.key]
+		if !exists {
+			continue
+		}
+
+		entryList, ok := entries.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("plugins.%s must be a list", section.key)
+		}
+
+		for i, entry := range entryList {
+			plugin, err := createPlugin(factory, section.pluginType, entry)
+			if err != nil {
+				return nil, fmt.Errorf("plugins.%s[%d]: %w", section.key, i, err)
+			}
+
+			if seen[plugin.ID()] {
+				return nil, fmt.Errorf("plugins.%s[%d]: duplicate plugin id: %s", section.key, i, plugin.ID())
 			}
+			seen[plugin.ID()] = true
+
+			plugins = append(plugins, plugin)
 		}
 	}
 
-	// Process output plugins
-	if outputsConf, ok := config["outputs"].([]interface{}); ok {
-		for _, outputConf := range outputsConf {
-			if outputMap, ok := outputConf.(map[string]interface{}); ok {
-				id, _ := outputMap["id"].(string)
-				typeName, _ := outputMap["type"].(string)
-				pluginConf, _ := outputMap["config"].(map[string]interface{})
-
-				plugin, err := factory.CreatePlugin(model.OutputPluginType, typeName, id)
-				if err != nil {
-					continue
-				}
-
-				plugin.Configure(pluginConf)
-				plugins = append(plugins, plugin)
-			}
+	return plugins, nil
+}
+
+// LoadStandardPlugins creates plugins from configuration and registers each with the registrar
+func LoadStandardPlugins(config map[string]interface{}, registrar PluginRegistrar) ([]model.Plugin, error) {
+	plugins, err := CreateStandardPlugins(config)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range plugins {
+		if err := registrar.RegisterPlugin(p); err != nil {
+			return nil, fmt.Errorf("failed to register plugin %s: %w", p.ID(), err)
 		}
 	}
 
 	return plugins, nil
-}
\ No newline at end of file
+}
+
+// createPlugin instantiates and configures a single plugin from its config entry
+func createPlugin(factory *PluginFactory, pluginType model.PluginType, entry interface{}) (model.Plugin, error) {
+	entryMap, ok := entry.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("plugin entry must be an object")
+	}
+
+	id, _ := entryMap["id"].(string)
+	if id == "" {
+		return nil, fmt.Errorf("plugin id is required")
+	}
+
+	typeName, _ := entryMap["type"].(string)
+	if typeName == "" {
+		return nil, fmt.Errorf("plugin type is required for %s", id)
+	}
+
+	// Plugins without a config block get an empty one
+	pluginConf := make(map[string]interface{})
+	if rawConf, exists := entryMap["config"]; exists && rawConf != nil {
+		pluginConf, ok = rawConf.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("config for plugin %s must be an object", id)
+		}
+	}
+
+	plugin, err := factory.CreatePlugin(pluginType, typeName, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create plugin %s: %w", id, err)
+	}
+
+	if !plugin.Configure(pluginConf) {
+		return nil, fmt.Errorf("failed to configure plugin %s", id)
+	}
+
+	return plugin, nil
+}
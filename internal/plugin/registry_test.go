@@ -0,0 +1,182 @@
+package plugin
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/sliink/collector/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// testInput is a minimal input plugin for factory tests
+type testInput struct {
+	BasePlugin
+}
+
+func (t *testInput) Initialize() bool            { return true }
+func (t *testInput) Start() bool                 { return true }
+func (t *testInput) Stop() bool                  { return true }
+func (t *testInput) Collect() []*model.DataBatch { return nil }
+
+// testOutput is a minimal output plugin whose Configure can be made to fail
+type testOutput struct {
+	BasePlugin
+}
+
+func (t *testOutput) Initialize() bool                 { return true }
+func (t *testOutput) Start() bool                      { return true }
+func (t *testOutput) Stop() bool                       { return true }
+func (t *testOutput) Send(batch *model.DataBatch) bool { return true }
+
+func (t *testOutput) Configure(config map[string]interface{}) bool {
+	if reject, ok := config["reject"].(bool); ok && reject {
+		return false
+	}
+	return t.BasePlugin.Configure(config)
+}
+
+// mockRegistrar records registered plugins and can fail on a given ID
+type mockRegistrar struct {
+	registered []string
+	failOn     string
+}
+
+func (m *mockRegistrar) RegisterPlugin(p model.Plugin) error {
+	if p.ID() == m.failOn {
+		return fmt.Errorf("rejected")
+	}
+	m.registered = append(m.registered, p.ID())
+	return nil
+}
+
+func init() {
+	RegisterStandardInput("test_input", func(id string) model.InputPlugin {
+		return &testInput{BasePlugin: NewBasePlugin(id, "Test Input", model.InputPluginType)}
+	})
+	RegisterStandardOutput("test_output", func(id string) model.OutputPlugin {
+		return &testOutput{BasePlugin: NewBasePlugin(id, "Test Output", model.OutputPluginType)}
+	})
+}
+
+func TestRegisterStandardPlugins(t *testing.T) {
+	factory := NewPluginFactory()
+	RegisterStandardPlugins(factory)
+
+	t.Run("Registered types can be created", func(t *testing.T) {
+		p, err := factory.CreatePlugin(model.InputPluginType, "test_input", "in")
+		assert.NoError(t, err)
+		assert.Equal(t, "in", p.ID())
+	})
+
+	t.Run("Type names are scoped by plugin type", func(t *testing.T) {
+		_, err := factory.CreatePlugin(model.OutputPluginType, "test_input", "in")
+		assert.Error(t, err)
+	})
+}
+
+func TestCreateStandardPlugins(t *testing.T) {
+	t.Run("Creates and configures plugins from config", func(t *testing.T) {
+		config := map[string]interface{}{
+			"inputs": []interface{}{
+				map[string]interface{}{
+					"id":     "in",
+					"type":   "test_input",
+					"config": map[string]interface{}{"paths": []interface{}{"/tmp/a.log"}},
+				},
+			},
+			"outputs": []interface{}{
+				map[string]interface{}{"id": "out", "type": "test_output"},
+			},
+		}
+
+		plugins, err := CreateStandardPlugins(config)
+		assert.NoError(t, err)
+		assert.Len(t, plugins, 2)
+		assert.Equal(t, "in", plugins[0].ID())
+		assert.Equal(t, model.InputPluginType, plugins[0].GetType())
+		assert.Equal(t, []interface{}{"/tmp/a.log"}, plugins[0].(*testInput).Config["paths"])
+		assert.Equal(t, "out", plugins[1].ID())
+		assert.NotNil(t, plugins[1].(*testOutput).Config)
+	})
+
+	t.Run("Unknown type is an error", func(t *testing.T) {
+		config := map[string]interface{}{
+			"inputs": []interface{}{
+				map[string]interface{}{"id": "in", "type": "does_not_exist"},
+			},
+		}
+
+		_, err := CreateStandardPlugins(config)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown input plugin: does_not_exist")
+	})
+
+	t.Run("Duplicate IDs are an error", func(t *testing.T) {
+		config := map[string]interface{}{
+			"inputs": []interface{}{
+				map[string]interface{}{"id": "dup", "type": "test_input"},
+			},
+			"outputs": []interface{}{
+				map[string]interface{}{"id": "dup", "type": "test_output"},
+			},
+		}
+
+		_, err := CreateStandardPlugins(config)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "duplicate plugin id: dup")
+	})
+
+	t.Run("Configure failure is an error", func(t *testing.T) {
+		config := map[string]interface{}{
+			"outputs": []interface{}{
+				map[string]interface{}{
+					"id":     "out",
+					"type":   "test_output",
+					"config": map[string]interface{}{"reject": true},
+				},
+			},
+		}
+
+		_, err := CreateStandardPlugins(config)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to configure plugin out")
+	})
+
+	t.Run("Missing id or type is an error", func(t *testing.T) {
+		_, err := CreateStandardPlugins(map[string]interface{}{
+			"inputs": []interface{}{map[string]interface{}{"type": "test_input"}},
+		})
+		assert.Error(t, err)
+
+		_, err = CreateStandardPlugins(map[string]interface{}{
+			"inputs": []interface{}{map[string]interface{}{"id": "in"}},
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadStandardPlugins(t *testing.T) {
+	config := map[string]interface{}{
+		"inputs": []interface{}{
+			map[string]interface{}{"id": "in", "type": "test_input"},
+		},
+		"outputs": []interface{}{
+			map[string]interface{}{"id": "out", "type": "test_output"},
+		},
+	}
+
+	t.Run("Registers every created plugin", func(t *testing.T) {
+		registrar := &mockRegistrar{}
+		plugins, err := LoadStandardPlugins(config, registrar)
+		assert.NoError(t, err)
+		assert.Len(t, plugins, 2)
+		assert.Equal(t, []string{"in", "out"}, registrar.registered)
+	})
+
+	t.Run("Registration failure is reported", func(t *testing.T) {
+		registrar := &mockRegistrar{failOn: "out"}
+		_, err := LoadStandardPlugins(config, registrar)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to register plugin out")
+	})
+}
@@ -0,0 +1,104 @@
+package plugin
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// ServerTLSConfig builds a TLS configuration for a listening plugin from a
+// "tls" config section with cert_file, key_file, an optional client_ca_file
+// that requires clients to present a certificate signed by it, and an
+// optional min_version of "1.2" or "1.3"
+func ServerTLSConfig(config map[string]interface{}) (*tls.Config, error) {
+	certFile, _ := config["cert_file"].(string)
+	keyFile, _ := config["key_file"].(string)
+	if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("tls requires cert_file and key_file")
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if minVersion, ok := config["min_version"].(string); ok {
+		switch minVersion {
+		case "1.2":
+			tlsConfig.MinVersion = tls.VersionTLS12
+		case "1.3":
+			tlsConfig.MinVersion = tls.VersionTLS13
+		default:
+			return nil, fmt.Errorf("invalid tls min_version: %s", minVersion)
+		}
+	}
+
+	if caFile, ok := config["client_ca_file"].(string); ok && caFile != "" {
+		pool, err := loadCertPool(caFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
+}
+
+// ClientTLSConfig builds a TLS configuration for a plugin that connects out
+// from a "tls" config section with an optional ca_file to verify the server
+// with, an optional cert_file and key_file to present to it, and
+// insecure_skip_verify to disable verification
+func ClientTLSConfig(config map[string]interface{}) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	certFile, _ := config["cert_file"].(string)
+	keyFile, _ := config["key_file"].(string)
+	if (certFile == "") != (keyFile == "") {
+		return nil, fmt.Errorf("tls requires both cert_file and key_file for a client certificate")
+	}
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if caFile, ok := config["ca_file"].(string); ok && caFile != "" {
+		pool, err := loadCertPool(caFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if skip, ok := config["insecure_skip_verify"].(bool); ok {
+		tlsConfig.InsecureSkipVerify = skip
+	}
+
+	return tlsConfig, nil
+}
+
+// loadCertPool reads PEM certificates into a pool
+func loadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA file: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in CA file: %s", path)
+	}
+
+	return pool, nil
+}